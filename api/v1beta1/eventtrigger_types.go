@@ -65,7 +65,8 @@ type ETRefObject struct {
 	Kind string `json:"kind"`
 
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
-	// The namespace that the reference object exists in.
+	// The namespace that the reference object exists in.  If set, it must be
+	// the namespace of the EventTrigger.
 	Namespace string `json:"namespace,omitempty"`
 
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
@@ -144,6 +145,56 @@ type EventTriggerStatus struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=status
 	// Status about each of the reference objects
 	References []ETRefObjectStatus `json:"references"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// Conditions for EventTrigger
+	Conditions []EventTriggerCondition `json:"conditions,omitempty"`
+}
+
+// EventTriggerCondition defines condition for EventTrigger
+type EventTriggerCondition struct {
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// Type is the type of the condition
+	Type EventTriggerConditionType `json:"type"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// Status is the status of the condition
+	// can be True, False or Unknown
+	Status corev1.ConditionStatus `json:"status"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// Last time the condition transitioned from one status to another.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// A one word, CamelCase, reason for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// A human readable message with details about the condition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+type EventTriggerConditionType string
+
+const (
+	// EventTriggerPaused indicates that the reconcile of the EventTrigger, or
+	// some parts of it, was stopped with the pause-reconcile annotation.
+	EventTriggerPaused EventTriggerConditionType = "Paused"
+)
+
+// Fixed index entries for each condition.
+const (
+	EventTriggerPausedIndex = iota
+)
+
+// EventTriggerConditionIndexMap is a map of the EventTriggerConditionType to
+// its index in the condition array
+var EventTriggerConditionIndexMap = map[EventTriggerConditionType]int{
+	EventTriggerPaused: EventTriggerPausedIndex,
 }
 
 // ETRefObjectStatus provides status information about a single reference object
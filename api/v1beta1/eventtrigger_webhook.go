@@ -106,6 +106,18 @@ func (e *EventTrigger) validateVerticaDBReferences(allErrs field.ErrorList) fiel
 			allErrs = append(allErrs, err)
 		}
 
+		// The reference must be in the namespace of the EventTrigger. We
+		// don't let an EventTrigger watch, and run jobs for, a VerticaDB in
+		// a namespace that the user may not have access to.
+		if ref.Object.Namespace != "" && ref.Object.Namespace != e.Namespace {
+			err := field.Invalid(
+				field.NewPath("spec").Child("reference").Child("object").Child("namespace"),
+				ref.Object.Namespace,
+				fmt.Sprintf("object.namespace must be empty or the namespace of the EventTrigger: %s", e.Namespace),
+			)
+			allErrs = append(allErrs, err)
+		}
+
 		if ref.Object.APIVersion != GroupVersion.String() {
 			err := field.Invalid(
 				field.NewPath("spec").Child("reference").Child("object").Child("apiVersion"),
@@ -27,6 +27,15 @@ var _ = Describe("eventtrigger_webhook", func() {
 		Expect(et.ValidateCreate()).ShouldNot(Succeed())
 	})
 
+	It("should fail if reference object is in another namespace", func() {
+		et := MakeET()
+		et.Spec.References[0].Object.Namespace = et.Namespace
+		Expect(et.ValidateCreate()).Should(Succeed())
+		et.Spec.References[0].Object.Namespace = "other-ns"
+		Expect(et.ValidateCreate()).ShouldNot(Succeed())
+		Expect(et.ValidateUpdate(et)).ShouldNot(Succeed())
+	})
+
 	It("should fail on multiple reference objects", func() {
 		et := MakeET()
 		name := MakeVDBName().Name
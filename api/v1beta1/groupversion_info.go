@@ -31,6 +31,7 @@ const (
 	VerticaDBKind         = "VerticaDB"
 	VerticaAutoscalerKind = "VerticaAutoscaler"
 	EventTriggerKind      = "EventTrigger"
+	VerticaBackupKind     = "VerticaBackup"
 )
 
 var (
@@ -47,4 +48,5 @@ var (
 	GkVDB = schema.GroupKind{Group: Group, Kind: VerticaDBKind}
 	GkVAS = schema.GroupKind{Group: Group, Kind: VerticaAutoscalerKind}
 	GkET  = schema.GroupKind{Group: Group, Kind: EventTriggerKind}
+	GkVB  = schema.GroupKind{Group: Group, Kind: VerticaBackupKind}
 )
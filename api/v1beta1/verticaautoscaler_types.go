@@ -120,6 +120,16 @@ type VerticaAutoscalerCondition struct {
 	// Last time the condition transitioned from one status to another.
 	// +optional
 	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// A one word, CamelCase, reason for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// A human readable message with details about the condition.
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 type VerticaAutoscalerConditionType string
@@ -127,13 +137,24 @@ type VerticaAutoscalerConditionType string
 const (
 	// TargetSizeInitialized indicates whether the operator has initialized targetSize in the spec
 	TargetSizeInitialized VerticaAutoscalerConditionType = "TargetSizeInitialized"
+	// AutoscalerPaused indicates that the reconcile of the VerticaAutoscaler,
+	// or some parts of it, was stopped with the pause-reconcile annotation.
+	AutoscalerPaused VerticaAutoscalerConditionType = "Paused"
 )
 
 // Fixed index entries for each condition.
 const (
 	TargetSizeInitializedIndex = iota
+	AutoscalerPausedIndex
 )
 
+// VerticaAutoscalerConditionIndexMap is a map of the
+// VerticaAutoscalerConditionType to its index in the condition array
+var VerticaAutoscalerConditionIndexMap = map[VerticaAutoscalerConditionType]int{
+	TargetSizeInitialized: TargetSizeInitializedIndex,
+	AutoscalerPaused:      AutoscalerPausedIndex,
+}
+
 //+kubebuilder:object:root=true
 //+kubebuilder:resource:categories=all;vertica,shortName=vas
 //+kubebuilder:subresource:status
@@ -0,0 +1,362 @@
+/*
+Copyright [2021-2023] Open Text.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//nolint:lll
+package v1beta1
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// VerticaBackupSpec defines the desired state of VerticaBackup
+type VerticaBackupSpec struct {
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:text"
+	// The name of the VerticaDB CR that will be backed up.  The VerticaDB
+	// object must exist in the same namespace as this object.
+	VerticaDBName string `json:"verticaDBName"`
+
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// The location where the backup is stored.
+	Target BackupTarget `json:"target"`
+
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:text"
+	// The name of the snapshot.  Each restore point that is created has this
+	// name as a prefix.  If omitted, the name of this object is used.
+	SnapshotName string `json:"snapshotName,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:text"
+	// The name of the subcluster to run vbr from.  If omitted, vbr is run in
+	// any pod that has an up vertica node.
+	Subcluster string `json:"subcluster,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:text"
+	// A cron expression to take backups periodically.  It uses the standard
+	// five field format (minute, hour, day of month, month and day of week)
+	// and is evaluated in UTC.  Descriptors such as @daily are also accepted.
+	// If omitted, a single backup is taken.  Scheduled backups are suspended
+	// while the VerticaDB is being upgraded.
+	Schedule string `json:"schedule,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// Controls how long the restore points for the snapshot are kept.  Restore
+	// points that fall outside of the policy are removed after each
+	// successful backup.  If omitted, restore points are never removed.
+	Retention BackupRetention `json:"retention,omitempty"`
+}
+
+// BackupRetention describes which restore points to keep.  When both fields
+// are set, a restore point is kept if either one of them applies to it.  The
+// most recent restore point is never removed.
+type BackupRetention struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum:=0
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:number"
+	// The number of most recent restore points to keep.  A value of 0 means
+	// restore points are not kept based on their count.
+	KeepLast int `json:"keepLast,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum:=0
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:number"
+	// The number of days to keep a restore point for.  A value of 0 means
+	// restore points are not kept based on their age.
+	KeepDays int `json:"keepDays,omitempty"`
+}
+
+// BackupTarget describes the object store where backups are written to.
+type BackupTarget struct {
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:text"
+	// The path to the backup location.  This must be a path in an object
+	// store.  It must start with s3://, gs:// or azb://.
+	Path string `json:"path"`
+
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:text"
+	// The URL to the object store endpoint.  The endpoint must be prefaced
+	// with http:// or https:// to know what protocol to connect with.  This
+	// has the same meaning as communal.endpoint in the VerticaDB.
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:io.kubernetes:Secret"
+	// The name of a secret that contains the credentials to connect to the
+	// backup location.  This secret must have the same layout as the secret
+	// used for communal.credentialSecret in the VerticaDB.  If omitted, the
+	// credential secret of the VerticaDB is used.
+	CredentialSecret string `json:"credentialSecret,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="us-east-1"
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:text"
+	// The region containing the bucket.  This only applies to S3 and Google
+	// Cloud Storage.
+	Region string `json:"region,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:text"
+	// The absolute path to a certificate bundle of trusted CAs.  This CA bundle
+	// is used when establishing TLS connections to the backup location.
+	CaFile string `json:"caFile,omitempty"`
+}
+
+// VerticaBackupStatus defines the observed state of VerticaBackup
+type VerticaBackupStatus struct {
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// Conditions for VerticaBackup
+	Conditions []VerticaBackupCondition `json:"conditions,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// The ID of the restore point that was created by the last successful
+	// backup.  This is the name of the archive that vbr generated.
+	RestorePointID string `json:"restorePointID,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// The pod that vbr was last run in.
+	PodName string `json:"podName,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// The time the last backup was started.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// The time the last backup completed successfully.
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// The scheduled time of the last backup that completed successfully.  This
+	// only applies if a schedule is set.
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// The next time a backup is scheduled to run.  This only applies if a
+	// schedule is set.
+	NextScheduleTime *metav1.Time `json:"nextScheduleTime,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// The restore points for the snapshot that exist in the backup location,
+	// ordered from oldest to newest.  This is refreshed after each backup.
+	RestorePoints []string `json:"restorePoints,omitempty"`
+}
+
+// VerticaBackupCondition defines condition for VerticaBackup
+type VerticaBackupCondition struct {
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// Type is the type of the condition
+	Type VerticaBackupConditionType `json:"type"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// Status is the status of the condition
+	// can be True, False or Unknown
+	Status corev1.ConditionStatus `json:"status"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// Last time the condition transitioned from one status to another.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// A one-word reason for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// A human readable message with details about the last transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+type VerticaBackupConditionType string
+
+const (
+	// BackupInProgress indicates that vbr is currently running
+	BackupInProgress VerticaBackupConditionType = "BackupInProgress"
+	// BackupComplete indicates that the last backup finished successfully
+	BackupComplete VerticaBackupConditionType = "BackupComplete"
+	// BackupFailed indicates that the last backup attempt failed
+	BackupFailed VerticaBackupConditionType = "BackupFailed"
+	// ScheduleSuspended indicates that scheduled backups are not being taken
+	// because the VerticaDB is being upgraded
+	ScheduleSuspended VerticaBackupConditionType = "ScheduleSuspended"
+)
+
+// Fixed index entries for each condition.
+const (
+	BackupInProgressIndex = iota
+	BackupCompleteIndex
+	BackupFailedIndex
+	ScheduleSuspendedIndex
+)
+
+// VerticaBackupConditionIndexMap is a map of the VerticaBackupConditionType to its
+// index in the condition array
+var VerticaBackupConditionIndexMap = map[VerticaBackupConditionType]int{
+	BackupInProgress:  BackupInProgressIndex,
+	BackupComplete:    BackupCompleteIndex,
+	BackupFailed:      BackupFailedIndex,
+	ScheduleSuspended: ScheduleSuspendedIndex,
+}
+
+// VerticaBackupConditionNameMap is the reverse of VerticaBackupConditionIndexMap.
+// It maps an index to the condition name.
+var VerticaBackupConditionNameMap = map[int]VerticaBackupConditionType{
+	BackupInProgressIndex:  BackupInProgress,
+	BackupCompleteIndex:    BackupComplete,
+	BackupFailedIndex:      BackupFailed,
+	ScheduleSuspendedIndex: ScheduleSuspended,
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:categories=all;vertica,shortName=vb
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="VerticaDB",type="string",JSONPath=".spec.verticaDBName"
+//+kubebuilder:printcolumn:name="Restore Point",type="string",JSONPath=".status.restorePointID"
+//+kubebuilder:printcolumn:name="Schedule",type="string",JSONPath=".spec.schedule"
+//+kubebuilder:printcolumn:name="Last Schedule",type="date",JSONPath=".status.lastScheduleTime"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+//+operator-sdk:csv:customresourcedefinitions:resources={{VerticaDB,vertica.com/v1beta1,""}}
+
+// VerticaBackup is a CR that takes a backup of a VerticaDB using vbr.
+type VerticaBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VerticaBackupSpec   `json:"spec,omitempty"`
+	Status VerticaBackupStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// VerticaBackupList contains a list of VerticaBackup
+type VerticaBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VerticaBackup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VerticaBackup{}, &VerticaBackupList{})
+}
+
+// MakeVBName is a helper that creates a sample name for test purposes
+func MakeVBName() types.NamespacedName {
+	return types.NamespacedName{Name: "vertica-vb-sample", Namespace: "default"}
+}
+
+// MakeVB is a helper that constructs a fully formed VerticaBackup struct using the sample name.
+// This is intended for test purposes.
+func MakeVB() *VerticaBackup {
+	vbNm := MakeVBName()
+	vdbNm := MakeVDBName()
+	return &VerticaBackup{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: GroupVersion.String(),
+			Kind:       VerticaBackupKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        vbNm.Name,
+			Namespace:   vbNm.Namespace,
+			UID:         "abcdef-ghi-vb",
+			Annotations: make(map[string]string),
+		},
+		Spec: VerticaBackupSpec{
+			VerticaDBName: vdbNm.Name,
+			Target: BackupTarget{
+				Path:             "s3://backup-bucket/db",
+				Endpoint:         "https://s3.amazonaws.com",
+				CredentialSecret: "s3-backup-creds",
+			},
+		},
+	}
+}
+
+// ExtractNamespacedName gets the name and returns it as a NamespacedName
+func (v *VerticaBackup) ExtractNamespacedName() types.NamespacedName {
+	return types.NamespacedName{
+		Name:      v.ObjectMeta.Name,
+		Namespace: v.ObjectMeta.Namespace,
+	}
+}
+
+// GetSnapshotName returns the name of the snapshot to use in the vbr config
+func (v *VerticaBackup) GetSnapshotName() string {
+	if v.Spec.SnapshotName != "" {
+		return v.Spec.SnapshotName
+	}
+	// vbr only allows alphanumeric characters, underscores and dashes in the
+	// snapshot name. Object names can have periods, so we convert them.
+	return strings.ReplaceAll(v.Name, ".", "_")
+}
+
+// IsS3 returns true if the backup target is in AWS S3
+func (v *VerticaBackup) IsS3() bool {
+	return v.Spec.Target.IsS3()
+}
+
+// IsGCloud returns true if the backup target is in Google Cloud Storage
+func (v *VerticaBackup) IsGCloud() bool {
+	return v.Spec.Target.IsGCloud()
+}
+
+// IsAzure returns true if the backup target is in Azure Blob Storage
+func (v *VerticaBackup) IsAzure() bool {
+	return v.Spec.Target.IsAzure()
+}
+
+// IsS3 returns true if the target is in AWS S3
+func (b *BackupTarget) IsS3() bool {
+	return strings.HasPrefix(b.Path, S3Prefix)
+}
+
+// IsGCloud returns true if the target is in Google Cloud Storage
+func (b *BackupTarget) IsGCloud() bool {
+	return strings.HasPrefix(b.Path, GCloudPrefix)
+}
+
+// IsAzure returns true if the target is in Azure Blob Storage
+func (b *BackupTarget) IsAzure() bool {
+	return strings.HasPrefix(b.Path, AzurePrefix)
+}
+
+// IsKnownObjectStore returns true if the target is in one of the object
+// stores that vbr can write backups to
+func (b *BackupTarget) IsKnownObjectStore() bool {
+	return b.IsS3() || b.IsGCloud() || b.IsAzure()
+}
+
+// IsScheduled returns true if backups are taken periodically
+func (v *VerticaBackup) IsScheduled() bool {
+	return v.Spec.Schedule != ""
+}
+
+// IsConditionSet will return true if the condition is set to true
+func (v *VerticaBackup) IsConditionSet(condType VerticaBackupConditionType) bool {
+	inx, ok := VerticaBackupConditionIndexMap[condType]
+	if !ok || inx >= len(v.Status.Conditions) {
+		return false
+	}
+	return v.Status.Conditions[inx].Status == corev1.ConditionTrue
+}
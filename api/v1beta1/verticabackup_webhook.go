@@ -0,0 +1,175 @@
+/*
+Copyright [2021-2023] Open Text.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//nolint:lll
+package v1beta1
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/vertica/vertica-kubernetes/pkg/cron"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var verticabackuplog = logf.Log.WithName("verticabackup-resource")
+
+// validSnapshotName is the set of characters vbr accepts in a snapshot name
+var validSnapshotName = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// validRestorePointID matches the names vbr gives to restore points.  It is
+// the snapshot name with a timestamp appended to it.
+var validRestorePointID = regexp.MustCompile(`^[a-zA-Z0-9_-]+_[0-9]{8}_[0-9]{6}$`)
+
+func (v *VerticaBackup) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(v).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-vertica-com-v1beta1-verticabackup,mutating=true,failurePolicy=fail,sideEffects=None,groups=vertica.com,resources=verticabackups,verbs=create;update,versions=v1beta1,name=mverticabackup.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &VerticaBackup{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (v *VerticaBackup) Default() {
+	verticabackuplog.Info("default", "name", v.Name)
+
+	if v.Spec.Target.Region == "" && (v.IsS3() || v.IsGCloud()) {
+		v.Spec.Target.Region = DefaultS3Region
+	}
+}
+
+//+kubebuilder:webhook:path=/validate-vertica-com-v1beta1-verticabackup,mutating=false,failurePolicy=fail,sideEffects=None,groups=vertica.com,resources=verticabackups,verbs=create;update,versions=v1beta1,name=vverticabackup.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &VerticaBackup{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (v *VerticaBackup) ValidateCreate() error {
+	verticabackuplog.Info("validate create", "name", v.Name)
+
+	allErrs := v.validateSpec()
+	if allErrs == nil {
+		return nil
+	}
+	return apierrors.NewInvalid(GkVB, v.Name, allErrs)
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (v *VerticaBackup) ValidateUpdate(old runtime.Object) error {
+	verticabackuplog.Info("validate update", "name", v.Name)
+
+	allErrs := append(v.validateImmutableFields(old), v.validateSpec()...)
+	if allErrs == nil {
+		return nil
+	}
+	return apierrors.NewInvalid(GkVB, v.Name, allErrs)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (v *VerticaBackup) ValidateDelete() error {
+	verticabackuplog.Info("validate delete", "name", v.Name)
+
+	return nil
+}
+
+func (v *VerticaBackup) validateImmutableFields(old runtime.Object) field.ErrorList {
+	var allErrs field.ErrorList
+	oldObj := old.(*VerticaBackup)
+	// verticaDBName cannot change after creation
+	if v.Spec.VerticaDBName != oldObj.Spec.VerticaDBName {
+		err := field.Invalid(field.NewPath("spec").Child("verticaDBName"),
+			v.Spec.VerticaDBName,
+			"verticaDBName cannot change after creation")
+		allErrs = append(allErrs, err)
+	}
+	return allErrs
+}
+
+func (v *VerticaBackup) validateSpec() field.ErrorList {
+	allErrs := v.hasValidTargetPath(field.ErrorList{})
+	allErrs = v.hasValidSnapshotName(allErrs)
+	allErrs = v.hasValidSchedule(allErrs)
+	allErrs = v.hasValidRetention(allErrs)
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return allErrs
+}
+
+func (v *VerticaBackup) hasValidTargetPath(allErrs field.ErrorList) field.ErrorList {
+	if v.Spec.Target.IsKnownObjectStore() {
+		return allErrs
+	}
+	err := field.Invalid(field.NewPath("spec").Child("target").Child("path"),
+		v.Spec.Target.Path,
+		fmt.Sprintf("target.path must start with %s, %s or %s", S3Prefix, GCloudPrefix, AzurePrefix))
+	return append(allErrs, err)
+}
+
+func (v *VerticaBackup) hasValidSnapshotName(allErrs field.ErrorList) field.ErrorList {
+	if v.Spec.SnapshotName == "" || validSnapshotName.MatchString(v.Spec.SnapshotName) {
+		return allErrs
+	}
+	err := field.Invalid(field.NewPath("spec").Child("snapshotName"),
+		v.Spec.SnapshotName,
+		"snapshotName can only contain alphanumeric characters, underscores and dashes")
+	return append(allErrs, err)
+}
+
+func (v *VerticaBackup) hasValidSchedule(allErrs field.ErrorList) field.ErrorList {
+	if !v.IsScheduled() {
+		return allErrs
+	}
+	sched, err := cron.Parse(v.Spec.Schedule)
+	if err != nil {
+		err := field.Invalid(field.NewPath("spec").Child("schedule"),
+			v.Spec.Schedule,
+			fmt.Sprintf("schedule must be a valid cron expression: %s", err))
+		return append(allErrs, err)
+	}
+	if sched.Next(time.Now()).IsZero() {
+		err := field.Invalid(field.NewPath("spec").Child("schedule"),
+			v.Spec.Schedule,
+			"schedule never fires")
+		allErrs = append(allErrs, err)
+	}
+	return allErrs
+}
+
+func (v *VerticaBackup) hasValidRetention(allErrs field.ErrorList) field.ErrorList {
+	path := field.NewPath("spec").Child("retention")
+	if v.Spec.Retention.KeepLast < 0 {
+		err := field.Invalid(path.Child("keepLast"),
+			v.Spec.Retention.KeepLast,
+			"keepLast cannot be negative")
+		allErrs = append(allErrs, err)
+	}
+	if v.Spec.Retention.KeepDays < 0 {
+		err := field.Invalid(path.Child("keepDays"),
+			v.Spec.Retention.KeepDays,
+			"keepDays cannot be negative")
+		allErrs = append(allErrs, err)
+	}
+	return allErrs
+}
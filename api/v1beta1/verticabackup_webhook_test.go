@@ -0,0 +1,93 @@
+/*
+Copyright [2021-2023] Open Text.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("verticabackup_webhook", func() {
+	It("should succeed with all valid fields", func() {
+		vb := MakeVB()
+		Expect(vb.ValidateCreate()).Should(Succeed())
+		Expect(vb.ValidateUpdate(vb)).Should(Succeed())
+	})
+
+	It("should fail if the target path isn't in an object store", func() {
+		vb := MakeVB()
+		vb.Spec.Target.Path = "/backups/db"
+		Expect(vb.ValidateCreate()).ShouldNot(Succeed())
+		vb.Spec.Target.Path = "gs://bucket/db"
+		Expect(vb.ValidateCreate()).Should(Succeed())
+		vb.Spec.Target.Path = "azb://account/container/db"
+		Expect(vb.ValidateCreate()).Should(Succeed())
+	})
+
+	It("should fail if the snapshot name has invalid characters", func() {
+		vb := MakeVB()
+		vb.Spec.SnapshotName = "nightly.backup"
+		Expect(vb.ValidateCreate()).ShouldNot(Succeed())
+		vb.Spec.SnapshotName = "nightly_backup-1"
+		Expect(vb.ValidateCreate()).Should(Succeed())
+	})
+
+	It("should fail if the schedule isn't a valid cron expression", func() {
+		vb := MakeVB()
+		vb.Spec.Schedule = "0 25 * * *"
+		Expect(vb.ValidateCreate()).ShouldNot(Succeed())
+		vb.Spec.Schedule = "0 2 * * *"
+		Expect(vb.ValidateCreate()).Should(Succeed())
+		vb.Spec.Schedule = "@daily"
+		Expect(vb.ValidateCreate()).Should(Succeed())
+		// February 30th never happens
+		vb.Spec.Schedule = "0 0 30 2 *"
+		Expect(vb.ValidateCreate()).ShouldNot(Succeed())
+	})
+
+	It("should fail if the retention is negative", func() {
+		vb := MakeVB()
+		vb.Spec.Retention.KeepLast = -1
+		Expect(vb.ValidateCreate()).ShouldNot(Succeed())
+		vb.Spec.Retention.KeepLast = 7
+		vb.Spec.Retention.KeepDays = -1
+		Expect(vb.ValidateCreate()).ShouldNot(Succeed())
+		vb.Spec.Retention.KeepDays = 30
+		Expect(vb.ValidateCreate()).Should(Succeed())
+	})
+
+	It("should not allow verticaDBName to change", func() {
+		vbOrig := MakeVB()
+		vb := MakeVB()
+		vb.Spec.VerticaDBName = "other-db"
+		Expect(vb.ValidateUpdate(vbOrig)).ShouldNot(Succeed())
+	})
+
+	It("should default the region for s3", func() {
+		vb := MakeVB()
+		vb.Default()
+		Expect(vb.Spec.Target.Region).Should(Equal(DefaultS3Region))
+	})
+
+	It("should derive a valid snapshot name from the object name", func() {
+		vb := MakeVB()
+		vb.Name = "db.backup"
+		Expect(vb.GetSnapshotName()).Should(Equal("db_backup"))
+		vb.Spec.SnapshotName = "nightly"
+		Expect(vb.GetSnapshotName()).Should(Equal("nightly"))
+	})
+})
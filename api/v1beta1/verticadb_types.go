@@ -23,8 +23,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/vertica/vertica-kubernetes/pkg/cron"
 	"github.com/vertica/vertica-kubernetes/pkg/paths"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -81,6 +83,16 @@ type VerticaDBSpec struct {
 	// manual upgrade, without the operator interfering.
 	AutoRestartVertica bool `json:"autoRestartVertica"`
 
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch","urn:alm:descriptor:com.tectonic.ui:advanced"}
+	// When set to true, the operator stops the database and scales the
+	// statefulset of every subcluster to zero pods.  The sizes of the
+	// subclusters in the spec are kept, so setting this back to false will
+	// bring the pods back and restart the database.  If the local data of the
+	// pods was lost while hibernated, an Eon Mode database is revived from
+	// communal storage.
+	Hibernate bool `json:"hibernate,omitempty"`
+
 	// +kubebuilder:default:="vertdb"
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:advanced"
@@ -102,8 +114,20 @@ type VerticaDBSpec struct {
 	// database's superuser. If this is not set, then we assume no such password
 	// is set for the database. If this is set, it is up the user to create this
 	// secret before deployment. The secret must have a key named password.
+	// If the password in the secret changes after the database is created,
+	// the operator will change the password in the database to match.
 	SuperuserPasswordSecret string `json:"superuserPasswordSecret,omitempty"`
 
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=dbadmin
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:advanced"
+	// The name of the database's superuser.  The operator authenticates as this
+	// user when it sends requests to the Vertica HTTP server, and it changes
+	// the password of this user when superuserPasswordSecret changes.  This
+	// only needs to be set if the database was created with a superuser other
+	// than dbadmin.
+	SuperuserName string `json:"superuserName,omitempty"`
+
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:io.kubernetes:Secret"
 	// The name of a secret that contains the contents of license files. The
@@ -126,9 +150,10 @@ type VerticaDBSpec struct {
 
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default:=Create
-	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:Create","urn:alm:descriptor:com.tectonic.ui:select:Revive","urn:alm:descriptor:com.tectonic.ui:select:ScheduleOnly"}
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:Create","urn:alm:descriptor:com.tectonic.ui:select:Revive","urn:alm:descriptor:com.tectonic.ui:select:Restore","urn:alm:descriptor:com.tectonic.ui:select:ScheduleOnly"}
 	// The initialization policy defines how to setup the database.  Available
-	// options are to create a new database or revive an existing one.
+	// options are to create a new database, revive an existing one or restore
+	// one from a vbr restore point.
 	InitPolicy CommunalInitPolicy `json:"initPolicy"`
 
 	// +kubebuilder:validation:Optional
@@ -179,6 +204,13 @@ type VerticaDBSpec struct {
 	// If InitPolicy is not Revive, this field can be ignored.
 	ReviveOrder []SubclusterPodCount `json:"reviveOrder,omitempty"`
 
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:fieldDependency:initPolicy:Restore","urn:alm:descriptor:com.tectonic.ui:advanced"}
+	// The vbr restore point to initialize the database from.  This must be set
+	// when initPolicy is Restore.  If InitPolicy is not Restore, this field
+	// can be ignored.
+	RestorePoint *RestorePointPolicy `json:"restorePoint,omitempty"`
+
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number","urn:alm:descriptor:com.tectonic.ui:advanced"}
 	// The timeout, in seconds, to use when admintools restarts a node or the
@@ -186,6 +218,18 @@ type VerticaDBSpec struct {
 	// of 20 minutes.
 	RestartTimeout int `json:"restartTimeout,omitempty"`
 
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// Database level configuration parameters that the operator keeps set.
+	// Each key is the name of a Vertica configuration parameter.  The values
+	// must be given the way Vertica reports them in the
+	// configuration_parameters system table (e.g. 1/0 for booleans).  The
+	// operator periodically compares them against the live values and sets
+	// any that have drifted.  If a parameter requires a restart to take
+	// effect, the operator restarts the database after setting it.  Removing
+	// a parameter from this map does not reset it in the database.
+	ConfigParameters map[string]string `json:"configParameters,omitempty"`
+
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	// Contains details about the communal storage.
@@ -240,6 +284,25 @@ type VerticaDBSpec struct {
 	//  If RequeueTime is not set either, then we set the default value only for upgrades. For other reconciles we use the exponential backoff algorithm.
 	UpgradeRequeueTime int `json:"upgradeRequeueTime,omitempty"`
 
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:advanced"
+	// The recurring periods of time when the operator is allowed to start a
+	// disruptive operation.  These are an image change (upgrade), shard
+	// rebalancing, removal of a subcluster and the resize of the persistent
+	// volumes.  If any of these are needed outside of a window, the reconcile
+	// is requeued until the next window opens.  An operation that has started
+	// is allowed to finish after its window closes.  If this is empty, the
+	// operations can happen at any time.  Set the
+	// vertica.com/ignore-maintenance-windows annotation to true to allow them
+	// outside of a window in an emergency.
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:advanced"
+	// Controls the NetworkPolicies the operator generates for the vertica pods.
+	// This is meant for clusters that deny all network traffic by default.
+	NetworkPolicy NetworkPolicyConfig `json:"networkPolicy,omitempty"`
+
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:advanced"
 	// Optional sidecar containers that run along side the vertica server.  The
@@ -339,6 +402,34 @@ type VerticaDBSpec struct {
 	// - ca.crt: The CA certificate
 	HTTPServerTLSSecret string `json:"httpServerTLSSecret,omitempty"`
 
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:hidden"
+	// +kubebuilder:validation:Optional
+	// A cert-manager issuer to sign the TLS credentials for the Vertica HTTP
+	// server.  This is only used when httpServerTLSSecret is empty.  The
+	// operator will create a cert-manager Certificate that references this
+	// issuer, wait for cert-manager to issue it, then set
+	// httpServerTLSSecret to the name of the issued secret.  The issuer must
+	// populate the ca.crt key in the secret.  If cert-manager is not
+	// installed, the operator generates a self-signed certificate instead.
+	HTTPServerCertIssuer *CertIssuerReference `json:"httpServerCertIssuer,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:validation:Optional
+	// Configures the server-side TLS that Vertica uses for client connections.
+	// When set, the operator installs the certificate in the database and
+	// alters the server TLS configuration to use it.  If the certificate in
+	// the secret changes, the operator installs the new one.
+	ClientTLS *ClientTLS `json:"clientTLS,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:hidden"
+	// +kubebuilder:default:=30
+	// +kubebuilder:validation:Optional
+	// The number of days before the HTTP server certificate expires that the
+	// operator will renew it.  This only applies to the certificate that the
+	// operator generates when httpServerTLSSecret is left empty.  If 0, the
+	// default of 30 days is used.
+	HTTPServerCertRenewalDays int `json:"httpServerCertRenewalDays,omitempty"`
+
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:hidden"
 	// +kubebuilder:validation:Optional
 	// Allows tuning of the Vertica pods readiness probe. Each of the values
@@ -375,6 +466,49 @@ type LocalObjectReference struct {
 	Name string `json:"name,omitempty" protobuf:"bytes,1,opt,name=name"`
 }
 
+// ClientTLS defines the server-side TLS that Vertica uses for client connections
+type ClientTLS struct {
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:io.kubernetes:Secret"
+	// +kubebuilder:validation:Optional
+	// A secret that contains the TLS credentials for client connections.  If
+	// this is empty, the operator will create a secret with a self-signed
+	// certificate and add the name of the generated secret in this field.
+	// When set, the secret must have the following keys defined:
+	// - tls.key: The private key of the server
+	// - tls.crt: The signed certificate for the private key
+	// - ca.crt: The CA certificate
+	Secret string `json:"secret,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default:=ENABLE
+	// +kubebuilder:validation:Optional
+	// The TLS mode of the server.  Valid values are: ENABLE, TRY_VERIFY or
+	// VERIFY_CA.  With ENABLE, the server doesn't verify client certificates.
+	// TRY_VERIFY verifies a client certificate if one is given, and
+	// VERIFY_CA requires all clients to present a certificate signed by the CA.
+	Mode string `json:"mode,omitempty"`
+}
+
+// CertIssuerReference refers to a cert-manager Issuer or ClusterIssuer
+type CertIssuerReference struct {
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// The name of the issuer.
+	Name string `json:"name"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default:=Issuer
+	// +kubebuilder:validation:Optional
+	// The kind of the issuer.  Valid values are: Issuer or ClusterIssuer.  The
+	// Issuer must be in the same namespace as the VerticaDB.
+	Kind string `json:"kind,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default:="cert-manager.io"
+	// +kubebuilder:validation:Optional
+	// The group of the issuer.  This only needs to be set for external issuers.
+	Group string `json:"group,omitempty"`
+}
+
 // SubclusterSelection is used to select between existing subcluster by name
 // or provide a template for a new subcluster.  This is used to specify what
 // subcluster gets client routing for subcluster we are restarting during online
@@ -414,8 +548,27 @@ const (
 	// packages. This will speed up the time it takes to create the db. This is
 	// only supported in Vertica release 12.0.1 or higher.
 	CommunalInitPolicyCreateSkipPackageInstall = "CreateSkipPackageInstall"
+	// The database is created with create_db, then its data is restored from
+	// the vbr restore point given in restorePoint.  The communal path must not
+	// have a preexisting database.
+	CommunalInitPolicyRestore = "Restore"
 )
 
+// RestorePointPolicy identifies a restore point that was created by vbr
+type RestorePointPolicy struct {
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:text"
+	// The name of the restore point.  This has the form
+	// <snapshotName>_<YYYYMMDD>_<HHMMSS>, which is what a VerticaBackup
+	// reports in status.restorePointID.
+	ID string `json:"id"`
+
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// The location where the restore point is stored.
+	Target BackupTarget `json:"target"`
+}
+
 // Set constant Upgrade Requeue Time
 const URTime = 30
 
@@ -447,6 +600,53 @@ const (
 	AutoUpgrade UpgradePolicyType = "Auto"
 )
 
+// MaintenanceWindow is a recurring period of time in which disruptive
+// operations can be started
+type MaintenanceWindow struct {
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// When the window opens, as a cron expression with five fields: minute,
+	// hour, day of month, month and day of week.  For example, "0 2 * * sat"
+	// opens a window every Saturday at 2am.
+	Schedule string `json:"schedule"`
+
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// How long the window stays open once it opens (e.g. 4h or 90m).
+	Duration metav1.Duration `json:"duration"`
+
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// The IANA name of the time zone the schedule is in (e.g.
+	// America/New_York).  If omitted, the schedule is in UTC.
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// NetworkPolicyConfig has the settings for the NetworkPolicies the operator
+// creates for the vertica pods
+type NetworkPolicyConfig struct {
+	// +kubebuilder:default:=false
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:booleanSwitch"
+	// If true, the operator creates NetworkPolicies that allow the traffic the
+	// database needs.  This includes all traffic between the pods of the
+	// VerticaDB (client, internal, spread, agent and the http server), traffic
+	// from the operator pods to the client and http server ports, and client
+	// traffic to the ports exposed by the subcluster service objects.  The
+	// policies only cover ingress.  If this is false, the operator removes any
+	// NetworkPolicies it created before.
+	Enabled bool `json:"enabled,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// The sources that are allowed to connect to the client ports of the
+	// subclusters.  Each entry is a namespace and/or pod selector, or an IP
+	// block, as in a NetworkPolicy.  A subcluster can override this with its
+	// own clientIngressFrom.  If neither are set, clients can connect from
+	// anywhere.
+	ClientIngressFrom []networkingv1.NetworkPolicyPeer `json:"clientIngressFrom,omitempty"`
+}
+
 type HTTPServerModeType string
 
 const (
@@ -455,6 +655,18 @@ const (
 	HTTPServerModeAuto     HTTPServerModeType = "Auto"
 )
 
+const (
+	ClientTLSModeEnable    = "ENABLE"
+	ClientTLSModeTryVerify = "TRY_VERIFY"
+	ClientTLSModeVerifyCA  = "VERIFY_CA"
+)
+
+const (
+	CertIssuerKindIssuer        = "Issuer"
+	CertIssuerKindClusterIssuer = "ClusterIssuer"
+	CertIssuerGroupCertManager  = "cert-manager.io"
+)
+
 type ServerSideEncryptionType string
 
 const (
@@ -528,6 +740,9 @@ type CommunalStorage struct {
 	// or to a ServiceAccount with IRSA (see
 	// https://docs.aws.amazon.com/eks/latest/userguide/iam-roles-for-service-accounts.html).
 	// IRSA requires a Vertica server running at least with version >= 12.0.3.
+	//
+	// If the contents of the secret change after the database is created, the
+	// operator sets the new credentials in the database.
 	CredentialSecret string `json:"credentialSecret"`
 
 	// +kubebuilder:validation:Optional
@@ -688,6 +903,22 @@ type Subcluster struct {
 	// new image.
 	IsTransient bool `json:"isTransient,omitempty"`
 
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch","urn:alm:descriptor:com.tectonic.ui:advanced"}
+	// When set to true, the operator drains the sessions of the subcluster,
+	// stops its vertica nodes and scales its statefulset to zero pods.  The
+	// subcluster stays in the database, so setting this back to false quickly
+	// brings it back with its depot intact.  Only secondary subclusters can
+	// be shut down.
+	Shutdown bool `json:"shutdown,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// Node level configuration parameters to set for each vertica node in
+	// the subcluster.  These override any database level setting.  The same
+	// rules as the configParameters in the VerticaDB spec apply.
+	ConfigParameters map[string]string `json:"configParameters,omitempty"`
+
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:hidden"
 	// This allows a different image to be used for the subcluster than the one
@@ -783,6 +1014,13 @@ type Subcluster struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	// A map of key/value pairs appended to service metadata.annotations.
 	ServiceAnnotations map[string]string `json:"serviceAnnotations,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:advanced"
+	// The sources that are allowed to connect to the client ports of the pods
+	// in this subcluster when networkPolicy.enabled is true.  This overrides
+	// networkPolicy.clientIngressFrom for the subcluster.
+	ClientIngressFrom []networkingv1.NetworkPolicyPeer `json:"clientIngressFrom,omitempty"`
 }
 
 // Affinity is used instead of corev1.Affinity and behaves the same.
@@ -837,6 +1075,74 @@ type VerticaDBStatus struct {
 	// Status message for the current running upgrade.   If no upgrade
 	// is occurring, this message remains blank.
 	UpgradeStatus string `json:"upgradeStatus"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	// The configuration parameters that were set but won't take effect until
+	// the database is restarted.
+	ConfigParametersPendingRestart []string `json:"configParametersPendingRestart,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	// The SHA-256 fingerprint of the server certificate that is set in the
+	// database for client TLS connections.
+	ClientTLSFingerprint string `json:"clientTLSFingerprint,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	// The TLS mode that is set in the database for client connections.
+	ClientTLSMode string `json:"clientTLSMode,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	// Details about the last management command that failed.  This is cleared
+	// once the same command succeeds.
+	LastFailure *MgmtFailure `json:"lastFailure,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	// Set while a disruptive operation is waiting for a maintenance window to
+	// open.  It names the operation that is waiting.
+	MaintenanceWindowStatus string `json:"maintenanceWindowStatus,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	// The time the next maintenance window opens.  This is only set while an
+	// operation is waiting for it.
+	NextMaintenanceWindow *metav1.Time `json:"nextMaintenanceWindow,omitempty"`
+}
+
+// MgmtFailure describes a failed management command, such as a call to
+// admintools, along with how the operator classified the failure.
+type MgmtFailure struct {
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// The management command that failed (e.g. restart_node, create_db)
+	Command string `json:"command"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// A one word, CamelCase, code for the class of failure.  This matches the
+	// reason of the event that was written for the failure.
+	Reason string `json:"reason"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// A human readable message with details about the failure.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// A hint on what can be done to fix the failure.
+	// +optional
+	Remediation string `json:"remediation,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// The last time the command failed.
+	Time metav1.Time `json:"time"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// The number of times the command was retried and failed again for the
+	// same reason.
+	// +optional
+	RetryCount int32 `json:"retryCount,omitempty"`
 }
 
 // VerticaDBConditionType defines type for VerticaDBCondition
@@ -856,6 +1162,41 @@ const (
 	// VerticaRestartNeeded is a condition that when set to true will force the
 	// operator to stop/start the vertica pods.
 	VerticaRestartNeeded VerticaDBConditionType = "VerticaRestartNeeded"
+	// Hibernated indicates the database was stopped and all of its pods were
+	// scaled down because spec.hibernate was set.
+	Hibernated VerticaDBConditionType = "Hibernated"
+	// Resuming indicates the pods of a hibernated database are coming back and
+	// the database is being restarted.
+	Resuming VerticaDBConditionType = "Resuming"
+	// Ready indicates that all of the vertica nodes that should be running
+	// are up and accepting connections.
+	Ready VerticaDBConditionType = "Ready"
+	// Progressing indicates that the operator hasn't finished reconciling the
+	// VerticaDB.  The reason and message tell which part of the reconcile
+	// stopped early.
+	Progressing VerticaDBConditionType = "Progressing"
+	// Degraded indicates that the last reconcile failed or that some of the
+	// vertica nodes are down.
+	Degraded VerticaDBConditionType = "Degraded"
+	// Paused indicates that the reconcile of the VerticaDB, or some parts of
+	// it, was stopped with the pause-reconcile annotation.
+	Paused VerticaDBConditionType = "Paused"
+)
+
+// Reasons for the Ready, Progressing, Degraded and Paused conditions
+const (
+	ReasonAllNodesUp        = "AllNodesUp"
+	ReasonNodesNotUp        = "NodesNotUp"
+	ReasonNodesDown         = "NodesDown"
+	ReasonDBNotInitialized  = "DBNotInitialized"
+	ReasonHibernated        = "Hibernated"
+	ReasonUpgradeInProgress = "UpgradeInProgress"
+	ReasonReconcileRequeued = "ReconcileRequeued"
+	ReasonReconcileFailed   = "ReconcileFailed"
+	ReasonReconcileComplete = "ReconcileComplete"
+	ReasonAsExpected        = "AsExpected"
+	ReasonReconcilePaused   = "ReconcilePaused"
+	ReasonActorsPaused      = "ActorsPaused"
 )
 
 // Fixed index entries for each condition.
@@ -866,6 +1207,12 @@ const (
 	OfflineUpgradeInProgressIndex
 	OnlineUpgradeInProgressIndex
 	VerticaRestartNeededIndex
+	HibernatedIndex
+	ResumingIndex
+	ReadyIndex
+	ProgressingIndex
+	DegradedIndex
+	PausedIndex
 )
 
 // VerticaDBConditionIndexMap is a map of the VerticaDBConditionType to its
@@ -877,6 +1224,12 @@ var VerticaDBConditionIndexMap = map[VerticaDBConditionType]int{
 	OfflineUpgradeInProgress: OfflineUpgradeInProgressIndex,
 	OnlineUpgradeInProgress:  OnlineUpgradeInProgressIndex,
 	VerticaRestartNeeded:     VerticaRestartNeededIndex,
+	Hibernated:               HibernatedIndex,
+	Resuming:                 ResumingIndex,
+	Ready:                    ReadyIndex,
+	Progressing:              ProgressingIndex,
+	Degraded:                 DegradedIndex,
+	Paused:                   PausedIndex,
 }
 
 // VerticaDBConditionNameMap is the reverse of VerticaDBConditionIndexMap.  It
@@ -888,6 +1241,12 @@ var VerticaDBConditionNameMap = map[int]VerticaDBConditionType{
 	OfflineUpgradeInProgressIndex: OfflineUpgradeInProgress,
 	OnlineUpgradeInProgressIndex:  OnlineUpgradeInProgress,
 	VerticaRestartNeededIndex:     VerticaRestartNeeded,
+	HibernatedIndex:               Hibernated,
+	ResumingIndex:                 Resuming,
+	ReadyIndex:                    Ready,
+	ProgressingIndex:              Progressing,
+	DegradedIndex:                 Degraded,
+	PausedIndex:                   Paused,
 }
 
 // VerticaDBCondition defines condition for VerticaDB
@@ -905,6 +1264,21 @@ type VerticaDBCondition struct {
 	// Last time the condition transitioned from one status to another.
 	// +optional
 	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// A one word, CamelCase, reason for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// A human readable message with details about the condition.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// The generation of the VerticaDB that the condition was set for.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
 
 // SubclusterStatus defines the per-subcluster status that we track
@@ -934,6 +1308,41 @@ type SubclusterStatus struct {
 	// A count of the number of pods that are in read-only state in this subcluster.
 	ReadOnlyCount int32 `json:"readOnlyCount"`
 
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +kubebuilder:validation:Optional
+	// True if the subcluster was shut down through its shutdown field.  A
+	// shut down subcluster has no up nodes but is still part of the database.
+	Shutdown bool `json:"shutdown,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +kubebuilder:validation:Optional
+	// The total size, in bytes, of the depot of the up nodes in this subcluster.
+	DepotSize int64 `json:"depotSize,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +kubebuilder:validation:Optional
+	// The total size, in bytes, of the local data persistent volumes of the
+	// running pods in this subcluster.
+	LocalDataSize int64 `json:"localDataSize,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +kubebuilder:validation:Optional
+	// The total amount of space, in bytes, left in the local data persistent
+	// volumes of the running pods in this subcluster.
+	LocalDataAvail int64 `json:"localDataAvail,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +kubebuilder:validation:Optional
+	// A count of the number of running pods that are low on space in their
+	// local data persistent volume.
+	LowDiskSpaceCount int32 `json:"lowDiskSpaceCount,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +kubebuilder:validation:Optional
+	// A count of the number of up nodes that are not subscribed to any shard.
+	// This is only tracked for Eon mode databases.
+	NoShardSubscriptionCount int32 `json:"noShardSubscriptionCount,omitempty"`
+
 	// +operator-sdk:csv:customresourcedefinitions:type=status
 	Detail []VerticaDBPodStatus `json:"detail"`
 }
@@ -957,6 +1366,38 @@ type VerticaDBPodStatus struct {
 	// +kubebuilder:validation:Optional
 	// True means the vertica process on this pod is in read-only state
 	ReadOnly bool `json:"readOnly"`
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +kubebuilder:validation:Optional
+	// The image, and thus the Vertica version, that is running in the pod.
+	Image string `json:"image,omitempty"`
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +kubebuilder:validation:Optional
+	// The size, in bytes, of the depot.  This is only set while the node is up.
+	DepotSize int64 `json:"depotSize,omitempty"`
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +kubebuilder:validation:Optional
+	// If the depot is sized as a percentage of the local disk, this is the
+	// percentage.  This is only set while the node is up.
+	DepotDiskPercentSize string `json:"depotDiskPercentSize,omitempty"`
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +kubebuilder:validation:Optional
+	// The size, in bytes, of the local data persistent volume.  This is only
+	// set while the pod is running.
+	LocalDataSize int64 `json:"localDataSize,omitempty"`
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +kubebuilder:validation:Optional
+	// The amount of space, in bytes, left in the local data persistent volume.
+	// This is only set while the pod is running.
+	LocalDataAvail int64 `json:"localDataAvail,omitempty"`
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +kubebuilder:validation:Optional
+	// The number of shards the node is subscribed to, not including the
+	// replica shard.  This is only set while the node is up.
+	ShardSubscriptions int32 `json:"shardSubscriptions,omitempty"`
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +kubebuilder:validation:Optional
+	// True means the vertica agent is running in the pod
+	AgentRunning bool `json:"agentRunning,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -1001,6 +1442,35 @@ const (
 	// Annotation to enable the agent
 	RunAgentAnnotation             = "vertica.com/run-agent"
 	RunAgentAnnotationEnabledValue = "yes"
+	// Annotation on the HTTP server TLS secret to indicate that it has a
+	// renewed certificate that hasn't been pushed to all of the pods yet.
+	HTTPServerCertRolloutPendingAnnotation = "vertica.com/http-server-cert-rollout-pending"
+	// Annotation on the HTTP server TLS secret with the SHA-256 fingerprint
+	// of the certificate that is in use by the pods.  It is used to detect
+	// when cert-manager reissues the certificate.
+	HTTPServerCertRolledOutAnnotation = "vertica.com/http-server-cert-rolled-out"
+	// Annotation that stops the operator from reconciling the object.  This is
+	// used when doing manual work in the pods.  Set it to true to pause the
+	// entire reconcile, or to a comma separated list of actor names (e.g.
+	// RestartReconciler) to only skip those parts of the reconcile.
+	PauseReconcileAnnotation = "vertica.com/pause-reconcile"
+	// Annotation that puts the VerticaDB in dry-run mode when set to true.  The
+	// operator runs the reconcile but only reports the changes it would make,
+	// in the <vdb-name>-dry-run ConfigMap, without making them.
+	DryRunAnnotation = "vertica.com/dry-run"
+	// Annotation that lets disruptive operations happen outside of the
+	// maintenance windows when set to true.  This is meant for emergencies.
+	IgnoreMaintenanceWindowsAnnotation = "vertica.com/ignore-maintenance-windows"
+	// Experimental annotation that fetches the node state through the REST
+	// interface of the Vertica HTTP server, rather than admintools, when set
+	// to true.  The HTTP server has no endpoint for the other node operations,
+	// so they always use admintools.
+	ExperimentalHTTPSNodeStateAnnotation = "vertica.com/experimental-https-node-state"
+
+	// The default for httpServerCertRenewalDays
+	DefaultHTTPServerCertRenewalDays = 30
+	// The default for superuserName
+	DefaultSuperuserName = "dbadmin"
 
 	DefaultS3Region       = "us-east-1"
 	DefaultGCloudRegion   = "US-EAST1"
@@ -1213,6 +1683,18 @@ func (v *VerticaDB) IsOnlineUpgradeInProgress() bool {
 	return v.isConditionIndexSet(OnlineUpgradeInProgressIndex)
 }
 
+// IsImageChangeInProgress returns true if the image of the database is being
+// changed, either with online or offline upgrade
+func (v *VerticaDB) IsImageChangeInProgress() bool {
+	return v.isConditionIndexSet(ImageChangeInProgressIndex)
+}
+
+// IsHibernated returns true if the database is meant to be hibernated and
+// the operator has finished scaling it down.
+func (v *VerticaDB) IsHibernated() bool {
+	return v.Spec.Hibernate && v.isConditionIndexSet(HibernatedIndex)
+}
+
 // IsConditionSet will return true if the status condition is set to true.
 // If the condition is not in the array then this implies the condition is
 // false.
@@ -1276,6 +1758,30 @@ func (v *VerticaDB) IsHTTPServerDisabled() bool {
 	return v.Spec.HTTPServerMode == HTTPServerModeDisabled
 }
 
+// GetSuperuserName returns the name of the database's superuser
+func (v *VerticaDB) GetSuperuserName() string {
+	if v.Spec.SuperuserName == "" {
+		return DefaultSuperuserName
+	}
+	return v.Spec.SuperuserName
+}
+
+// IsExperimentalHTTPSNodeStateEnabled returns true if the annotation to fetch
+// the node state through the HTTP server has been set
+func (v *VerticaDB) IsExperimentalHTTPSNodeStateEnabled() bool {
+	return strings.EqualFold(v.ObjectMeta.Annotations[ExperimentalHTTPSNodeStateAnnotation], "true")
+}
+
+// GetHTTPServerCertRenewalWindow returns how long before the expiry of the
+// generated HTTP server certificate that we renew it.
+func (v *VerticaDB) GetHTTPServerCertRenewalWindow() time.Duration {
+	days := v.Spec.HTTPServerCertRenewalDays
+	if days == 0 {
+		days = DefaultHTTPServerCertRenewalDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
 // IsHTTPServerEnabled will return true if the http server is enabled to run for
 // this instance of the vdb.
 func (v *VerticaDB) IsHTTPServerEnabled() bool {
@@ -1305,6 +1811,65 @@ func (v *VerticaDB) IsEON() bool {
 	return v.Spec.ShardCount > 0
 }
 
+// GetClientIngressFrom returns the sources that are allowed to connect to the
+// client ports of a subcluster
+func (v *VerticaDB) GetClientIngressFrom(sc *Subcluster) []networkingv1.NetworkPolicyPeer {
+	if len(sc.ClientIngressFrom) > 0 {
+		return sc.ClientIngressFrom
+	}
+	return v.Spec.NetworkPolicy.ClientIngressFrom
+}
+
+// Parse returns the schedule of the maintenance window in its time zone
+func (m *MaintenanceWindow) Parse() (*cron.Schedule, error) {
+	loc := time.UTC
+	if m.TimeZone != "" {
+		var err error
+		if loc, err = time.LoadLocation(m.TimeZone); err != nil {
+			return nil, err
+		}
+	}
+	return cron.ParseInLocation(m.Schedule, loc)
+}
+
+// CheckMaintenanceWindow returns true if a disruptive operation can be started
+// at the given time.  This is true if there are no maintenance windows, one of
+// them is open or the override annotation is set.  If it returns false, next
+// is the time the next window opens.  The zero time is returned for next if
+// none of the windows will ever open.
+func (v *VerticaDB) CheckMaintenanceWindow(now time.Time) (allowed bool, next time.Time, err error) {
+	if len(v.Spec.MaintenanceWindows) == 0 ||
+		strings.EqualFold(v.Annotations[IgnoreMaintenanceWindowsAnnotation], "true") {
+		return true, time.Time{}, nil
+	}
+	for i := range v.Spec.MaintenanceWindows {
+		w := &v.Spec.MaintenanceWindows[i]
+		sched, err := w.Parse()
+		if err != nil {
+			return false, time.Time{}, fmt.Errorf("failed to parse maintenance window %d: %w", i, err)
+		}
+		// The first opening after now-duration is either a window that is
+		// still open or the next one to open.
+		opens := sched.Next(now.Add(-w.Duration.Duration))
+		if opens.IsZero() {
+			continue
+		}
+		if !opens.After(now) {
+			return true, time.Time{}, nil
+		}
+		if next.IsZero() || opens.Before(next) {
+			next = opens
+		}
+	}
+	return false, next, nil
+}
+
+// IsDryRunEnabled returns true if the annotation to only report the changes
+// of the reconcile has been set
+func (v *VerticaDB) IsDryRunEnabled() bool {
+	return strings.EqualFold(v.ObjectMeta.Annotations[DryRunAnnotation], "true")
+}
+
 // IsAgentEnabled returns true if the annotation to enable the agent
 // has been set to the correct value
 func (v *VerticaDB) IsAgentEnabled() bool {
@@ -1315,3 +1880,23 @@ func (v *VerticaDB) IsAgentEnabled() bool {
 func (v *VerticaDB) IsAdditionalConfigMapEmpty() bool {
 	return len(v.Spec.Communal.AdditionalConfig) == 0
 }
+
+// ParsePauseReconcileAnnotation will interpret the pause-reconcile annotation
+// from the given annotations.  all is true if the entire reconcile is paused.
+// Otherwise, actors has the names of the actors that should be skipped.
+func ParsePauseReconcileAnnotation(annotations map[string]string) (all bool, actors map[string]bool) {
+	val := strings.TrimSpace(annotations[PauseReconcileAnnotation])
+	if val == "" || strings.EqualFold(val, "false") {
+		return false, nil
+	}
+	if strings.EqualFold(val, "true") {
+		return true, nil
+	}
+	actors = map[string]bool{}
+	for _, a := range strings.Split(val, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			actors[a] = true
+		}
+	}
+	return false, actors
+}
@@ -16,8 +16,11 @@
 package v1beta1
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 var _ = Describe("verticadb_types", func() {
@@ -73,4 +76,48 @@ var _ = Describe("verticadb_types", func() {
 		vdb.ObjectMeta.Annotations[VersionAnnotation] = HTTPServerAutoMinVersion
 		Expect(vdb.IsHTTPServerEnabled()).Should(BeTrue())
 	})
+
+	It("should parse the pause-reconcile annotation", func() {
+		all, actors := ParsePauseReconcileAnnotation(map[string]string{})
+		Expect(all).Should(BeFalse())
+		Expect(actors).Should(BeEmpty())
+		all, actors = ParsePauseReconcileAnnotation(map[string]string{PauseReconcileAnnotation: "false"})
+		Expect(all).Should(BeFalse())
+		Expect(actors).Should(BeEmpty())
+		all, actors = ParsePauseReconcileAnnotation(map[string]string{PauseReconcileAnnotation: "True"})
+		Expect(all).Should(BeTrue())
+		Expect(actors).Should(BeEmpty())
+		all, actors = ParsePauseReconcileAnnotation(map[string]string{
+			PauseReconcileAnnotation: "RestartReconciler, ,UpgradeOperator120Reconciler",
+		})
+		Expect(all).Should(BeFalse())
+		Expect(actors).Should(Equal(map[string]bool{"RestartReconciler": true, "UpgradeOperator120Reconciler": true}))
+	})
+
+	It("should only allow disruptive operations in a maintenance window", func() {
+		vdb := MakeVDB()
+		now, err := time.Parse(time.RFC3339, "2023-05-06T03:00:00Z") // A Saturday
+		Expect(err).Should(Succeed())
+		allowed, _, err := vdb.CheckMaintenanceWindow(now)
+		Expect(err).Should(Succeed())
+		Expect(allowed).Should(BeTrue())
+
+		vdb.Spec.MaintenanceWindows = []MaintenanceWindow{
+			{Schedule: "0 2 * * sat", Duration: metav1.Duration{Duration: 2 * time.Hour}},
+			{Schedule: "0 22 * * *", Duration: metav1.Duration{Duration: time.Hour}},
+		}
+		allowed, _, err = vdb.CheckMaintenanceWindow(now)
+		Expect(err).Should(Succeed())
+		Expect(allowed).Should(BeTrue())
+
+		allowed, next, err := vdb.CheckMaintenanceWindow(now.Add(time.Hour))
+		Expect(err).Should(Succeed())
+		Expect(allowed).Should(BeFalse())
+		Expect(next.UTC().Format(time.RFC3339)).Should(Equal("2023-05-06T22:00:00Z"))
+
+		vdb.Annotations[IgnoreMaintenanceWindowsAnnotation] = "true"
+		allowed, _, err = vdb.CheckMaintenanceWindow(now.Add(time.Hour))
+		Expect(err).Should(Succeed())
+		Expect(allowed).Should(BeTrue())
+	})
 })
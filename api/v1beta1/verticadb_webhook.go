@@ -20,8 +20,11 @@ package v1beta1
 import (
 	"fmt"
 	"reflect"
+	"regexp"
 	"strings"
+	"time"
 
+	"github.com/vertica/vertica-kubernetes/pkg/cron"
 	"github.com/vertica/vertica-kubernetes/pkg/paths"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -56,6 +59,10 @@ const (
 // hdfsPrefixes are prefixes for an HDFS path.
 var hdfsPrefixes = []string{"webhdfs://", "swebhdfs://"}
 
+// validConfigParmName is the pattern a vertica configuration parameter name
+// must match.  We are strict here since the name is put directly in SQL.
+var validConfigParmName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
 // log is for logging in this package.
 var verticadblog = logf.Log.WithName("verticadb-resource")
 
@@ -153,6 +160,10 @@ func (v *VerticaDB) Default() {
 	if v.Spec.Communal.Endpoint == "" && v.IsGCloud() {
 		v.Spec.Communal.Endpoint = DefaultGCloudEndpoint
 	}
+	if v.Spec.RestorePoint != nil && v.Spec.RestorePoint.Target.Region == "" &&
+		(v.Spec.RestorePoint.Target.IsS3() || v.Spec.RestorePoint.Target.IsGCloud()) {
+		v.Spec.RestorePoint.Target.Region = DefaultS3Region
+	}
 	v.Spec.TemporarySubclusterRouting.Template.IsPrimary = false
 	v.setDefaultServiceName()
 }
@@ -257,18 +268,21 @@ func (v *VerticaDB) validateImmutableFields(old runtime.Object) field.ErrorList
 	allErrs = v.checkImmutableLocalPathChange(oldObj, allErrs)
 	allErrs = v.checkImmutableShardCount(oldObj, allErrs)
 	allErrs = v.checkImmutableS3ServerSideEncryption(oldObj, allErrs)
+	allErrs = v.checkImmutableSubclustersWhileHibernated(oldObj, allErrs)
 	return allErrs
 }
 
 func (v *VerticaDB) validateVerticaDBSpec() field.ErrorList {
 	allErrs := v.hasAtLeastOneSC(field.ErrorList{})
 	allErrs = v.hasValidInitPolicy(allErrs)
+	allErrs = v.hasValidRestorePoint(allErrs)
 	allErrs = v.hasValidDBName(allErrs)
 	allErrs = v.hasPrimarySubcluster(allErrs)
 	allErrs = v.validateKsafety(allErrs)
 	allErrs = v.validateCommunalPath(allErrs)
 	allErrs = v.validateS3ServerSideEncryption(allErrs)
 	allErrs = v.validateAdditionalConfigParms(allErrs)
+	allErrs = v.validateConfigParameters(allErrs)
 	allErrs = v.validateEndpoint(allErrs)
 	allErrs = v.hasValidDomainName(allErrs)
 	allErrs = v.hasValidNodePort(allErrs)
@@ -285,7 +299,13 @@ func (v *VerticaDB) validateVerticaDBSpec() field.ErrorList {
 	allErrs = v.validateEncryptSpreadComm(allErrs)
 	allErrs = v.validateLocalPaths(allErrs)
 	allErrs = v.validateHTTPServerMode(allErrs)
+	allErrs = v.validateHTTPServerCertIssuer(allErrs)
+	allErrs = v.validateClientTLS(allErrs)
+	allErrs = v.validateExperimentalHTTPSNodeState(allErrs)
 	allErrs = v.hasValidShardCount(allErrs)
+	allErrs = v.hasValidHibernate(allErrs)
+	allErrs = v.hasValidSubclusterShutdown(allErrs)
+	allErrs = v.hasValidMaintenanceWindows(allErrs)
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -309,12 +329,84 @@ func (v *VerticaDB) hasValidInitPolicy(allErrs field.ErrorList) field.ErrorList
 	case CommunalInitPolicyCreateSkipPackageInstall:
 	case CommunalInitPolicyRevive:
 	case CommunalInitPolicyScheduleOnly:
+	case CommunalInitPolicyRestore:
 	default:
 		err := field.Invalid(field.NewPath("spec").Child("initPolicy"),
 			v.Spec.InitPolicy,
-			fmt.Sprintf("initPolicy should either be %s, %s, %s or %s",
+			fmt.Sprintf("initPolicy should either be %s, %s, %s, %s or %s",
 				CommunalInitPolicyCreate, CommunalInitPolicyCreateSkipPackageInstall,
-				CommunalInitPolicyRevive, CommunalInitPolicyScheduleOnly))
+				CommunalInitPolicyRevive, CommunalInitPolicyRestore, CommunalInitPolicyScheduleOnly))
+		allErrs = append(allErrs, err)
+	}
+	return allErrs
+}
+
+func (v *VerticaDB) hasValidRestorePoint(allErrs field.ErrorList) field.ErrorList {
+	if v.Spec.InitPolicy != CommunalInitPolicyRestore {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("restorePoint")
+	if v.Spec.RestorePoint == nil {
+		err := field.Invalid(path,
+			v.Spec.RestorePoint,
+			fmt.Sprintf("restorePoint must be set when initPolicy is %s", CommunalInitPolicyRestore))
+		return append(allErrs, err)
+	}
+	if !validRestorePointID.MatchString(v.Spec.RestorePoint.ID) {
+		err := field.Invalid(path.Child("id"),
+			v.Spec.RestorePoint.ID,
+			"restorePoint.id must have the form <snapshotName>_<YYYYMMDD>_<HHMMSS>")
+		allErrs = append(allErrs, err)
+	}
+	if !v.Spec.RestorePoint.Target.IsKnownObjectStore() {
+		err := field.Invalid(path.Child("target").Child("path"),
+			v.Spec.RestorePoint.Target.Path,
+			fmt.Sprintf("restorePoint.target.path must start with %s, %s or %s", S3Prefix, GCloudPrefix, AzurePrefix))
+		allErrs = append(allErrs, err)
+	}
+	return allErrs
+}
+
+func (v *VerticaDB) hasValidSubclusterShutdown(allErrs field.ErrorList) field.ErrorList {
+	for i := range v.Spec.Subclusters {
+		sc := &v.Spec.Subclusters[i]
+		if sc.Shutdown && sc.IsPrimary {
+			err := field.Invalid(field.NewPath("spec").Child("subclusters").Index(i).Child("shutdown"),
+				sc.Shutdown,
+				fmt.Sprintf("subcluster %s cannot be shut down because it is a primary subcluster", sc.Name))
+			allErrs = append(allErrs, err)
+		}
+	}
+	return allErrs
+}
+
+func (v *VerticaDB) hasValidMaintenanceWindows(allErrs field.ErrorList) field.ErrorList {
+	for i := range v.Spec.MaintenanceWindows {
+		w := &v.Spec.MaintenanceWindows[i]
+		path := field.NewPath("spec").Child("maintenanceWindows").Index(i)
+		if w.TimeZone != "" {
+			if _, err := time.LoadLocation(w.TimeZone); err != nil {
+				allErrs = append(allErrs, field.Invalid(path.Child("timeZone"), w.TimeZone,
+					fmt.Sprintf("timeZone is not a known time zone: %s", err)))
+			}
+		}
+		if _, err := cron.Parse(w.Schedule); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("schedule"), w.Schedule,
+				fmt.Sprintf("schedule must be a valid cron expression: %s", err)))
+		}
+		if w.Duration.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(path.Child("duration"), w.Duration.String(),
+				"duration must be greater than zero"))
+		}
+	}
+	return allErrs
+}
+
+func (v *VerticaDB) hasValidHibernate(allErrs field.ErrorList) field.ErrorList {
+	if v.Spec.Hibernate && v.Spec.InitPolicy == CommunalInitPolicyScheduleOnly {
+		err := field.Invalid(field.NewPath("spec").Child("hibernate"),
+			v.Spec.Hibernate,
+			fmt.Sprintf("hibernate cannot be used when initPolicy is %s", CommunalInitPolicyScheduleOnly))
 		allErrs = append(allErrs, err)
 	}
 	return allErrs
@@ -385,6 +477,35 @@ func (v *VerticaDB) validateAdditionalConfigParms(allErrs field.ErrorList) field
 	return allErrs
 }
 
+func (v *VerticaDB) validateConfigParameters(allErrs field.ErrorList) field.ErrorList {
+	allErrs = validateConfigParmMap(v.Spec.ConfigParameters, field.NewPath("spec").Child("configParameters"), allErrs)
+	for i := range v.Spec.Subclusters {
+		path := field.NewPath("spec").Child("subclusters").Index(i).Child("configParameters")
+		allErrs = validateConfigParmMap(v.Spec.Subclusters[i].ConfigParameters, path, allErrs)
+	}
+	return allErrs
+}
+
+// validateConfigParmMap checks the names in a map of configuration parameters.
+// Names are case insensitive in vertica, so keys that only differ by case are
+// seen as duplicates.
+func validateConfigParmMap(parms map[string]string, path *field.Path, allErrs field.ErrorList) field.ErrorList {
+	seen := map[string]bool{}
+	for k := range parms {
+		if !validConfigParmName.MatchString(k) {
+			err := field.Invalid(path, k,
+				"configuration parameter names must start with a letter and can only contain alphanumeric characters and underscores")
+			allErrs = append(allErrs, err)
+		}
+		if seen[strings.ToLower(k)] {
+			err := field.Invalid(path, k, fmt.Sprintf("duplicates key %s", k))
+			allErrs = append(allErrs, err)
+		}
+		seen[strings.ToLower(k)] = true
+	}
+	return allErrs
+}
+
 func (v *VerticaDB) validateEndpoint(allErrs field.ErrorList) field.ErrorList {
 	if v.Spec.InitPolicy == CommunalInitPolicyScheduleOnly {
 		return allErrs
@@ -815,6 +936,12 @@ func (v *VerticaDB) validateRequeueTimes(allErrs field.ErrorList) field.ErrorLis
 			"upgradeRequeueTime cannot be negative")
 		allErrs = append(allErrs, err)
 	}
+	if v.Spec.HTTPServerCertRenewalDays < 0 {
+		err := field.Invalid(prefix.Child("httpServerCertRenewalDays"),
+			v.Spec.HTTPServerCertRenewalDays,
+			"httpServerCertRenewalDays cannot be negative")
+		allErrs = append(allErrs, err)
+	}
 	return allErrs
 }
 
@@ -887,6 +1014,55 @@ func (v *VerticaDB) validateHTTPServerMode(allErrs field.ErrorList) field.ErrorL
 	return append(allErrs, err)
 }
 
+func (v *VerticaDB) validateHTTPServerCertIssuer(allErrs field.ErrorList) field.ErrorList {
+	issuer := v.Spec.HTTPServerCertIssuer
+	if issuer == nil {
+		return allErrs
+	}
+	prefix := field.NewPath("spec").Child("httpServerCertIssuer")
+	if issuer.Name == "" {
+		err := field.Invalid(prefix.Child("name"),
+			issuer.Name,
+			"name of the cert-manager issuer must be set")
+		allErrs = append(allErrs, err)
+	}
+	// External issuers can have any kind, so we only check the kind for the
+	// issuers that come with cert-manager.
+	if (issuer.Group == "" || issuer.Group == CertIssuerGroupCertManager) &&
+		issuer.Kind != "" && issuer.Kind != CertIssuerKindIssuer && issuer.Kind != CertIssuerKindClusterIssuer {
+		err := field.Invalid(prefix.Child("kind"),
+			issuer.Kind,
+			fmt.Sprintf("Valid values are: %s or %s", CertIssuerKindIssuer, CertIssuerKindClusterIssuer))
+		allErrs = append(allErrs, err)
+	}
+	return allErrs
+}
+
+func (v *VerticaDB) validateClientTLS(allErrs field.ErrorList) field.ErrorList {
+	if v.Spec.ClientTLS == nil {
+		return allErrs
+	}
+	switch v.Spec.ClientTLS.Mode {
+	case "", ClientTLSModeEnable, ClientTLSModeTryVerify, ClientTLSModeVerifyCA:
+		return allErrs
+	}
+	err := field.Invalid(field.NewPath("spec").Child("clientTLS").Child("mode"),
+		v.Spec.ClientTLS.Mode,
+		fmt.Sprintf("Valid values are: %s, %s or %s", ClientTLSModeEnable, ClientTLSModeTryVerify, ClientTLSModeVerifyCA))
+	return append(allErrs, err)
+}
+
+func (v *VerticaDB) validateExperimentalHTTPSNodeState(allErrs field.ErrorList) field.ErrorList {
+	if v.IsExperimentalHTTPSNodeStateEnabled() && v.IsHTTPServerDisabled() {
+		err := field.Invalid(field.NewPath("metadata").Child("annotations").Key(ExperimentalHTTPSNodeStateAnnotation),
+			v.ObjectMeta.Annotations[ExperimentalHTTPSNodeStateAnnotation],
+			fmt.Sprintf("The node state can only be fetched through the http server if httpServerMode isn't %s",
+				HTTPServerModeDisabled))
+		allErrs = append(allErrs, err)
+	}
+	return allErrs
+}
+
 func (v *VerticaDB) hasValidShardCount(allErrs field.ErrorList) field.ErrorList {
 	if v.Spec.ShardCount > 0 {
 		return allErrs
@@ -999,6 +1175,28 @@ func (v *VerticaDB) checkImmutableShardCount(oldObj *VerticaDB, allErrs field.Er
 	return allErrs
 }
 
+// checkImmutableSubclustersWhileHibernated will make sure the subclusters
+// don't change while the database is hibernated.  The pods are scaled back to
+// the sizes in the spec when we resume, which bypasses the steps we normally
+// go through when scaling down.
+func (v *VerticaDB) checkImmutableSubclustersWhileHibernated(oldObj *VerticaDB, allErrs field.ErrorList) field.ErrorList {
+	if !v.Spec.Hibernate || !oldObj.Spec.Hibernate {
+		return allErrs
+	}
+	changed := len(v.Spec.Subclusters) != len(oldObj.Spec.Subclusters)
+	for i := 0; !changed && i < len(v.Spec.Subclusters); i++ {
+		changed = v.Spec.Subclusters[i].Name != oldObj.Spec.Subclusters[i].Name ||
+			v.Spec.Subclusters[i].Size != oldObj.Spec.Subclusters[i].Size
+	}
+	if changed {
+		err := field.Invalid(field.NewPath("spec").Child("subclusters"),
+			v.Spec.Subclusters,
+			"subclusters cannot be added, removed or resized while hibernate is set")
+		allErrs = append(allErrs, err)
+	}
+	return allErrs
+}
+
 // checkImmutableS3ServerSideEncryption will make sure communal.s3ServerSideEncryption
 // does not change after creation
 func (v *VerticaDB) checkImmutableS3ServerSideEncryption(oldObj *VerticaDB, allErrs field.ErrorList) field.ErrorList {
@@ -17,12 +17,14 @@ package v1beta1
 
 import (
 	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/vertica/vertica-kubernetes/pkg/paths"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 var _ = Describe("verticadb_webhook", func() {
@@ -136,6 +138,34 @@ var _ = Describe("verticadb_webhook", func() {
 		validateSpecValuesHaveErr(vdb, false)
 	})
 
+	It("should validate the names of the config parameters", func() {
+		vdb := createVDBHelper()
+		vdb.Spec.ConfigParameters = map[string]string{
+			"MaxClientSessions": "100",
+			"EnableSSL":         "1",
+		}
+		validateSpecValuesHaveErr(vdb, false)
+		vdb.Spec.ConfigParameters["maxclientsessions"] = "50"
+		validateSpecValuesHaveErr(vdb, true)
+		vdb.Spec.ConfigParameters = map[string]string{
+			"MaxClientSessions; drop table t": "100",
+		}
+		validateSpecValuesHaveErr(vdb, true)
+		vdb.Spec.ConfigParameters = nil
+		vdb.Spec.Subclusters[0].ConfigParameters = map[string]string{
+			"Max-Client-Sessions": "100",
+		}
+		validateSpecValuesHaveErr(vdb, true)
+		vdb.Spec.Subclusters[0].ConfigParameters = map[string]string{
+			"1MaxClientSessions": "100",
+		}
+		validateSpecValuesHaveErr(vdb, true)
+		vdb.Spec.Subclusters[0].ConfigParameters = map[string]string{
+			"MaxClient'Sessions": "100",
+		}
+		validateSpecValuesHaveErr(vdb, true)
+	})
+
 	It("should have invalid subcluster name", func() {
 		vdb := createVDBHelper()
 		sc := &vdb.Spec.Subclusters[0]
@@ -298,6 +328,17 @@ var _ = Describe("verticadb_webhook", func() {
 		}
 		validateImmutableFields(vdbUpdate, true)
 	})
+	It("should not change subclusters while hibernated", func() {
+		vdbUpdate := createVDBHelper()
+		vdbUpdate.Spec.Hibernate = true
+		vdbUpdate.Spec.Subclusters[0].Size++
+		validateImmutableFields(vdbUpdate, false)
+		vdbOrig := createVDBHelper()
+		vdbOrig.Spec.Hibernate = true
+		Expect(vdbUpdate.validateImmutableFields(vdbOrig)).ShouldNot(BeNil())
+		vdbUpdate.Spec.Subclusters[0].Size--
+		Expect(vdbUpdate.validateImmutableFields(vdbOrig)).Should(BeNil())
+	})
 	It("should not change isPrimary after creation", func() {
 		vdbUpdate := createVDBHelper()
 		vdbUpdate.Spec.Subclusters[0].IsPrimary = !vdbUpdate.Spec.Subclusters[0].IsPrimary
@@ -372,6 +413,22 @@ var _ = Describe("verticadb_webhook", func() {
 		validateSpecValuesHaveErr(vdb, true)
 	})
 
+	It("should require a valid restore point for the Restore initPolicy", func() {
+		vdb := createVDBHelper()
+		vdb.Spec.InitPolicy = CommunalInitPolicyRestore
+		validateSpecValuesHaveErr(vdb, true)
+		vdb.Spec.RestorePoint = &RestorePointPolicy{
+			ID:     "nightly_20230101_020000",
+			Target: BackupTarget{Path: "s3://backup-bucket/db"},
+		}
+		validateSpecValuesHaveErr(vdb, false)
+		vdb.Spec.RestorePoint.ID = "nightly"
+		validateSpecValuesHaveErr(vdb, true)
+		vdb.Spec.RestorePoint.ID = "nightly_20230101_020000"
+		vdb.Spec.RestorePoint.Target.Path = "/backups"
+		validateSpecValuesHaveErr(vdb, true)
+	})
+
 	It("should only allow nodePort if serviceType allows for it", func() {
 		vdb := createVDBHelper()
 		vdb.Spec.Subclusters[0].ServiceType = v1.ServiceTypeNodePort
@@ -590,6 +647,49 @@ var _ = Describe("verticadb_webhook", func() {
 		validateSpecValuesHaveErr(vdb, false)
 	})
 
+	It("should prevent negative values for httpServerCertRenewalDays", func() {
+		vdb := MakeVDB()
+		vdb.Spec.HTTPServerCertRenewalDays = -1
+		validateSpecValuesHaveErr(vdb, true)
+		vdb.Spec.HTTPServerCertRenewalDays = 60
+		validateSpecValuesHaveErr(vdb, false)
+	})
+
+	It("should validate the httpServerCertIssuer", func() {
+		vdb := MakeVDB()
+		vdb.Spec.HTTPServerCertIssuer = &CertIssuerReference{}
+		validateSpecValuesHaveErr(vdb, true)
+		vdb.Spec.HTTPServerCertIssuer.Name = "my-issuer"
+		validateSpecValuesHaveErr(vdb, false)
+		vdb.Spec.HTTPServerCertIssuer.Kind = "BadKind"
+		validateSpecValuesHaveErr(vdb, true)
+		vdb.Spec.HTTPServerCertIssuer.Kind = CertIssuerKindClusterIssuer
+		validateSpecValuesHaveErr(vdb, false)
+		vdb.Spec.HTTPServerCertIssuer.Kind = "ExternalIssuer"
+		vdb.Spec.HTTPServerCertIssuer.Group = "example.com"
+		validateSpecValuesHaveErr(vdb, false)
+	})
+
+	It("should validate the clientTLS mode", func() {
+		vdb := MakeVDB()
+		vdb.Spec.ClientTLS = &ClientTLS{}
+		validateSpecValuesHaveErr(vdb, false)
+		vdb.Spec.ClientTLS.Mode = ClientTLSModeVerifyCA
+		validateSpecValuesHaveErr(vdb, false)
+		vdb.Spec.ClientTLS.Mode = "VERIFY_FULL"
+		validateSpecValuesHaveErr(vdb, true)
+	})
+
+	It("should only allow the experimental https node state if the http server is enabled", func() {
+		vdb := MakeVDB()
+		vdb.Spec.HTTPServerMode = HTTPServerModeDisabled
+		validateSpecValuesHaveErr(vdb, false)
+		vdb.Annotations[ExperimentalHTTPSNodeStateAnnotation] = "true"
+		validateSpecValuesHaveErr(vdb, true)
+		vdb.Spec.HTTPServerMode = HTTPServerModeEnabled
+		validateSpecValuesHaveErr(vdb, false)
+	})
+
 	It("should prevent encryptSpreadComm from changing", func() {
 		vdbOrig := MakeVDB()
 		vdbOrig.Spec.EncryptSpreadComm = EncryptSpreadCommWithVertica
@@ -647,6 +747,23 @@ var _ = Describe("verticadb_webhook", func() {
 		validateSpecValuesHaveErr(vdb, false)
 	})
 
+	It("should only allow secondary subclusters to be shut down", func() {
+		vdb := MakeVDB()
+		vdb.Spec.Subclusters = append(vdb.Spec.Subclusters, Subcluster{Name: "sc2", Size: 3, IsPrimary: false})
+		vdb.Spec.Subclusters[1].Shutdown = true
+		validateSpecValuesHaveErr(vdb, false)
+		vdb.Spec.Subclusters[0].Shutdown = true
+		validateSpecValuesHaveErr(vdb, true)
+	})
+
+	It("should not allow hibernate with ScheduleOnly", func() {
+		vdb := MakeVDB()
+		vdb.Spec.Hibernate = true
+		validateSpecValuesHaveErr(vdb, false)
+		vdb.Spec.InitPolicy = CommunalInitPolicyScheduleOnly
+		validateSpecValuesHaveErr(vdb, true)
+	})
+
 	It("should verify the shard count", func() {
 		vdb := MakeVDB()
 		vdb.Spec.ShardCount = 0
@@ -656,6 +773,24 @@ var _ = Describe("verticadb_webhook", func() {
 		vdb.Spec.ShardCount = 1
 		validateSpecValuesHaveErr(vdb, false)
 	})
+
+	It("should verify the maintenance windows", func() {
+		vdb := MakeVDB()
+		vdb.Spec.MaintenanceWindows = []MaintenanceWindow{
+			{Schedule: "0 2 * * sat", Duration: metav1.Duration{Duration: 4 * time.Hour}},
+		}
+		validateSpecValuesHaveErr(vdb, false)
+		vdb.Spec.MaintenanceWindows[0].TimeZone = "UTC"
+		validateSpecValuesHaveErr(vdb, false)
+		vdb.Spec.MaintenanceWindows[0].TimeZone = "Not/AZone"
+		validateSpecValuesHaveErr(vdb, true)
+		vdb.Spec.MaintenanceWindows[0].TimeZone = ""
+		vdb.Spec.MaintenanceWindows[0].Schedule = "0 2 * *"
+		validateSpecValuesHaveErr(vdb, true)
+		vdb.Spec.MaintenanceWindows[0].Schedule = "0 2 * * *"
+		vdb.Spec.MaintenanceWindows[0].Duration = metav1.Duration{}
+		validateSpecValuesHaveErr(vdb, true)
+	})
 })
 
 func createVDBHelper() *VerticaDB {
@@ -0,0 +1,322 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	"github.com/vertica/vertica-kubernetes/pkg/vctl"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// commonFlags are the flags that every subcommand accepts to find the cluster
+type commonFlags struct {
+	kubeconfig string
+	kubeCtx    string
+	namespace  string
+}
+
+// subcommand is a single operation of the plugin
+type subcommand struct {
+	usage string
+	help  string
+	run   func(ctx context.Context, args []string) error
+}
+
+var subcommands = map[string]subcommand{
+	"status": {
+		usage: "status <verticadb>",
+		help:  "Show the subclusters, pods, upgrade progress and related objects of a VerticaDB",
+		run:   runStatus,
+	},
+	"restart": {
+		usage: "restart <verticadb> [--subcluster=<name>] [--timeout=<duration>] [--force]",
+		help:  "Evict the pods of a VerticaDB one at a time, waiting for each to be ready again",
+		run:   runRestart,
+	},
+	"pause": {
+		usage: "pause [<kind>/]<name> [--actors=<name>,...]",
+		help:  "Stop the operator from reconciling a VerticaDB (the default kind), VerticaAutoscaler or EventTrigger",
+		run:   func(ctx context.Context, args []string) error { return runPause(ctx, args, true) },
+	},
+	"resume": {
+		usage: "resume [<kind>/]<name>",
+		help:  "Let the operator reconcile an object that was paused",
+		run:   func(ctx context.Context, args []string) error { return runPause(ctx, args, false) },
+	},
+	"diag": {
+		usage: "diag <verticadb> [--output-dir=<dir>]",
+		help:  "Collect the objects, events and logs of a VerticaDB into a directory",
+		run:   runDiag,
+	},
+	"admintools-conf": {
+		usage: "admintools-conf <verticadb> [--from-pod=<pod>]",
+		help:  "Print the admintools.conf the operator generates for the pods, or the one in a pod",
+		run:   runATConf,
+	},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: kubectl vertica <command> [OPTIONS]\n\nCommands:\n")
+	for _, nm := range []string{"status", "restart", "pause", "resume", "diag", "admintools-conf"} {
+		fmt.Fprintf(os.Stderr, "  %s\n      %s\n", subcommands[nm].usage, subcommands[nm].help)
+	}
+	fmt.Fprintf(os.Stderr, "\nAll commands accept --kubeconfig, --context and -n/--namespace.\n")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+	cmd, ok := subcommands[os.Args[1]]
+	if !ok {
+		if os.Args[1] != "-h" && os.Args[1] != "--help" && os.Args[1] != "help" {
+			fmt.Fprintf(os.Stderr, "Unknown command '%s'\n\n", os.Args[1])
+		}
+		usage()
+		os.Exit(1)
+	}
+	if err := cmd.run(context.Background(), os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// newFlagSet returns the flag set for a subcommand with the common flags
+// already added.
+func newFlagSet(name string, common *commonFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("kubectl vertica "+name, flag.ExitOnError)
+	fs.StringVar(&common.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file to use")
+	fs.StringVar(&common.kubeCtx, "context", "", "The name of the kubeconfig context to use")
+	fs.StringVar(&common.namespace, "namespace", "", "The namespace of the object. Defaults to the one in the kubeconfig.")
+	fs.StringVar(&common.namespace, "n", "", "Shorthand for --namespace")
+	return fs
+}
+
+// parseArgs will parse the flags of a subcommand.  Unlike flag.Parse, flags
+// can come after the positional arguments, which are returned.
+func parseArgs(fs *flag.FlagSet, args []string, numPositional int) []string {
+	positional := []string{}
+	for {
+		_ = fs.Parse(args) // ExitOnError handles the errors
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(positional) != numPositional {
+		fmt.Fprintf(os.Stderr, "Expected %d positional arguments but got %d\n", numPositional, len(positional))
+		fs.Usage()
+		os.Exit(1)
+	}
+	return positional
+}
+
+// makeClients returns the clients to use for the cluster, along with the
+// namespace the command applies to.
+func (c *commonFlags) makeClients() (client.Client, *rest.Config, string, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = c.kubeconfig
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: c.kubeCtx}
+	overrides.Context.Namespace = c.namespace
+	cc := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides)
+	cfg, err := cc.ClientConfig()
+	if err != nil {
+		return nil, nil, "", err
+	}
+	ns, _, err := cc.Namespace()
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, nil, "", err
+	}
+	if err := vapi.AddToScheme(scheme); err != nil {
+		return nil, nil, "", err
+	}
+	clnt, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return clnt, cfg, ns, nil
+}
+
+// fetchVDB returns the VerticaDB named in the positional argument
+func fetchVDB(ctx context.Context, clnt client.Client, ns, name string) (*vapi.VerticaDB, error) {
+	vdb := &vapi.VerticaDB{}
+	if err := clnt.Get(ctx, types.NamespacedName{Namespace: ns, Name: name}, vdb); err != nil {
+		return nil, err
+	}
+	return vdb, nil
+}
+
+func runStatus(ctx context.Context, args []string) error {
+	common := commonFlags{}
+	fs := newFlagSet("status", &common)
+	pos := parseArgs(fs, args, 1)
+	clnt, _, ns, err := common.makeClients()
+	if err != nil {
+		return err
+	}
+	report, err := vctl.FetchStatusReport(ctx, clnt, types.NamespacedName{Namespace: ns, Name: pos[0]})
+	if err != nil {
+		return err
+	}
+	return report.Print(os.Stdout)
+}
+
+func runRestart(ctx context.Context, args []string) error {
+	common := commonFlags{}
+	fs := newFlagSet("restart", &common)
+	subclusters := ""
+	opts := vctl.RestartOptions{PollInterval: vctl.DefaultRestartPollInterval, Out: os.Stdout}
+	fs.StringVar(&subclusters, "subcluster", "",
+		"A comma separated list of subclusters to restart. If omitted, all subclusters are restarted.")
+	fs.DurationVar(&opts.Timeout, "timeout", vctl.DefaultRestartTimeout,
+		"The amount of time to wait for each pod to be ready after it was restarted")
+	fs.BoolVar(&opts.Force, "force", false,
+		"Restart the primary pods even if the database has a k-safety of 0, which takes the database down")
+	pos := parseArgs(fs, args, 1)
+	if subclusters != "" {
+		opts.Subclusters = strings.Split(subclusters, ",")
+	}
+	clnt, _, ns, err := common.makeClients()
+	if err != nil {
+		return err
+	}
+	vdb, err := fetchVDB(ctx, clnt, ns, pos[0])
+	if err != nil {
+		return err
+	}
+	return vctl.RollingRestart(ctx, clnt, vdb, &opts)
+}
+
+func runPause(ctx context.Context, args []string, pause bool) error {
+	name := "resume"
+	if pause {
+		name = "pause"
+	}
+	common := commonFlags{}
+	fs := newFlagSet(name, &common)
+	actors := ""
+	if pause {
+		fs.StringVar(&actors, "actors", "",
+			"A comma separated list of the reconcile actors to skip (e.g. RestartReconciler). If omitted, the entire reconcile is paused.")
+	}
+	pos := parseArgs(fs, args, 1)
+	kind, objName, found := strings.Cut(pos[0], "/")
+	if !found {
+		kind, objName = vapi.VerticaDBKind, pos[0]
+	}
+	obj, err := vctl.MakeObjectForKind(kind)
+	if err != nil {
+		return err
+	}
+	clnt, _, ns, err := common.makeClients()
+	if err != nil {
+		return err
+	}
+	var actorList []string
+	if actors != "" {
+		actorList = strings.Split(actors, ",")
+	}
+	if err := vctl.SetPauseReconcile(ctx, clnt, obj, types.NamespacedName{Namespace: ns, Name: objName}, pause, actorList); err != nil {
+		return err
+	}
+	fmt.Printf("Reconcile of %s/%s is %sd\n", kind, objName, name)
+	return nil
+}
+
+func runDiag(ctx context.Context, args []string) error {
+	common := commonFlags{}
+	fs := newFlagSet("diag", &common)
+	dir := ""
+	fs.StringVar(&dir, "output-dir", "",
+		"The directory to write the diagnostics to. Defaults to a new directory in the current one.")
+	pos := parseArgs(fs, args, 1)
+	clnt, cfg, ns, err := common.makeClients()
+	if err != nil {
+		return err
+	}
+	vdb, err := fetchVDB(ctx, clnt, ns, pos[0])
+	if err != nil {
+		return err
+	}
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+	if dir == "" {
+		dir = fmt.Sprintf("vertica-diag-%s-%s", vdb.Name, time.Now().Format("20060102-150405"))
+	}
+	d := vctl.DiagCollector{
+		Client:    clnt,
+		Clientset: clientset,
+		PRunner:   cmds.MakeClusterPodRunner(logr.Discard(), cfg, ""),
+		Vdb:       vdb,
+		Dir:       dir,
+		Out:       os.Stdout,
+	}
+	return d.Collect(ctx)
+}
+
+func runATConf(ctx context.Context, args []string) error {
+	common := commonFlags{}
+	fs := newFlagSet("admintools-conf", &common)
+	fromPod := ""
+	fs.StringVar(&fromPod, "from-pod", "",
+		"Print the admintools.conf that is currently in this pod instead of generating one")
+	pos := parseArgs(fs, args, 1)
+	clnt, cfg, ns, err := common.makeClients()
+	if err != nil {
+		return err
+	}
+	vdb, err := fetchVDB(ctx, clnt, ns, pos[0])
+	if err != nil {
+		return err
+	}
+	var contents string
+	if fromPod != "" {
+		prunner := cmds.MakeClusterPodRunner(logr.Discard(), cfg, "")
+		contents, err = vctl.FetchATConf(ctx, prunner, names.GenNamespacedName(vdb, fromPod))
+	} else {
+		contents, err = vctl.GenATConf(ctx, clnt, vdb)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Print(contents)
+	return nil
+}
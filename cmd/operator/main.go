@@ -23,6 +23,9 @@ import (
 	"os"
 	"strconv"
 	"time"
+	// Embed the time zone database so the time zones of the maintenance
+	// windows can be loaded in the operator image.
+	_ "time/tzdata"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -36,6 +39,7 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/config/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -44,7 +48,9 @@ import (
 	"github.com/vertica/vertica-kubernetes/pkg/builder"
 	"github.com/vertica/vertica-kubernetes/pkg/controllers/et"
 	"github.com/vertica/vertica-kubernetes/pkg/controllers/vas"
+	"github.com/vertica/vertica-kubernetes/pkg/controllers/vb"
 	"github.com/vertica/vertica-kubernetes/pkg/controllers/vdb"
+	"github.com/vertica/vertica-kubernetes/pkg/monitoring"
 	"github.com/vertica/vertica-kubernetes/pkg/opcfg"
 	"github.com/vertica/vertica-kubernetes/pkg/security"
 	//+kubebuilder:scaffold:imports
@@ -80,6 +86,35 @@ func getWatchNamespace() (string, error) {
 	return ns, nil
 }
 
+// getWatchNamespaces returns the namespaces the operator should be watching for
+// changes.  An empty slice means we watch all namespaces.
+func getWatchNamespaces(oc *opcfg.OperatorConfig) []string {
+	if oc.WatchNamespaces != "" {
+		return opcfg.ParseWatchNamespaces(oc.WatchNamespaces)
+	}
+	ns, err := getWatchNamespace()
+	if err != nil {
+		return []string{}
+	}
+	return opcfg.ParseWatchNamespaces(ns)
+}
+
+// getOperatorNamespace returns the namespace the operator is deployed in
+func getOperatorNamespace() (string, error) {
+	const OperatorNamespaceEnvVar = "OPERATOR_NAMESPACE"
+	ns, found := os.LookupEnv(OperatorNamespaceEnvVar)
+	if found && ns != "" {
+		return ns, nil
+	}
+	// For backwards compatibility with deployments that don't set the env,
+	// the operator namespace is the namespace that we watch.
+	ns, err := getWatchNamespace()
+	if err != nil || len(opcfg.ParseWatchNamespaces(ns)) != 1 {
+		return "", fmt.Errorf("%s must be set", OperatorNamespaceEnvVar)
+	}
+	return ns, nil
+}
+
 // getIsWebhookEnabled will return true if the webhook is enabled
 func getIsWebhookEnabled() bool {
 	const DefaultEnabled = true
@@ -100,6 +135,9 @@ func getIsWebhookEnabled() bool {
 // addReconcilersToManager will add a controller for each CR that this operator
 // handles.  If any failure occurs, if will exit the program.
 func addReconcilersToManager(mgr manager.Manager, restCfg *rest.Config, oc *opcfg.OperatorConfig) {
+	// The namespace is only used to narrow the NetworkPolicies down to the
+	// operator pods, so it is fine if we can't determine it.
+	operatorNamespace, _ := getOperatorNamespace()
 	if err := (&vdb.VerticaDBReconciler{
 		Client: mgr.GetClient(),
 		Log:    ctrl.Log.WithName("controllers").WithName("VerticaDB"),
@@ -110,7 +148,9 @@ func addReconcilersToManager(mgr manager.Manager, restCfg *rest.Config, oc *opcf
 		DeploymentNames: builder.DeploymentNames{
 			ServiceAccountName: oc.ServiceAccountName,
 			PrefixName:         oc.PrefixName,
+			Namespace:          operatorNamespace,
 		},
+		PFactsCache: vdb.MakePodFactsCache(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "VerticaDB")
 		os.Exit(1)
@@ -129,10 +169,22 @@ func addReconcilersToManager(mgr manager.Manager, restCfg *rest.Config, oc *opcf
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 		Log:    ctrl.Log.WithName("controllers").WithName("EventTrigger"),
+		EVRec:  mgr.GetEventRecorderFor(builder.OperatorName),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "EventTrigger")
 		os.Exit(1)
 	}
+	if err := (&vb.VerticaBackupReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		Log:    ctrl.Log.WithName("controllers").WithName("VerticaBackup"),
+		Cfg:    restCfg,
+		EVRec:  mgr.GetEventRecorderFor(builder.OperatorName),
+		OpCfg:  *oc,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "VerticaBackup")
+		os.Exit(1)
+	}
 	//+kubebuilder:scaffold:builder
 }
 
@@ -160,25 +212,88 @@ func addWebhooksToManager(mgr manager.Manager) {
 		setupLog.Error(err, "unable to create webhook", "webhook", "EventTrigger")
 		os.Exit(1)
 	}
+	if err := (&vapi.VerticaBackup{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "VerticaBackup")
+		os.Exit(1)
+	}
+}
+
+// addOperatorServiceMonitor will have the manager create the ServiceMonitor
+// for the operator metrics once it starts.  Failures are logged but don't stop
+// the operator since the metrics aren't critical.
+func addOperatorServiceMonitor(mgr manager.Manager, oc *opcfg.OperatorConfig) {
+	if oc.MetricsAddr == "0" {
+		setupLog.Info("Skipping the ServiceMonitor for the operator metrics since they are disabled")
+		return
+	}
+	operatorNamespace, err := getOperatorNamespace()
+	if err != nil {
+		setupLog.Info("Skipping the ServiceMonitor for the operator metrics since we cannot determine the namespace of the operator",
+			"err", err)
+		return
+	}
+	err = mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		installed, err := monitoring.IsKindInstalled(mgr.GetRESTMapper(), monitoring.ServiceMonitorGVK)
+		if err != nil {
+			setupLog.Error(err, "unable to check if the ServiceMonitor CRD is installed")
+			return nil
+		}
+		if !installed {
+			setupLog.Info("Skipping the ServiceMonitor for the operator metrics since the prometheus operator is not installed")
+			return nil
+		}
+		nm := monitoring.GenOperatorServiceMonitorName(oc.PrefixName, operatorNamespace)
+		if _, err := monitoring.Apply(ctx, mgr.GetClient(), monitoring.BuildOperatorServiceMonitor(nm, oc.MetricsAddr)); err != nil {
+			setupLog.Error(err, "unable to create the ServiceMonitor for the operator metrics")
+		}
+		return nil
+	}))
+	if err != nil {
+		setupLog.Error(err, "unable to add the ServiceMonitor creation to the manager")
+	}
 }
 
 // setupWebhook will setup the webhook in the manager if enabled
 func setupWebhook(ctx context.Context, mgr manager.Manager, restCfg *rest.Config, oc *opcfg.OperatorConfig) error {
 	if getIsWebhookEnabled() {
-		watchNamespace, err := getWatchNamespace()
+		operatorNamespace, err := getOperatorNamespace()
 		if err != nil {
-			// We cannot setup webhooks if we are watching multiple namespaces
-			// because the webhook config uses a namespaceSelector.
-			setupLog.Info("Disabling webhook since we are not watching a single namespace")
+			// The webhook config and service are named after the namespace
+			// the operator is deployed in.
+			setupLog.Info("Disabling webhook since we cannot determine the namespace of the operator", "err", err)
 			return nil
 		}
-		if oc.WebhookCertSecret == "" {
-			if err := security.GenerateWebhookCert(ctx, &setupLog, restCfg, CertDir, oc.PrefixName, watchNamespace); err != nil {
+		if oc.WebhookCertSecret == "" && oc.WebhookCertIssuerName != "" {
+			issuer := security.CertManagerIssuer{Name: oc.WebhookCertIssuerName, Kind: oc.WebhookCertIssuerKind}
+			if err := security.GenerateWebhookCertWithCertManager(ctx, &setupLog, restCfg, CertDir, oc.PrefixName,
+				operatorNamespace, issuer); err != nil {
+				return err
+			}
+			// cert-manager renews the cert in place. Keep the copy in CertDir
+			// current for as long as the manager runs.
+			err = mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+				return security.WatchWebhookCertFromCertManager(ctx, &setupLog, restCfg, CertDir, oc.PrefixName, operatorNamespace)
+			}))
+			if err != nil {
+				return err
+			}
+		} else if oc.WebhookCertSecret == "" {
+			if err := security.GenerateWebhookCert(ctx, &setupLog, restCfg, CertDir, oc.PrefixName, operatorNamespace); err != nil {
 				return err
 			}
 		} else if !oc.SkipWebhookPatch {
 			if err := security.PatchWebhookCABundleFromSecret(ctx, &setupLog, restCfg, oc.WebhookCertSecret,
-				oc.PrefixName, watchNamespace); err != nil {
+				oc.PrefixName, operatorNamespace); err != nil {
+				return err
+			}
+		}
+		// The namespaceSelector in the webhook config must include every
+		// namespace that we watch. When the CA bundle isn't patched, we don't
+		// have access to the webhook config and rely on the deployment to
+		// set the selector.
+		if !oc.SkipWebhookPatch {
+			if err := security.PatchWebhookNamespaceSelector(ctx, &setupLog, restCfg, oc.PrefixName,
+				operatorNamespace, getWatchNamespaces(oc)); err != nil {
 				return err
 			}
 		}
@@ -224,35 +339,46 @@ func main() {
 
 	restCfg := ctrl.GetConfigOrDie()
 
-	watchNamespace, err := getWatchNamespace()
-	if err != nil {
-		setupLog.Info("unable to get WatchNamespace, " +
-			"the manager will watch and manage resources in all namespaces")
-	}
-
-	mgr, err := ctrl.NewManager(restCfg, ctrl.Options{
+	mgrOpts := ctrl.Options{
 		Scheme:                 scheme,
 		MetricsBindAddress:     oc.MetricsAddr,
 		Port:                   9443,
 		HealthProbeBindAddress: oc.ProbeAddr,
 		LeaderElection:         oc.EnableLeaderElection,
 		LeaderElectionID:       "5c1e6227.vertica.com",
-		Namespace:              watchNamespace,
 		CertDir:                CertDir,
 		Controller: v1alpha1.ControllerConfigurationSpec{
 			GroupKindConcurrency: map[string]int{
 				vapi.GkVDB.String(): 1,
 				vapi.GkVAS.String(): 1,
 				vapi.GkET.String():  1,
+				vapi.GkVB.String():  1,
 			},
 		},
-	})
+	}
+	watchNamespaces := getWatchNamespaces(oc)
+	switch len(watchNamespaces) {
+	case 0:
+		setupLog.Info("the manager will watch and manage resources in all namespaces")
+	case 1:
+		mgrOpts.Namespace = watchNamespaces[0]
+	default:
+		// The multi-namespace cache keeps an informer per namespace. Objects
+		// in other namespaces are never seen by the controllers.
+		mgrOpts.NewCache = cache.MultiNamespacedCacheBuilder(watchNamespaces)
+	}
+	setupLog.Info("watching namespaces", "namespaces", watchNamespaces)
+
+	mgr, err := ctrl.NewManager(restCfg, mgrOpts)
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
 	addReconcilersToManager(mgr, restCfg, oc)
+	if oc.CreatePrometheusMonitors {
+		addOperatorServiceMonitor(mgr, oc)
+	}
 	ctx := ctrl.SetupSignalHandler()
 	if err := setupWebhook(ctx, mgr, restCfg, oc); err != nil {
 		setupLog.Error(err, "unable to setup webhook")
@@ -41,3 +41,16 @@ it is generated internally)
 {{- include "vdb-op.name" . }}-controller-manager-service-cert
 {{- end }}
 {{- end }}
+
+{{/*
+The namespaceSelector expression used in the webhook configurations. The
+webhook must apply to every namespace the operator watches.
+*/}}
+{{- define "vdb-op.webhookNamespaceMatch" -}}
+{{- if eq (trim .Values.watchNamespaces) "*" }}
+operator: "Exists"
+{{- else }}
+operator: "In"
+values: [{{ default .Release.Namespace .Values.watchNamespaces }}]
+{{- end }}
+{{- end }}
@@ -28,6 +28,8 @@ import (
 	"github.com/vertica/vertica-kubernetes/pkg/paths"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -43,6 +45,9 @@ const (
 	InternalVerticaCommPort = 5434
 	SSHPort                 = 22
 
+	// The value of the app.kubernetes.io/name label in the operator pods
+	OperatorPodAppName = "verticadb-operator"
+
 	// Standard environment variables that are set in each pod
 	PodIPEnv        = "POD_IP"
 	HostIPEnv       = "HOST_IP"
@@ -689,6 +694,12 @@ func getStorageClassName(vdb *vapi.VerticaDB) *string {
 
 // BuildStsSpec builds manifest for a subclusters statefulset
 func BuildStsSpec(nm types.NamespacedName, vdb *vapi.VerticaDB, sc *vapi.Subcluster, deployNames *DeploymentNames) *appsv1.StatefulSet {
+	// A subcluster that is shut down has no pods, but it keeps its size so
+	// that it can be started again.
+	replicas := sc.Size
+	if sc.Shutdown {
+		replicas = 0
+	}
 	return &appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        nm.Name,
@@ -701,7 +712,7 @@ func BuildStsSpec(nm types.NamespacedName, vdb *vapi.VerticaDB, sc *vapi.Subclus
 				MatchLabels: MakeStsSelectorLabels(vdb, sc),
 			},
 			ServiceName: names.GenHlSvcName(vdb).Name,
-			Replicas:    &sc.Size,
+			Replicas:    &replicas,
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels:      MakeLabelsForPodObject(vdb, sc),
@@ -731,6 +742,176 @@ func BuildStsSpec(nm types.NamespacedName, vdb *vapi.VerticaDB, sc *vapi.Subclus
 	}
 }
 
+// BuildPDB builds the PodDisruptionBudget for a secondary subcluster's pods.
+// The pods of the primary subclusters share the one from BuildPrimaryPDB.
+func BuildPDB(nm types.NamespacedName, vdb *vapi.VerticaDB, sc *vapi.Subcluster) *policyv1.PodDisruptionBudget {
+	maxUnavailable := intstr.FromInt(int(getPDBMaxUnavailable(vdb, sc)))
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        nm.Name,
+			Namespace:   nm.Namespace,
+			Labels:      makeLabelsForObject(vdb, sc, false),
+			Annotations: MakeAnnotationsForObject(vdb),
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: MakeStsSelectorLabels(vdb, sc),
+			},
+			MaxUnavailable: &maxUnavailable,
+		},
+	}
+}
+
+// BuildPrimaryPDB builds the PodDisruptionBudget for the pods of all of the
+// primary subclusters.  A single budget is used because quorum and shard
+// coverage are for the entire database, not for each subcluster.
+func BuildPrimaryPDB(nm types.NamespacedName, vdb *vapi.VerticaDB) *policyv1.PodDisruptionBudget {
+	maxUnavailable := intstr.FromInt(int(getPrimaryPDBMaxUnavailable(vdb)))
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        nm.Name,
+			Namespace:   nm.Namespace,
+			Labels:      makeLabelsForObject(vdb, nil, false),
+			Annotations: MakeAnnotationsForObject(vdb),
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: MakePrimarySelectorLabels(vdb),
+			},
+			MaxUnavailable: &maxUnavailable,
+		},
+	}
+}
+
+// IsPDBNeeded returns true if the subcluster has its own PodDisruptionBudget.
+// The primary subclusters are covered by the one from BuildPrimaryPDB, and a
+// subcluster that is shut down has no pods to protect.
+func IsPDBNeeded(sc *vapi.Subcluster) bool {
+	return !sc.IsPrimary && !sc.Shutdown
+}
+
+// getPrimaryPDBMaxUnavailable returns the number of primary pods that can be
+// voluntarily disrupted at once (e.g. by a node drain).  Primary nodes count
+// toward quorum, so we can never lose half of them.  On top of that, a
+// k-safety 0 database can't lose any of them and a k-safety 1 database can
+// lose one at a time.
+func getPrimaryPDBMaxUnavailable(vdb *vapi.VerticaDB) int32 {
+	var maxUnavailable int32
+	if vdb.Spec.KSafety != vapi.KSafety0 {
+		var primaryCount int32
+		for i := range vdb.Spec.Subclusters {
+			if vdb.Spec.Subclusters[i].IsPrimary {
+				primaryCount += vdb.Spec.Subclusters[i].Size
+			}
+		}
+		maxUnavailable = (primaryCount - 1) / 2
+		if maxUnavailable > 1 {
+			maxUnavailable = 1
+		}
+	}
+	return relaxPDBForImageChange(vdb, maxUnavailable)
+}
+
+// getPDBMaxUnavailable returns the number of pods in a secondary subcluster
+// that can be voluntarily disrupted at once.  Secondary and transient
+// subclusters don't affect quorum, so we only keep half of the pods up to
+// continue serving queries.
+func getPDBMaxUnavailable(vdb *vapi.VerticaDB, sc *vapi.Subcluster) int32 {
+	if sc.Size/2 > 1 {
+		return relaxPDBForImageChange(vdb, sc.Size/2)
+	}
+	return relaxPDBForImageChange(vdb, 1)
+}
+
+// relaxPDBForImageChange will allow at least one pod to be disrupted while
+// the image is being changed.  The operator only takes down the pods it needs
+// to, so we don't open the budget any further than that.
+func relaxPDBForImageChange(vdb *vapi.VerticaDB, maxUnavailable int32) int32 {
+	if vdb.IsImageChangeInProgress() && maxUnavailable < 1 {
+		return 1
+	}
+	return maxUnavailable
+}
+
+// BuildNetworkPolicy builds the NetworkPolicy that allows the traffic
+// between the pods of the VerticaDB and from the operator.  All ports are
+// open between the pods as the database uses a handful of them (client,
+// internal, spread, agent, http server) and spread ports can be changed.
+func BuildNetworkPolicy(nm types.NamespacedName, vdb *vapi.VerticaDB, deployNames *DeploymentNames) *networkingv1.NetworkPolicy {
+	operatorPeer := networkingv1.NetworkPolicyPeer{
+		PodSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"app.kubernetes.io/name": OperatorPodAppName},
+		},
+		// An empty namespace selector matches all namespaces.  We use it if
+		// we don't know where the operator is deployed.
+		NamespaceSelector: &metav1.LabelSelector{},
+	}
+	if deployNames.Namespace != "" {
+		operatorPeer.NamespaceSelector.MatchLabels = map[string]string{corev1.LabelMetadataName: deployNames.Namespace}
+	}
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        nm.Name,
+			Namespace:   nm.Namespace,
+			Labels:      makeLabelsForObject(vdb, nil, false),
+			Annotations: MakeAnnotationsForObject(vdb),
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: MakeBaseSvcSelectorLabels(vdb),
+			},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{
+					From: []networkingv1.NetworkPolicyPeer{
+						{PodSelector: &metav1.LabelSelector{MatchLabels: MakeBaseSvcSelectorLabels(vdb)}},
+					},
+				},
+				{
+					From:  []networkingv1.NetworkPolicyPeer{operatorPeer},
+					Ports: makeNetworkPolicyPorts(VerticaClientPort, VerticaHTTPPort),
+				},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+}
+
+// BuildClientNetworkPolicy builds the NetworkPolicy that allows client
+// traffic to the ports exposed by the service object of a subcluster.
+func BuildClientNetworkPolicy(nm types.NamespacedName, vdb *vapi.VerticaDB, sc *vapi.Subcluster) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        nm.Name,
+			Namespace:   nm.Namespace,
+			Labels:      makeLabelsForObject(vdb, sc, false),
+			Annotations: MakeAnnotationsForObject(vdb),
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: MakeStsSelectorLabels(vdb, sc),
+			},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{
+					From:  vdb.GetClientIngressFrom(sc),
+					Ports: makeNetworkPolicyPorts(VerticaClientPort, VerticaHTTPPort, VerticaAgentPort),
+				},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+}
+
+// makeNetworkPolicyPorts returns the TCP ports for a NetworkPolicy rule
+func makeNetworkPolicyPorts(ports ...int) []networkingv1.NetworkPolicyPort {
+	npPorts := make([]networkingv1.NetworkPolicyPort, len(ports))
+	for i := range ports {
+		proto := corev1.ProtocolTCP
+		port := intstr.FromInt(ports[i])
+		npPorts[i] = networkingv1.NetworkPolicyPort{Protocol: &proto, Port: &port}
+	}
+	return npPorts
+}
+
 // buildPod will construct a spec for a pod.
 // This is only here for testing purposes when we need to construct the pods ourselves.  This
 // bit is typically handled by the statefulset controller.
@@ -21,7 +21,10 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
 	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 var _ = Describe("builder", func() {
@@ -170,6 +173,97 @@ var _ = Describe("builder", func() {
 		Expect(c.SecurityContext.Sysctls[1].Name).Should(Equal("net.ipv4.tcp_keepalive_intvl"))
 		Expect(c.SecurityContext.Sysctls[1].Value).Should(Equal("5"))
 	})
+
+	It("should have zero replicas for a subcluster that is shut down", func() {
+		vdb := vapi.MakeVDB()
+		sc := &vdb.Spec.Subclusters[0]
+		nm := names.GenStsName(vdb, sc)
+		sts := BuildStsSpec(nm, vdb, sc, &DeploymentNames{})
+		Expect(*sts.Spec.Replicas).Should(Equal(sc.Size))
+		sc.Shutdown = true
+		sts = BuildStsSpec(nm, vdb, sc, &DeploymentNames{})
+		Expect(*sts.Spec.Replicas).Should(Equal(int32(0)))
+		Expect(sc.Size).ShouldNot(Equal(int32(0)))
+	})
+
+	It("should derive the maxUnavailable of the PDB from the k-safety and subcluster type", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.Subclusters = []vapi.Subcluster{
+			{Name: "pri", IsPrimary: true, Size: 3},
+			{Name: "sec", IsPrimary: false, Size: 6},
+			{Name: "small", IsPrimary: false, Size: 1},
+			{Name: "pri2", IsPrimary: true, Size: 3},
+			{Name: "down", IsPrimary: false, Size: 3, Shutdown: true},
+		}
+		Expect(getPrimaryPDBMaxUnavailable(vdb)).Should(Equal(int32(1)))
+		Expect(getPDBMaxUnavailable(vdb, &vdb.Spec.Subclusters[1])).Should(Equal(int32(3)))
+		Expect(getPDBMaxUnavailable(vdb, &vdb.Spec.Subclusters[2])).Should(Equal(int32(1)))
+		Expect(IsPDBNeeded(&vdb.Spec.Subclusters[0])).Should(BeFalse())
+		Expect(IsPDBNeeded(&vdb.Spec.Subclusters[1])).Should(BeTrue())
+		Expect(IsPDBNeeded(&vdb.Spec.Subclusters[4])).Should(BeFalse())
+		vdb.Spec.KSafety = vapi.KSafety0
+		Expect(getPrimaryPDBMaxUnavailable(vdb)).Should(Equal(int32(0)))
+
+		// A single primary node can never be disrupted as we would lose quorum
+		vdb.Spec.KSafety = vapi.KSafety1
+		vdb.Spec.Subclusters = []vapi.Subcluster{{Name: "pri", IsPrimary: true, Size: 1}}
+		Expect(getPrimaryPDBMaxUnavailable(vdb)).Should(Equal(int32(0)))
+
+		pdb := BuildPrimaryPDB(names.GenPrimaryPDBName(vdb), vdb)
+		Expect(pdb.Spec.Selector.MatchLabels).Should(Equal(MakePrimarySelectorLabels(vdb)))
+		Expect(pdb.Spec.MaxUnavailable.IntValue()).Should(Equal(0))
+	})
+
+	It("should only relax the PDB to a single pod during an image change", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.KSafety = vapi.KSafety0
+		vdb.Spec.Subclusters = []vapi.Subcluster{
+			{Name: "pri", IsPrimary: true, Size: 3},
+			{Name: "sec", IsPrimary: false, Size: 6},
+		}
+		vdb.Status.Conditions = make([]vapi.VerticaDBCondition, vapi.ImageChangeInProgressIndex+1)
+		vdb.Status.Conditions[vapi.ImageChangeInProgressIndex] = vapi.VerticaDBCondition{
+			Type:   vapi.ImageChangeInProgress,
+			Status: v1.ConditionTrue,
+		}
+		Expect(getPrimaryPDBMaxUnavailable(vdb)).Should(Equal(int32(1)))
+
+		pdb := BuildPDB(names.GenPDBName(vdb, &vdb.Spec.Subclusters[1]), vdb, &vdb.Spec.Subclusters[1])
+		Expect(pdb.Spec.Selector.MatchLabels).Should(Equal(MakeStsSelectorLabels(vdb, &vdb.Spec.Subclusters[1])))
+		Expect(pdb.Spec.MaxUnavailable.IntValue()).Should(Equal(3))
+	})
+
+	It("should restrict client ingress of the NetworkPolicies to the selectors in the vdb", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.Subclusters = []vapi.Subcluster{
+			{Name: "sc1", IsPrimary: true, Size: 3},
+			{Name: "sc2", IsPrimary: false, Size: 3},
+		}
+		appPeer := networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "app"}},
+		}
+		biPeer := networkingv1.NetworkPolicyPeer{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "bi"}},
+		}
+		vdb.Spec.NetworkPolicy.ClientIngressFrom = []networkingv1.NetworkPolicyPeer{appPeer}
+		vdb.Spec.Subclusters[1].ClientIngressFrom = []networkingv1.NetworkPolicyPeer{biPeer}
+
+		np := BuildClientNetworkPolicy(names.GenClientNetworkPolicyName(vdb, &vdb.Spec.Subclusters[0]), vdb, &vdb.Spec.Subclusters[0])
+		Expect(np.Spec.PodSelector.MatchLabels).Should(Equal(MakeStsSelectorLabels(vdb, &vdb.Spec.Subclusters[0])))
+		Expect(np.Spec.Ingress[0].From).Should(Equal([]networkingv1.NetworkPolicyPeer{appPeer}))
+		Expect(np.Spec.Ingress[0].Ports[0].Port.IntValue()).Should(Equal(VerticaClientPort))
+		np = BuildClientNetworkPolicy(names.GenClientNetworkPolicyName(vdb, &vdb.Spec.Subclusters[1]), vdb, &vdb.Spec.Subclusters[1])
+		Expect(np.Spec.Ingress[0].From).Should(Equal([]networkingv1.NetworkPolicyPeer{biPeer}))
+
+		deployNames := DefaultDeploymentNames()
+		np = BuildNetworkPolicy(names.GenNetworkPolicyName(vdb), vdb, deployNames)
+		Expect(np.Spec.Ingress[0].Ports).Should(BeEmpty())
+		Expect(np.Spec.Ingress[1].From[0].NamespaceSelector.MatchLabels).Should(BeEmpty())
+		deployNames.Namespace = "vertica-operator"
+		np = BuildNetworkPolicy(names.GenNetworkPolicyName(vdb), vdb, deployNames)
+		Expect(np.Spec.Ingress[1].From[0].NamespaceSelector.MatchLabels).Should(
+			Equal(map[string]string{v1.LabelMetadataName: "vertica-operator"}))
+	})
 })
 
 // makeSubPaths is a helper that extracts all of the subPaths from the volume mounts.
@@ -21,6 +21,7 @@ import "fmt"
 type DeploymentNames struct {
 	ServiceAccountName string // Name of the service account to use for vertica pods
 	PrefixName         string // The common prefix for all objects created when deploying the operator
+	Namespace          string // The namespace the operator is deployed in.  Blank if it isn't known.
 }
 
 func (d *DeploymentNames) getConfigMapName() string {
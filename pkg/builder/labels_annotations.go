@@ -162,6 +162,14 @@ func MakeBaseSvcSelectorLabels(vdb *vapi.VerticaDB) map[string]string {
 	}
 }
 
+// MakePrimarySelectorLabels returns the labels that select the pods of all of
+// the primary subclusters
+func MakePrimarySelectorLabels(vdb *vapi.VerticaDB) map[string]string {
+	m := MakeBaseSvcSelectorLabels(vdb)
+	m[SubclusterTypeLabel] = vapi.PrimarySubclusterType
+	return m
+}
+
 // MakeSvcSelectorLabelsForServiceNameRouting will create the labels for when we
 // want a service object to pick the pods based on the service name.  This
 // allows us to combine multiple subcluster under a single service object.
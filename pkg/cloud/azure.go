@@ -15,6 +15,12 @@
 
 package cloud
 
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
 const (
 	// Key names in the communal credentials for Azure blob storage endpoints.
 	AzureAccountName           = "accountName"
@@ -45,3 +51,68 @@ type AzureEndpointConfig struct {
 	Protocol               string `json:"protocol,omitempty"`
 	IsMultiAccountEndpoint bool   `json:"isMultiAccountEndpoint,omitempty"`
 }
+
+// ReadAzureCredential will parse the data of a communal credential secret that
+// is setup for azure. It returns the credential and endpoint config that can be
+// passed to the server. An error is returned if the secret isn't setup
+// properly.
+func ReadAzureCredential(data map[string][]byte) (AzureCredential, AzureEndpointConfig, error) {
+	accountName, hasAccountName := data[AzureAccountName]
+	blobEndpointRaw, hasBlobEndpoint := data[AzureBlobEndpoint]
+
+	if !hasAccountName && !hasBlobEndpoint {
+		return AzureCredential{}, AzureEndpointConfig{},
+			fmt.Errorf("it must have one '%s' or '%s'", AzureAccountName, AzureBlobEndpoint)
+	}
+
+	// The blob endpoint may have a protocol scheme as a prefix.  Strip that off
+	// so its just the host and port.
+	var blobEndpoint string
+	if hasBlobEndpoint {
+		blobEndpoint = GetEndpointHostPort(string(blobEndpointRaw))
+	}
+
+	accountKey, hasAccountKey := data[AzureAccountKey]
+	sas, hasSAS := data[AzureSharedAccessSignature]
+
+	if hasAccountKey && hasSAS {
+		return AzureCredential{}, AzureEndpointConfig{},
+			fmt.Errorf("it cannot have both '%s' and '%s'", AzureAccountKey, AzureSharedAccessSignature)
+	}
+
+	return AzureCredential{
+			AccountName:           string(accountName),
+			BlobEndpoint:          blobEndpoint,
+			AccountKey:            string(accountKey),
+			SharedAccessSignature: string(sas),
+		},
+		AzureEndpointConfig{
+			AccountName:  string(accountName),
+			BlobEndpoint: blobEndpoint,
+			Protocol:     GetEndpointProtocol(string(blobEndpointRaw)),
+		},
+		nil
+}
+
+// GetEndpointProtocol returns the protocol (HTTPS or HTTP) for the given endpoint
+func GetEndpointProtocol(blobEndpoint string) string {
+	if blobEndpoint == "" {
+		return AzureDefaultProtocol
+	}
+	re := regexp.MustCompile(`([a-z]+)://`)
+	m := re.FindAllStringSubmatch(blobEndpoint, 1)
+	if len(m) == 0 || len(m[0]) < 2 {
+		return AzureDefaultProtocol
+	}
+	return strings.ToUpper(m[0][1])
+}
+
+// GetEndpointHostPort returns just the host and port portion of a endpoint
+func GetEndpointHostPort(blobEndpoint string) string {
+	re := regexp.MustCompile(`([a-z]+)://(.*)`)
+	m := re.FindAllStringSubmatch(blobEndpoint, 1)
+	if len(m) == 0 || len(m[0]) < 3 {
+		return blobEndpoint
+	}
+	return strings.TrimSuffix(m[0][2], "/")
+}
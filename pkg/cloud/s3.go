@@ -36,3 +36,18 @@ func IsEndpointBadError(op string) bool {
 func IsBucketNotExistError(op string) bool {
 	return strings.Contains(op, "The specified bucket does not exist")
 }
+
+// GetAccessAndSecretKey returns the access key and secret key stored in the
+// data of a communal credential secret. If either key is missing, the name of
+// the key that couldn't be found is returned in missingKey.
+func GetAccessAndSecretKey(data map[string][]byte) (accessKey, secretKey, missingKey string) {
+	ak, ok := data[CommunalAccessKeyName]
+	if !ok {
+		return "", "", CommunalAccessKeyName
+	}
+	sk, ok := data[CommunalSecretKeyName]
+	if !ok {
+		return "", "", CommunalSecretKeyName
+	}
+	return strings.TrimSuffix(string(ak), "\n"), strings.TrimSuffix(string(sk), "\n"), ""
+}
@@ -78,4 +78,47 @@ var _ = Describe("k8s/cmds", func() {
 		lastCall := fpr.FindCommands("/opt/vertica/bin/admintools", "-t", "db_add_node")
 		Expect(len(lastCall)).Should(Equal(1))
 	})
+
+	It("should record commands without running them or exposing the password", func() {
+		recorded := []string{}
+		rpr := MakeRecordingPodRunner(&FakePodRunner{}, func(podName types.NamespacedName, contName, command string) {
+			recorded = append(recorded, command)
+		})
+		rpr.SetSUPassword("vertica")
+		podName := types.NamespacedName{Namespace: "default", Name: "vdb-pod"}
+		stdout, _, err := rpr.ExecAdmintools(ctx, podName, "server", "-t", "db_add_node", "--password", "secret")
+		Expect(err).Should(Succeed())
+		Expect(stdout).Should(BeEmpty())
+		_, _, _ = rpr.ExecVSQL(ctx, podName, "server", "-tAc", "alter user dbadmin identified by 'new'")
+		_, _, _ = rpr.CopyToPod(ctx, podName, "server", "/tmp/at.conf", "/opt/vertica/config/admintools.conf", "chmod", "600")
+		Expect(recorded).Should(Equal([]string{
+			"admintools -t db_add_node --password *******",
+			"vsql -tAc alter user dbadmin identified by ****",
+			"copy /tmp/at.conf to /opt/vertica/config/admintools.conf && chmod 600",
+		}))
+	})
+
+	It("should run the commands that only read state with the real pod runner", func() {
+		recorded := []string{}
+		fpr := &FakePodRunner{Results: make(CmdResults)}
+		rpr := MakeRecordingPodRunner(fpr, func(podName types.NamespacedName, contName, command string) {
+			recorded = append(recorded, command)
+		})
+		podName := types.NamespacedName{Namespace: "default", Name: "vdb-pod"}
+		fpr.Results[podName] = []CmdResult{{Stdout: "v_db_node0001 UP\n"}}
+		stdout, _, err := rpr.ExecAdmintools(ctx, podName, "server", "-t", "list_allnodes")
+		Expect(err).Should(Succeed())
+		Expect(stdout).Should(Equal("v_db_node0001 UP\n"))
+		_, _, _ = rpr.ExecVSQL(ctx, podName, "server", "-tAc", "select subcluster_name from subclusters where is_default is true")
+		_, _, _ = rpr.ExecInPod(ctx, podName, "server", "vsql", "--password", "secret", "-tAc", "select 1")
+		_, _, _ = rpr.ExecInPod(ctx, podName, "server", "/opt/vertica/bin/vertica", "--version")
+		Expect(fpr.Histories).Should(HaveLen(4))
+		Expect(recorded).Should(BeEmpty())
+
+		_, _, _ = rpr.ExecVSQL(ctx, podName, "server", "-tAc", "select sync_catalog()")
+		_, _, _ = rpr.ExecVSQL(ctx, podName, "server", "-tAc", "select 1 from dual; drop table t")
+		_, _, _ = rpr.ExecInPod(ctx, podName, "server", "bash", "-c", "cat /tmp/a > /tmp/b")
+		Expect(fpr.Histories).Should(HaveLen(4))
+		Expect(recorded).Should(HaveLen(3))
+	})
 })
@@ -39,6 +39,7 @@ type PodRunner interface {
 	ExecAdmintools(ctx context.Context, podName types.NamespacedName, contName string, command ...string) (string, string, error)
 	CopyToPod(ctx context.Context, podName types.NamespacedName, contName string, sourceFile string,
 		destFile string, executeCmd ...string) (stdout, stderr string, err error)
+	SetSUPassword(passwd string)
 }
 
 type ClusterPodRunner struct {
@@ -52,6 +53,11 @@ func MakeClusterPodRunner(log logr.Logger, cfg *rest.Config, passwd string) *Clu
 	return &ClusterPodRunner{Log: log, Cfg: cfg, SUPassword: passwd}
 }
 
+// SetSUPassword changes the superuser password used for vsql and admintools
+func (c *ClusterPodRunner) SetSUPassword(passwd string) {
+	c.SUPassword = passwd
+}
+
 // logInfoCmd calls log function for the given command
 func (c *ClusterPodRunner) logInfoCmd(podName types.NamespacedName, command ...string) {
 	c.Log.Info("ExecInPod entry", "pod", podName, "command", generateLogOutput(command...))
@@ -83,6 +89,10 @@ func obfuscateForLog(s string) string {
 		"awsauth = .*":                 "awsauth = ****",
 		"GCSAuth = .*":                 "GCSAuth = ****",
 		"AzureStorageCredentials = .*": "AzureStorageCredentials = ****",
+		"identified by .*":             "identified by ****",
+		"S3SseCustomerKey = .*":        "S3SseCustomerKey = ****",
+		// The PEM block spans lines, so we need '.' to match new lines
+		"(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----": "****",
 	}
 	for expr, replacement := range pats {
 		r := regexp.MustCompile(expr)
@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -37,6 +38,9 @@ type FakePodRunner struct {
 	Histories []CmdHistory
 	// fake password
 	SUPassword string
+	// Guards Results and Histories.  Pod facts are collected from many pods
+	// at once, so the exec calls can come in concurrently.
+	mu sync.Mutex
 }
 
 // CmdResults stores the command result.  The key is the pod name.
@@ -60,6 +64,8 @@ type CmdHistory struct {
 // is passed in are saved as a history that tests can later inspect.
 func (f *FakePodRunner) ExecInPod(ctx context.Context, podName types.NamespacedName,
 	contName string, command ...string) (stdout, stderr string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	// Record the call that come in.  Some testcases can use this in assertions.
 	f.Histories = append(f.Histories, CmdHistory{Pod: podName, Command: command})
 	// We fake out what is returned by doing a lookup in fakePodOutputs
@@ -87,6 +93,11 @@ func (f *FakePodRunner) ExecVSQL(ctx context.Context, podName types.NamespacedNa
 	return f.ExecInPod(ctx, podName, contName, command...)
 }
 
+// SetSUPassword changes the fake password
+func (f *FakePodRunner) SetSUPassword(passwd string) {
+	f.SUPassword = passwd
+}
+
 // CopyToPod will mimic a real copy file into a pod
 func (f *FakePodRunner) CopyToPod(ctx context.Context, podName types.NamespacedName,
 	contName string, sourceFile string, destFile string, executeCmd ...string) (stdout, stderr string, err error) {
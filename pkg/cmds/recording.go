@@ -0,0 +1,165 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// RecordingPodRunner is a PodRunner that never changes anything in the pods.
+// Commands that only read state are passed to a real PodRunner so that the
+// decisions based on them are the same as a normal reconcile.  Every other
+// command is passed to a callback instead.  It is used to report the commands
+// the operator would have run.
+type RecordingPodRunner struct {
+	// The PodRunner used for the commands that only read state
+	Reader PodRunner
+	// Called for each command that isn't run with a printable form of it.
+	// Sensitive parts of the command, like passwords, are obfuscated.
+	OnExec func(podName types.NamespacedName, contName, command string)
+}
+
+// The admintools tools that only read state
+var readOnlyAdmintoolsTools = map[string]bool{
+	"list_allnodes":  true,
+	"list_db":        true,
+	"list_host":      true,
+	"list_node":      true,
+	"show_active_db": true,
+	"view_cluster":   true,
+}
+
+// The programs that only read state when they are run directly, without a shell
+var readOnlyPrograms = map[string]bool{
+	"cat":  true,
+	"ls":   true,
+	"test": true,
+}
+
+// MakeRecordingPodRunner will build a RecordingPodRunner object
+func MakeRecordingPodRunner(reader PodRunner,
+	onExec func(podName types.NamespacedName, contName, command string)) *RecordingPodRunner {
+	return &RecordingPodRunner{Reader: reader, OnExec: onExec}
+}
+
+// ExecInPod runs the command if it only reads state.  Otherwise, the command is
+// recorded and the output is empty.
+func (r *RecordingPodRunner) ExecInPod(ctx context.Context, podName types.NamespacedName,
+	contName string, command ...string) (stdout, stderr string, err error) {
+	if isReadOnlyCommand(command) {
+		return r.Reader.ExecInPod(ctx, podName, contName, command...)
+	}
+	r.record(podName, contName, command)
+	return "", "", nil
+}
+
+// ExecVSQL runs the vsql command if it only reads state.  Otherwise, it is
+// recorded without the password.
+func (r *RecordingPodRunner) ExecVSQL(ctx context.Context, podName types.NamespacedName,
+	contName string, command ...string) (stdout, stderr string, err error) {
+	if isReadOnlyVSQL(command) {
+		return r.Reader.ExecVSQL(ctx, podName, contName, command...)
+	}
+	r.record(podName, contName, UpdateVsqlCmd("", command...))
+	return "", "", nil
+}
+
+// ExecAdmintools runs the admintools command if it only reads state.
+// Otherwise, it is recorded without the password.
+func (r *RecordingPodRunner) ExecAdmintools(ctx context.Context, podName types.NamespacedName,
+	contName string, command ...string) (stdout, stderr string, err error) {
+	if isReadOnlyAdmintools(command) {
+		return r.Reader.ExecAdmintools(ctx, podName, contName, command...)
+	}
+	r.record(podName, contName, append([]string{"admintools"}, command...))
+	return "", "", nil
+}
+
+// CopyToPod records the copy of the file, along with the command that is
+// run after it.
+func (r *RecordingPodRunner) CopyToPod(ctx context.Context, podName types.NamespacedName,
+	contName string, sourceFile string, destFile string, executeCmd ...string) (stdout, stderr string, err error) {
+	command := fmt.Sprintf("copy %s to %s", sourceFile, destFile)
+	if executeCmd != nil {
+		command = fmt.Sprintf("%s && %s", command, strings.TrimSpace(generateLogOutput(executeCmd...)))
+	}
+	r.OnExec(podName, contName, command)
+	return "", "", nil
+}
+
+// SetSUPassword is a no-op since the password is never changed in dry-run mode
+func (r *RecordingPodRunner) SetSUPassword(passwd string) {}
+
+// record passes a printable form of the command to the callback
+func (r *RecordingPodRunner) record(podName types.NamespacedName, contName string, command []string) {
+	r.OnExec(podName, contName, strings.TrimSpace(generateLogOutput(command...)))
+}
+
+// isReadOnlyCommand returns true if the command run in the pod only reads
+// state.  Anything run through a shell is treated as a change since we can't
+// tell what the script does.
+func isReadOnlyCommand(command []string) bool {
+	if len(command) == 0 {
+		return false
+	}
+	switch {
+	case readOnlyPrograms[command[0]]:
+		return true
+	case command[0] == "/opt/vertica/bin/vertica":
+		return len(command) == 2 && command[1] == "--version"
+	case command[0] == "vsql":
+		return isReadOnlyVSQL(command[1:])
+	}
+	return false
+}
+
+// isReadOnlyAdmintools returns true if the admintools command only reads state
+func isReadOnlyAdmintools(command []string) bool {
+	for i := 0; i < len(command)-1; i++ {
+		if command[i] == "-t" {
+			return readOnlyAdmintoolsTools[command[i+1]]
+		}
+	}
+	return false
+}
+
+// isReadOnlyVSQL returns true if the vsql options run a single select that
+// only reads state.  A select without a from clause that calls a function is
+// treated as a change since that is how vertica's meta-functions, like
+// sync_catalog() or rebalance_shards(), are called.
+func isReadOnlyVSQL(command []string) bool {
+	sql := ""
+	for i := 0; i < len(command); i++ {
+		switch command[i] {
+		case "--password":
+			i++
+		case "-c", "-tAc":
+			if i+1 < len(command) {
+				sql = command[i+1]
+			}
+			i++
+		}
+	}
+	sql = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(sql), ";"))
+	if !strings.HasPrefix(sql, "select ") || strings.Contains(sql, ";") {
+		return false
+	}
+	return strings.Contains(sql, " from ") || !strings.Contains(sql, "(")
+}
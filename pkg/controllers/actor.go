@@ -17,6 +17,7 @@ package controllers
 
 import (
 	"context"
+	"reflect"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 )
@@ -26,3 +27,13 @@ import (
 type ReconcileActor interface {
 	Reconcile(ctx context.Context, req *ctrl.Request) (ctrl.Result, error)
 }
+
+// GetActorName returns the name of the actor's type without the package.  This
+// is the name used to refer to an actor in the pause-reconcile annotation.
+func GetActorName(act ReconcileActor) string {
+	t := reflect.TypeOf(act)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
@@ -21,11 +21,13 @@ import (
 	"fmt"
 
 	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -38,6 +40,8 @@ import (
 	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
 	"github.com/vertica/vertica-kubernetes/pkg/controllers"
 	verrors "github.com/vertica/vertica-kubernetes/pkg/errors"
+	"github.com/vertica/vertica-kubernetes/pkg/etstatus"
+	"github.com/vertica/vertica-kubernetes/pkg/events"
 )
 
 // EventTriggerReconciler reconciles a EventTrigger object
@@ -45,6 +49,7 @@ type EventTriggerReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 	Log    logr.Logger
+	EVRec  record.EventRecorder
 }
 
 const (
@@ -77,10 +82,23 @@ func (r *EventTriggerReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, err
 	}
 
+	pauseAll, pausedActors := vapi.ParsePauseReconcileAnnotation(et.Annotations)
+	if err = r.updatePausedCondition(ctx, log, et, pauseAll, pausedActors); err != nil {
+		return ctrl.Result{}, err
+	}
+	if pauseAll {
+		log.Info("skipping reconcile of EventTrigger as it is paused", "annotation", vapi.PauseReconcileAnnotation)
+		return ctrl.Result{}, nil
+	}
+
 	// Iterate over each actor
 	actors := r.constructActors(et, log)
 	var res ctrl.Result
 	for _, act := range actors {
+		if pausedActors[controllers.GetActorName(act)] {
+			log.Info("skipping actor as it is paused", "name", fmt.Sprintf("%T", act))
+			continue
+		}
 		log.Info("starting actor", "name", fmt.Sprintf("%T", act))
 		res, err = act.Reconcile(ctx, &req)
 		// Error or a request to requeue will stop the reconciliation.
@@ -94,6 +112,42 @@ func (r *EventTriggerReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	return res, err
 }
 
+// updatePausedCondition will set the Paused condition from the pause-reconcile
+// annotation.  An event is written each time the reconcile gets paused or
+// resumed.  The condition is left out of the status until the first pause.
+func (r *EventTriggerReconciler) updatePausedCondition(ctx context.Context, log logr.Logger, et *vapi.EventTrigger,
+	pauseAll bool, pausedActors map[string]bool) error {
+	ps := controllers.MakePauseState("EventTrigger", pauseAll, pausedActors)
+	wasPaused := false
+	inx := vapi.EventTriggerPausedIndex
+	if inx < len(et.Status.Conditions) {
+		cur := &et.Status.Conditions[inx]
+		if cur.Status == ps.Status && cur.Reason == ps.Reason && cur.Message == ps.Message {
+			return nil
+		}
+		wasPaused = cur.Status == corev1.ConditionTrue
+	} else if !ps.IsPaused() {
+		return nil
+	}
+	cond := vapi.EventTriggerCondition{
+		Type:    vapi.EventTriggerPaused,
+		Status:  ps.Status,
+		Reason:  ps.Reason,
+		Message: ps.Message,
+	}
+	if err := etstatus.UpdateCondition(ctx, r.Client, log, et, &cond); err != nil {
+		return err
+	}
+	switch {
+	case ps.IsPaused() && !wasPaused:
+		r.EVRec.Eventf(et, corev1.EventTypeWarning, events.ReconcilePaused,
+			"Reconcile paused with the %s annotation: %s", vapi.PauseReconcileAnnotation, ps.Message)
+	case !ps.IsPaused() && wasPaused:
+		r.EVRec.Event(et, corev1.EventTypeNormal, events.ReconcileResumed, "Reconcile of the EventTrigger resumed")
+	}
+	return nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *EventTriggerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	if err := r.setupFieldIndexer(mgr.GetFieldIndexer()); err != nil {
@@ -112,29 +166,40 @@ func (r *EventTriggerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 }
 
 // setupFieldIndexer will setup an index over object names. This allows us to
-// lookup VerticaDB by name in the reference object.
+// lookup VerticaDB by name in the reference object. The index value includes
+// the namespace so that it works when the operator watches many namespaces.
 func (r *EventTriggerReconciler) setupFieldIndexer(indx client.FieldIndexer) error {
 	return indx.IndexField(context.Background(), &vapi.EventTrigger{}, vdbNameField, func(rawObj client.Object) []string {
+		et := rawObj.(*vapi.EventTrigger)
 		var res []string
-		for _, ref := range rawObj.(*vapi.EventTrigger).Spec.References {
+		for _, ref := range et.Spec.References {
 			if ref.Object == nil {
 				continue
 			}
 			if ref.Object.Kind != vapi.VerticaDBKind {
 				continue
 			}
-			res = append(res, ref.Object.Name)
+			res = append(res, genRefNamespacedName(et, ref.Object).String())
 		}
 		return res
 	})
 }
 
+// genRefNamespacedName returns the name of the object in the reference. The
+// object is always in the same namespace as the EventTrigger. The webhook
+// rejects references to other namespaces, but we don't rely on it being
+// enabled.
+func genRefNamespacedName(et *vapi.EventTrigger, obj *vapi.ETRefObject) types.NamespacedName {
+	return types.NamespacedName{Namespace: et.Namespace, Name: obj.Name}
+}
+
 // findObjectsForVerticaDB will generate requests to reconcile EventTriggers
 // based on watched VerticaDB.
 func (r *EventTriggerReconciler) findObjectsForVerticaDB(vdb client.Object) []reconcile.Request {
 	attachedTriggers := &vapi.EventTriggerList{}
+	nm := types.NamespacedName{Namespace: vdb.GetNamespace(), Name: vdb.GetName()}
 	listOps := &client.ListOptions{
-		FieldSelector: fields.OneTermEqualSelector(vdbNameField, vdb.GetName()),
+		FieldSelector: fields.OneTermEqualSelector(vdbNameField, nm.String()),
 		Namespace:     vdb.GetNamespace(),
 	}
 	err := r.List(context.Background(), attachedTriggers, listOps)
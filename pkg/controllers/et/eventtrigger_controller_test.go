@@ -22,6 +22,10 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/events"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
@@ -42,4 +46,53 @@ var _ = Describe("eventtrigger_controller", func() {
 		et := vapi.MakeET()
 		Expect(etRec.Reconcile(ctx, ctrl.Request{NamespacedName: et.ExtractNamespacedName()})).Should(Equal(ctrl.Result{}))
 	})
+
+	It("should skip the reconcile of an EventTrigger that is paused", func() {
+		for _, val := range []string{"true", "VerticaDBRefReconciler"} {
+			et := vapi.MakeET()
+			et.Annotations = map[string]string{vapi.PauseReconcileAnnotation: val}
+			Expect(k8sClient.Create(ctx, et)).Should(Succeed())
+
+			Expect(etRec.Reconcile(ctx, ctrl.Request{NamespacedName: et.ExtractNamespacedName()})).Should(Equal(ctrl.Result{}))
+			etrigger := getEventTriggerStatus(ctx, et.ExtractNamespacedName())
+			Expect(etrigger.Status.References).Should(BeEmpty())
+			Expect(k8sClient.Delete(ctx, et)).Should(Succeed())
+		}
+	})
+
+	It("should only write an event when the pause state changes", func() {
+		et := vapi.MakeET()
+		et.Annotations = map[string]string{vapi.PauseReconcileAnnotation: "true"}
+		Expect(k8sClient.Create(ctx, et)).Should(Succeed())
+		defer func() { Expect(k8sClient.Delete(ctx, et)).Should(Succeed()) }()
+
+		evRec := record.NewFakeRecorder(10)
+		r := &EventTriggerReconciler{Client: k8sClient, Scheme: etRec.Scheme, Log: logger, EVRec: evRec}
+		for i := 0; i < 2; i++ {
+			Expect(r.updatePausedCondition(ctx, logger, et, true, nil)).Should(Succeed())
+		}
+		Expect(evRec.Events).Should(HaveLen(1))
+		Expect(<-evRec.Events).Should(ContainSubstring(events.ReconcilePaused))
+		etrigger := getEventTriggerStatus(ctx, et.ExtractNamespacedName())
+		Expect(len(etrigger.Status.Conditions)).Should(Equal(vapi.EventTriggerPausedIndex + 1))
+		Expect(etrigger.Status.Conditions[vapi.EventTriggerPausedIndex].Status).Should(Equal(corev1.ConditionTrue))
+
+		Expect(r.updatePausedCondition(ctx, logger, et, false, nil)).Should(Succeed())
+		Expect(evRec.Events).Should(HaveLen(1))
+		Expect(<-evRec.Events).Should(ContainSubstring(events.ReconcileResumed))
+		etrigger = getEventTriggerStatus(ctx, et.ExtractNamespacedName())
+		Expect(etrigger.Status.Conditions[vapi.EventTriggerPausedIndex].Status).Should(Equal(corev1.ConditionFalse))
+	})
+
+	It("should only look for the reference object in the EventTrigger's namespace", func() {
+		vdb := vapi.MakeVDB()
+		et := vapi.MakeET()
+		ref := makeETRefObjectOfVDB(vdb)
+		ref.Object.Namespace = ""
+		Expect(genRefNamespacedName(et, ref.Object)).Should(Equal(
+			types.NamespacedName{Namespace: et.Namespace, Name: vdb.Name}))
+		ref.Object.Namespace = "other-ns"
+		Expect(genRefNamespacedName(et, ref.Object)).Should(Equal(
+			types.NamespacedName{Namespace: et.Namespace, Name: vdb.Name}))
+	})
 })
@@ -26,6 +26,7 @@ import (
 
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -80,6 +81,7 @@ var _ = BeforeSuite(func() {
 		Client: k8sClient,
 		Scheme: scheme.Scheme,
 		Log:    logger,
+		EVRec:  record.NewFakeRecorder(100),
 	}
 	Expect(err).NotTo(HaveOccurred())
 })
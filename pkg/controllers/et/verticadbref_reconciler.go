@@ -24,7 +24,6 @@ import (
 	"github.com/vertica/vertica-kubernetes/pkg/controllers"
 	"github.com/vertica/vertica-kubernetes/pkg/etstatus"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
@@ -49,7 +48,7 @@ func (r *VerticaDBRefReconciler) Reconcile(ctx context.Context, req *ctrl.Reques
 		refStatus := etstatus.Fetch(r.Et, ref.Object)
 
 		vdb := &vapi.VerticaDB{}
-		nm := types.NamespacedName{Namespace: r.Et.Namespace, Name: ref.Object.Name}
+		nm := genRefNamespacedName(r.Et, ref.Object)
 
 		if err := r.VRec.Client.Get(ctx, nm, vdb); err != nil {
 			if errors.IsNotFound(err) {
@@ -0,0 +1,68 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PauseState is the state of the Paused condition that is derived from the
+// pause-reconcile annotation.  It is shared by all of the CRs that honour the
+// annotation.
+type PauseState struct {
+	Status  corev1.ConditionStatus
+	Reason  string
+	Message string
+}
+
+// MakePauseState will build the PauseState from the parsed pause-reconcile
+// annotation.  The kind is the name of the CR used in the message.
+func MakePauseState(kind string, pauseAll bool, pausedActors map[string]bool) PauseState {
+	switch {
+	case pauseAll:
+		return PauseState{
+			Status:  corev1.ConditionTrue,
+			Reason:  vapi.ReasonReconcilePaused,
+			Message: fmt.Sprintf("The operator is not reconciling the %s", kind),
+		}
+	case len(pausedActors) > 0:
+		actorNames := []string{}
+		for nm := range pausedActors {
+			actorNames = append(actorNames, nm)
+		}
+		sort.Strings(actorNames)
+		return PauseState{
+			Status:  corev1.ConditionTrue,
+			Reason:  vapi.ReasonActorsPaused,
+			Message: fmt.Sprintf("The operator skips these parts of the reconcile: %s", strings.Join(actorNames, ", ")),
+		}
+	default:
+		return PauseState{
+			Status: corev1.ConditionFalse,
+			Reason: vapi.ReasonAsExpected,
+		}
+	}
+}
+
+// IsPaused returns true if any part of the reconcile is paused
+func (p *PauseState) IsPaused() bool {
+	return p.Status == corev1.ConditionTrue
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
@@ -30,6 +31,8 @@ import (
 	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
 	"github.com/vertica/vertica-kubernetes/pkg/controllers"
 	verrors "github.com/vertica/vertica-kubernetes/pkg/errors"
+	"github.com/vertica/vertica-kubernetes/pkg/events"
+	"github.com/vertica/vertica-kubernetes/pkg/vasstatus"
 )
 
 // VerticaAutoscalerReconciler reconciles a VerticaAutoscaler object
@@ -67,6 +70,15 @@ func (r *VerticaAutoscalerReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return ctrl.Result{}, err
 	}
 
+	pauseAll, pausedActors := vapi.ParsePauseReconcileAnnotation(vas.Annotations)
+	if err = r.updatePausedCondition(ctx, log, &req, vas, pauseAll, pausedActors); err != nil {
+		return ctrl.Result{}, err
+	}
+	if pauseAll {
+		log.Info("skipping reconcile of VerticaAutoscaler as it is paused", "annotation", vapi.PauseReconcileAnnotation)
+		return ctrl.Result{}, nil
+	}
+
 	// The actors that will be applied, in sequence, to reconcile a vas.
 	actors := []controllers.ReconcileActor{
 		// Sanity check to make sure the VerticaDB referenced in vas actually exists.
@@ -87,6 +99,10 @@ func (r *VerticaAutoscalerReconciler) Reconcile(ctx context.Context, req ctrl.Re
 
 	// Iterate over each actor
 	for _, act := range actors {
+		if pausedActors[controllers.GetActorName(act)] {
+			log.Info("skipping actor as it is paused", "name", fmt.Sprintf("%T", act))
+			continue
+		}
 		log.Info("starting actor", "name", fmt.Sprintf("%T", act))
 		res, err = act.Reconcile(ctx, &req)
 		// Error or a request to requeue will stop the reconciliation.
@@ -100,6 +116,42 @@ func (r *VerticaAutoscalerReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	return res, err
 }
 
+// updatePausedCondition will set the Paused condition from the pause-reconcile
+// annotation.  An event is written each time the reconcile gets paused or
+// resumed.  The condition is left out of the status until the first pause.
+func (r *VerticaAutoscalerReconciler) updatePausedCondition(ctx context.Context, log logr.Logger, req *ctrl.Request,
+	vas *vapi.VerticaAutoscaler, pauseAll bool, pausedActors map[string]bool) error {
+	ps := controllers.MakePauseState("VerticaAutoscaler", pauseAll, pausedActors)
+	wasPaused := false
+	inx := vapi.AutoscalerPausedIndex
+	if inx < len(vas.Status.Conditions) {
+		cur := &vas.Status.Conditions[inx]
+		if cur.Status == ps.Status && cur.Reason == ps.Reason && cur.Message == ps.Message {
+			return nil
+		}
+		wasPaused = cur.Status == corev1.ConditionTrue
+	} else if !ps.IsPaused() {
+		return nil
+	}
+	cond := vapi.VerticaAutoscalerCondition{
+		Type:    vapi.AutoscalerPaused,
+		Status:  ps.Status,
+		Reason:  ps.Reason,
+		Message: ps.Message,
+	}
+	if err := vasstatus.UpdateCondition(ctx, r.Client, log, req, cond); err != nil {
+		return err
+	}
+	switch {
+	case ps.IsPaused() && !wasPaused:
+		r.EVRec.Eventf(vas, corev1.EventTypeWarning, events.ReconcilePaused,
+			"Reconcile paused with the %s annotation: %s", vapi.PauseReconcileAnnotation, ps.Message)
+	case !ps.IsPaused() && wasPaused:
+		r.EVRec.Event(vas, corev1.EventTypeNormal, events.ReconcileResumed, "Reconcile of the VerticaAutoscaler resumed")
+	}
+	return nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *VerticaAutoscalerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
@@ -0,0 +1,374 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/cloud"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/controllers"
+	vdbcontroller "github.com/vertica/vertica-kubernetes/pkg/controllers/vdb"
+	verrors "github.com/vertica/vertica-kubernetes/pkg/errors"
+	"github.com/vertica/vertica-kubernetes/pkg/events"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	"github.com/vertica/vertica-kubernetes/pkg/paths"
+	"github.com/vertica/vertica-kubernetes/pkg/vbr"
+	"github.com/vertica/vertica-kubernetes/pkg/vbstatus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// Reasons used in the status conditions
+	ReasonVbrRunning         = "VbrRunning"
+	ReasonVbrSucceeded       = "VbrSucceeded"
+	ReasonVbrFailed          = "VbrFailed"
+	ReasonEndpointIssue      = "EndpointIssue"
+	ReasonBucketDoesNotExist = "BucketDoesNotExist"
+	ReasonAccessDenied       = "AccessDenied"
+)
+
+// BackupReconciler will run vbr to take a backup of the database
+type BackupReconciler struct {
+	VRec       *VerticaBackupReconciler
+	Log        logr.Logger
+	Vb         *vapi.VerticaBackup
+	Vdb        *vapi.VerticaDB
+	PRunner    cmds.PodRunner
+	PFacts     *vdbcontroller.PodFacts
+	SUPassword string
+	// For scheduled backups, these are the time the current backup was
+	// scheduled for and the time the following one is scheduled for.
+	scheduledTime    *metav1.Time
+	nextScheduleTime *metav1.Time
+}
+
+// MakeBackupReconciler will build a BackupReconciler object
+func MakeBackupReconciler(r *VerticaBackupReconciler, log logr.Logger, vb *vapi.VerticaBackup, vdb *vapi.VerticaDB,
+	prunner cmds.PodRunner, pfacts *vdbcontroller.PodFacts, passwd string) controllers.ReconcileActor {
+	return &BackupReconciler{
+		VRec:       r,
+		Log:        log.WithName("BackupReconciler"),
+		Vb:         vb,
+		Vdb:        vdb,
+		PRunner:    prunner,
+		PFacts:     pfacts,
+		SUPassword: passwd,
+	}
+}
+
+// Reconcile will take a backup of the database if one hasn't been taken yet,
+// or if a scheduled backup is due.
+func (b *BackupReconciler) Reconcile(ctx context.Context, req *ctrl.Request) (ctrl.Result, error) {
+	if b.Vb.IsScheduled() {
+		if due, res, err := b.checkSchedule(ctx); !due || err != nil {
+			return res, err
+		}
+	} else if b.Vb.IsConditionSet(vapi.BackupComplete) {
+		// An unscheduled VerticaBackup takes a single backup. Once it has
+		// completed there is nothing left to do.
+		return ctrl.Result{}, nil
+	}
+
+	isSet, err := b.Vdb.IsConditionSet(vapi.DBInitialized)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !isSet {
+		b.Log.Info("Waiting for the database to be initialized before taking a backup")
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	if err := b.PFacts.Collect(ctx, b.Vdb); err != nil {
+		return ctrl.Result{}, err
+	}
+	atPod, ok := b.PFacts.FindUpPodName(b.Vb.Spec.Subcluster)
+	if !ok {
+		b.Log.Info("No up pod found to run vbr. Requeueing reconciliation.")
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	env, res, err := b.genVbrEnv(ctx)
+	if verrors.IsReconcileAborted(res, err) {
+		return res, err
+	}
+
+	res, err = b.runBackup(ctx, atPod, env)
+	if verrors.IsReconcileAborted(res, err) || b.scheduledTime == nil {
+		return res, err
+	}
+	// Come back when the next scheduled backup is due
+	return requeueForSchedule(b.nextScheduleTime.Time, time.Now()), nil
+}
+
+// runBackup will copy the vbr files into the pod and run vbr
+func (b *BackupReconciler) runBackup(ctx context.Context, atPod types.NamespacedName, env string) (ctrl.Result, error) {
+	if err := b.copyVbrFiles(ctx, atPod, env); err != nil {
+		return ctrl.Result{}, err
+	}
+	// The env and password files have sensitive information. They are removed
+	// as soon as vbr finishes.
+	defer b.destroySensitiveFiles(ctx, atPod)
+
+	if err := b.reportBackupStart(ctx, atPod); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	op, err := b.runVbrTask(ctx, atPod, "init")
+	// The init task is needed the first time the backup location is used. It
+	// is safe to ignore the error if a prior backup has initialized it.
+	if err != nil && !vbr.IsBackupLocationInitialized(op) {
+		return b.reportBackupFailure(ctx, "init", op, err)
+	}
+	op, err = b.runVbrTask(ctx, atPod, "backup")
+	if err != nil {
+		return b.reportBackupFailure(ctx, "backup", op, err)
+	}
+
+	op, err = b.runVbrTask(ctx, atPod, "listbackup")
+	if err != nil {
+		return b.reportBackupFailure(ctx, "listbackup", op, err)
+	}
+	points := parseRestorePoints(op, b.Vb.GetSnapshotName())
+	restorePoint := ""
+	if len(points) > 0 {
+		restorePoint = points[len(points)-1]
+	}
+	points = b.pruneRestorePoints(ctx, atPod, points)
+	return ctrl.Result{}, b.reportBackupSuccess(ctx, restorePoint, points)
+}
+
+// reportBackupStart will update the status and log an event that vbr is
+// about to be run.
+func (b *BackupReconciler) reportBackupStart(ctx context.Context, atPod types.NamespacedName) error {
+	b.VRec.Eventf(b.Vb, corev1.EventTypeNormal, events.BackupStart,
+		"Starting backup of database '%s' in pod '%s'", b.Vdb.Spec.DBName, atPod.Name)
+	now := metav1.Now()
+	return vbstatus.Update(ctx, b.VRec.Client, b.Vb, func(vb *vapi.VerticaBackup) error {
+		vb.Status.StartTime = &now
+		vb.Status.PodName = atPod.Name
+		return vbstatus.SetConditionInPlace(vb, &vapi.VerticaBackupCondition{
+			Type:    vapi.BackupInProgress,
+			Status:  corev1.ConditionTrue,
+			Reason:  ReasonVbrRunning,
+			Message: fmt.Sprintf("vbr is running in pod %s", atPod.Name),
+		})
+	})
+}
+
+// reportBackupSuccess will update the status with the restore point that was
+// created and log an event.
+func (b *BackupReconciler) reportBackupSuccess(ctx context.Context, restorePoint string, points []string) error {
+	b.VRec.Eventf(b.Vb, corev1.EventTypeNormal, events.BackupSucceeded,
+		"Successfully backed up database '%s'. Restore point is '%s'", b.Vdb.Spec.DBName, restorePoint)
+	now := metav1.Now()
+	return vbstatus.Update(ctx, b.VRec.Client, b.Vb, func(vb *vapi.VerticaBackup) error {
+		vb.Status.RestorePointID = restorePoint
+		vb.Status.RestorePoints = points
+		vb.Status.CompletionTime = &now
+		if b.scheduledTime != nil {
+			vb.Status.LastScheduleTime = b.scheduledTime
+			vb.Status.NextScheduleTime = b.nextScheduleTime
+		}
+		conds := []vapi.VerticaBackupCondition{
+			{Type: vapi.BackupInProgress, Status: corev1.ConditionFalse, Reason: ReasonVbrSucceeded},
+			{Type: vapi.BackupFailed, Status: corev1.ConditionFalse},
+			{Type: vapi.BackupComplete, Status: corev1.ConditionTrue, Reason: ReasonVbrSucceeded,
+				Message: fmt.Sprintf("Restore point %s was created", restorePoint)},
+		}
+		for i := range conds {
+			if err := vbstatus.SetConditionInPlace(vb, &conds[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// reportBackupFailure will update the status and log an event when a vbr
+// task fails.  The reconcile is requeued so that the backup is retried.
+func (b *BackupReconciler) reportBackupFailure(ctx context.Context, task, op string, vbrErr error) (ctrl.Result, error) {
+	reason := classifyVbrFailure(op)
+	msg := vbr.GetErrorMessage(op, vbrErr)
+	b.VRec.Eventf(b.Vb, corev1.EventTypeWarning, events.BackupFailed,
+		"vbr %s failed for database '%s': %s", task, b.Vdb.Spec.DBName, msg)
+	err := vbstatus.Update(ctx, b.VRec.Client, b.Vb, func(vb *vapi.VerticaBackup) error {
+		conds := []vapi.VerticaBackupCondition{
+			{Type: vapi.BackupInProgress, Status: corev1.ConditionFalse, Reason: reason},
+			{Type: vapi.BackupFailed, Status: corev1.ConditionTrue, Reason: reason,
+				Message: fmt.Sprintf("vbr %s failed: %s", task, msg)},
+		}
+		for i := range conds {
+			if err := vbstatus.SetConditionInPlace(vb, &conds[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return ctrl.Result{Requeue: true}, err
+}
+
+// runVbrTask will run a single vbr task in the given pod. The output of vbr is
+// returned.
+func (b *BackupReconciler) runVbrTask(ctx context.Context, atPod types.NamespacedName, task string,
+	extraArgs ...string) (string, error) {
+	cmd := vbr.GenTaskCmd(b.getEnvFileName(), b.getConfigFileName(), task, extraArgs...)
+	stdout, _, err := b.PRunner.ExecInPod(ctx, atPod, names.ServerContainer, cmd...)
+	return stdout, err
+}
+
+// copyVbrFiles will copy the vbr config, environment and password files into
+// the pod.
+func (b *BackupReconciler) copyVbrFiles(ctx context.Context, atPod types.NamespacedName, env string) error {
+	_, _, err := b.PRunner.ExecInPod(ctx, atPod, names.ServerContainer,
+		"mkdir", "-p", paths.VbrConfigPath, paths.VbrLockPath)
+	if err != nil {
+		return err
+	}
+	files := map[string]string{
+		b.getConfigFileName(): b.genVbrConfig(),
+		b.getEnvFileName():    env,
+	}
+	if b.SUPassword != "" {
+		files[b.getPasswordFileName()] = vbr.GenPasswordFile(b.SUPassword)
+	}
+	for dest, content := range files {
+		if err := vbr.CopyFileToPod(ctx, b.PRunner, atPod, dest, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// destroySensitiveFiles will remove the files that have credentials in them.
+// This is a best effort.  A failure is logged but otherwise ignored.
+func (b *BackupReconciler) destroySensitiveFiles(ctx context.Context, atPod types.NamespacedName) {
+	_, _, err := b.PRunner.ExecInPod(ctx, atPod, names.ServerContainer,
+		"rm", "-f", b.getEnvFileName(), b.getPasswordFileName())
+	if err != nil {
+		b.Log.Info("failed to remove vbr credential files, ignoring failure", "err", err)
+	}
+}
+
+// genVbrConfig will generate the contents of the vbr config file
+func (b *BackupReconciler) genVbrConfig() string {
+	cfg := vbr.Config{
+		BackupPath:   b.Vb.Spec.Target.Path,
+		SnapshotName: b.Vb.GetSnapshotName(),
+		DBName:       b.Vdb.Spec.DBName,
+		DBUser:       b.Vdb.GetSuperuserName(),
+	}
+	if b.SUPassword != "" {
+		cfg.PasswordFile = b.getPasswordFileName()
+	}
+	return cfg.Gen()
+}
+
+// genVbrEnv will generate the contents of the environment file that vbr is
+// run with.  It has the credentials to access the backup location and, for
+// Eon databases, communal storage.
+func (b *BackupReconciler) genVbrEnv(ctx context.Context) (string, ctrl.Result, error) {
+	backupSecret := b.Vb.Spec.Target.CredentialSecret
+	if backupSecret == "" {
+		backupSecret = b.Vdb.Spec.Communal.CredentialSecret
+	}
+	locs := []vbr.Location{
+		{
+			EnvPrefix:  vbr.BackupEnvPrefix,
+			IsAzure:    b.Vb.IsAzure(),
+			SecretName: backupSecret,
+			Endpoint:   b.Vb.Spec.Target.Endpoint,
+			Region:     b.Vb.Spec.Target.Region,
+			CaFile:     b.Vb.Spec.Target.CaFile,
+		},
+	}
+	if b.Vdb.IsEON() && !b.Vdb.IsHDFS() {
+		locs = append(locs, vbr.Location{
+			EnvPrefix:  vbr.CommunalEnvPrefix,
+			IsAzure:    b.Vdb.IsAzure(),
+			SecretName: b.Vdb.Spec.Communal.CredentialSecret,
+			Endpoint:   b.Vdb.Spec.Communal.Endpoint,
+			Region:     b.Vdb.Spec.Communal.Region,
+			CaFile:     b.Vdb.Spec.Communal.CaFile,
+		})
+	}
+
+	env := vbr.MakeEnv()
+	for i := range locs {
+		if res, err := b.addLocationToEnv(ctx, env, &locs[i]); verrors.IsReconcileAborted(res, err) {
+			return "", res, err
+		}
+	}
+	content, err := env.GenContent()
+	return content, ctrl.Result{}, err
+}
+
+// addLocationToEnv will read the credential secret for a storage location and
+// add it to the vbr environment.
+func (b *BackupReconciler) addLocationToEnv(ctx context.Context, env *vbr.Env, loc *vbr.Location) (ctrl.Result, error) {
+	if loc.SecretName != "" {
+		secret, res, err := getSecret(ctx, b.VRec, b.Vb, loc.SecretName)
+		if verrors.IsReconcileAborted(res, err) {
+			return res, err
+		}
+		loc.SecretData = secret.Data
+	}
+	if err := env.AddLocation(loc); err != nil {
+		b.VRec.Eventf(b.Vb, corev1.EventTypeWarning, events.CommunalCredsWrongKey,
+			"The credential secret '%s' is not setup properly: %s", loc.SecretName, err.Error())
+		return ctrl.Result{Requeue: true}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// getConfigFileName returns the path, in the pod, of the vbr config file
+func (b *BackupReconciler) getConfigFileName() string {
+	return fmt.Sprintf("%s/%s.ini", paths.VbrConfigPath, b.Vb.Name)
+}
+
+// getEnvFileName returns the path, in the pod, of the file that has the
+// environment variables for vbr.
+func (b *BackupReconciler) getEnvFileName() string {
+	return fmt.Sprintf("%s/%s.env", paths.VbrConfigPath, b.Vb.Name)
+}
+
+// getPasswordFileName returns the path, in the pod, of the vbr password file
+func (b *BackupReconciler) getPasswordFileName() string {
+	return fmt.Sprintf("%s/%s.pw", paths.VbrConfigPath, b.Vb.Name)
+}
+
+// classifyVbrFailure returns the reason to use in the status condition for a
+// failed vbr task.
+func classifyVbrFailure(op string) string {
+	switch {
+	case cloud.IsEndpointBadError(op):
+		return ReasonEndpointIssue
+	case cloud.IsBucketNotExistError(op):
+		return ReasonBucketDoesNotExist
+	case strings.Contains(op, "Access Denied") || strings.Contains(op, "AccessDenied"):
+		return ReasonAccessDenied
+	default:
+		return ReasonVbrFailed
+	}
+}
@@ -0,0 +1,208 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vb
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/cloud"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	vdbcontroller "github.com/vertica/vertica-kubernetes/pkg/controllers/vdb"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("backup_reconcile", func() {
+	ctx := context.Background()
+
+	It("should classify common vbr failures", func() {
+		Expect(classifyVbrFailure("Error: Unable to connect to endpoint")).Should(Equal(ReasonEndpointIssue))
+		Expect(classifyVbrFailure("The specified bucket does not exist")).Should(Equal(ReasonBucketDoesNotExist))
+		Expect(classifyVbrFailure("something else")).Should(Equal(ReasonVbrFailed))
+	})
+
+	It("should generate a vbr config from the spec", func() {
+		vdb := vapi.MakeVDB()
+		vb := vapi.MakeVB()
+		vdb.Spec.SuperuserName = "admin"
+		vb.Spec.SnapshotName = "nightly"
+		act := makeBackupReconciler(vb, vdb, &cmds.FakePodRunner{}, "secret")
+		cfg := act.genVbrConfig()
+		Expect(cfg).Should(ContainSubstring("cloud_storage_backup_path = s3://backup-bucket/db/"))
+		Expect(cfg).Should(ContainSubstring("snapshotName = nightly"))
+		Expect(cfg).Should(ContainSubstring(fmt.Sprintf("dbName = %s", vdb.Spec.DBName)))
+		Expect(cfg).Should(ContainSubstring("dbUser = admin\n"))
+		Expect(cfg).Should(ContainSubstring("passwordFile = "))
+	})
+
+	It("should include backup and communal credentials in the vbr environment", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.Communal.CredentialSecret = "communal-creds"
+		vb := vapi.MakeVB()
+		createS3Secret(ctx, vb.Namespace, vdb.Spec.Communal.CredentialSecret, "commAK", "commSK")
+		defer deleteSecret(ctx, vb.Namespace, vdb.Spec.Communal.CredentialSecret)
+		createS3Secret(ctx, vb.Namespace, vb.Spec.Target.CredentialSecret, "backupAK", "backupSK")
+		defer deleteSecret(ctx, vb.Namespace, vb.Spec.Target.CredentialSecret)
+
+		act := makeBackupReconciler(vb, vdb, &cmds.FakePodRunner{}, "")
+		env, res, err := act.genVbrEnv(ctx)
+		Expect(err).Should(Succeed())
+		Expect(res).Should(Equal(ctrl.Result{}))
+		Expect(env).Should(ContainSubstring("VBR_BACKUP_STORAGE_ACCESS_KEY_ID='backupAK'"))
+		Expect(env).Should(ContainSubstring("VBR_BACKUP_STORAGE_SECRET_ACCESS_KEY='backupSK'"))
+		Expect(env).Should(ContainSubstring("VBR_BACKUP_STORAGE_ENDPOINT_URL='https://s3.amazonaws.com'"))
+		Expect(env).Should(ContainSubstring("VBR_COMMUNAL_STORAGE_ACCESS_KEY_ID='commAK'"))
+	})
+
+	It("should requeue if the backup credential secret is missing", func() {
+		vdb := vapi.MakeVDB()
+		vb := vapi.MakeVB()
+		act := makeBackupReconciler(vb, vdb, &cmds.FakePodRunner{}, "")
+		_, res, err := act.genVbrEnv(ctx)
+		Expect(err).Should(Succeed())
+		Expect(res).Should(Equal(ctrl.Result{Requeue: true}))
+	})
+
+	It("should run vbr and save the restore point in the status", func() {
+		vdb := vapi.MakeVDB()
+		vb := vapi.MakeVB()
+		vb.Spec.SnapshotName = "snap"
+		Expect(k8sClient.Create(ctx, vb)).Should(Succeed())
+		defer func() { Expect(k8sClient.Delete(ctx, vb)).Should(Succeed()) }()
+
+		atPod := names.GenPodName(vdb, &vdb.Spec.Subclusters[0], 0)
+		fpr := &cmds.FakePodRunner{Results: cmds.CmdResults{
+			atPod: []cmds.CmdResult{
+				{}, // mkdir
+				{}, // copy config
+				{}, // copy env
+				{}, // init
+				{}, // backup
+				{Stdout: "snap_20230101_120000  full  10  v_db_node0001\n"}, // listbackup
+			},
+		}}
+		act := makeBackupReconciler(vb, vdb, fpr, "")
+		Expect(act.runBackup(ctx, atPod, "")).Should(Equal(ctrl.Result{}))
+		Expect(fpr.FindCommands("--task backup")).Should(HaveLen(1))
+		Expect(fpr.FindCommands("rm", "-f")).Should(HaveLen(1))
+
+		fetchVb := &vapi.VerticaBackup{}
+		Expect(k8sClient.Get(ctx, vb.ExtractNamespacedName(), fetchVb)).Should(Succeed())
+		Expect(fetchVb.Status.RestorePointID).Should(Equal("snap_20230101_120000"))
+		Expect(fetchVb.Status.PodName).Should(Equal(atPod.Name))
+		Expect(fetchVb.IsConditionSet(vapi.BackupComplete)).Should(BeTrue())
+		Expect(fetchVb.IsConditionSet(vapi.BackupInProgress)).Should(BeFalse())
+	})
+
+	It("should report a failure in the status if vbr fails", func() {
+		vdb := vapi.MakeVDB()
+		vb := vapi.MakeVB()
+		Expect(k8sClient.Create(ctx, vb)).Should(Succeed())
+		defer func() { Expect(k8sClient.Delete(ctx, vb)).Should(Succeed()) }()
+
+		atPod := names.GenPodName(vdb, &vdb.Spec.Subclusters[0], 0)
+		fpr := &cmds.FakePodRunner{Results: cmds.CmdResults{
+			atPod: []cmds.CmdResult{
+				{}, // mkdir
+				{}, // copy config
+				{}, // copy env
+				{}, // init
+				{Stdout: "Error: Unable to connect to endpoint", Err: fmt.Errorf("command terminated with exit code 1")},
+			},
+		}}
+		act := makeBackupReconciler(vb, vdb, fpr, "")
+		Expect(act.runBackup(ctx, atPod, "")).Should(Equal(ctrl.Result{Requeue: true}))
+
+		fetchVb := &vapi.VerticaBackup{}
+		Expect(k8sClient.Get(ctx, vb.ExtractNamespacedName(), fetchVb)).Should(Succeed())
+		Expect(fetchVb.IsConditionSet(vapi.BackupFailed)).Should(BeTrue())
+		Expect(fetchVb.Status.Conditions[vapi.BackupFailedIndex].Reason).Should(Equal(ReasonEndpointIssue))
+		Expect(fetchVb.IsConditionSet(vapi.BackupComplete)).Should(BeFalse())
+	})
+
+	It("should remove restore points that fall outside of the retention policy", func() {
+		vdb := vapi.MakeVDB()
+		vb := vapi.MakeVB()
+		vb.Spec.SnapshotName = "snap"
+		vb.Spec.Retention.KeepLast = 1
+		Expect(k8sClient.Create(ctx, vb)).Should(Succeed())
+		defer func() { Expect(k8sClient.Delete(ctx, vb)).Should(Succeed()) }()
+
+		atPod := names.GenPodName(vdb, &vdb.Spec.Subclusters[0], 0)
+		fpr := &cmds.FakePodRunner{Results: cmds.CmdResults{
+			atPod: []cmds.CmdResult{
+				{}, // mkdir
+				{}, // copy config
+				{}, // copy env
+				{}, // init
+				{}, // backup
+				{Stdout: "snap_20230101_120000  full\nsnap_20230102_120000  full\nsnap_20230103_120000  full\n"},
+				{}, // remove 20230101_120000
+				{Stdout: "Error: Failed to remove", Err: fmt.Errorf("command terminated with exit code 1")},
+			},
+		}}
+		act := makeBackupReconciler(vb, vdb, fpr, "")
+		Expect(act.runBackup(ctx, atPod, "")).Should(Equal(ctrl.Result{}))
+		Expect(fpr.FindCommands("--task remove")).Should(HaveLen(2))
+		Expect(fpr.FindCommands("--archive '20230101_120000'")).Should(HaveLen(1))
+
+		fetchVb := &vapi.VerticaBackup{}
+		Expect(k8sClient.Get(ctx, vb.ExtractNamespacedName(), fetchVb)).Should(Succeed())
+		Expect(fetchVb.Status.RestorePointID).Should(Equal("snap_20230103_120000"))
+		// The restore point we failed to remove is still reported
+		Expect(fetchVb.Status.RestorePoints).Should(Equal([]string{"snap_20230102_120000", "snap_20230103_120000"}))
+	})
+
+	It("should wait for the database to be initialized", func() {
+		vdb := vapi.MakeVDB()
+		vb := vapi.MakeVB()
+		fpr := &cmds.FakePodRunner{}
+		act := makeBackupReconciler(vb, vdb, fpr, "")
+		Expect(act.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{Requeue: true}))
+		Expect(fpr.Histories).Should(BeEmpty())
+	})
+})
+
+// makeBackupReconciler is a test helper to build the backup actor
+func makeBackupReconciler(vb *vapi.VerticaBackup, vdb *vapi.VerticaDB, fpr *cmds.FakePodRunner,
+	passwd string) *BackupReconciler {
+	pfacts := vdbcontroller.MakePodFacts(vbRec.makeVerticaDBReconciler(logger), fpr)
+	return MakeBackupReconciler(vbRec, logger, vb, vdb, fpr, &pfacts, passwd).(*BackupReconciler)
+}
+
+func createS3Secret(ctx context.Context, ns, name, accessKey, secretKey string) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+		Data: map[string][]byte{
+			cloud.CommunalAccessKeyName: []byte(accessKey),
+			cloud.CommunalSecretKeyName: []byte(secretKey),
+		},
+	}
+	Expect(k8sClient.Create(ctx, secret)).Should(Succeed())
+}
+
+func deleteSecret(ctx context.Context, ns, name string) {
+	secret := &corev1.Secret{}
+	Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: ns, Name: name}, secret)).Should(Succeed())
+	Expect(k8sClient.Delete(ctx, secret)).Should(Succeed())
+}
@@ -0,0 +1,63 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vb
+
+import (
+	"context"
+	"fmt"
+
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/events"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// fetchVDB will fetch the VerticaDB that is referenced in a VerticaBackup.
+// This will log an event if the VerticaDB is not found.
+func fetchVDB(ctx context.Context, vrec *VerticaBackupReconciler,
+	vb *vapi.VerticaBackup, vdb *vapi.VerticaDB) (ctrl.Result, error) {
+	nm := types.NamespacedName{
+		Namespace: vb.Namespace,
+		Name:      vb.Spec.VerticaDBName,
+	}
+	err := vrec.Client.Get(ctx, nm, vdb)
+	if err != nil && errors.IsNotFound(err) {
+		vrec.Eventf(vb, corev1.EventTypeWarning, events.VerticaDBNotFound,
+			"The VerticaDB named '%s' was not found", vb.Spec.VerticaDBName)
+		return ctrl.Result{Requeue: true}, nil
+	}
+	return ctrl.Result{}, err
+}
+
+// getSecret will fetch a secret in the same namespace as the VerticaBackup.
+// If the secret is not found, an event is logged and the reconciliation is
+// requeued.
+func getSecret(ctx context.Context, vrec *VerticaBackupReconciler, vb *vapi.VerticaBackup,
+	secretName string) (*corev1.Secret, ctrl.Result, error) {
+	secret := &corev1.Secret{}
+	nm := types.NamespacedName{Namespace: vb.Namespace, Name: secretName}
+	if err := vrec.Client.Get(ctx, nm, secret); err != nil {
+		if errors.IsNotFound(err) {
+			vrec.Eventf(vb, corev1.EventTypeWarning, events.ObjectNotFound,
+				"Could not find the Secret '%s'", nm)
+			return nil, ctrl.Result{Requeue: true}, nil
+		}
+		return nil, ctrl.Result{}, fmt.Errorf("could not read the secret %s: %w", nm, err)
+	}
+	return secret, ctrl.Result{}, nil
+}
@@ -0,0 +1,123 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vb
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/events"
+	"github.com/vertica/vertica-kubernetes/pkg/vbr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// pruneRestorePoints will remove the restore points that fall outside of the
+// retention policy.  It returns the restore points that still exist.  A
+// failure to remove a restore point is reported with an event but does not
+// fail the backup.
+func (b *BackupReconciler) pruneRestorePoints(ctx context.Context, atPod types.NamespacedName, points []string) []string {
+	snapshotName := b.Vb.GetSnapshotName()
+	toPrune := getRestorePointsToPrune(points, snapshotName, &b.Vb.Spec.Retention, time.Now())
+	if len(toPrune) == 0 {
+		return points
+	}
+
+	removed := map[string]bool{}
+	for _, rp := range toPrune {
+		op, err := b.runVbrTask(ctx, atPod, "remove", "--archive", getArchiveID(rp, snapshotName))
+		if err != nil {
+			b.VRec.Eventf(b.Vb, corev1.EventTypeWarning, events.RestorePointPruneFailed,
+				"Failed to remove restore point '%s': %s", rp, vbr.GetErrorMessage(op, err))
+			continue
+		}
+		removed[rp] = true
+	}
+	if len(removed) == 0 {
+		return points
+	}
+	b.VRec.Eventf(b.Vb, corev1.EventTypeNormal, events.RestorePointsPruned,
+		"Removed %d restore point(s) that fall outside of the retention policy", len(removed))
+
+	remaining := []string{}
+	for _, rp := range points {
+		if !removed[rp] {
+			remaining = append(remaining, rp)
+		}
+	}
+	return remaining
+}
+
+// getRestorePointsToPrune returns the restore points that can be removed
+// according to the retention policy.  The restore points must be sorted from
+// oldest to newest.  The most recent restore point is never returned.
+func getRestorePointsToPrune(points []string, snapshotName string, ret *vapi.BackupRetention, now time.Time) []string {
+	if (ret.KeepLast == 0 && ret.KeepDays == 0) || len(points) == 0 {
+		return nil
+	}
+	keepSince := now.AddDate(0, 0, -ret.KeepDays)
+	toPrune := []string{}
+	for i, rp := range points[:len(points)-1] {
+		keepByCount := ret.KeepLast > 0 && len(points)-i <= ret.KeepLast
+		keepByAge := false
+		if ret.KeepDays > 0 {
+			t, ok := parseRestorePointTime(rp, snapshotName)
+			// If we can't tell how old it is, we keep it to be safe
+			keepByAge = !ok || t.After(keepSince)
+		}
+		if !keepByCount && !keepByAge {
+			toPrune = append(toPrune, rp)
+		}
+	}
+	return toPrune
+}
+
+// parseRestorePoints will parse the output of vbr's listbackup task and
+// return all of the restore points for the given snapshot.  Restore points
+// are named <snapshotName>_<YYYYMMDD>_<HHMMSS>, so sorting them lexically
+// orders them from oldest to newest.
+func parseRestorePoints(op, snapshotName string) []string {
+	points := []string{}
+	for _, line := range strings.Split(op, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || !strings.HasPrefix(fields[0], snapshotName+"_") {
+			continue
+		}
+		// Skip anything that belongs to another snapshot whose name shares
+		// our prefix.
+		if _, ok := parseRestorePointTime(fields[0], snapshotName); !ok {
+			continue
+		}
+		points = append(points, fields[0])
+	}
+	sort.Strings(points)
+	return points
+}
+
+// parseRestorePointTime returns the time a restore point was created
+func parseRestorePointTime(restorePoint, snapshotName string) (time.Time, bool) {
+	t, err := time.Parse(vbr.RestorePointTimeLayout, getArchiveID(restorePoint, snapshotName))
+	return t, err == nil
+}
+
+// getArchiveID returns the portion of the restore point name that vbr uses to
+// identify it in the --archive option.
+func getArchiveID(restorePoint, snapshotName string) string {
+	return strings.TrimPrefix(restorePoint, snapshotName+"_")
+}
@@ -0,0 +1,74 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vb
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+)
+
+var _ = Describe("retention", func() {
+	points := []string{
+		"vb_20230101_120000",
+		"vb_20230102_120000",
+		"vb_20230103_120000",
+		"vb_20230104_120000",
+	}
+	now := time.Date(2023, 1, 4, 13, 0, 0, 0, time.UTC)
+
+	It("should parse all restore points for the snapshot from listbackup", func() {
+		op := `backup                     backup_type   epoch   objects   include_patterns   exclude_patterns   nodes(hosts)   version   file_system_type
+vb_20230102_090000          full          30                                                  v_db_node0001   v12.0.4   [S3]
+other_20230105_101010       full          10                                                  v_db_node0001   v12.0.4   [S3]
+vb_extra_20230105_101010    full          10                                                  v_db_node0001   v12.0.4   [S3]
+vb_20230101_120000          full          20                                                  v_db_node0001   v12.0.4   [S3]
+`
+		Expect(parseRestorePoints(op, "vb")).Should(Equal([]string{"vb_20230101_120000", "vb_20230102_090000"}))
+		Expect(parseRestorePoints(op, "missing")).Should(BeEmpty())
+	})
+
+	It("should not prune anything without a retention policy", func() {
+		Expect(getRestorePointsToPrune(points, "vb", &vapi.BackupRetention{}, now)).Should(BeEmpty())
+	})
+
+	It("should keep the last N restore points", func() {
+		Expect(getRestorePointsToPrune(points, "vb", &vapi.BackupRetention{KeepLast: 2}, now)).Should(
+			Equal([]string{"vb_20230101_120000", "vb_20230102_120000"}))
+		Expect(getRestorePointsToPrune(points, "vb", &vapi.BackupRetention{KeepLast: 10}, now)).Should(BeEmpty())
+	})
+
+	It("should keep restore points for D days", func() {
+		Expect(getRestorePointsToPrune(points, "vb", &vapi.BackupRetention{KeepDays: 2}, now)).Should(
+			Equal([]string{"vb_20230101_120000"}))
+		// The most recent restore point is always kept
+		Expect(getRestorePointsToPrune(points, "vb", &vapi.BackupRetention{KeepDays: 1},
+			now.AddDate(1, 0, 0))).Should(HaveLen(len(points) - 1))
+	})
+
+	It("should keep a restore point if either policy applies to it", func() {
+		Expect(getRestorePointsToPrune(points, "vb", &vapi.BackupRetention{KeepLast: 1, KeepDays: 2}, now)).Should(
+			Equal([]string{"vb_20230101_120000"}))
+		Expect(getRestorePointsToPrune(points, "vb", &vapi.BackupRetention{KeepLast: 3, KeepDays: 1}, now)).Should(
+			Equal([]string{"vb_20230101_120000"}))
+	})
+
+	It("should return the archive ID of a restore point", func() {
+		Expect(getArchiveID("vb_20230101_120000", "vb")).Should(Equal("20230101_120000"))
+	})
+})
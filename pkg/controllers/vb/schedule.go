@@ -0,0 +1,178 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vb
+
+import (
+	"context"
+	"time"
+
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/cron"
+	"github.com/vertica/vertica-kubernetes/pkg/events"
+	"github.com/vertica/vertica-kubernetes/pkg/vbstatus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// How long to wait before checking again if the VerticaDB upgrade has
+	// finished.  We don't watch the VerticaDB, so we must poll.
+	suspendedRequeueTime = time.Minute
+
+	// Reasons used in the ScheduleSuspended condition
+	ReasonUpgradeInProgress = "UpgradeInProgress"
+	ReasonUpgradeComplete   = "UpgradeComplete"
+)
+
+// checkSchedule will decide if a scheduled backup needs to be taken now.  It
+// returns true only if the backup is due.  If it isn't time yet, or scheduled
+// backups are suspended, the result will have the reconcile requeued for
+// later.  A schedule that can't be parsed or never fires stops here without a
+// requeue as only a change to the spec can fix it.  When the backup is due,
+// the scheduled time of the backup is saved in the reconciler so that it can
+// be reported in the status.
+func (b *BackupReconciler) checkSchedule(ctx context.Context) (bool, ctrl.Result, error) {
+	sched, err := cron.Parse(b.Vb.Spec.Schedule)
+	if err != nil {
+		b.VRec.Eventf(b.Vb, corev1.EventTypeWarning, events.BackupScheduleInvalid,
+			"Could not parse the schedule '%s': %s", b.Vb.Spec.Schedule, err)
+		return false, ctrl.Result{}, nil
+	}
+
+	now := time.Now()
+	due, next := b.getScheduleTimes(sched, now)
+	if due.IsZero() {
+		if next.IsZero() {
+			b.VRec.Eventf(b.Vb, corev1.EventTypeWarning, events.BackupScheduleInvalid,
+				"The schedule '%s' never fires", b.Vb.Spec.Schedule)
+			return false, ctrl.Result{}, nil
+		}
+		if err := b.updateNextScheduleTime(ctx, next); err != nil {
+			return false, ctrl.Result{}, err
+		}
+		return false, requeueForSchedule(next, now), nil
+	}
+
+	suspended, err := b.isUpgradeInProgress()
+	if err != nil {
+		return false, ctrl.Result{}, err
+	}
+	if suspended {
+		return false, ctrl.Result{RequeueAfter: suspendedRequeueTime}, b.suspendSchedule(ctx)
+	}
+	if err := b.resumeSchedule(ctx); err != nil {
+		return false, ctrl.Result{}, err
+	}
+
+	b.scheduledTime = &metav1.Time{Time: due}
+	b.nextScheduleTime = &metav1.Time{Time: next}
+	return true, ctrl.Result{}, nil
+}
+
+// getScheduleTimes returns the most recent scheduled time that has passed but
+// has not had a backup taken for it yet.  The zero time is returned if no
+// backup is due.  It also returns the next time the schedule fires after now.
+func (b *BackupReconciler) getScheduleTimes(sched *cron.Schedule, now time.Time) (due, next time.Time) {
+	// We start searching from the last backup we took.  If we haven't taken
+	// one yet, then the first backup happens at the first scheduled time after
+	// the object was created.
+	start := b.Vb.CreationTimestamp.Time
+	if b.Vb.Status.LastScheduleTime != nil {
+		start = b.Vb.Status.LastScheduleTime.Time
+	}
+	if start.IsZero() {
+		start = now
+	}
+
+	// If we missed several scheduled times, such as when the operator was
+	// down, we only take one backup for the most recent time.
+	next = sched.Next(start)
+	for !next.IsZero() && !next.After(now) {
+		due = next
+		next = sched.Next(next)
+	}
+	return due, next
+}
+
+// isUpgradeInProgress returns true if the VerticaDB is being upgraded.  No
+// scheduled backups are taken during this time.
+func (b *BackupReconciler) isUpgradeInProgress() (bool, error) {
+	for _, cond := range []vapi.VerticaDBConditionType{vapi.OnlineUpgradeInProgress, vapi.OfflineUpgradeInProgress} {
+		isSet, err := b.Vdb.IsConditionSet(cond)
+		if err != nil || isSet {
+			return isSet, err
+		}
+	}
+	return false, nil
+}
+
+// suspendSchedule will set the ScheduleSuspended condition.  An event is
+// only logged the first time we notice the upgrade.
+func (b *BackupReconciler) suspendSchedule(ctx context.Context) error {
+	if b.Vb.IsConditionSet(vapi.ScheduleSuspended) {
+		return nil
+	}
+	b.VRec.Eventf(b.Vb, corev1.EventTypeNormal, events.BackupScheduleSuspended,
+		"Scheduled backups are suspended while the VerticaDB '%s' is being upgraded", b.Vdb.Name)
+	return vbstatus.UpdateCondition(ctx, b.VRec.Client, b.Vb, &vapi.VerticaBackupCondition{
+		Type:    vapi.ScheduleSuspended,
+		Status:  corev1.ConditionTrue,
+		Reason:  ReasonUpgradeInProgress,
+		Message: "Waiting for the VerticaDB upgrade to finish",
+	})
+}
+
+// resumeSchedule will clear the ScheduleSuspended condition if it was set
+func (b *BackupReconciler) resumeSchedule(ctx context.Context) error {
+	if !b.Vb.IsConditionSet(vapi.ScheduleSuspended) {
+		return nil
+	}
+	b.VRec.Eventf(b.Vb, corev1.EventTypeNormal, events.BackupScheduleResumed,
+		"Scheduled backups are resumed now that the VerticaDB '%s' upgrade has finished", b.Vdb.Name)
+	return vbstatus.UpdateCondition(ctx, b.VRec.Client, b.Vb, &vapi.VerticaBackupCondition{
+		Type:   vapi.ScheduleSuspended,
+		Status: corev1.ConditionFalse,
+		Reason: ReasonUpgradeComplete,
+	})
+}
+
+// updateNextScheduleTime will save the next scheduled time in the status if
+// it has changed.
+func (b *BackupReconciler) updateNextScheduleTime(ctx context.Context, next time.Time) error {
+	cur := b.Vb.Status.NextScheduleTime
+	if next.IsZero() || (cur != nil && cur.Time.Equal(next)) {
+		return nil
+	}
+	return vbstatus.Update(ctx, b.VRec.Client, b.Vb, func(vb *vapi.VerticaBackup) error {
+		vb.Status.NextScheduleTime = &metav1.Time{Time: next}
+		return nil
+	})
+}
+
+// requeueForSchedule returns the result to use so that we reconcile again
+// when the next scheduled backup is due.
+func requeueForSchedule(next, now time.Time) ctrl.Result {
+	// The schedule will never fire again
+	if next.IsZero() {
+		return ctrl.Result{}
+	}
+	wait := next.Sub(now)
+	if wait <= 0 {
+		return ctrl.Result{Requeue: true}
+	}
+	return ctrl.Result{RequeueAfter: wait}
+}
@@ -0,0 +1,125 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vb
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/cron"
+	"github.com/vertica/vertica-kubernetes/pkg/vbstatus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("schedule", func() {
+	ctx := context.Background()
+
+	It("should only return the most recent missed scheduled time", func() {
+		sched, err := cron.Parse("0 2 * * *")
+		Expect(err).Should(Succeed())
+		vb := vapi.MakeVB()
+		vb.Status.LastScheduleTime = &metav1.Time{Time: time.Date(2023, 1, 1, 2, 0, 0, 0, time.UTC)}
+		act := makeBackupReconciler(vb, vapi.MakeVDB(), &cmds.FakePodRunner{}, "")
+
+		due, next := act.getScheduleTimes(sched, time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC))
+		Expect(due.IsZero()).Should(BeTrue())
+		Expect(next).Should(Equal(time.Date(2023, 1, 2, 2, 0, 0, 0, time.UTC)))
+
+		due, next = act.getScheduleTimes(sched, time.Date(2023, 1, 5, 12, 0, 0, 0, time.UTC))
+		Expect(due).Should(Equal(time.Date(2023, 1, 5, 2, 0, 0, 0, time.UTC)))
+		Expect(next).Should(Equal(time.Date(2023, 1, 6, 2, 0, 0, 0, time.UTC)))
+	})
+
+	It("should requeue until the next scheduled backup is due", func() {
+		vdb := vapi.MakeVDB()
+		vb := vapi.MakeVB()
+		vb.Spec.Schedule = "@yearly"
+		Expect(k8sClient.Create(ctx, vb)).Should(Succeed())
+		defer func() { Expect(k8sClient.Delete(ctx, vb)).Should(Succeed()) }()
+
+		fpr := &cmds.FakePodRunner{}
+		act := makeBackupReconciler(vb, vdb, fpr, "")
+		res, err := act.Reconcile(ctx, &ctrl.Request{})
+		Expect(err).Should(Succeed())
+		Expect(res.RequeueAfter).Should(BeNumerically(">", 0))
+		Expect(fpr.Histories).Should(BeEmpty())
+
+		fetchVb := &vapi.VerticaBackup{}
+		Expect(k8sClient.Get(ctx, vb.ExtractNamespacedName(), fetchVb)).Should(Succeed())
+		Expect(fetchVb.Status.NextScheduleTime).ShouldNot(BeNil())
+	})
+
+	It("should suspend scheduled backups while the database is upgraded", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Status.Conditions = make([]vapi.VerticaDBCondition, vapi.OnlineUpgradeInProgressIndex+1)
+		vdb.Status.Conditions[vapi.OnlineUpgradeInProgressIndex] = vapi.VerticaDBCondition{
+			Type: vapi.OnlineUpgradeInProgress, Status: corev1.ConditionTrue,
+		}
+		vb := vapi.MakeVB()
+		vb.Spec.Schedule = "* * * * *"
+		Expect(k8sClient.Create(ctx, vb)).Should(Succeed())
+		defer func() { Expect(k8sClient.Delete(ctx, vb)).Should(Succeed()) }()
+		Expect(vbstatus.Update(ctx, k8sClient, vb, func(vb *vapi.VerticaBackup) error {
+			vb.Status.LastScheduleTime = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+			return nil
+		})).Should(Succeed())
+
+		fpr := &cmds.FakePodRunner{}
+		act := makeBackupReconciler(vb, vdb, fpr, "")
+		Expect(act.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{RequeueAfter: suspendedRequeueTime}))
+		Expect(fpr.Histories).Should(BeEmpty())
+		Expect(vb.IsConditionSet(vapi.ScheduleSuspended)).Should(BeTrue())
+
+		// Once the upgrade is done, the backup is attempted.  It waits for
+		// the database to be initialized, but the schedule is resumed.
+		vdb.Status.Conditions[vapi.OnlineUpgradeInProgressIndex].Status = corev1.ConditionFalse
+		Expect(act.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{Requeue: true}))
+		Expect(vb.IsConditionSet(vapi.ScheduleSuspended)).Should(BeFalse())
+	})
+
+	It("should not take a backup if the schedule never fires or can't be parsed", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Status.Conditions = make([]vapi.VerticaDBCondition, vapi.DBInitializedIndex+1)
+		vdb.Status.Conditions[vapi.DBInitializedIndex] = vapi.VerticaDBCondition{
+			Type: vapi.DBInitialized, Status: corev1.ConditionTrue,
+		}
+		for _, schedule := range []string{"0 0 30 2 *", "not a schedule"} {
+			vb := vapi.MakeVB()
+			vb.Spec.Schedule = schedule
+			fpr := &cmds.FakePodRunner{}
+			act := makeBackupReconciler(vb, vdb, fpr, "")
+			due, res, err := act.checkSchedule(ctx)
+			Expect(err).Should(Succeed())
+			Expect(due).Should(BeFalse())
+			Expect(res).Should(Equal(ctrl.Result{}))
+			Expect(act.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+			Expect(fpr.Histories).Should(BeEmpty())
+		}
+	})
+
+	It("should compute the requeue time for the next scheduled backup", func() {
+		now := time.Now()
+		Expect(requeueForSchedule(time.Time{}, now)).Should(Equal(ctrl.Result{}))
+		Expect(requeueForSchedule(now.Add(-time.Minute), now)).Should(Equal(ctrl.Result{Requeue: true}))
+		Expect(requeueForSchedule(now.Add(time.Hour), now)).Should(Equal(ctrl.Result{RequeueAfter: time.Hour}))
+	})
+})
@@ -0,0 +1,157 @@
+/*
+Copyright [2021-2023] Open Text.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vb
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/go-logr/logr"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/builder"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/controllers"
+	vdbcontroller "github.com/vertica/vertica-kubernetes/pkg/controllers/vdb"
+	verrors "github.com/vertica/vertica-kubernetes/pkg/errors"
+	"github.com/vertica/vertica-kubernetes/pkg/opcfg"
+)
+
+// VerticaBackupReconciler reconciles a VerticaBackup object
+type VerticaBackupReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Log    logr.Logger
+	Cfg    *rest.Config
+	EVRec  record.EventRecorder
+	OpCfg  opcfg.OperatorConfig
+}
+
+//nolint:lll
+//+kubebuilder:rbac:groups=vertica.com,namespace=WATCH_NAMESPACE,resources=verticabackups,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=vertica.com,namespace=WATCH_NAMESPACE,resources=verticabackups/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=vertica.com,namespace=WATCH_NAMESPACE,resources=verticabackups/finalizers,verbs=update
+//+kubebuilder:rbac:groups=vertica.com,namespace=WATCH_NAMESPACE,resources=verticadbs,verbs=get;list;watch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.14.5/pkg/reconcile
+func (r *VerticaBackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("verticabackup", req.NamespacedName)
+	log.Info("starting reconcile of VerticaBackup")
+
+	vb := &vapi.VerticaBackup{}
+	err := r.Get(ctx, req.NamespacedName, vb)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("VerticaBackup resource not found.  Ignoring since object must be deleted")
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "failed to get VerticaBackup")
+		return ctrl.Result{}, err
+	}
+
+	// Sanity check to make sure the VerticaDB referenced in vb actually
+	// exists.  We need it to know where to run vbr.
+	vdb := &vapi.VerticaDB{}
+	if res, err := fetchVDB(ctx, r, vb, vdb); verrors.IsReconcileAborted(res, err) {
+		return res, err
+	}
+
+	// Pod facts are collected through the VerticaDB reconciler.  We build one
+	// that shares our client and config so that we pick pods exactly the same
+	// way the VerticaDB controller does.
+	vdbRec := r.makeVerticaDBReconciler(log)
+	passwd, err := vdbRec.GetActiveSuperuserPassword(ctx, vdb, log)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	prunner := cmds.MakeClusterPodRunner(log, r.Cfg, passwd)
+	pfacts := vdbcontroller.MakePodFacts(vdbRec, prunner)
+
+	// Iterate over each actor
+	actors := r.constructActors(log, vb, vdb, prunner, &pfacts, passwd)
+	var res ctrl.Result
+	for _, act := range actors {
+		log.Info("starting actor", "name", fmt.Sprintf("%T", act))
+		res, err = act.Reconcile(ctx, &req)
+		// Error or a request to requeue will stop the reconciliation.
+		if verrors.IsReconcileAborted(res, err) {
+			log.Info("aborting reconcile of VerticaBackup", "result", res, "err", err)
+			return res, err
+		}
+	}
+
+	log.Info("ending reconcile of VerticaBackup", "result", res, "err", err)
+	return res, err
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *VerticaBackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&vapi.VerticaBackup{}).
+		Complete(r)
+}
+
+// constructActors will a list of actors that should be run for the reconcile.
+// Order matters in that some actors depend on the successeful execution of
+// earlier ones.
+func (r *VerticaBackupReconciler) constructActors(log logr.Logger, vb *vapi.VerticaBackup, vdb *vapi.VerticaDB,
+	prunner cmds.PodRunner, pfacts *vdbcontroller.PodFacts, passwd string) []controllers.ReconcileActor {
+	// The actors that will be applied, in sequence, to reconcile a vb.
+	return []controllers.ReconcileActor{
+		// Run vbr to take the backup and report the restore point
+		MakeBackupReconciler(r, log, vb, vdb, prunner, pfacts, passwd),
+	}
+}
+
+// makeVerticaDBReconciler will build a VerticaDBReconciler that can be used
+// to collect pod facts.  It is never registered with the manager.
+func (r *VerticaBackupReconciler) makeVerticaDBReconciler(log logr.Logger) *vdbcontroller.VerticaDBReconciler {
+	return &vdbcontroller.VerticaDBReconciler{
+		Client: r.Client,
+		Log:    log,
+		Scheme: r.Scheme,
+		Cfg:    r.Cfg,
+		EVRec:  r.EVRec,
+		OpCfg:  r.OpCfg,
+		DeploymentNames: builder.DeploymentNames{
+			ServiceAccountName: r.OpCfg.ServiceAccountName,
+			PrefixName:         r.OpCfg.PrefixName,
+		},
+	}
+}
+
+// Event a wrapper for Event() that also writes a log entry
+func (r *VerticaBackupReconciler) Event(vb *vapi.VerticaBackup, eventtype, reason, message string) {
+	r.Log.Info("Event logging", "eventtype", eventtype, "reason", reason, "message", message)
+	r.EVRec.Event(vb, eventtype, reason, message)
+}
+
+// Eventf is a wrapper for Eventf() that also writes a log entry
+func (r *VerticaBackupReconciler) Eventf(vb *vapi.VerticaBackup, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Log.Info("Event logging", "eventtype", eventtype, "reason", reason, "message", fmt.Sprintf(messageFmt, args...))
+	r.EVRec.Eventf(vb, eventtype, reason, messageFmt, args...)
+}
@@ -35,13 +35,11 @@ import (
 // -- checking errors at the end to ensure we attempt at each pod.
 func distributeAdmintoolsConf(ctx context.Context, vdb *vapi.VerticaDB, vrec *VerticaDBReconciler,
 	pf *PodFacts, pr cmds.PodRunner, atConfTempFile string) error {
-	// We always distribute to a well known base pod first. The admintools.conf
-	// on this pod is used as the base for any subsequent changes.
-	basePod, err := findPodForFirstCopy(vdb, pf)
+	pods, err := getATConfCopyTargets(vdb, pf)
 	if err != nil {
 		return err
 	}
-	_, _, err = pr.CopyToPod(ctx, basePod, names.ServerContainer, atConfTempFile, paths.AdminToolsConf)
+	_, _, err = pr.CopyToPod(ctx, pods[0], names.ServerContainer, atConfTempFile, paths.AdminToolsConf)
 	if err != nil {
 		return err
 	}
@@ -49,15 +47,8 @@ func distributeAdmintoolsConf(ctx context.Context, vdb *vapi.VerticaDB, vrec *Ve
 	// Copy the admintools.conf to the rest of the pods.  We will do error
 	// checking at the end so that we try to copy it to each pod.
 	errs := []error{}
-	for _, p := range pf.Detail {
-		if !p.isPodRunning {
-			continue
-		}
-		// Skip base pod as it was copied at the start of this function.
-		if p.name == basePod {
-			continue
-		}
-		_, _, e := pr.CopyToPod(ctx, p.name, names.ServerContainer, atConfTempFile, paths.AdminToolsConf)
+	for _, pn := range pods[1:] {
+		_, _, e := pr.CopyToPod(ctx, pn, names.ServerContainer, atConfTempFile, paths.AdminToolsConf)
 		// Save off any error and go onto the next pod
 		if e != nil {
 			errs = append(errs, e)
@@ -65,14 +56,40 @@ func distributeAdmintoolsConf(ctx context.Context, vdb *vapi.VerticaDB, vrec *Ve
 	}
 	// If at least one error occurred, log an event and return the first error.
 	if len(errs) > 0 {
-		vrec.Eventf(vdb, corev1.EventTypeWarning, events.ATConfPartiallyCopied,
-			"Distributing new admintools.conf was successful only at some of the pods.  "+
-				"There was an error copying to %d of the pod(s).", len(errs))
+		logATConfPartiallyCopied(vdb, vrec, len(errs))
 		return errs[0]
 	}
 	return nil
 }
 
+// logATConfPartiallyCopied will write an event when admintools.conf could only
+// be copied to some of the pods.
+func logATConfPartiallyCopied(vdb *vapi.VerticaDB, vrec *VerticaDBReconciler, failed int) {
+	vrec.Eventf(vdb, corev1.EventTypeWarning, events.ATConfPartiallyCopied,
+		"Distributing new admintools.conf was successful only at some of the pods.  "+
+			"There was an error copying to %d of the pod(s).", failed)
+}
+
+// getATConfCopyTargets returns the running pods that admintools.conf should be
+// copied to.  We always distribute to a well known base pod first. The
+// admintools.conf on this pod is used as the base for any subsequent changes.
+// So, it is the first pod in the returned list.
+func getATConfCopyTargets(vdb *vapi.VerticaDB, pf *PodFacts) ([]types.NamespacedName, error) {
+	basePod, err := findPodForFirstCopy(vdb, pf)
+	if err != nil {
+		return nil, err
+	}
+	pods := []types.NamespacedName{basePod}
+	for _, p := range pf.Detail {
+		// Skip base pod as it is already at the front of the list.
+		if !p.isPodRunning || p.name == basePod {
+			continue
+		}
+		pods = append(pods, p.name)
+	}
+	return pods, nil
+}
+
 // findATBasePod will return the pod to use for the base admintools.conf file.
 // The base is used as the initial state of admintools.conf.  The caller then
 // applies any addition or removals of hosts from that base.
@@ -0,0 +1,336 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/builder"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/controllers"
+	"github.com/vertica/vertica-kubernetes/pkg/events"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	"github.com/vertica/vertica-kubernetes/pkg/security"
+	"github.com/vertica/vertica-kubernetes/pkg/vdbstatus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// The objects we create in the database are suffixed with a prefix of
+	// the certificate fingerprint.  This lets us create the new objects
+	// before we drop the old ones when the certificate is rotated.
+	clientTLSNameSuffixLen = 12
+	clientTLSKeyPrefix     = "k8s_client_tls_key_"
+	clientTLSCertPrefix    = "k8s_client_tls_cert_"
+	clientTLSCAPrefix      = "k8s_client_tls_ca_"
+)
+
+// ClientTLSReconciler will configure the server TLS that Vertica uses for
+// client connections with the certificate in the clientTLS secret.
+type ClientTLSReconciler struct {
+	VRec    *VerticaDBReconciler
+	Log     logr.Logger
+	Vdb     *vapi.VerticaDB // Vdb is the CRD we are acting on.
+	PRunner cmds.PodRunner
+	PFacts  *PodFacts
+}
+
+// MakeClientTLSReconciler will build a ClientTLSReconciler object
+func MakeClientTLSReconciler(vdbrecon *VerticaDBReconciler, log logr.Logger,
+	vdb *vapi.VerticaDB, prunner cmds.PodRunner, pfacts *PodFacts) controllers.ReconcileActor {
+	return &ClientTLSReconciler{
+		VRec:    vdbrecon,
+		Log:     log,
+		Vdb:     vdb,
+		PRunner: prunner,
+		PFacts:  pfacts,
+	}
+}
+
+// Reconcile will install the client TLS certificate in the database if it
+// differs from the one that was last installed.
+func (c *ClientTLSReconciler) Reconcile(ctx context.Context, req *ctrl.Request) (ctrl.Result, error) {
+	// Nothing to do if client TLS isn't configured or the operator doesn't
+	// own the database
+	if c.Vdb.Spec.ClientTLS == nil || c.Vdb.Spec.InitPolicy == vapi.CommunalInitPolicyScheduleOnly {
+		return ctrl.Result{}, nil
+	}
+	if c.Vdb.Spec.ClientTLS.Secret == "" {
+		if err := c.createSecret(ctx); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if isSet, err := c.Vdb.IsConditionSet(vapi.DBInitialized); !isSet || err != nil {
+		return ctrl.Result{}, err
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.VRec.Client.Get(ctx, names.GenNamespacedName(c.Vdb, c.Vdb.Spec.ClientTLS.Secret), secret); err != nil {
+		return ctrl.Result{}, err
+	}
+	// The certificate we generate must name every subcluster service. Regenerate
+	// it if subclusters were added since it was generated.
+	if c.isGeneratedSecret(secret) && !c.hasAllDNSNames(secret) {
+		if err := c.regenerateCert(ctx, secret); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	for _, key := range []string{corev1.TLSPrivateKeyKey, corev1.TLSCertKey, security.CACertKey} {
+		if len(secret.Data[key]) == 0 {
+			c.VRec.Eventf(c.Vdb, corev1.EventTypeWarning, events.ClientTLSApplyFailed,
+				"The client TLS secret '%s' is missing the key '%s'", secret.Name, key)
+			return ctrl.Result{}, nil
+		}
+	}
+	fingerprint, err := security.MakeCertificate(secret.Data[corev1.TLSPrivateKeyKey], secret.Data[corev1.TLSCertKey]).Fingerprint()
+	if err != nil {
+		c.VRec.Eventf(c.Vdb, corev1.EventTypeWarning, events.ClientTLSApplyFailed,
+			"Could not parse the certificate in the client TLS secret '%s': %s", secret.Name, err)
+		return ctrl.Result{}, nil
+	}
+	mode := c.getMode()
+	if c.Vdb.Status.ClientTLSFingerprint == fingerprint && c.Vdb.Status.ClientTLSMode == mode {
+		return ctrl.Result{}, nil
+	}
+	return c.applyClientTLS(ctx, secret, fingerprint, mode)
+}
+
+// getMode returns the TLS mode to set for the server
+func (c *ClientTLSReconciler) getMode() string {
+	if c.Vdb.Spec.ClientTLS.Mode == "" {
+		return vapi.ClientTLSModeEnable
+	}
+	return c.Vdb.Spec.ClientTLS.Mode
+}
+
+// applyClientTLS will create the key and certificates in the database, then
+// alter the server TLS configuration to use them.
+func (c *ClientTLSReconciler) applyClientTLS(ctx context.Context, secret *corev1.Secret, fingerprint, mode string) (ctrl.Result, error) {
+	if err := c.PFacts.Collect(ctx, c.Vdb); err != nil {
+		return ctrl.Result{}, err
+	}
+	pf, ok := c.PFacts.findPodToRunVsql(false, "")
+	if !ok {
+		c.Log.Info("No up pod found to set the client TLS configuration. Requeue reconciliation.")
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	keyName, certName, caName := genClientTLSObjectNames(fingerprint)
+	prevFingerprint := c.Vdb.Status.ClientTLSFingerprint
+	// If only the mode changed, the certificates are already in place
+	stmts := []string{fmt.Sprintf("alter tls configuration server tlsmode '%s'", mode)}
+	if prevFingerprint != fingerprint {
+		stmts = genClientTLSInstallStmts(secret, keyName, certName, caName, mode)
+	}
+	for _, sql := range stmts {
+		if _, _, err := c.PRunner.ExecVSQL(ctx, pf.name, names.ServerContainer, "-tAc", sql); err != nil {
+			c.VRec.Event(c.Vdb, corev1.EventTypeWarning, events.ClientTLSApplyFailed,
+				"Failed to set the client TLS configuration in the database")
+			return ctrl.Result{}, err
+		}
+	}
+
+	if prevFingerprint != "" && prevFingerprint != fingerprint {
+		c.dropClientTLSObjects(ctx, pf, prevFingerprint)
+	}
+
+	err := vdbstatus.Update(ctx, c.VRec.Client, c.Vdb, func(vdb *vapi.VerticaDB) error {
+		vdb.Status.ClientTLSFingerprint = fingerprint
+		vdb.Status.ClientTLSMode = mode
+		return nil
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	c.VRec.Eventf(c.Vdb, corev1.EventTypeNormal, events.ClientTLSApplied,
+		"Set the client TLS configuration with mode %s and certificate fingerprint %s", mode, fingerprint)
+	return ctrl.Result{}, nil
+}
+
+// genClientTLSInstallStmts returns the SQL statements that create the key and
+// certificates in the database and have the server TLS configuration use them.
+func genClientTLSInstallStmts(secret *corev1.Secret, keyName, certName, caName, mode string) []string {
+	return []string{
+		fmt.Sprintf("create key if not exists %s type 'RSA' as '%s'",
+			keyName, escapeSQLString(string(secret.Data[corev1.TLSPrivateKeyKey]))),
+		fmt.Sprintf("create certificate if not exists %s as '%s' key %s",
+			certName, escapeSQLString(string(secret.Data[corev1.TLSCertKey])), keyName),
+		fmt.Sprintf("create ca certificate if not exists %s as '%s'",
+			caName, escapeSQLString(string(secret.Data[security.CACertKey]))),
+		fmt.Sprintf("alter tls configuration server certificate %s add ca certificates %s tlsmode '%s'",
+			certName, caName, mode),
+	}
+}
+
+// dropClientTLSObjects will remove the key and certificates of a previous
+// certificate.  Failures are only logged since the new certificate is already
+// in use.
+func (c *ClientTLSReconciler) dropClientTLSObjects(ctx context.Context, pf *PodFact, fingerprint string) {
+	keyName, certName, caName := genClientTLSObjectNames(fingerprint)
+	stmts := []string{
+		fmt.Sprintf("alter tls configuration server remove ca certificates %s", caName),
+		fmt.Sprintf("drop certificate if exists %s", certName),
+		fmt.Sprintf("drop certificate if exists %s", caName),
+		fmt.Sprintf("drop key if exists %s", keyName),
+	}
+	for _, sql := range stmts {
+		if _, _, err := c.PRunner.ExecVSQL(ctx, pf.name, names.ServerContainer, "-tAc", sql); err != nil {
+			c.Log.Info("Failed to drop the previous client TLS object", "sql", sql, "err", err)
+		}
+	}
+}
+
+// genClientTLSCerts will generate a self-signed CA and a certificate, signed
+// by that CA, for client connections to the database.
+func genClientTLSCerts(vdb *vapi.VerticaDB) (cert, caCert security.Certificate, err error) {
+	const PKKeySize = 2048
+	caCert, err = security.NewSelfSignedCACertificate(PKKeySize)
+	if err != nil {
+		return nil, nil, err
+	}
+	dnsNames := getClientTLSDNSNames(vdb)
+	cert, err = security.NewCertificate(caCert, PKKeySize, dnsNames[0], dnsNames)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, caCert, nil
+}
+
+// getClientTLSDNSNames returns the DNS names to include in the client TLS
+// certificate that we generate.  Clients connect through the service of each
+// subcluster, or directly to a pod through the headless service.
+func getClientTLSDNSNames(vdb *vapi.VerticaDB) []string {
+	dnsNames := []string{}
+	for i := range vdb.Spec.Subclusters {
+		svcName := names.GenExtSvcName(vdb, &vdb.Spec.Subclusters[i]).Name
+		for _, nm := range []string{
+			svcName,
+			fmt.Sprintf("%s.%s", svcName, vdb.Namespace),
+			fmt.Sprintf("%s.%s.svc", svcName, vdb.Namespace),
+			fmt.Sprintf("%s.%s.svc.cluster.local", svcName, vdb.Namespace),
+		} {
+			dnsNames = appendIfMissing(dnsNames, nm)
+		}
+	}
+	hlSvcName := names.GenHlSvcName(vdb).Name
+	return append(dnsNames,
+		fmt.Sprintf("*.%s.%s.svc", hlSvcName, vdb.Namespace),
+		fmt.Sprintf("*.%s.%s.svc.cluster.local", hlSvcName, vdb.Namespace),
+	)
+}
+
+// isGeneratedSecret returns true if the secret was generated by the operator
+// for this vdb.
+func (c *ClientTLSReconciler) isGeneratedSecret(secret *corev1.Secret) bool {
+	for i := range secret.OwnerReferences {
+		if secret.OwnerReferences[i].UID == c.Vdb.UID {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAllDNSNames returns true if the certificate in the secret has all of the
+// DNS names that we would generate it with now.
+func (c *ClientTLSReconciler) hasAllDNSNames(secret *corev1.Secret) bool {
+	x509Cert, err := security.MakeCertificate(secret.Data[corev1.TLSPrivateKeyKey], secret.Data[corev1.TLSCertKey]).Buildx509()
+	if err != nil {
+		// Let the caller report the bad certificate
+		return true
+	}
+	certNames := map[string]bool{}
+	for _, nm := range x509Cert.DNSNames {
+		certNames[nm] = true
+	}
+	for _, nm := range getClientTLSDNSNames(c.Vdb) {
+		if !certNames[nm] {
+			return false
+		}
+	}
+	return true
+}
+
+// regenerateCert will replace the certificate in a secret we generated with
+// one that has the DNS names of the current subclusters.
+func (c *ClientTLSReconciler) regenerateCert(ctx context.Context, secret *corev1.Secret) error {
+	cert, caCert, err := genClientTLSCerts(c.Vdb)
+	if err != nil {
+		return err
+	}
+	secret.Data = genHTTPServerSecretData(cert, caCert)
+	c.Log.Info("Regenerating the client TLS certificate to include the subcluster services", "secret", secret.Name)
+	return c.VRec.Client.Update(ctx, secret)
+}
+
+// createSecret will generate a self-signed certificate for client TLS and
+// store it in a new secret.  The name of the secret is saved in the vdb.
+func (c *ClientTLSReconciler) createSecret(ctx context.Context) error {
+	cert, caCert, err := genClientTLSCerts(c.Vdb)
+	if err != nil {
+		return err
+	}
+	isController := true
+	blockOwnerDeletion := false
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: fmt.Sprintf("%s-client-tls-", c.Vdb.Name),
+			Namespace:    c.Vdb.Namespace,
+			Annotations:  builder.MakeAnnotationsForObject(c.Vdb),
+			Labels:       builder.MakeOperatorLabels(c.Vdb),
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         vapi.GroupVersion.String(),
+					Kind:               vapi.VerticaDBKind,
+					Name:               c.Vdb.Name,
+					UID:                c.Vdb.GetUID(),
+					Controller:         &isController,
+					BlockOwnerDeletion: &blockOwnerDeletion,
+				},
+			},
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: genHTTPServerSecretData(cert, caCert),
+	}
+	if err := c.VRec.Client.Create(ctx, secret); err != nil {
+		return err
+	}
+
+	nm := c.Vdb.ExtractNamespacedName()
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		// Always fetch the latest in case we are in the retry loop
+		if err := c.VRec.Client.Get(ctx, nm, c.Vdb); err != nil {
+			return err
+		}
+		c.Vdb.Spec.ClientTLS.Secret = secret.Name
+		return c.VRec.Client.Update(ctx, c.Vdb)
+	})
+}
+
+// genClientTLSObjectNames returns the names of the key and certificates we
+// create in the database for a certificate with the given fingerprint.
+func genClientTLSObjectNames(fingerprint string) (keyName, certName, caName string) {
+	suffix := fingerprint
+	if len(suffix) > clientTLSNameSuffixLen {
+		suffix = suffix[:clientTLSNameSuffixLen]
+	}
+	return clientTLSKeyPrefix + suffix, clientTLSCertPrefix + suffix, clientTLSCAPrefix + suffix
+}
@@ -0,0 +1,150 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vdb
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	"github.com/vertica/vertica-kubernetes/pkg/security"
+	"github.com/vertica/vertica-kubernetes/pkg/test"
+	"github.com/vertica/vertica-kubernetes/pkg/vdbstatus"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("clienttls_reconcile", func() {
+	ctx := context.Background()
+
+	// setupVDB will create an initialized vdb with client TLS enabled
+	setupVDB := func(mode string) *vapi.VerticaDB {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.ClientTLS = &vapi.ClientTLS{Mode: mode}
+		test.CreateVDB(ctx, k8sClient, vdb)
+		Expect(vdbstatus.UpdateCondition(ctx, k8sClient, vdb,
+			vapi.VerticaDBCondition{Type: vapi.DBInitialized, Status: corev1.ConditionTrue})).Should(Succeed())
+		test.CreatePods(ctx, k8sClient, vdb, test.AllPodsRunning)
+		return vdb
+	}
+
+	cleanupVDB := func(vdb *vapi.VerticaDB) {
+		test.DeletePods(ctx, k8sClient, vdb)
+		if vdb.Spec.ClientTLS.Secret != "" {
+			deleteSecret(ctx, vdb, vdb.Spec.ClientTLS.Secret)
+		}
+		test.DeleteVDB(ctx, k8sClient, vdb)
+	}
+
+	getFingerprint := func(vdb *vapi.VerticaDB) string {
+		secret := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, names.GenNamespacedName(vdb, vdb.Spec.ClientTLS.Secret), secret)).Should(Succeed())
+		fp, err := security.MakeCertificate(secret.Data[corev1.TLSPrivateKeyKey], secret.Data[corev1.TLSCertKey]).Fingerprint()
+		Expect(err).Should(Succeed())
+		return fp
+	}
+
+	It("should be a no-op if clientTLS isn't set", func() {
+		vdb := vapi.MakeVDB()
+		test.CreateVDB(ctx, k8sClient, vdb)
+		defer test.DeleteVDB(ctx, k8sClient, vdb)
+
+		fpr := &cmds.FakePodRunner{}
+		recon := MakeClientTLSReconciler(vdbRec, logger, vdb, fpr, createPodFactsDefault(fpr))
+		Expect(recon.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+		Expect(fpr.Histories).Should(BeEmpty())
+	})
+
+	It("should generate a secret and set the client TLS configuration", func() {
+		vdb := setupVDB(vapi.ClientTLSModeVerifyCA)
+		defer cleanupVDB(vdb)
+
+		fpr := &cmds.FakePodRunner{}
+		recon := MakeClientTLSReconciler(vdbRec, logger, vdb, fpr, createPodFactsDefault(fpr))
+		Expect(recon.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+		Expect(vdb.Spec.ClientTLS.Secret).ShouldNot(Equal(""))
+
+		fp := getFingerprint(vdb)
+		_, certName, caName := genClientTLSObjectNames(fp)
+		Expect(fpr.FindCommands("create key if not exists")).Should(HaveLen(1))
+		Expect(fpr.FindCommands("alter tls configuration server certificate " + certName +
+			" add ca certificates " + caName + " tlsmode 'VERIFY_CA'")).Should(HaveLen(1))
+		Expect(vdb.Status.ClientTLSFingerprint).Should(Equal(fp))
+		Expect(vdb.Status.ClientTLSMode).Should(Equal(vapi.ClientTLSModeVerifyCA))
+
+		// Nothing should be done if we run again
+		fpr.Histories = []cmds.CmdHistory{}
+		Expect(recon.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+		Expect(fpr.Histories).Should(BeEmpty())
+	})
+
+	It("should drop the old certificate when the secret is rotated", func() {
+		vdb := setupVDB("")
+		defer cleanupVDB(vdb)
+
+		fpr := &cmds.FakePodRunner{}
+		recon := MakeClientTLSReconciler(vdbRec, logger, vdb, fpr, createPodFactsDefault(fpr))
+		Expect(recon.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+		oldFp := vdb.Status.ClientTLSFingerprint
+		Expect(vdb.Status.ClientTLSMode).Should(Equal(vapi.ClientTLSModeEnable))
+
+		// Rotate the cert in the secret
+		cert, caCert, err := genClientTLSCerts(vdb)
+		Expect(err).Should(Succeed())
+		secret := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, names.GenNamespacedName(vdb, vdb.Spec.ClientTLS.Secret), secret)).Should(Succeed())
+		secret.Data = genHTTPServerSecretData(cert, caCert)
+		Expect(k8sClient.Update(ctx, secret)).Should(Succeed())
+
+		fpr.Histories = []cmds.CmdHistory{}
+		Expect(recon.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+		Expect(vdb.Status.ClientTLSFingerprint).ShouldNot(Equal(oldFp))
+		oldKey, oldCert, _ := genClientTLSObjectNames(oldFp)
+		Expect(fpr.FindCommands("drop certificate if exists " + oldCert)).Should(HaveLen(1))
+		Expect(fpr.FindCommands("drop key if exists " + oldKey)).Should(HaveLen(1))
+	})
+
+	It("should include every subcluster service in the generated certificate", func() {
+		vdb := setupVDB("")
+		defer cleanupVDB(vdb)
+
+		getDNSNames := func() []string {
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, names.GenNamespacedName(vdb, vdb.Spec.ClientTLS.Secret), secret)).Should(Succeed())
+			x509Cert, err := security.MakeCertificate(secret.Data[corev1.TLSPrivateKeyKey], secret.Data[corev1.TLSCertKey]).Buildx509()
+			Expect(err).Should(Succeed())
+			return x509Cert.DNSNames
+		}
+
+		fpr := &cmds.FakePodRunner{}
+		recon := MakeClientTLSReconciler(vdbRec, logger, vdb, fpr, createPodFactsDefault(fpr))
+		Expect(recon.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+		svcName := names.GenExtSvcName(vdb, &vdb.Spec.Subclusters[0]).Name
+		Expect(getDNSNames()).Should(ContainElements(svcName, fmt.Sprintf("%s.%s.svc", svcName, vdb.Namespace)))
+		oldFp := vdb.Status.ClientTLSFingerprint
+
+		// A new subcluster causes the certificate to be regenerated
+		vdb.Spec.Subclusters = append(vdb.Spec.Subclusters, vapi.Subcluster{Name: "sc2", Size: 1})
+		svcName = names.GenExtSvcName(vdb, &vdb.Spec.Subclusters[1]).Name
+		Expect(recon.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+		Expect(getDNSNames()).Should(ContainElement(fmt.Sprintf("%s.%s.svc.cluster.local", svcName, vdb.Namespace)))
+		Expect(vdb.Status.ClientTLSFingerprint).ShouldNot(Equal(oldFp))
+	})
+})
@@ -0,0 +1,195 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/controllers"
+	verrors "github.com/vertica/vertica-kubernetes/pkg/errors"
+	"github.com/vertica/vertica-kubernetes/pkg/events"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// CommunalCredsReconciler will set new communal credentials in the database
+// when the communal credential secret, or the SSE-C key secret, changes.
+type CommunalCredsReconciler struct {
+	VRec    *VerticaDBReconciler
+	Log     logr.Logger
+	Vdb     *vapi.VerticaDB // Vdb is the CRD we are acting on.
+	PRunner cmds.PodRunner
+	PFacts  *PodFacts
+}
+
+// communalCredParm is a single config parameter that has a communal
+// credential in it.
+type communalCredParm struct {
+	name  string
+	value string
+}
+
+// MakeCommunalCredsReconciler will build a CommunalCredsReconciler object
+func MakeCommunalCredsReconciler(vdbrecon *VerticaDBReconciler, log logr.Logger,
+	vdb *vapi.VerticaDB, prunner cmds.PodRunner, pfacts *PodFacts) controllers.ReconcileActor {
+	return &CommunalCredsReconciler{
+		VRec:    vdbrecon,
+		Log:     log,
+		Vdb:     vdb,
+		PRunner: prunner,
+		PFacts:  pfacts,
+	}
+}
+
+// Reconcile will update the communal credentials in the database if they
+// differ from the ones we last set.
+func (c *CommunalCredsReconciler) Reconcile(ctx context.Context, req *ctrl.Request) (ctrl.Result, error) {
+	// The operator doesn't own the database in this mode
+	if c.Vdb.Spec.InitPolicy == vapi.CommunalInitPolicyScheduleOnly || !c.Vdb.IsEON() {
+		return ctrl.Result{}, nil
+	}
+	if isSet, err := c.Vdb.IsConditionSet(vapi.DBInitialized); !isSet || err != nil {
+		return ctrl.Result{}, err
+	}
+
+	parms, res, err := c.genCredParms(ctx)
+	if verrors.IsReconcileAborted(res, err) || len(parms) == 0 {
+		return res, err
+	}
+	applied, found, err := c.VRec.getAppliedSecret(ctx, names.GenCommunalCredsAppliedSecretName(c.Vdb))
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	// If we never recorded the credentials, the database was initialized with
+	// the ones that are currently in the secret.
+	if !found {
+		return ctrl.Result{}, c.saveAppliedCreds(ctx, parms)
+	}
+	if credsMatch(applied, parms) {
+		return ctrl.Result{}, nil
+	}
+	return c.updateCreds(ctx, parms)
+}
+
+// genCredParms builds the config parameters for the communal credentials.
+// This uses the same values that are written to the auth parms when the
+// database is created or revived.
+func (c *CommunalCredsReconciler) genCredParms(ctx context.Context) ([]communalCredParm, ctrl.Result, error) {
+	g := &GenericDatabaseInitializer{
+		VRec:    c.VRec,
+		Log:     c.Log,
+		Vdb:     c.Vdb,
+		PRunner: c.PRunner,
+		PFacts:  c.PFacts,
+	}
+	parms := []communalCredParm{}
+	if c.Vdb.Spec.Communal.CredentialSecret != "" {
+		switch {
+		case c.Vdb.IsS3(), c.Vdb.IsGCloud():
+			auth, res, err := g.getCommunalAuth(ctx)
+			if verrors.IsReconcileAborted(res, err) {
+				return nil, res, err
+			}
+			parmName := "awsauth"
+			if c.Vdb.IsGCloud() {
+				parmName = "GCSAuth"
+			}
+			parms = append(parms, communalCredParm{name: parmName, value: auth})
+		case c.Vdb.IsAzure():
+			azureCreds, _, res, err := g.getAzureAuth(ctx)
+			if verrors.IsReconcileAborted(res, err) {
+				return nil, res, err
+			}
+			parms = append(parms, communalCredParm{name: "AzureStorageCredentials", value: genAzureCredsJSON(&azureCreds)})
+		}
+	}
+	if c.Vdb.IsS3() && c.Vdb.IsSseC() {
+		clientKey, res, err := g.getS3SseCustomerKeyValue(ctx)
+		if verrors.IsReconcileAborted(res, err) {
+			return nil, res, err
+		}
+		parms = append(parms, communalCredParm{name: S3SseCustomerKey, value: clientKey})
+	}
+	return parms, ctrl.Result{}, nil
+}
+
+// updateCreds will set the new credentials in the database, then verify we
+// can still access communal storage.
+func (c *CommunalCredsReconciler) updateCreds(ctx context.Context, parms []communalCredParm) (ctrl.Result, error) {
+	if err := c.PFacts.Collect(ctx, c.Vdb); err != nil {
+		return ctrl.Result{}, err
+	}
+	pf, ok := c.PFacts.findPodToRunVsql(false, "")
+	if !ok {
+		c.Log.Info("No up pod found to update the communal credentials. Requeue reconciliation.")
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	c.VRec.Event(c.Vdb, corev1.EventTypeNormal, events.CommunalCredsUpdateStart,
+		"Setting new communal credentials in the database")
+	for i := range parms {
+		sql := fmt.Sprintf("alter database default set parameter %s = '%s'", parms[i].name, escapeSQLString(parms[i].value))
+		if _, _, err := c.PRunner.ExecVSQL(ctx, pf.name, names.ServerContainer, "-tAc", sql); err != nil {
+			c.VRec.Eventf(c.Vdb, corev1.EventTypeWarning, events.CommunalCredsUpdateFailed,
+				"Failed to set the config parameter '%s' with the new communal credentials", parms[i].name)
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Syncing the catalog writes to communal storage, so it will fail if the
+	// new credentials don't work.
+	if _, _, err := c.PRunner.ExecVSQL(ctx, pf.name, names.ServerContainer, "-tAc", "select sync_catalog()"); err != nil {
+		c.VRec.Event(c.Vdb, corev1.EventTypeWarning, events.CommunalCredsVerifyFailed,
+			"Failed to access communal storage with the new communal credentials")
+		return ctrl.Result{}, err
+	}
+
+	if err := c.saveAppliedCreds(ctx, parms); err != nil {
+		return ctrl.Result{}, err
+	}
+	c.VRec.Event(c.Vdb, corev1.EventTypeNormal, events.CommunalCredsUpdated,
+		"Successfully set new communal credentials in the database")
+	return ctrl.Result{}, nil
+}
+
+// saveAppliedCreds will save the credentials that are set in the database in
+// a secret that the operator owns.
+func (c *CommunalCredsReconciler) saveAppliedCreds(ctx context.Context, parms []communalCredParm) error {
+	data := map[string][]byte{}
+	for i := range parms {
+		data[parms[i].name] = []byte(parms[i].value)
+	}
+	return c.VRec.saveAppliedSecret(ctx, c.Vdb, names.GenCommunalCredsAppliedSecretName(c.Vdb), data)
+}
+
+// credsMatch returns true if the applied credentials are the same as parms
+func credsMatch(applied map[string][]byte, parms []communalCredParm) bool {
+	if len(applied) != len(parms) {
+		return false
+	}
+	for i := range parms {
+		v, ok := applied[parms[i].name]
+		if !ok || string(v) != parms[i].value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,115 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vdb
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	"github.com/vertica/vertica-kubernetes/pkg/test"
+	"github.com/vertica/vertica-kubernetes/pkg/vdbstatus"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("communalcreds_reconcile", func() {
+	ctx := context.Background()
+
+	// setupVDB will create an initialized vdb along with its communal
+	// credentials.  If appliedAuth is set, it is recorded as the awsauth
+	// that was last set in the database.
+	setupVDB := func(appliedAuth string) *vapi.VerticaDB {
+		vdb := vapi.MakeVDB()
+		test.CreateVDB(ctx, k8sClient, vdb)
+		createS3CredSecret(ctx, vdb)
+		if appliedAuth != "" {
+			Expect(vdbRec.saveAppliedSecret(ctx, vdb, names.GenCommunalCredsAppliedSecretName(vdb),
+				map[string][]byte{"awsauth": []byte(appliedAuth)})).Should(Succeed())
+		}
+		Expect(vdbstatus.UpdateCondition(ctx, k8sClient, vdb,
+			vapi.VerticaDBCondition{Type: vapi.DBInitialized, Status: corev1.ConditionTrue})).Should(Succeed())
+		test.CreatePods(ctx, k8sClient, vdb, test.AllPodsRunning)
+		return vdb
+	}
+
+	cleanupVDB := func(vdb *vapi.VerticaDB) {
+		test.DeletePods(ctx, k8sClient, vdb)
+		deleteCommunalCredSecret(ctx, vdb)
+		deleteSecret(ctx, vdb, names.GenCommunalCredsAppliedSecretName(vdb).Name)
+		test.DeleteVDB(ctx, k8sClient, vdb)
+	}
+
+	expectedAuth := fmt.Sprintf("%s:%s", testAccessKey, testSecretKey)
+
+	getAppliedAuth := func(vdb *vapi.VerticaDB) string {
+		data, found, err := vdbRec.getAppliedSecret(ctx, names.GenCommunalCredsAppliedSecretName(vdb))
+		Expect(err).Should(Succeed())
+		Expect(found).Should(BeTrue())
+		return string(data["awsauth"])
+	}
+
+	It("should record the credentials the first time without setting them", func() {
+		vdb := setupVDB("")
+		defer cleanupVDB(vdb)
+
+		fpr := &cmds.FakePodRunner{}
+		recon := MakeCommunalCredsReconciler(vdbRec, logger, vdb, fpr, createPodFactsDefault(fpr))
+		Expect(recon.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+		Expect(fpr.Histories).Should(BeEmpty())
+		Expect(getAppliedAuth(vdb)).Should(Equal(expectedAuth))
+	})
+
+	It("should set the new credentials when the secret changed", func() {
+		vdb := setupVDB("stale")
+		defer cleanupVDB(vdb)
+
+		fpr := &cmds.FakePodRunner{}
+		recon := MakeCommunalCredsReconciler(vdbRec, logger, vdb, fpr, createPodFactsDefault(fpr))
+		Expect(recon.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+		Expect(fpr.FindCommands(fmt.Sprintf("alter database default set parameter awsauth = '%s:%s'",
+			testAccessKey, testSecretKey))).Should(HaveLen(1))
+		Expect(fpr.FindCommands("select sync_catalog()")).Should(HaveLen(1))
+		Expect(getAppliedAuth(vdb)).Should(Equal(expectedAuth))
+
+		// Nothing should be done if we run again
+		fpr.Histories = []cmds.CmdHistory{}
+		Expect(recon.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+		Expect(fpr.Histories).Should(BeEmpty())
+	})
+
+	It("should not record the new credentials if communal access fails", func() {
+		vdb := setupVDB("stale")
+		defer cleanupVDB(vdb)
+
+		fpr := &cmds.FakePodRunner{Results: make(cmds.CmdResults)}
+		sc := &vdb.Spec.Subclusters[0]
+		for i := int32(0); i < sc.Size; i++ {
+			fpr.Results[names.GenPodName(vdb, sc, i)] = []cmds.CmdResult{
+				{},
+				{Err: fmt.Errorf("access denied")},
+			}
+		}
+		recon := MakeCommunalCredsReconciler(vdbRec, logger, vdb, fpr, createPodFactsDefault(fpr))
+		_, err := recon.Reconcile(ctx, &ctrl.Request{})
+		Expect(err).ShouldNot(Succeed())
+		Expect(getAppliedAuth(vdb)).Should(Equal("stale"))
+	})
+})
@@ -0,0 +1,374 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vdb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/controllers"
+	"github.com/vertica/vertica-kubernetes/pkg/events"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	"github.com/vertica/vertica-kubernetes/pkg/vdbstatus"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// How often we compare the live configuration parameters against the spec
+	configParmsCheckInterval = 5 * time.Minute
+	// The node name vertica reports for a parameter that isn't set at the
+	// node level.
+	allNodesName = "ALL"
+)
+
+// ConfigParmsReconciler will set the configuration parameters from the spec
+// in the database.  It periodically checks the live values so that any drift
+// gets corrected.
+type ConfigParmsReconciler struct {
+	VRec    *VerticaDBReconciler
+	Log     logr.Logger
+	Vdb     *vapi.VerticaDB // Vdb is the CRD we are acting on.
+	PRunner cmds.PodRunner
+	PFacts  *PodFacts
+}
+
+// configParmValue is the state of a single parameter as reported by the
+// configuration_parameters system table.
+type configParmValue struct {
+	currentValue    string
+	databaseValue   string
+	restartValue    string
+	requiresRestart bool
+}
+
+// liveConfigParms holds the live values of the parameters.  It is keyed by the
+// lowercase parameter name, then by the node name.
+type liveConfigParms map[string]map[string]configParmValue
+
+// configParmChange is a parameter that needs to be set.  An empty vnodeName
+// means it is set at the database level.
+type configParmChange struct {
+	name            string
+	value           string
+	vnodeName       string
+	requiresRestart bool
+}
+
+// MakeConfigParmsReconciler will build a ConfigParmsReconciler object
+func MakeConfigParmsReconciler(vdbrecon *VerticaDBReconciler, log logr.Logger,
+	vdb *vapi.VerticaDB, prunner cmds.PodRunner, pfacts *PodFacts) controllers.ReconcileActor {
+	return &ConfigParmsReconciler{
+		VRec:    vdbrecon,
+		Log:     log,
+		Vdb:     vdb,
+		PRunner: prunner,
+		PFacts:  pfacts,
+	}
+}
+
+// Reconcile will set any configuration parameter that differs from the spec
+func (c *ConfigParmsReconciler) Reconcile(ctx context.Context, req *ctrl.Request) (ctrl.Result, error) {
+	// The operator doesn't own the database in this mode
+	if c.Vdb.Spec.InitPolicy == vapi.CommunalInitPolicyScheduleOnly {
+		return ctrl.Result{}, nil
+	}
+	if !c.hasConfigParms() {
+		return ctrl.Result{}, c.updatePendingRestart(ctx, nil)
+	}
+
+	if err := c.PFacts.Collect(ctx, c.Vdb); err != nil {
+		return ctrl.Result{}, err
+	}
+	pf, ok := c.PFacts.findPodToRunVsql(false, "")
+	if !ok {
+		c.Log.Info("No up pod found to check the configuration parameters. Will check again later.")
+		deferRequeue(ctx, configParmsCheckInterval)
+		return ctrl.Result{}, nil
+	}
+
+	live, err := c.fetchLiveParms(ctx, pf)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	changes := c.findDrift(live)
+	restartNeeded := false
+	for i := range changes {
+		if err := c.setParm(ctx, pf, &changes[i]); err != nil {
+			return ctrl.Result{}, err
+		}
+		live.apply(&changes[i])
+		if changes[i].requiresRestart {
+			restartNeeded = true
+		}
+	}
+	pending := live.pendingRestart()
+	if len(changes) > 0 {
+		c.VRec.Eventf(c.Vdb, corev1.EventTypeNormal, events.ConfigParametersSet,
+			"Set %d configuration parameter(s) in the database", len(changes))
+	}
+
+	sort.Strings(pending)
+	if err := c.updatePendingRestart(ctx, pending); err != nil {
+		return ctrl.Result{}, err
+	}
+	if restartNeeded {
+		c.VRec.Eventf(c.Vdb, corev1.EventTypeNormal, events.ConfigParameterRestartNeeded,
+			"The database will be restarted for these configuration parameters to take effect: %s",
+			strings.Join(pending, ", "))
+		cond := vapi.VerticaDBCondition{Type: vapi.VerticaRestartNeeded, Status: corev1.ConditionTrue}
+		if err := vdbstatus.UpdateCondition(ctx, c.VRec.Client, c.Vdb, cond); err != nil {
+			return ctrl.Result{}, err
+		}
+		// Requeue so that the stop and restart reconcilers pick up the condition
+		return ctrl.Result{Requeue: true}, nil
+	}
+	// Check again later for drift.  This doesn't stop the actors after us.
+	deferRequeue(ctx, configParmsCheckInterval)
+	return ctrl.Result{}, nil
+}
+
+// hasConfigParms returns true if any configuration parameter is in the spec
+func (c *ConfigParmsReconciler) hasConfigParms() bool {
+	if len(c.Vdb.Spec.ConfigParameters) > 0 {
+		return true
+	}
+	for i := range c.Vdb.Spec.Subclusters {
+		if len(c.Vdb.Spec.Subclusters[i].ConfigParameters) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// configParmNames returns the lowercase name of every parameter in the spec
+func (c *ConfigParmsReconciler) configParmNames() []string {
+	parmNames := []string{}
+	addNames := func(parms map[string]string) {
+		for k := range parms {
+			parmNames = appendIfMissing(parmNames, strings.ToLower(k))
+		}
+	}
+	addNames(c.Vdb.Spec.ConfigParameters)
+	for i := range c.Vdb.Spec.Subclusters {
+		addNames(c.Vdb.Spec.Subclusters[i].ConfigParameters)
+	}
+	sort.Strings(parmNames)
+	return parmNames
+}
+
+// fetchLiveParms will query the database for the current state of the
+// parameters that are in the spec.
+func (c *ConfigParmsReconciler) fetchLiveParms(ctx context.Context, pf *PodFact) (liveConfigParms, error) {
+	quoted := []string{}
+	for _, n := range c.configParmNames() {
+		quoted = append(quoted, fmt.Sprintf("'%s'", escapeSQLString(n)))
+	}
+	sql := fmt.Sprintf("select node_name, lower(parameter_name), current_value, database_value, restart_value, "+
+		"change_requires_restart from configuration_parameters where lower(parameter_name) in (%s)",
+		strings.Join(quoted, ", "))
+	stdout, _, err := c.PRunner.ExecVSQL(ctx, pf.name, names.ServerContainer, "-tAc", sql)
+	if err != nil {
+		return nil, err
+	}
+	return parseLiveConfigParms(stdout), nil
+}
+
+// parseLiveConfigParms will parse the output of the configuration_parameters
+// query.
+func parseLiveConfigParms(stdout string) liveConfigParms {
+	const ExpectedCols = 6
+	live := liveConfigParms{}
+	for _, line := range strings.Split(stdout, "\n") {
+		cols := strings.Split(line, "|")
+		if len(cols) != ExpectedCols {
+			continue
+		}
+		if _, ok := live[cols[1]]; !ok {
+			live[cols[1]] = map[string]configParmValue{}
+		}
+		live[cols[1]][cols[0]] = configParmValue{
+			currentValue:    cols[2],
+			databaseValue:   cols[3],
+			restartValue:    cols[4],
+			requiresRestart: cols[5] == "t",
+		}
+	}
+	return live
+}
+
+// lookup returns the state of the parameter for the given node.  If the
+// parameter isn't set at the node level, the database wide state is returned.
+func (l liveConfigParms) lookup(parmName, vnodeName string) (configParmValue, bool) {
+	nodes, ok := l[strings.ToLower(parmName)]
+	if !ok {
+		return configParmValue{}, false
+	}
+	if v, ok := nodes[vnodeName]; ok {
+		return v, true
+	}
+	if v, ok := nodes[allNodesName]; ok {
+		return v, true
+	}
+	// Fallback to any node.  The database value is the same for all of them.
+	for _, v := range nodes {
+		return v, true
+	}
+	return configParmValue{}, false
+}
+
+// pendingRestart returns the names of the parameters whose value won't take
+// effect until the database is restarted.
+func (l liveConfigParms) pendingRestart() []string {
+	pending := []string{}
+	for parmName, nodes := range l {
+		for _, v := range nodes {
+			if v.requiresRestart && v.currentValue != v.restartValue {
+				pending = appendIfMissing(pending, parmName)
+			}
+		}
+	}
+	return pending
+}
+
+// apply will update the live state to reflect a parameter that we just set.
+// Parameters that require a restart only have their restart value changed.
+func (l liveConfigParms) apply(chg *configParmChange) {
+	parmName := strings.ToLower(chg.name)
+	nodeName := chg.vnodeName
+	if nodeName == "" {
+		nodeName = allNodesName
+	}
+	v, ok := l.lookup(parmName, nodeName)
+	if !ok {
+		return
+	}
+	v.restartValue = chg.value
+	if !v.requiresRestart {
+		v.currentValue = chg.value
+	}
+	l[parmName][nodeName] = v
+}
+
+// findDrift returns the parameters whose live value differs from the spec.  At
+// both the database and node level we compare against the restart value, which
+// is the value the parameter will have once any pending restart is done.
+func (c *ConfigParmsReconciler) findDrift(live liveConfigParms) []configParmChange {
+	changes := []configParmChange{}
+	dbParmNames := sortedKeys(c.Vdb.Spec.ConfigParameters)
+	for _, k := range dbParmNames {
+		v, ok := live.lookup(k, allNodesName)
+		if !ok {
+			c.reportUnknownParm(k)
+			continue
+		}
+		if v.restartValue != c.Vdb.Spec.ConfigParameters[k] {
+			changes = append(changes, configParmChange{
+				name: k, value: c.Vdb.Spec.ConfigParameters[k], requiresRestart: v.requiresRestart,
+			})
+		}
+	}
+
+	for i := range c.Vdb.Spec.Subclusters {
+		sc := &c.Vdb.Spec.Subclusters[i]
+		for _, k := range sortedKeys(sc.ConfigParameters) {
+			for j := int32(0); j < sc.Size; j++ {
+				pf, ok := c.PFacts.Detail[names.GenPodName(c.Vdb, sc, j)]
+				// Node level parameters can only be checked for nodes that are up
+				if !ok || !pf.upNode || pf.vnodeName == "" {
+					continue
+				}
+				v, ok := live.lookup(k, pf.vnodeName)
+				if !ok {
+					c.reportUnknownParm(k)
+					break
+				}
+				if v.restartValue != sc.ConfigParameters[k] {
+					changes = append(changes, configParmChange{
+						name: k, value: sc.ConfigParameters[k], vnodeName: pf.vnodeName, requiresRestart: v.requiresRestart,
+					})
+				}
+			}
+		}
+	}
+	return changes
+}
+
+// reportUnknownParm will log an event for a parameter that vertica doesn't know about
+func (c *ConfigParmsReconciler) reportUnknownParm(parmName string) {
+	c.VRec.Eventf(c.Vdb, corev1.EventTypeWarning, events.InvalidConfigParameter,
+		"The configuration parameter '%s' does not exist in the database", parmName)
+}
+
+// setParm will set a single parameter in the database
+func (c *ConfigParmsReconciler) setParm(ctx context.Context, pf *PodFact, chg *configParmChange) error {
+	target := "database default"
+	if chg.vnodeName != "" {
+		target = fmt.Sprintf("node %s", chg.vnodeName)
+	}
+	sql := fmt.Sprintf("alter %s set parameter %s = '%s'", target, chg.name, escapeSQLString(chg.value))
+	c.Log.Info("Setting configuration parameter", "name", chg.name, "value", chg.value, "node", chg.vnodeName)
+	if _, _, err := c.PRunner.ExecVSQL(ctx, pf.name, names.ServerContainer, "-tAc", sql); err != nil {
+		c.VRec.Eventf(c.Vdb, corev1.EventTypeWarning, events.ConfigParameterSetFailed,
+			"Failed to set the configuration parameter '%s'", chg.name)
+		return err
+	}
+	return nil
+}
+
+// updatePendingRestart will update the status with the list of parameters
+// that are waiting for a restart.
+func (c *ConfigParmsReconciler) updatePendingRestart(ctx context.Context, pending []string) error {
+	if len(pending) == 0 && len(c.Vdb.Status.ConfigParametersPendingRestart) == 0 {
+		return nil
+	}
+	return vdbstatus.Update(ctx, c.VRec.Client, c.Vdb, func(vdb *vapi.VerticaDB) error {
+		vdb.Status.ConfigParametersPendingRestart = pending
+		return nil
+	})
+}
+
+// escapeSQLString will escape a value so that it can be put in a single
+// quoted SQL string literal.
+func escapeSQLString(s string) string {
+	return strings.ReplaceAll(s, "'", "''")
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// appendIfMissing will add a string to a slice if it isn't already there
+func appendIfMissing(s []string, v string) []string {
+	for i := range s {
+		if s[i] == v {
+			return s
+		}
+	}
+	return append(s, v)
+}
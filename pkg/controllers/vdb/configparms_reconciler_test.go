@@ -0,0 +1,142 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vdb
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	"github.com/vertica/vertica-kubernetes/pkg/test"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("configparms_reconcile", func() {
+	ctx := context.Background()
+
+	// setQueryResult will have every pod return the given output for the
+	// configuration_parameters query. Only one of them will be used.
+	setQueryResult := func(fpr *cmds.FakePodRunner, vdb *vapi.VerticaDB, stdout string) {
+		sc := &vdb.Spec.Subclusters[0]
+		for i := int32(0); i < sc.Size; i++ {
+			fpr.Results[names.GenPodName(vdb, sc, i)] = []cmds.CmdResult{{Stdout: stdout}}
+		}
+	}
+
+	It("should be a no-op if no config parameters are in the spec", func() {
+		vdb := vapi.MakeVDB()
+		fpr := &cmds.FakePodRunner{}
+		recon := MakeConfigParmsReconciler(vdbRec, logger, vdb, fpr, createPodFactsDefault(fpr))
+		Expect(recon.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+		Expect(fpr.Histories).Should(BeEmpty())
+	})
+
+	It("should set database parameters that drifted and request a restart", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.ConfigParameters = map[string]string{
+			"MaxClientSessions": "100",
+			"EnableSSL":         "1",
+			"DataSSLParams":     "a'b",
+		}
+		test.CreateVDB(ctx, k8sClient, vdb)
+		defer test.DeleteVDB(ctx, k8sClient, vdb)
+		test.CreatePods(ctx, k8sClient, vdb, test.AllPodsRunning)
+		defer test.DeletePods(ctx, k8sClient, vdb)
+
+		fpr := &cmds.FakePodRunner{Results: make(cmds.CmdResults)}
+		setQueryResult(fpr, vdb, "ALL|maxclientsessions|50|50|50|f\n"+
+			"ALL|enablessl|0|0|0|t\n"+
+			"ALL|datasslparams|a'b|a'b|a'b|f\n")
+		recon := MakeConfigParmsReconciler(vdbRec, logger, vdb, fpr, createPodFactsDefault(fpr))
+		Expect(recon.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{Requeue: true}))
+		Expect(fpr.FindCommands("alter database default set parameter MaxClientSessions = '100'")).Should(HaveLen(1))
+		Expect(fpr.FindCommands("alter database default set parameter EnableSSL = '1'")).Should(HaveLen(1))
+		Expect(fpr.FindCommands("DataSSLParams =")).Should(BeEmpty())
+
+		Expect(vdb.IsConditionSet(vapi.VerticaRestartNeeded)).Should(BeTrue())
+		Expect(vdb.Status.ConfigParametersPendingRestart).Should(Equal([]string{"enablessl"}))
+	})
+
+	It("should only recheck the parameters later if nothing drifted", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.ConfigParameters = map[string]string{"MaxClientSessions": "100"}
+		test.CreateVDB(ctx, k8sClient, vdb)
+		defer test.DeleteVDB(ctx, k8sClient, vdb)
+		test.CreatePods(ctx, k8sClient, vdb, test.AllPodsRunning)
+		defer test.DeletePods(ctx, k8sClient, vdb)
+
+		fpr := &cmds.FakePodRunner{Results: make(cmds.CmdResults)}
+		setQueryResult(fpr, vdb, "ALL|maxclientsessions|100|100|100|f\n")
+		recon := MakeConfigParmsReconciler(vdbRec, logger, vdb, fpr, createPodFactsDefault(fpr))
+		dctx, deferred := withDeferredRequeue(ctx)
+		Expect(recon.Reconcile(dctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+		Expect(deferred.requeueAfter).Should(Equal(configParmsCheckInterval))
+		Expect(fpr.FindCommands("alter ")).Should(BeEmpty())
+	})
+
+	It("should not set a parameter again if it is only waiting for a restart", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.ConfigParameters = map[string]string{"EnableSSL": "1"}
+		test.CreateVDB(ctx, k8sClient, vdb)
+		defer test.DeleteVDB(ctx, k8sClient, vdb)
+		test.CreatePods(ctx, k8sClient, vdb, test.AllPodsRunning)
+		defer test.DeletePods(ctx, k8sClient, vdb)
+
+		fpr := &cmds.FakePodRunner{Results: make(cmds.CmdResults)}
+		setQueryResult(fpr, vdb, "ALL|enablessl|0|1|1|t\n")
+		recon := MakeConfigParmsReconciler(vdbRec, logger, vdb, fpr, createPodFactsDefault(fpr))
+		dctx, deferred := withDeferredRequeue(ctx)
+		Expect(recon.Reconcile(dctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+		Expect(deferred.requeueAfter).Should(Equal(configParmsCheckInterval))
+		Expect(fpr.FindCommands("alter ")).Should(BeEmpty())
+		Expect(vdb.Status.ConfigParametersPendingRestart).Should(Equal([]string{"enablessl"}))
+	})
+
+	It("should set node level parameters for each up node in the subcluster", func() {
+		vdb := vapi.MakeVDB()
+		sc := &vdb.Spec.Subclusters[0]
+		sc.ConfigParameters = map[string]string{"MaxClientSessions": "20"}
+		test.CreateVDB(ctx, k8sClient, vdb)
+		defer test.DeleteVDB(ctx, k8sClient, vdb)
+		test.CreatePods(ctx, k8sClient, vdb, test.AllPodsRunning)
+		defer test.DeletePods(ctx, k8sClient, vdb)
+
+		fpr := &cmds.FakePodRunner{Results: make(cmds.CmdResults)}
+		pfacts := createPodFactsDefault(fpr)
+		Expect(pfacts.Collect(ctx, vdb)).Should(Succeed())
+		for i := int32(0); i < sc.Size; i++ {
+			pfacts.Detail[names.GenPodName(vdb, sc, i)].vnodeName = fmt.Sprintf("v_db_node%04d", i+1)
+		}
+		// The first node already has the parameter set at the node level
+		setQueryResult(fpr, vdb, "ALL|maxclientsessions|50|50|50|f\n"+
+			"v_db_node0001|maxclientsessions|20|50|20|f\n")
+		recon := MakeConfigParmsReconciler(vdbRec, logger, vdb, fpr, pfacts)
+		dctx, deferred := withDeferredRequeue(ctx)
+		Expect(recon.Reconcile(dctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+		Expect(deferred.requeueAfter).Should(Equal(configParmsCheckInterval))
+		Expect(fpr.FindCommands("alter node")).Should(HaveLen(2))
+		Expect(fpr.FindCommands("alter node v_db_node0001")).Should(BeEmpty())
+		Expect(fpr.FindCommands("alter node v_db_node0002 set parameter MaxClientSessions = '20'")).Should(HaveLen(1))
+	})
+
+	It("should escape single quotes in parameter values", func() {
+		Expect(escapeSQLString("a'b")).Should(Equal("a''b"))
+	})
+})
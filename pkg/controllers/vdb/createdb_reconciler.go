@@ -30,7 +30,7 @@ import (
 	"github.com/vertica/vertica-kubernetes/pkg/license"
 	"github.com/vertica/vertica-kubernetes/pkg/mgmterrors"
 	"github.com/vertica/vertica-kubernetes/pkg/names"
-	"github.com/vertica/vertica-kubernetes/pkg/paths"
+	"github.com/vertica/vertica-kubernetes/pkg/vadmin"
 	"github.com/vertica/vertica-kubernetes/pkg/vdbstatus"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -45,24 +45,26 @@ const (
 
 // CreateDBReconciler will create a database if one wasn't created yet.
 type CreateDBReconciler struct {
-	VRec    *VerticaDBReconciler
-	Log     logr.Logger
-	Vdb     *vapi.VerticaDB // Vdb is the CRD we are acting on.
-	PRunner cmds.PodRunner
-	PFacts  *PodFacts
-	EVLogr  mgmterrors.EventLogger
+	VRec       *VerticaDBReconciler
+	Log        logr.Logger
+	Vdb        *vapi.VerticaDB // Vdb is the CRD we are acting on.
+	PRunner    cmds.PodRunner
+	PFacts     *PodFacts
+	EVLogr     mgmterrors.EventLogger
+	Dispatcher vadmin.Dispatcher
 }
 
 // MakeCreateDBReconciler will build a CreateDBReconciler object
 func MakeCreateDBReconciler(vdbrecon *VerticaDBReconciler, log logr.Logger,
 	vdb *vapi.VerticaDB, prunner cmds.PodRunner, pfacts *PodFacts) controllers.ReconcileActor {
 	return &CreateDBReconciler{
-		VRec:    vdbrecon,
-		Log:     log,
-		Vdb:     vdb,
-		PRunner: prunner,
-		PFacts:  pfacts,
-		EVLogr:  mgmterrors.MakeATErrors(vdbrecon, vdb, events.CreateDBFailed),
+		VRec:       vdbrecon,
+		Log:        log,
+		Vdb:        vdb,
+		PRunner:    prunner,
+		PFacts:     pfacts,
+		EVLogr:     mgmterrors.MakeATErrors(vdbrecon, vdbrecon.Client, vdb, events.CreateDBFailed),
+		Dispatcher: vdbrecon.makeDispatcher(log, vdb, prunner),
 	}
 }
 
@@ -73,6 +75,11 @@ func (c *CreateDBReconciler) Reconcile(ctx context.Context, req *ctrl.Request) (
 		c.Vdb.Spec.InitPolicy != vapi.CommunalInitPolicyCreateSkipPackageInstall {
 		return ctrl.Result{}, nil
 	}
+	// A database that is resuming from hibernation already exists.  If its
+	// local data is gone, it gets revived by ReviveDBReconciler.
+	if isResumingInitializedDB(c.Vdb) {
+		return ctrl.Result{}, nil
+	}
 
 	// The remaining create_db logic is driven from GenericDatabaseInitializer.
 	// This exists to creation an abstraction that is common with revive_db.
@@ -87,15 +94,22 @@ func (c *CreateDBReconciler) Reconcile(ctx context.Context, req *ctrl.Request) (
 	return g.checkAndRunInit(ctx)
 }
 
-// execCmd will do the actual execution of admintools -t create_db.
+// execCmd will do the actual execution of create_db.
 // This handles logging of necessary events.
-func (c *CreateDBReconciler) execCmd(ctx context.Context, atPod types.NamespacedName, cmd []string) (ctrl.Result, error) {
-	c.VRec.Event(c.Vdb, corev1.EventTypeNormal, events.CreateDBStart,
-		"Calling 'admintools -t create_db'")
+func (c *CreateDBReconciler) execCmd(ctx context.Context, atPod types.NamespacedName, hostList []string) (ctrl.Result, error) {
+	opts, err := c.genOptions(ctx, atPod, hostList)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	c.VRec.Eventf(c.Vdb, corev1.EventTypeNormal, events.CreateDBStart,
+		"Calling '%s'", c.Dispatcher.DescribeOp(vadmin.OpCreateDB))
 	start := time.Now()
-	stdout, _, err := c.PRunner.ExecAdmintools(ctx, atPod, names.ServerContainer, cmd...)
+	stdout, err := c.Dispatcher.CreateDB(ctx, opts)
 	if err != nil {
-		return c.EVLogr.LogFailure("create_db", stdout, err)
+		return c.EVLogr.LogFailure(ctx, "create_db", stdout, err)
+	}
+	if err := c.EVLogr.LogSuccess(ctx, "create_db"); err != nil {
+		return ctrl.Result{}, err
 	}
 	sc := c.getFirstPrimarySubcluster()
 	c.VRec.Eventf(c.Vdb, corev1.EventTypeNormal, events.CreateDBSucceeded,
@@ -224,45 +238,17 @@ func (c *CreateDBReconciler) getFirstPrimarySubcluster() *vapi.Subcluster {
 	return &c.Vdb.Spec.Subclusters[0]
 }
 
-// genCmd will return the command to run in the pod to create the database
-func (c *CreateDBReconciler) genCmd(ctx context.Context, hostList []string) ([]string, error) {
+// genOptions will return the options to create the database with
+func (c *CreateDBReconciler) genOptions(ctx context.Context, atPod types.NamespacedName,
+	hostList []string) (*vadmin.CreateDBOptions, error) {
 	licPath, err := license.GetPath(ctx, c.VRec.Client, c.Vdb)
 	if err != nil {
-		return []string{}, err
-	}
-
-	cmd := []string{
-		"-t", "create_db",
-		"--skip-fs-checks",
-		"--hosts=" + strings.Join(hostList, ","),
-		"--sql=" + PostDBCreateSQLFile,
-		"--catalog_path=" + c.Vdb.Spec.Local.GetCatalogPath(),
-		"--database", c.Vdb.Spec.DBName,
-		"--force-cleanup-on-failure",
-		"--noprompt",
-		"--license", licPath,
-		"--depot-path=" + c.Vdb.Spec.Local.DepotPath,
-	}
-
-	// If a communal path is set, include all of the EON parameters.
-	if c.Vdb.Spec.Communal.Path != "" {
-		cmd = append(cmd,
-			"--communal-storage-location="+c.Vdb.GetCommunalPath(),
-			"--communal-storage-params="+paths.AuthParmsFile,
-		)
-	}
-
-	if c.Vdb.Spec.ShardCount > 0 {
-		cmd = append(cmd,
-			fmt.Sprintf("--shard-count=%d", c.Vdb.Spec.ShardCount),
-		)
-	}
-
-	if c.Vdb.Spec.InitPolicy == vapi.CommunalInitPolicyCreateSkipPackageInstall {
-		vinf, ok := c.Vdb.MakeVersionInfo()
-		if ok && vinf.IsEqualOrNewer(vapi.CreateDBSkipPackageInstallVersion) {
-			cmd = append(cmd, "--skip-package-install")
-		}
-	}
-	return cmd, nil
+		return nil, err
+	}
+	return &vadmin.CreateDBOptions{
+		InitiatorOptions:    c.PFacts.makeInitiatorOptions(atPod),
+		Hosts:               hostList,
+		PostDBCreateSQLFile: PostDBCreateSQLFile,
+		LicensePath:         licPath,
+	}, nil
 }
@@ -26,24 +26,26 @@ import (
 	"github.com/vertica/vertica-kubernetes/pkg/controllers"
 	verrors "github.com/vertica/vertica-kubernetes/pkg/errors"
 	"github.com/vertica/vertica-kubernetes/pkg/events"
-	"github.com/vertica/vertica-kubernetes/pkg/names"
+	"github.com/vertica/vertica-kubernetes/pkg/vadmin"
 	corev1 "k8s.io/api/core/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
 // DBAddNodeReconciler will ensure each pod is added to the database.
 type DBAddNodeReconciler struct {
-	VRec    *VerticaDBReconciler
-	Log     logr.Logger
-	Vdb     *vapi.VerticaDB // Vdb is the CRD we are acting on.
-	PRunner cmds.PodRunner
-	PFacts  *PodFacts
+	VRec       *VerticaDBReconciler
+	Log        logr.Logger
+	Vdb        *vapi.VerticaDB // Vdb is the CRD we are acting on.
+	PRunner    cmds.PodRunner
+	PFacts     *PodFacts
+	Dispatcher vadmin.Dispatcher
 }
 
 // MakeDBAddNodeReconciler will build a DBAddNodeReconciler object
 func MakeDBAddNodeReconciler(vdbrecon *VerticaDBReconciler, log logr.Logger,
 	vdb *vapi.VerticaDB, prunner cmds.PodRunner, pfacts *PodFacts) controllers.ReconcileActor {
-	return &DBAddNodeReconciler{VRec: vdbrecon, Log: log, Vdb: vdb, PRunner: prunner, PFacts: pfacts}
+	return &DBAddNodeReconciler{VRec: vdbrecon, Log: log, Vdb: vdb, PRunner: prunner, PFacts: pfacts,
+		Dispatcher: vdbrecon.makeDispatcher(log, vdb, prunner)}
 }
 
 // Reconcile will ensure a DB exists and create one if it doesn't
@@ -140,10 +142,14 @@ func (d *DBAddNodeReconciler) runAddNode(ctx context.Context, pods []*PodFact) (
 func (d *DBAddNodeReconciler) runAddNodeForPod(ctx context.Context, pods []*PodFact, atPod *PodFact) (string, error) {
 	podNames := genPodNames(pods)
 	d.VRec.Eventf(d.Vdb, corev1.EventTypeNormal, events.AddNodeStart,
-		"Calling 'admintools -t db_add_node' for pod(s) '%s'", podNames)
+		"Calling '%s' for pod(s) '%s'", d.Dispatcher.DescribeOp(vadmin.OpAddNode), podNames)
 	start := time.Now()
-	cmd := d.genAddNodeCommand(pods)
-	stdout, _, err := d.PRunner.ExecAdmintools(ctx, atPod.name, names.ServerContainer, cmd...)
+	opts := vadmin.AddNodeOptions{
+		InitiatorOptions: d.PFacts.makeInitiatorOptions(atPod.name),
+		Hosts:            genAddNodeHostList(pods),
+		Subcluster:       pods[0].subclusterName,
+	}
+	stdout, err := d.Dispatcher.AddNode(ctx, &opts)
 	if err != nil {
 		switch {
 		case isLicenseLimitError(stdout):
@@ -151,11 +157,11 @@ func (d *DBAddNodeReconciler) runAddNodeForPod(ctx context.Context, pods []*PodF
 				"You cannot add more nodes to the database.  You have reached the limit allowed by your license.")
 		default:
 			d.VRec.Eventf(d.Vdb, corev1.EventTypeWarning, events.AddNodeFailed,
-				"Failed when calling 'admintools -t db_add_node' for pod(s) '%s'", podNames)
+				"Failed when calling '%s' for pod(s) '%s'", d.Dispatcher.DescribeOp(vadmin.OpAddNode), podNames)
 		}
 	} else {
 		d.VRec.Eventf(d.Vdb, corev1.EventTypeNormal, events.AddNodeSucceeded,
-			"Successfully called 'admintools -t db_add_node' and it took %s", time.Since(start))
+			"Successfully called '%s' and it took %s", d.Dispatcher.DescribeOp(vadmin.OpAddNode), time.Since(start))
 	}
 	return stdout, err
 }
@@ -165,18 +171,11 @@ func isLicenseLimitError(stdout string) bool {
 	return strings.Contains(stdout, "Cannot create another node. The current license permits")
 }
 
-// genAddNodeCommand returns the command to run to add nodes to the cluster.
-func (d *DBAddNodeReconciler) genAddNodeCommand(pods []*PodFact) []string {
+// genAddNodeHostList returns the host names of the pods to add to the cluster.
+func genAddNodeHostList(pods []*PodFact) []string {
 	hostNames := make([]string, 0, len(pods))
 	for _, pod := range pods {
 		hostNames = append(hostNames, pod.dnsName)
 	}
-
-	return []string{
-		"-t", "db_add_node",
-		"--hosts", strings.Join(hostNames, ","),
-		"--database", d.Vdb.Spec.DBName,
-		"--subcluster", pods[0].subclusterName,
-		"--noprompt",
-	}
+	return hostNames
 }
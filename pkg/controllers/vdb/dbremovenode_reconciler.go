@@ -18,7 +18,6 @@ package vdb
 import (
 	"context"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -29,6 +28,7 @@ import (
 	"github.com/vertica/vertica-kubernetes/pkg/events"
 	"github.com/vertica/vertica-kubernetes/pkg/iter"
 	"github.com/vertica/vertica-kubernetes/pkg/names"
+	"github.com/vertica/vertica-kubernetes/pkg/vadmin"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -37,22 +37,24 @@ import (
 
 // DBRemoveNodeReconciler will handle removing a node from the database during scale down.
 type DBRemoveNodeReconciler struct {
-	VRec    *VerticaDBReconciler
-	Log     logr.Logger
-	Vdb     *vapi.VerticaDB // Vdb is the CRD we are acting on.
-	PRunner cmds.PodRunner
-	PFacts  *PodFacts
+	VRec       *VerticaDBReconciler
+	Log        logr.Logger
+	Vdb        *vapi.VerticaDB // Vdb is the CRD we are acting on.
+	PRunner    cmds.PodRunner
+	PFacts     *PodFacts
+	Dispatcher vadmin.Dispatcher
 }
 
 // MakeDBRemoveNodeReconciler will build and return the DBRemoveNodeReconciler object.
 func MakeDBRemoveNodeReconciler(vdbrecon *VerticaDBReconciler, log logr.Logger,
 	vdb *vapi.VerticaDB, prunner cmds.PodRunner, pfacts *PodFacts) controllers.ReconcileActor {
 	return &DBRemoveNodeReconciler{
-		VRec:    vdbrecon,
-		Log:     log,
-		Vdb:     vdb,
-		PRunner: prunner,
-		PFacts:  pfacts,
+		VRec:       vdbrecon,
+		Log:        log,
+		Vdb:        vdb,
+		PRunner:    prunner,
+		PFacts:     pfacts,
+		Dispatcher: vdbrecon.makeDispatcher(log, vdb, prunner),
 	}
 }
 
@@ -112,7 +114,6 @@ func (d *DBRemoveNodeReconciler) removeNodesInSubcluster(ctx context.Context, sc
 	startPodIndex, endPodIndex int32) (ctrl.Result, error) {
 	podsToRemove, requeueNeeded := d.findPodsSuitableForScaleDown(sc, startPodIndex, endPodIndex)
 	if len(podsToRemove) > 0 {
-		cmd := d.genCmdRemoveNode(podsToRemove)
 		atPod, ok := d.PFacts.findPodToRunAdmintoolsAny()
 		if !ok {
 			// Requeue since we couldn't find a running pod
@@ -120,8 +121,8 @@ func (d *DBRemoveNodeReconciler) removeNodesInSubcluster(ctx context.Context, sc
 			return ctrl.Result{Requeue: true}, nil
 		}
 
-		if err := d.execATCmd(ctx, atPod.name, genPodNames(podsToRemove), cmd); err != nil {
-			return ctrl.Result{}, fmt.Errorf("failed to call admintools -t db_remove_node: %w", err)
+		if err := d.execATCmd(ctx, atPod.name, podsToRemove); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to remove nodes: %w", err)
 		}
 
 		// We successfully called db_remove_node, invalidate the pod facts cache
@@ -135,19 +136,23 @@ func (d *DBRemoveNodeReconciler) removeNodesInSubcluster(ctx context.Context, sc
 	return ctrl.Result{Requeue: requeueNeeded}, nil
 }
 
-// execATCmd will run the admintools command to remove the node
+// execATCmd will run the command to remove the nodes
 // This handles recording of the events.
-func (d *DBRemoveNodeReconciler) execATCmd(ctx context.Context, atPod types.NamespacedName, podNames string, cmd []string) error {
+func (d *DBRemoveNodeReconciler) execATCmd(ctx context.Context, atPod types.NamespacedName, pods []*PodFact) error {
 	d.VRec.Eventf(d.Vdb, corev1.EventTypeNormal, events.RemoveNodesStart,
-		"Calling 'admintools -t db_remove_node' for pods '%s'", podNames)
+		"Calling '%s' for pods '%s'", d.Dispatcher.DescribeOp(vadmin.OpRemoveNode), genPodNames(pods))
 	start := time.Now()
-	if _, _, err := d.PRunner.ExecAdmintools(ctx, atPod, names.ServerContainer, cmd...); err != nil {
-		d.VRec.Event(d.Vdb, corev1.EventTypeWarning, events.RemoveNodesFailed,
-			"Failed when calling 'admintools -t db_remove_node'")
+	opts := vadmin.RemoveNodeOptions{
+		InitiatorOptions: d.PFacts.makeInitiatorOptions(atPod),
+		Hosts:            genRemoveNodeHostList(pods),
+	}
+	if _, err := d.Dispatcher.RemoveNode(ctx, &opts); err != nil {
+		d.VRec.Eventf(d.Vdb, corev1.EventTypeWarning, events.RemoveNodesFailed,
+			"Failed when calling '%s'", d.Dispatcher.DescribeOp(vadmin.OpRemoveNode))
 		return err
 	}
 	d.VRec.Eventf(d.Vdb, corev1.EventTypeNormal, events.RemoveNodesSucceeded,
-		"Successfully called 'admintools -t db_remove_node' and it took %s", time.Since(start))
+		"Successfully called '%s' and it took %s", d.Dispatcher.DescribeOp(vadmin.OpRemoveNode), time.Since(start))
 	return nil
 }
 
@@ -180,16 +185,11 @@ func (d *DBRemoveNodeReconciler) findPodsSuitableForScaleDown(sc *vapi.Subcluste
 	return pods, requeueNeeded
 }
 
-// genCmdUninstall generates the command to use to uninstall a single host
-func (d *DBRemoveNodeReconciler) genCmdRemoveNode(pods []*PodFact) []string {
+// genRemoveNodeHostList returns the host names of the pods to remove
+func genRemoveNodeHostList(pods []*PodFact) []string {
 	hostNames := make([]string, 0, len(pods))
 	for _, pod := range pods {
 		hostNames = append(hostNames, pod.dnsName)
 	}
-	return []string{
-		"-t", "db_remove_node",
-		"--database", d.Vdb.Spec.DBName,
-		"--hosts=" + strings.Join(hostNames, ","),
-		"--noprompts",
-	}
+	return hostNames
 }
@@ -74,6 +74,9 @@ func (d *DBRemoveSubclusterReconciler) removeExtraSubclusters(ctx context.Contex
 	}
 
 	if len(subclusters) > 0 {
+		if wait, err := waitForMaintenanceWindow(ctx, d.VRec, d.Log, d.Vdb, "remove subclusters"); wait || err != nil {
+			return ctrl.Result{}, err
+		}
 		atPod, ok := d.PFacts.findPodToRunAdmintoolsAny()
 		if !ok || !atPod.upNode {
 			d.Log.Info("No pod found to run admintools from. Requeue reconciliation.")
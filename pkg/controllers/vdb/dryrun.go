@@ -0,0 +1,132 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/builder"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/controllers"
+	"github.com/vertica/vertica-kubernetes/pkg/dryrun"
+	"github.com/vertica/vertica-kubernetes/pkg/events"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// The key in the dry-run ConfigMap that has the plan
+	DryRunPlanKey = "plan"
+)
+
+// reconcileDryRun will run the actors for a VerticaDB in dry-run mode.  The
+// actors read the current state as usual, but every change they would make is
+// recorded in a plan that is saved in a ConfigMap.  The commands run in the pods
+// are only recorded if they would change something.  The actors that requeue
+// don't stop the plan since nothing they wait for will happen.
+func (r *VerticaDBReconciler) reconcileDryRun(ctx context.Context, log logr.Logger, req *ctrl.Request,
+	vdb *vapi.VerticaDB, prunner cmds.PodRunner, pausedActors map[string]bool) (ctrl.Result, error) {
+	plan := dryrun.MakePlan()
+	dryRec := *r
+	dryRec.Client = dryrun.MakeClient(r.Client, plan)
+	dryRec.EVRec = dryrun.MakeEventRecorder(plan)
+	dryRec.PFactsCache = nil
+	dryRec.dryRunPlan = plan
+	// The commands that only read state are run so that the actors see the
+	// real database.  Only the ones that would change something are recorded.
+	dprunner := cmds.MakeRecordingPodRunner(prunner, func(podName types.NamespacedName, contName, command string) {
+		plan.Record("Exec", fmt.Sprintf("pod %s", podName.Name), command)
+	})
+	// The pod facts are read from the pods, so they use the real pod runner.
+	pfacts := MakePodFacts(&dryRec, prunner)
+
+	actors := dryRec.constructActors(log, vdb, dprunner, &pfacts)
+	for _, act := range actors {
+		actorName := controllers.GetActorName(act)
+		if pausedActors[actorName] {
+			continue
+		}
+		plan.SetActor(actorName)
+		log.Info("starting actor in dry-run mode", "name", actorName)
+		res, err := act.Reconcile(ctx, req)
+		if err != nil {
+			plan.Record("Fail", "", err.Error())
+			break
+		}
+		if res.Requeue || res.RequeueAfter > 0 {
+			plan.Record("Requeue", "", "the reconcile would stop here and be retried")
+		}
+	}
+	return ctrl.Result{}, r.saveDryRunPlan(ctx, log, vdb, plan)
+}
+
+// saveDryRunPlan will write the plan to the dry-run ConfigMap.  An event is
+// written whenever the plan changes.
+func (r *VerticaDBReconciler) saveDryRunPlan(ctx context.Context, log logr.Logger, vdb *vapi.VerticaDB,
+	plan *dryrun.Plan) error {
+	contents := fmt.Sprintf("# Changes the operator would make for generation %d of VerticaDB %s\n%s",
+		vdb.Generation, vdb.Name, plan.String())
+	nm := names.GenDryRunConfigMapName(vdb)
+	cm := &corev1.ConfigMap{}
+	if err := r.Client.Get(ctx, nm, cm); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		isController := true
+		blockOwnerDeletion := false
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        nm.Name,
+				Namespace:   nm.Namespace,
+				Annotations: builder.MakeAnnotationsForObject(vdb),
+				Labels:      builder.MakeOperatorLabels(vdb),
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion:         vapi.GroupVersion.String(),
+						Kind:               vapi.VerticaDBKind,
+						Name:               vdb.Name,
+						UID:                vdb.GetUID(),
+						Controller:         &isController,
+						BlockOwnerDeletion: &blockOwnerDeletion,
+					},
+				},
+			},
+			Data: map[string]string{DryRunPlanKey: contents},
+		}
+		if err := r.Client.Create(ctx, cm); err != nil {
+			return err
+		}
+	} else {
+		if cm.Data[DryRunPlanKey] == contents {
+			return nil
+		}
+		cm.Data = map[string]string{DryRunPlanKey: contents}
+		if err := r.Client.Update(ctx, cm); err != nil {
+			return err
+		}
+	}
+	log.Info("saved the dry-run plan", "configMap", nm, "actions", len(plan.Actions()))
+	r.Eventf(vdb, corev1.EventTypeNormal, events.DryRunPlanUpdated,
+		"The changes the operator would make are in ConfigMap %s", nm.Name)
+	return nil
+}
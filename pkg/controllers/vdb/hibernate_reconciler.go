@@ -0,0 +1,201 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vdb
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/controllers"
+	"github.com/vertica/vertica-kubernetes/pkg/events"
+	"github.com/vertica/vertica-kubernetes/pkg/vdbstatus"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// HibernateReconciler will stop the database and scale all of its pods down
+// when spec.hibernate is set.  It handles bringing the pods back when it is
+// cleared.
+type HibernateReconciler struct {
+	VRec    *VerticaDBReconciler
+	Log     logr.Logger
+	Vdb     *vapi.VerticaDB // Vdb is the CRD we are acting on.
+	PRunner cmds.PodRunner
+	PFacts  *PodFacts
+}
+
+// MakeHibernateReconciler will build a HibernateReconciler object
+func MakeHibernateReconciler(vdbrecon *VerticaDBReconciler, log logr.Logger,
+	vdb *vapi.VerticaDB, prunner cmds.PodRunner, pfacts *PodFacts) controllers.ReconcileActor {
+	return &HibernateReconciler{
+		VRec:    vdbrecon,
+		Log:     log,
+		Vdb:     vdb,
+		PRunner: prunner,
+		PFacts:  pfacts,
+	}
+}
+
+// Reconcile will hibernate or resume the database according to spec.hibernate
+func (h *HibernateReconciler) Reconcile(ctx context.Context, req *ctrl.Request) (ctrl.Result, error) {
+	// The operator doesn't own the database in this mode
+	if h.Vdb.Spec.InitPolicy == vapi.CommunalInitPolicyScheduleOnly {
+		return ctrl.Result{}, nil
+	}
+
+	hibernated, err := h.Vdb.IsConditionSet(vapi.Hibernated)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if h.Vdb.Spec.Hibernate {
+		if hibernated {
+			// Make sure nothing scaled the pods back up while we were
+			// hibernated.
+			return ctrl.Result{}, h.scaleStatefulSets(ctx, true)
+		}
+		return h.hibernate(ctx)
+	}
+	if hibernated {
+		return h.resume(ctx)
+	}
+
+	resuming, err := h.Vdb.IsConditionSet(vapi.Resuming)
+	if err != nil || !resuming {
+		return ctrl.Result{}, err
+	}
+	return h.checkResumeProgress(ctx)
+}
+
+// hibernate will stop the database, then scale every statefulset to zero
+func (h *HibernateReconciler) hibernate(ctx context.Context) (ctrl.Result, error) {
+	if err := h.PFacts.Collect(ctx, h.Vdb); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	h.VRec.Event(h.Vdb, corev1.EventTypeNormal, events.HibernateStart,
+		"Starting hibernation of the database")
+	// We stop vertica ourselves rather than letting the pods get killed, so
+	// that all of the data is persisted to communal storage.
+	if h.PFacts.getUpNodeCount() > 0 {
+		stopDB := MakeStopDBReconciler(h.VRec, h.Vdb, h.PRunner, h.PFacts).(*StopDBReconciler)
+		if err := stopDB.stopVertica(ctx); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := h.scaleStatefulSets(ctx, true); err != nil {
+		return ctrl.Result{}, err
+	}
+	h.PFacts.Invalidate()
+
+	conds := []vapi.VerticaDBCondition{
+		{Type: vapi.Resuming, Status: corev1.ConditionFalse},
+		{Type: vapi.Hibernated, Status: corev1.ConditionTrue},
+	}
+	for i := range conds {
+		if err := vdbstatus.UpdateCondition(ctx, h.VRec.Client, h.Vdb, conds[i]); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	h.VRec.Event(h.Vdb, corev1.EventTypeNormal, events.HibernateSucceeded,
+		"Successfully hibernated the database")
+	// Requeue so that the next iteration only runs the actors that apply to
+	// a hibernated database.
+	return ctrl.Result{Requeue: true}, nil
+}
+
+// resume will bring back the pods of a hibernated database.  The remaining
+// actors will restart the database once the pods are running.
+func (h *HibernateReconciler) resume(ctx context.Context) (ctrl.Result, error) {
+	h.VRec.Event(h.Vdb, corev1.EventTypeNormal, events.ResumeStart,
+		"Resuming the database from hibernation")
+	if err := h.scaleStatefulSets(ctx, false); err != nil {
+		return ctrl.Result{}, err
+	}
+	h.PFacts.Invalidate()
+
+	conds := []vapi.VerticaDBCondition{
+		{Type: vapi.Resuming, Status: corev1.ConditionTrue},
+		{Type: vapi.Hibernated, Status: corev1.ConditionFalse},
+	}
+	for i := range conds {
+		if err := vdbstatus.UpdateCondition(ctx, h.VRec.Client, h.Vdb, conds[i]); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// checkResumeProgress will clear the Resuming condition once every pod has
+// an up vertica node.
+func (h *HibernateReconciler) checkResumeProgress(ctx context.Context) (ctrl.Result, error) {
+	if err := h.PFacts.Collect(ctx, h.Vdb); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	totalPods := 0
+	for i := range h.Vdb.Spec.Subclusters {
+		totalPods += int(h.Vdb.Spec.Subclusters[i].Size)
+	}
+	if h.PFacts.getUpNodeCount() < totalPods {
+		// An Eon database is revived from communal storage if all of the local
+		// data was lost.  This cannot be done for an Enterprise database, so
+		// we let the user know the database cannot come back on its own.
+		if !h.Vdb.IsEON() && !h.PFacts.doesDBExist() &&
+			h.PFacts.countRunningAndInstalled() == totalPods && totalPods > 0 {
+			h.VRec.Event(h.Vdb, corev1.EventTypeWarning, events.ResumeLocalDataLost,
+				"Cannot resume the database because the local data of all of the pods is gone")
+		}
+		return ctrl.Result{}, nil
+	}
+
+	h.VRec.Event(h.Vdb, corev1.EventTypeNormal, events.ResumeSucceeded,
+		"Successfully resumed the database from hibernation")
+	return ctrl.Result{}, vdbstatus.UpdateCondition(ctx, h.VRec.Client, h.Vdb,
+		vapi.VerticaDBCondition{Type: vapi.Resuming, Status: corev1.ConditionFalse})
+}
+
+// scaleStatefulSets will set the replicas of the statefulset for each
+// subcluster.  When scaling down, every statefulset goes to zero.  Otherwise,
+// they go back to the size of the subcluster in the spec.
+func (h *HibernateReconciler) scaleStatefulSets(ctx context.Context, toZero bool) error {
+	for i := range h.Vdb.Spec.Subclusters {
+		sc := &h.Vdb.Spec.Subclusters[i]
+		replicas := sc.Size
+		if toZero || sc.Shutdown {
+			replicas = 0
+		}
+		// A missing statefulset is created by ObjReconciler when we resume
+		scaled, err := scaleSts(ctx, h.VRec, h.Vdb, sc, replicas)
+		if err != nil {
+			return err
+		}
+		if scaled {
+			h.Log.Info("Scaled statefulset for hibernation", "subcluster", sc.Name, "replicas", replicas)
+		}
+	}
+	return nil
+}
+
+// isResumingInitializedDB returns true if the database was initialized before
+// it was hibernated and it is now being resumed.
+func isResumingInitializedDB(vdb *vapi.VerticaDB) bool {
+	resuming, _ := vdb.IsConditionSet(vapi.Resuming)
+	initialized, _ := vdb.IsConditionSet(vapi.DBInitialized)
+	return resuming && initialized
+}
@@ -0,0 +1,75 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vdb
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	"github.com/vertica/vertica-kubernetes/pkg/test"
+	appsv1 "k8s.io/api/apps/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("hibernate_reconcile", func() {
+	ctx := context.Background()
+
+	It("should be a no-op if hibernate isn't set", func() {
+		vdb := vapi.MakeVDB()
+		test.CreateVDB(ctx, k8sClient, vdb)
+		defer test.DeleteVDB(ctx, k8sClient, vdb)
+
+		fpr := &cmds.FakePodRunner{}
+		pfacts := MakePodFacts(vdbRec, fpr)
+		recon := MakeHibernateReconciler(vdbRec, logger, vdb, fpr, &pfacts)
+		Expect(recon.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+		Expect(len(fpr.Histories)).Should(Equal(0))
+	})
+
+	It("should stop the database and scale to zero, then scale back on resume", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.Hibernate = true
+		test.CreateVDB(ctx, k8sClient, vdb)
+		defer test.DeleteVDB(ctx, k8sClient, vdb)
+		test.CreatePods(ctx, k8sClient, vdb, test.AllPodsRunning)
+		defer test.DeletePods(ctx, k8sClient, vdb)
+
+		fpr := &cmds.FakePodRunner{}
+		pfacts := createPodFactsDefault(fpr)
+		recon := MakeHibernateReconciler(vdbRec, logger, vdb, fpr, pfacts)
+		Expect(recon.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{Requeue: true}))
+		Expect(fpr.FindCommands("stop_db")).Should(HaveLen(1))
+		Expect(vdb.IsHibernated()).Should(BeTrue())
+		// The size in the spec is kept
+		Expect(vdb.Spec.Subclusters[0].Size).Should(Equal(int32(3)))
+
+		sts := &appsv1.StatefulSet{}
+		stsName := names.GenStsName(vdb, &vdb.Spec.Subclusters[0])
+		Expect(k8sClient.Get(ctx, stsName, sts)).Should(Succeed())
+		Expect(*sts.Spec.Replicas).Should(Equal(int32(0)))
+
+		vdb.Spec.Hibernate = false
+		Expect(recon.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+		Expect(vdb.IsConditionSet(vapi.Hibernated)).Should(BeFalse())
+		Expect(vdb.IsConditionSet(vapi.Resuming)).Should(BeTrue())
+		Expect(k8sClient.Get(ctx, stsName, sts)).Should(Succeed())
+		Expect(*sts.Spec.Replicas).Should(Equal(vdb.Spec.Subclusters[0].Size))
+	})
+})
@@ -22,6 +22,8 @@ import (
 	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
 	"github.com/vertica/vertica-kubernetes/pkg/builder"
 	"github.com/vertica/vertica-kubernetes/pkg/controllers"
+	"github.com/vertica/vertica-kubernetes/pkg/events"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
 	"github.com/vertica/vertica-kubernetes/pkg/paths"
 	"github.com/vertica/vertica-kubernetes/pkg/security"
 	corev1 "k8s.io/api/core/v1"
@@ -46,18 +48,28 @@ func MakeHTTPServerCertGenReconciler(vdbrecon *VerticaDBReconciler, vdb *vapi.Ve
 
 // Reconcile will create a TLS secret for the http server if one is missing
 func (h *HTTPServerCertGenReconciler) Reconcile(ctx context.Context, req *ctrl.Request) (ctrl.Result, error) {
-	const PKKeySize = 2048
 	// Early out if http server is explicitly disabled or we already have a TLS secret.
 	// For auto, we continue even if the version may not support it. Assuming
 	// its needed will save a few reconcile iteration during bootstrap.
 	if h.Vdb.IsHTTPServerDisabled() || h.Vdb.Spec.HTTPServerTLSSecret != "" {
 		return ctrl.Result{}, nil
 	}
-	caCert, err := security.NewSelfSignedCACertificate(PKKeySize)
-	if err != nil {
-		return ctrl.Result{}, err
+	if h.Vdb.Spec.HTTPServerCertIssuer != nil {
+		secretName, err := h.requestCertFromCertManager(ctx)
+		switch {
+		case security.IsCertManagerNotInstalled(err):
+			h.VRec.Event(h.Vdb, corev1.EventTypeWarning, events.CertManagerNotInstalled,
+				"cert-manager is not installed. Generating a self-signed certificate for the http server instead.")
+		case err != nil:
+			return ctrl.Result{}, err
+		case secretName == "":
+			h.VRec.Log.Info("Waiting for cert-manager to issue the http server certificate")
+			return ctrl.Result{Requeue: true}, nil
+		default:
+			return ctrl.Result{}, h.setSecretNameInVDB(ctx, secretName)
+		}
 	}
-	cert, err := security.NewCertificate(caCert, PKKeySize, "dbadmin", h.getDNSNames())
+	cert, caCert, err := genHTTPServerCerts(h.Vdb)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
@@ -68,40 +80,88 @@ func (h *HTTPServerCertGenReconciler) Reconcile(ctx context.Context, req *ctrl.R
 	return ctrl.Result{}, h.setSecretNameInVDB(ctx, secret.ObjectMeta.Name)
 }
 
-// getDNSNames returns the DNS names to include in the certificate that we generate
-func (h *HTTPServerCertGenReconciler) getDNSNames() []string {
+// genHTTPServerCerts will generate a self-signed CA and a certificate, signed
+// by that CA, for the http server.
+func genHTTPServerCerts(vdb *vapi.VerticaDB) (cert, caCert security.Certificate, err error) {
+	const PKKeySize = 2048
+	caCert, err = security.NewSelfSignedCACertificate(PKKeySize)
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err = security.NewCertificate(caCert, PKKeySize, "dbadmin", getHTTPServerDNSNames(vdb))
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, caCert, nil
+}
+
+// getHTTPServerDNSNames returns the DNS names to include in the certificate that we generate
+func getHTTPServerDNSNames(vdb *vapi.VerticaDB) []string {
 	return []string{
-		fmt.Sprintf("*.%s.svc", h.Vdb.Namespace),
-		fmt.Sprintf("*.%s.svc.cluster.local", h.Vdb.Namespace),
+		fmt.Sprintf("*.%s.svc", vdb.Namespace),
+		fmt.Sprintf("*.%s.svc.cluster.local", vdb.Namespace),
 	}
 }
 
-func (h *HTTPServerCertGenReconciler) createSecret(ctx context.Context, cert, caCert security.Certificate) (*corev1.Secret, error) {
+// genHTTPServerSecretData returns the data to store in the http server TLS secret
+func genHTTPServerSecretData(cert, caCert security.Certificate) map[string][]byte {
+	return map[string][]byte{
+		corev1.TLSPrivateKeyKey:   cert.TLSKey(),
+		corev1.TLSCertKey:         cert.TLSCrt(),
+		paths.HTTPServerCACrtName: caCert.TLSCrt(),
+	}
+}
+
+// requestCertFromCertManager will create a cert-manager Certificate for the
+// http server and return the name of the secret once it has been issued. The
+// secret name is empty if cert-manager hasn't issued the certificate yet.
+func (h *HTTPServerCertGenReconciler) requestCertFromCertManager(ctx context.Context) (string, error) {
+	nm := names.GenHTTPServerCertName(h.Vdb)
+	secret, err := security.GetCertManagerSecret(ctx, h.VRec.Client, nm)
+	if err != nil || secret != nil {
+		return nm.Name, err
+	}
+
+	issuer := h.Vdb.Spec.HTTPServerCertIssuer
+	dnsNames := getHTTPServerDNSNames(h.Vdb)
+	cert := security.MakeCertManagerCertificate(nm, nm.Name, dnsNames[0], dnsNames,
+		security.CertManagerIssuer{Name: issuer.Name, Kind: issuer.Kind, Group: issuer.Group})
+	cert.SetLabels(builder.MakeOperatorLabels(h.Vdb))
+	cert.SetOwnerReferences([]metav1.OwnerReference{h.makeOwnerReference()})
+	if err := security.CreateCertManagerCertificate(ctx, h.VRec.Client, cert); err != nil {
+		return "", err
+	}
+	h.VRec.Eventf(h.Vdb, corev1.EventTypeNormal, events.CertManagerCertRequested,
+		"Requested the http server certificate from the cert-manager issuer '%s'", issuer.Name)
+	return "", nil
+}
+
+// makeOwnerReference returns an OwnerReference for the objects we create for
+// the http server certificate
+func (h *HTTPServerCertGenReconciler) makeOwnerReference() metav1.OwnerReference {
 	isController := true
 	blockOwnerDeletion := false
+	return metav1.OwnerReference{
+		APIVersion:         vapi.GroupVersion.String(),
+		Kind:               vapi.VerticaDBKind,
+		Name:               h.Vdb.Name,
+		UID:                h.Vdb.GetUID(),
+		Controller:         &isController,
+		BlockOwnerDeletion: &blockOwnerDeletion,
+	}
+}
+
+func (h *HTTPServerCertGenReconciler) createSecret(ctx context.Context, cert, caCert security.Certificate) (*corev1.Secret, error) {
 	secret := corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			GenerateName: fmt.Sprintf("%s-http-server-tls-", h.Vdb.Name),
-			Namespace:    h.Vdb.Namespace,
-			Annotations:  builder.MakeAnnotationsForObject(h.Vdb),
-			Labels:       builder.MakeOperatorLabels(h.Vdb),
-			OwnerReferences: []metav1.OwnerReference{
-				{
-					APIVersion:         vapi.GroupVersion.String(),
-					Kind:               vapi.VerticaDBKind,
-					Name:               h.Vdb.Name,
-					UID:                h.Vdb.GetUID(),
-					Controller:         &isController,
-					BlockOwnerDeletion: &blockOwnerDeletion,
-				},
-			},
+			GenerateName:    fmt.Sprintf("%s-http-server-tls-", h.Vdb.Name),
+			Namespace:       h.Vdb.Namespace,
+			Annotations:     builder.MakeAnnotationsForObject(h.Vdb),
+			Labels:          builder.MakeOperatorLabels(h.Vdb),
+			OwnerReferences: []metav1.OwnerReference{h.makeOwnerReference()},
 		},
 		Type: corev1.SecretTypeTLS,
-		Data: map[string][]byte{
-			corev1.TLSPrivateKeyKey:   cert.TLSKey(),
-			corev1.TLSCertKey:         cert.TLSCrt(),
-			paths.HTTPServerCACrtName: caCert.TLSCrt(),
-		},
+		Data: genHTTPServerSecretData(cert, caCert),
 	}
 	err := h.VRec.Client.Create(ctx, &secret)
 	return &secret, err
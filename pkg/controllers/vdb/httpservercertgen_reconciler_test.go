@@ -21,9 +21,11 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
 	"github.com/vertica/vertica-kubernetes/pkg/paths"
 	"github.com/vertica/vertica-kubernetes/pkg/test"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
@@ -73,4 +75,48 @@ var _ = Describe("httpservercertgen_reconcile", func() {
 		Expect(len(secret.Data[corev1.TLSCertKey])).ShouldNot(Equal(0))
 		Expect(len(secret.Data[paths.HTTPServerCACrtName])).ShouldNot(Equal(0))
 	})
+
+	It("should create a self-signed secret if the cert-manager issuer is set but cert-manager isn't installed", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.HTTPServerMode = vapi.HTTPServerModeEnabled
+		vdb.Spec.HTTPServerTLSSecret = ""
+		vdb.Spec.HTTPServerCertIssuer = &vapi.CertIssuerReference{Name: "my-issuer", Kind: vapi.CertIssuerKindIssuer}
+		test.CreateVDB(ctx, k8sClient, vdb)
+		defer test.DeleteVDB(ctx, k8sClient, vdb)
+
+		r := MakeHTTPServerCertGenReconciler(vdbRec, vdb)
+		Expect(r.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+		Expect(vdb.Spec.HTTPServerTLSSecret).ShouldNot(Equal(""))
+		Expect(vdb.Spec.HTTPServerTLSSecret).ShouldNot(Equal(names.GenHTTPServerCertName(vdb).Name))
+		nm := types.NamespacedName{Namespace: vdb.Namespace, Name: vdb.Spec.HTTPServerTLSSecret}
+		secret := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, nm, secret)).Should(Succeed())
+		Expect(len(secret.Data[corev1.TLSCertKey])).ShouldNot(Equal(0))
+	})
+
+	It("should use the cert-manager secret once it has been issued", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.HTTPServerMode = vapi.HTTPServerModeEnabled
+		vdb.Spec.HTTPServerTLSSecret = ""
+		vdb.Spec.HTTPServerCertIssuer = &vapi.CertIssuerReference{Name: "my-issuer", Kind: vapi.CertIssuerKindIssuer}
+		test.CreateVDB(ctx, k8sClient, vdb)
+		defer test.DeleteVDB(ctx, k8sClient, vdb)
+
+		// Mimic cert-manager having issued the cert
+		nm := names.GenHTTPServerCertName(vdb)
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: nm.Name, Namespace: nm.Namespace},
+			Data: map[string][]byte{
+				corev1.TLSPrivateKeyKey:   []byte("key"),
+				corev1.TLSCertKey:         []byte("crt"),
+				paths.HTTPServerCACrtName: []byte("ca"),
+			},
+		}
+		Expect(k8sClient.Create(ctx, secret)).Should(Succeed())
+		defer deleteSecret(ctx, vdb, nm.Name)
+
+		r := MakeHTTPServerCertGenReconciler(vdbRec, vdb)
+		Expect(r.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+		Expect(vdb.Spec.HTTPServerTLSSecret).Should(Equal(nm.Name))
+	})
 })
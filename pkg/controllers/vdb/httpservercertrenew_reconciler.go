@@ -0,0 +1,247 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vdb
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/controllers"
+	"github.com/vertica/vertica-kubernetes/pkg/events"
+	"github.com/vertica/vertica-kubernetes/pkg/httpconf"
+	"github.com/vertica/vertica-kubernetes/pkg/metrics"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	"github.com/vertica/vertica-kubernetes/pkg/paths"
+	"github.com/vertica/vertica-kubernetes/pkg/security"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// HTTPServerCertRenewReconciler will renew the http server certificate that
+// the operator generated before it expires.  The new certificate is pushed to
+// all of the pods and the http server is restarted.  The database itself keeps
+// running.
+type HTTPServerCertRenewReconciler struct {
+	VRec    *VerticaDBReconciler
+	Log     logr.Logger
+	Vdb     *vapi.VerticaDB // Vdb is the CRD we are acting on.
+	PRunner cmds.PodRunner
+	PFacts  *PodFacts
+}
+
+// MakeHTTPServerCertRenewReconciler will build a HTTPServerCertRenewReconciler object
+func MakeHTTPServerCertRenewReconciler(vdbrecon *VerticaDBReconciler, log logr.Logger,
+	vdb *vapi.VerticaDB, prunner cmds.PodRunner, pfacts *PodFacts) controllers.ReconcileActor {
+	return &HTTPServerCertRenewReconciler{
+		VRec:    vdbrecon,
+		Log:     log,
+		Vdb:     vdb,
+		PRunner: prunner,
+		PFacts:  pfacts,
+	}
+}
+
+// Reconcile will check the expiry of the http server certificate and renew it
+// if it is within the renewal window.  We don't requeue to check the expiry
+// later.  We rely on the periodic resync of the manager for that, which is
+// much more frequent than the renewal window.
+func (h *HTTPServerCertRenewReconciler) Reconcile(ctx context.Context, req *ctrl.Request) (ctrl.Result, error) {
+	if h.Vdb.IsHTTPServerDisabled() || h.Vdb.Spec.HTTPServerTLSSecret == "" {
+		return ctrl.Result{}, nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := h.VRec.Client.Get(ctx, names.GenNamespacedName(h.Vdb, h.Vdb.Spec.HTTPServerTLSSecret), secret); err != nil {
+		// A missing secret is reported by the ObjReconciler
+		if k8sErrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	notAfter, err := security.MakeCertificate(secret.Data[corev1.TLSPrivateKeyKey], secret.Data[corev1.TLSCertKey]).NotAfter()
+	if err != nil {
+		h.Log.Info("Could not parse the http server certificate", "secret", secret.Name, "err", err)
+		return ctrl.Result{}, nil
+	}
+	metrics.HTTPServerCertExpiry.With(metrics.MakeVDBLabels(h.Vdb)).Set(float64(notAfter.Unix()))
+
+	// cert-manager renews the certificate it issued in place. We only need to
+	// push it to the pods.
+	if h.isCertManagerSecret(secret) {
+		return ctrl.Result{}, h.reconcileCertManagerSecret(ctx, secret)
+	}
+	// We only renew the certificate that we generated. A user provided
+	// certificate must be renewed by the user.
+	if !h.isGeneratedSecret(secret) {
+		return ctrl.Result{}, nil
+	}
+	if secret.Annotations[vapi.HTTPServerCertRolloutPendingAnnotation] == "" {
+		if time.Until(notAfter) > h.Vdb.GetHTTPServerCertRenewalWindow() {
+			return ctrl.Result{}, nil
+		}
+		if err := h.renewCert(ctx, secret, notAfter); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, h.rolloutCert(ctx, secret)
+}
+
+// isGeneratedSecret returns true if the secret was generated by the operator
+// for this vdb.
+func (h *HTTPServerCertRenewReconciler) isGeneratedSecret(secret *corev1.Secret) bool {
+	for i := range secret.OwnerReferences {
+		if secret.OwnerReferences[i].UID == h.Vdb.UID {
+			return true
+		}
+	}
+	return false
+}
+
+// isCertManagerSecret returns true if the secret was issued by cert-manager
+// for the Certificate that we requested for this vdb.
+func (h *HTTPServerCertRenewReconciler) isCertManagerSecret(secret *corev1.Secret) bool {
+	return h.Vdb.Spec.HTTPServerCertIssuer != nil && secret.Name == names.GenHTTPServerCertName(h.Vdb).Name
+}
+
+// reconcileCertManagerSecret will roll out the certificate issued by
+// cert-manager if it differs from the one the pods are using.
+func (h *HTTPServerCertRenewReconciler) reconcileCertManagerSecret(ctx context.Context, secret *corev1.Secret) error {
+	if secret.Annotations[vapi.HTTPServerCertRolloutPendingAnnotation] == "" {
+		rolledOut, ok := secret.Annotations[vapi.HTTPServerCertRolledOutAnnotation]
+		fingerprint := genCertFingerprint(secret)
+		if ok && rolledOut == fingerprint {
+			return nil
+		}
+		if secret.Annotations == nil {
+			secret.Annotations = map[string]string{}
+		}
+		// If we have never recorded the certificate, the pods were set up with
+		// the one that is currently in the secret.
+		if !ok {
+			secret.Annotations[vapi.HTTPServerCertRolledOutAnnotation] = fingerprint
+			return h.VRec.Client.Update(ctx, secret)
+		}
+		secret.Annotations[vapi.HTTPServerCertRolloutPendingAnnotation] = "true"
+		if err := h.VRec.Client.Update(ctx, secret); err != nil {
+			return err
+		}
+		h.VRec.Eventf(h.Vdb, corev1.EventTypeNormal, events.CertManagerCertReissued,
+			"cert-manager reissued the http server certificate in secret '%s'", secret.Name)
+	}
+	return h.rolloutCert(ctx, secret)
+}
+
+// genCertFingerprint returns the SHA-256 fingerprint of the certificate in the
+// secret.  The certificate is public, so it is safe to store this.
+func genCertFingerprint(secret *corev1.Secret) string {
+	sum := sha256.Sum256(secret.Data[corev1.TLSCertKey])
+	return hex.EncodeToString(sum[:])
+}
+
+// renewCert will generate a new certificate and store it in the existing
+// secret. The secret is marked so that we know to push the new certificate to
+// the pods.
+func (h *HTTPServerCertRenewReconciler) renewCert(ctx context.Context, secret *corev1.Secret, oldNotAfter time.Time) error {
+	cert, caCert, err := genHTTPServerCerts(h.Vdb)
+	if err != nil {
+		return err
+	}
+	secret.Data = genHTTPServerSecretData(cert, caCert)
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[vapi.HTTPServerCertRolloutPendingAnnotation] = "true"
+	if err := h.VRec.Client.Update(ctx, secret); err != nil {
+		return err
+	}
+
+	notAfter, err := cert.NotAfter()
+	if err != nil {
+		return err
+	}
+	metrics.HTTPServerCertExpiry.With(metrics.MakeVDBLabels(h.Vdb)).Set(float64(notAfter.Unix()))
+	h.VRec.Eventf(h.Vdb, corev1.EventTypeNormal, events.HTTPServerCertRenewed,
+		"Renewed the http server certificate in secret '%s' that was set to expire on %s",
+		secret.Name, oldNotAfter.Format(time.RFC3339))
+	return nil
+}
+
+// rolloutCert will copy the new certificate to each pod, then restart the
+// http server.  We wait until every pod is running so that none of them are
+// left with the old certificate.
+func (h *HTTPServerCertRenewReconciler) rolloutCert(ctx context.Context, secret *corev1.Secret) error {
+	if err := h.PFacts.Collect(ctx, h.Vdb); err != nil {
+		return err
+	}
+	pods := h.PFacts.filterPods(func(v *PodFact) bool {
+		return !v.shutdown
+	})
+	for _, pf := range pods {
+		if !pf.isPodRunning || !pf.isInstalled {
+			h.Log.Info("Waiting for all pods to be running before rolling out the new http server certificate",
+				"pod", pf.name)
+			return nil
+		}
+	}
+
+	for _, pf := range pods {
+		if err := h.copyHTTPSTLSConf(ctx, pf); err != nil {
+			return err
+		}
+	}
+	if pf, ok := h.PFacts.findPodToRunVsql(true, ""); ok {
+		cmd := []string{"-tAc", "select http_server_ctrl('restart', '')"}
+		if _, _, err := h.PRunner.ExecVSQL(ctx, pf.name, names.ServerContainer, cmd...); err != nil {
+			h.VRec.Event(h.Vdb, corev1.EventTypeWarning, events.HTTPServerRestartFailed,
+				"Failed to restart the http server with the new certificate")
+			return err
+		}
+	}
+
+	delete(secret.Annotations, vapi.HTTPServerCertRolloutPendingAnnotation)
+	secret.Annotations[vapi.HTTPServerCertRolledOutAnnotation] = genCertFingerprint(secret)
+	if err := h.VRec.Client.Update(ctx, secret); err != nil {
+		return err
+	}
+	h.VRec.Event(h.Vdb, corev1.EventTypeNormal, events.HTTPServerCertRolledOut,
+		"The renewed http server certificate is in use by all of the pods")
+	return nil
+}
+
+// copyHTTPSTLSConf will regenerate the httpstls.json file and copy it to the pod
+func (h *HTTPServerCertRenewReconciler) copyHTTPSTLSConf(ctx context.Context, pf *PodFact) error {
+	frwt := httpconf.FileWriter{}
+	fname, err := frwt.GenConf(ctx, h.VRec.Client, names.GenNamespacedName(h.Vdb, h.Vdb.Spec.HTTPServerTLSSecret))
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("failed generating the %s file", paths.HTTPTLSConfFileName))
+	}
+	_, _, err = h.PRunner.CopyToPod(ctx, pf.name, names.ServerContainer, fname, paths.HTTPTLSConfFile)
+	_ = os.Remove(fname)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("failed to copy %s to the pod %s", fname, pf.name))
+	}
+	return nil
+}
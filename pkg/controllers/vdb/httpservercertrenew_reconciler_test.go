@@ -0,0 +1,148 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vdb
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	"github.com/vertica/vertica-kubernetes/pkg/paths"
+	"github.com/vertica/vertica-kubernetes/pkg/test"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("httpservercertrenew_reconcile", func() {
+	ctx := context.Background()
+
+	// setupVDB will create a vdb along with a generated http server secret
+	setupVDB := func(renewalDays int) *vapi.VerticaDB {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.HTTPServerMode = vapi.HTTPServerModeEnabled
+		vdb.Spec.HTTPServerTLSSecret = ""
+		vdb.Spec.HTTPServerCertRenewalDays = renewalDays
+		test.CreateVDB(ctx, k8sClient, vdb)
+		r := MakeHTTPServerCertGenReconciler(vdbRec, vdb)
+		Expect(r.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+		Expect(vdb.Spec.HTTPServerTLSSecret).ShouldNot(Equal(""))
+		test.CreatePods(ctx, k8sClient, vdb, test.AllPodsRunning)
+		return vdb
+	}
+
+	cleanupVDB := func(vdb *vapi.VerticaDB) {
+		test.DeletePods(ctx, k8sClient, vdb)
+		deleteSecret(ctx, vdb, vdb.Spec.HTTPServerTLSSecret)
+		test.DeleteVDB(ctx, k8sClient, vdb)
+	}
+
+	getSecret := func(vdb *vapi.VerticaDB) *corev1.Secret {
+		secret := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, names.GenNamespacedName(vdb, vdb.Spec.HTTPServerTLSSecret), secret)).Should(Succeed())
+		return secret
+	}
+
+	It("should not renew the certificate if it isn't close to expiring", func() {
+		vdb := setupVDB(vapi.DefaultHTTPServerCertRenewalDays)
+		defer cleanupVDB(vdb)
+		oldCrt := getSecret(vdb).Data[corev1.TLSCertKey]
+
+		fpr := &cmds.FakePodRunner{}
+		r := MakeHTTPServerCertRenewReconciler(vdbRec, logger, vdb, fpr, createPodFactsDefault(fpr))
+		Expect(r.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+		Expect(fpr.Histories).Should(BeEmpty())
+		Expect(getSecret(vdb).Data[corev1.TLSCertKey]).Should(Equal(oldCrt))
+	})
+
+	It("should renew the certificate and push it to the pods when inside the renewal window", func() {
+		// The generated certificate is valid for 10 years
+		const renewalDays = 10*365 + 1
+		vdb := setupVDB(renewalDays)
+		defer cleanupVDB(vdb)
+		oldCrt := getSecret(vdb).Data[corev1.TLSCertKey]
+
+		fpr := &cmds.FakePodRunner{}
+		r := MakeHTTPServerCertRenewReconciler(vdbRec, logger, vdb, fpr, createPodFactsDefault(fpr))
+		Expect(r.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+
+		secret := getSecret(vdb)
+		Expect(secret.Data[corev1.TLSCertKey]).ShouldNot(Equal(oldCrt))
+		Expect(secret.Annotations).ShouldNot(HaveKey(vapi.HTTPServerCertRolloutPendingAnnotation))
+		Expect(fpr.FindCommands(paths.HTTPTLSConfFile)).Should(HaveLen(int(vdb.Spec.Subclusters[0].Size)))
+		Expect(fpr.FindCommands("select http_server_ctrl('restart', '')")).Should(HaveLen(1))
+	})
+
+	It("should not renew a certificate that the user provided", func() {
+		vdb := setupVDB(10*365 + 1)
+		defer cleanupVDB(vdb)
+		secret := getSecret(vdb)
+		secret.OwnerReferences = nil
+		Expect(k8sClient.Update(ctx, secret)).Should(Succeed())
+
+		fpr := &cmds.FakePodRunner{}
+		r := MakeHTTPServerCertRenewReconciler(vdbRec, logger, vdb, fpr, createPodFactsDefault(fpr))
+		Expect(r.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+		Expect(fpr.Histories).Should(BeEmpty())
+		Expect(getSecret(vdb).Data[corev1.TLSCertKey]).Should(Equal(secret.Data[corev1.TLSCertKey]))
+	})
+
+	It("should push a certificate reissued by cert-manager to the pods", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.HTTPServerMode = vapi.HTTPServerModeEnabled
+		vdb.Spec.HTTPServerCertIssuer = &vapi.CertIssuerReference{Name: "my-issuer"}
+		vdb.Spec.HTTPServerTLSSecret = names.GenHTTPServerCertName(vdb).Name
+		test.CreateVDB(ctx, k8sClient, vdb)
+		test.CreatePods(ctx, k8sClient, vdb, test.AllPodsRunning)
+		defer cleanupVDB(vdb)
+
+		// Mimic the secret that cert-manager creates
+		cert, caCert, err := genHTTPServerCerts(vdb)
+		Expect(err).Should(Succeed())
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: vdb.Spec.HTTPServerTLSSecret, Namespace: vdb.Namespace},
+			Type:       corev1.SecretTypeTLS,
+			Data:       genHTTPServerSecretData(cert, caCert),
+		}
+		Expect(k8sClient.Create(ctx, secret)).Should(Succeed())
+
+		// The first time we only record the certificate the pods are using
+		fpr := &cmds.FakePodRunner{}
+		r := MakeHTTPServerCertRenewReconciler(vdbRec, logger, vdb, fpr, createPodFactsDefault(fpr))
+		Expect(r.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+		Expect(fpr.Histories).Should(BeEmpty())
+		Expect(getSecret(vdb).Annotations).Should(HaveKeyWithValue(vapi.HTTPServerCertRolledOutAnnotation,
+			genCertFingerprint(secret)))
+
+		// cert-manager renews the certificate in place
+		cert, caCert, err = genHTTPServerCerts(vdb)
+		Expect(err).Should(Succeed())
+		secret = getSecret(vdb)
+		secret.Data = genHTTPServerSecretData(cert, caCert)
+		Expect(k8sClient.Update(ctx, secret)).Should(Succeed())
+
+		Expect(r.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+		Expect(fpr.FindCommands(paths.HTTPTLSConfFile)).Should(HaveLen(int(vdb.Spec.Subclusters[0].Size)))
+		Expect(fpr.FindCommands("select http_server_ctrl('restart', '')")).Should(HaveLen(1))
+		secret = getSecret(vdb)
+		Expect(secret.Annotations).ShouldNot(HaveKey(vapi.HTTPServerCertRolloutPendingAnnotation))
+		Expect(secret.Annotations).Should(HaveKeyWithValue(vapi.HTTPServerCertRolledOutAnnotation,
+			genCertFingerprint(secret)))
+	})
+})
@@ -18,7 +18,6 @@ package vdb
 import (
 	"context"
 	"fmt"
-	"regexp"
 	"strings"
 
 	"github.com/go-logr/logr"
@@ -50,8 +49,7 @@ const (
 type DatabaseInitializer interface {
 	getPodList() ([]*PodFact, bool)
 	findPodToRunInit() (*PodFact, bool)
-	genCmd(ctx context.Context, hostList []string) ([]string, error)
-	execCmd(ctx context.Context, atPod types.NamespacedName, cmd []string) (ctrl.Result, error)
+	execCmd(ctx context.Context, atPod types.NamespacedName, hostList []string) (ctrl.Result, error)
 	preCmdSetup(ctx context.Context, atPod types.NamespacedName, podList []*PodFact) (ctrl.Result, error)
 	postCmdCleanup(ctx context.Context) (ctrl.Result, error)
 }
@@ -118,11 +116,7 @@ func (g *GenericDatabaseInitializer) runInit(ctx context.Context) (ctrl.Result,
 		debugDumpAdmintoolsConf(ctx, g.PRunner, atPod)
 	}
 
-	cmd, err := g.initializer.genCmd(ctx, getHostList(podList))
-	if err != nil {
-		return ctrl.Result{}, err
-	}
-	if res, err := g.initializer.execCmd(ctx, atPod, cmd); verrors.IsReconcileAborted(res, err) {
+	if res, err := g.initializer.execCmd(ctx, atPod, getHostList(podList)); verrors.IsReconcileAborted(res, err) {
 		return res, err
 	}
 
@@ -341,6 +335,16 @@ func (g *GenericDatabaseInitializer) getAzureAuthParmsContent(ctx context.Contex
 		return "", res, err
 	}
 
+	content := fmt.Sprintf(`
+	  AzureStorageCredentials = %s
+	  AzureStorageEndpointConfig = %s
+	  %s
+	`, genAzureCredsJSON(&azureCreds), genAzureConfigJSON(&azureConfig), g.getCAFile())
+	return dedent.Dedent(content), ctrl.Result{}, nil
+}
+
+// genAzureCredsJSON returns the value for the AzureStorageCredentials parameter
+func genAzureCredsJSON(azureCreds *cloud.AzureCredential) string {
 	var azureCredsJSON strings.Builder
 	elemPrefix := ""
 	azureCredsJSON.WriteString("[{")
@@ -360,9 +364,13 @@ func (g *GenericDatabaseInitializer) getAzureAuthParmsContent(ctx context.Contex
 		azureCredsJSON.WriteString(fmt.Sprintf(`%s"sharedAccessSignature": %q`, elemPrefix, azureCreds.SharedAccessSignature))
 	}
 	azureCredsJSON.WriteString("}]")
+	return azureCredsJSON.String()
+}
 
+// genAzureConfigJSON returns the value for the AzureStorageEndpointConfig parameter
+func genAzureConfigJSON(azureConfig *cloud.AzureEndpointConfig) string {
 	var azureConfigJSON strings.Builder
-	elemPrefix = ""
+	elemPrefix := ""
 	azureConfigJSON.WriteString("[{")
 	if azureConfig.AccountName != "" {
 		azureConfigJSON.WriteString(fmt.Sprintf(`"accountName": %q`, azureConfig.AccountName))
@@ -376,13 +384,7 @@ func (g *GenericDatabaseInitializer) getAzureAuthParmsContent(ctx context.Contex
 		azureConfigJSON.WriteString(fmt.Sprintf(`%s"protocol": %q`, elemPrefix, azureConfig.Protocol))
 	}
 	azureConfigJSON.WriteString("}]")
-
-	content := fmt.Sprintf(`
-	  AzureStorageCredentials = %s
-	  AzureStorageEndpointConfig = %s
-	  %s
-	`, azureCredsJSON.String(), azureConfigJSON.String(), g.getCAFile())
-	return dedent.Dedent(content), ctrl.Result{}, nil
+	return azureConfigJSON.String()
 }
 
 // getAdditionalConfigParmsContent constructs a string containing additional server config parameters
@@ -426,22 +428,14 @@ func (g *GenericDatabaseInitializer) getCommunalAuth(ctx context.Context) (strin
 		return "", res, err
 	}
 
-	accessKey, ok := secret.Data[cloud.CommunalAccessKeyName]
-	if !ok {
-		g.VRec.Eventf(g.Vdb, corev1.EventTypeWarning, events.CommunalCredsWrongKey,
-			"The communal credential secret '%s' does not have a key named '%s'", g.Vdb.Spec.Communal.CredentialSecret, cloud.CommunalAccessKeyName)
-		return "", ctrl.Result{Requeue: true}, nil
-	}
-
-	secretKey, ok := secret.Data[cloud.CommunalSecretKeyName]
-	if !ok {
+	accessKey, secretKey, missingKey := cloud.GetAccessAndSecretKey(secret.Data)
+	if missingKey != "" {
 		g.VRec.Eventf(g.Vdb, corev1.EventTypeWarning, events.CommunalCredsWrongKey,
-			"The communal credential secret '%s' does not have a key named '%s'", g.Vdb.Spec.Communal.CredentialSecret, cloud.CommunalSecretKeyName)
+			"The communal credential secret '%s' does not have a key named '%s'", g.Vdb.Spec.Communal.CredentialSecret, missingKey)
 		return "", ctrl.Result{Requeue: true}, nil
 	}
 
-	auth := fmt.Sprintf("%s:%s", strings.TrimSuffix(string(accessKey), "\n"),
-		strings.TrimSuffix(string(secretKey), "\n"))
+	auth := fmt.Sprintf("%s:%s", accessKey, secretKey)
 	return auth, ctrl.Result{}, nil
 }
 
@@ -453,45 +447,14 @@ func (g *GenericDatabaseInitializer) getAzureAuth(ctx context.Context) (
 		return cloud.AzureCredential{}, cloud.AzureEndpointConfig{}, res, err
 	}
 
-	accountName, hasAccountName := secret.Data[cloud.AzureAccountName]
-	blobEndpointRaw, hasBlobEndpoint := secret.Data[cloud.AzureBlobEndpoint]
-
-	if !hasAccountName && !hasBlobEndpoint {
-		g.VRec.Eventf(g.Vdb, corev1.EventTypeWarning, events.CommunalCredsWrongKey,
-			"The communal credential secret '%s' is not setup properly for azure.  It must have one '%s' or '%s'",
-			g.Vdb.Spec.Communal.CredentialSecret, cloud.AzureAccountName, cloud.AzureBlobEndpoint)
-		return cloud.AzureCredential{}, cloud.AzureEndpointConfig{}, ctrl.Result{Requeue: true}, nil
-	}
-
-	// The blob endpoint may have a protocol scheme as a prefix.  Strip that off
-	// so its just the host and port.
-	var blobEndpoint string
-	if hasBlobEndpoint {
-		blobEndpoint = getEndpointHostPort(string(blobEndpointRaw))
-	}
-
-	accountKey, hasAccountKey := secret.Data[cloud.AzureAccountKey]
-	sas, hasSAS := secret.Data[cloud.AzureSharedAccessSignature]
-
-	if hasAccountKey && hasSAS {
+	azureCreds, azureConfig, err := cloud.ReadAzureCredential(secret.Data)
+	if err != nil {
 		g.VRec.Eventf(g.Vdb, corev1.EventTypeWarning, events.CommunalCredsWrongKey,
-			"The communal credential secret '%s' is not setup properly for azure.  It cannot have both '%s' and '%s'",
-			g.Vdb.Spec.Communal.CredentialSecret, cloud.AzureAccountKey, cloud.AzureSharedAccessSignature)
+			"The communal credential secret '%s' is not setup properly for azure: %s",
+			g.Vdb.Spec.Communal.CredentialSecret, err.Error())
 		return cloud.AzureCredential{}, cloud.AzureEndpointConfig{}, ctrl.Result{Requeue: true}, nil
 	}
-
-	return cloud.AzureCredential{
-			AccountName:           string(accountName),
-			BlobEndpoint:          blobEndpoint,
-			AccountKey:            string(accountKey),
-			SharedAccessSignature: string(sas),
-		},
-		cloud.AzureEndpointConfig{
-			AccountName:  string(accountName),
-			BlobEndpoint: blobEndpoint,
-			Protocol:     getEndpointProtocol(string(blobEndpointRaw)),
-		},
-		ctrl.Result{}, nil
+	return azureCreds, azureConfig, ctrl.Result{}, nil
 }
 
 // getCommunalCredsSecret returns the contents of the communal credentials
@@ -581,6 +544,17 @@ func (g *GenericDatabaseInitializer) getS3SseCustomerKey(ctx context.Context) (s
 		return "", ctrl.Result{}, nil
 	}
 
+	clientKey, res, err := g.getS3SseCustomerKeyValue(ctx)
+	if verrors.IsReconcileAborted(res, err) {
+		return "", res, err
+	}
+	content := fmt.Sprintf("%s = %s", S3SseCustomerKey, clientKey)
+	return content, ctrl.Result{}, nil
+}
+
+// getS3SseCustomerKeyValue returns the client key for SSE-C that is stored in
+// the s3SseCustomerKey secret.
+func (g *GenericDatabaseInitializer) getS3SseCustomerKeyValue(ctx context.Context) (string, ctrl.Result, error) {
 	secret, res, err := g.getS3SseCustomerKeySecret(ctx)
 	if verrors.IsReconcileAborted(res, err) {
 		return "", res, err
@@ -593,8 +567,7 @@ func (g *GenericDatabaseInitializer) getS3SseCustomerKey(ctx context.Context) (s
 			g.Vdb.Spec.Communal.S3SseCustomerKeySecret, cloud.S3SseCustomerKeyName)
 		return "", ctrl.Result{Requeue: true}, nil
 	}
-	content := fmt.Sprintf("%s = %s", S3SseCustomerKey, string(clientKey))
-	return content, ctrl.Result{}, nil
+	return string(clientKey), ctrl.Result{}, nil
 }
 
 // getRegion will return an entry for region, specific to the cloud provider
@@ -607,29 +580,6 @@ func (g *GenericDatabaseInitializer) getRegion(parmName string) string {
 	return fmt.Sprintf("%s = %s", parmName, g.Vdb.Spec.Communal.Region)
 }
 
-// getEndpointProtocol returns the protocol (HTTPS or HTTP) for the given endpoint
-func getEndpointProtocol(blobEndpoint string) string {
-	if blobEndpoint == "" {
-		return cloud.AzureDefaultProtocol
-	}
-	re := regexp.MustCompile(`([a-z]+)://`)
-	m := re.FindAllStringSubmatch(blobEndpoint, 1)
-	if len(m) == 0 || len(m[0]) < 2 {
-		return cloud.AzureDefaultProtocol
-	}
-	return strings.ToUpper(m[0][1])
-}
-
-// getEndpointHostPort returns just the host and port portion of a endpoint
-func getEndpointHostPort(blobEndpoint string) string {
-	re := regexp.MustCompile(`([a-z]+)://(.*)`)
-	m := re.FindAllStringSubmatch(blobEndpoint, 1)
-	if len(m) == 0 || len(m[0]) < 3 {
-		return blobEndpoint
-	}
-	return strings.TrimSuffix(m[0][2], "/")
-}
-
 // getHadoopConfDir gets the string to include in the auth parms for
 // HadoopConfDir.  If that isn't present, an empty string is returned.
 func (g *GenericDatabaseInitializer) getHadoopConfDir() string {
@@ -206,22 +206,22 @@ var _ = Describe("init_db", func() {
 		ExpectWithOffset(1, res).Should(Equal(ctrl.Result{Requeue: true}))
 	})
 
-	It("should return correct protocol when calling getEndpointProtocol", func() {
-		Expect(getEndpointProtocol("")).Should(Equal(cloud.AzureDefaultProtocol))
-		Expect(getEndpointProtocol("192.168.0.1")).Should(Equal(cloud.AzureDefaultProtocol))
-		Expect(getEndpointProtocol("accountname.mcr.net")).Should(Equal(cloud.AzureDefaultProtocol))
-		Expect(getEndpointProtocol("https://accountname.mcr.net")).Should(Equal(cloud.AzureDefaultProtocol))
-		Expect(getEndpointProtocol("http://accountname.mcr.net:300")).Should(Equal("HTTP"))
-		Expect(getEndpointProtocol("http://192.168.0.1")).Should(Equal("HTTP"))
+	It("should return correct protocol when calling GetEndpointProtocol", func() {
+		Expect(cloud.GetEndpointProtocol("")).Should(Equal(cloud.AzureDefaultProtocol))
+		Expect(cloud.GetEndpointProtocol("192.168.0.1")).Should(Equal(cloud.AzureDefaultProtocol))
+		Expect(cloud.GetEndpointProtocol("accountname.mcr.net")).Should(Equal(cloud.AzureDefaultProtocol))
+		Expect(cloud.GetEndpointProtocol("https://accountname.mcr.net")).Should(Equal(cloud.AzureDefaultProtocol))
+		Expect(cloud.GetEndpointProtocol("http://accountname.mcr.net:300")).Should(Equal("HTTP"))
+		Expect(cloud.GetEndpointProtocol("http://192.168.0.1")).Should(Equal("HTTP"))
 	})
 
-	It("should return host/port without protocol when calling getEndpointHostPort", func() {
-		Expect(getEndpointHostPort("192.168.0.1")).Should(Equal("192.168.0.1"))
-		Expect(getEndpointHostPort("hostname:10000")).Should(Equal("hostname:10000"))
-		Expect(getEndpointHostPort("http://hostname")).Should(Equal("hostname"))
-		Expect(getEndpointHostPort("https://tlsHost:3000")).Should(Equal("tlsHost:3000"))
-		Expect(getEndpointHostPort("account@myhost")).Should(Equal("account@myhost"))
-		Expect(getEndpointHostPort("azb://account/container/db/")).Should(Equal("account/container/db"))
+	It("should return host/port without protocol when calling GetEndpointHostPort", func() {
+		Expect(cloud.GetEndpointHostPort("192.168.0.1")).Should(Equal("192.168.0.1"))
+		Expect(cloud.GetEndpointHostPort("hostname:10000")).Should(Equal("hostname:10000"))
+		Expect(cloud.GetEndpointHostPort("http://hostname")).Should(Equal("hostname"))
+		Expect(cloud.GetEndpointHostPort("https://tlsHost:3000")).Should(Equal("tlsHost:3000"))
+		Expect(cloud.GetEndpointHostPort("account@myhost")).Should(Equal("account@myhost"))
+		Expect(cloud.GetEndpointHostPort("azb://account/container/db/")).Should(Equal("account/container/db"))
 
 	})
 
@@ -24,13 +24,13 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
-	"github.com/vertica/vertica-kubernetes/pkg/atconf"
 	"github.com/vertica/vertica-kubernetes/pkg/cmds"
 	"github.com/vertica/vertica-kubernetes/pkg/controllers"
 	"github.com/vertica/vertica-kubernetes/pkg/events"
 	"github.com/vertica/vertica-kubernetes/pkg/httpconf"
 	"github.com/vertica/vertica-kubernetes/pkg/names"
 	"github.com/vertica/vertica-kubernetes/pkg/paths"
+	"github.com/vertica/vertica-kubernetes/pkg/vadmin"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -38,24 +38,24 @@ import (
 
 // InstallReconciler will handle reconcile for install of vertica
 type InstallReconciler struct {
-	VRec     *VerticaDBReconciler
-	Log      logr.Logger
-	Vdb      *vapi.VerticaDB // Vdb is the CRD we are acting on.
-	PRunner  cmds.PodRunner
-	PFacts   *PodFacts
-	ATWriter atconf.Writer
+	VRec       *VerticaDBReconciler
+	Log        logr.Logger
+	Vdb        *vapi.VerticaDB // Vdb is the CRD we are acting on.
+	PRunner    cmds.PodRunner
+	PFacts     *PodFacts
+	Dispatcher vadmin.Dispatcher
 }
 
 // MakeInstallReconciler will build and return the InstallReconciler object.
 func MakeInstallReconciler(vdbrecon *VerticaDBReconciler, log logr.Logger,
 	vdb *vapi.VerticaDB, prunner cmds.PodRunner, pfacts *PodFacts) controllers.ReconcileActor {
 	return &InstallReconciler{
-		VRec:     vdbrecon,
-		Log:      log,
-		Vdb:      vdb,
-		PRunner:  prunner,
-		PFacts:   pfacts,
-		ATWriter: atconf.MakeFileWriter(log, vdb, prunner),
+		VRec:       vdbrecon,
+		Log:        log,
+		Vdb:        vdb,
+		PRunner:    prunner,
+		PFacts:     pfacts,
+		Dispatcher: vdbrecon.makeDispatcher(log, vdb, prunner),
 	}
 }
 
@@ -128,16 +128,24 @@ func (d *InstallReconciler) addHostsToATConf(ctx context.Context) error {
 		}
 	}
 
-	atConfTempFile, err := d.ATWriter.AddHosts(ctx, installPod, ipsToInstall)
+	copyTargets, err := getATConfCopyTargets(d.Vdb, d.PFacts)
 	if err != nil {
 		return err
 	}
-	defer os.Remove(atConfTempFile)
 
 	if d.VRec.OpCfg.DevMode {
 		debugDumpAdmintoolsConfForPods(ctx, d.PRunner, installedPods)
 	}
-	if err := distributeAdmintoolsConf(ctx, d.Vdb, d.VRec, d.PFacts, d.PRunner, atConfTempFile); err != nil {
+	opts := vadmin.InstallOptions{
+		InitiatorOptions: vadmin.InitiatorOptions{Initiator: installPod},
+		Hosts:            ipsToInstall,
+		Pods:             copyTargets,
+	}
+	if _, err := d.Dispatcher.Install(ctx, &opts); err != nil {
+		partialErr := &vadmin.PartialCopyError{}
+		if errors.As(err, &partialErr) {
+			logATConfPartiallyCopied(d.Vdb, d.VRec, partialErr.Failed)
+		}
 		return err
 	}
 	installedPods = append(installedPods, pods...)
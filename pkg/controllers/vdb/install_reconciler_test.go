@@ -27,6 +27,7 @@ import (
 	"github.com/vertica/vertica-kubernetes/pkg/names"
 	"github.com/vertica/vertica-kubernetes/pkg/paths"
 	"github.com/vertica/vertica-kubernetes/pkg/test"
+	"github.com/vertica/vertica-kubernetes/pkg/vadmin"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -66,7 +67,7 @@ var _ = Describe("k8s/install_reconcile_test", func() {
 		pfact.Detail[names.GenPodName(vdb, sc, 2)].isInstalled = false
 		actor := MakeInstallReconciler(vdbRec, logger, vdb, fpr, pfact)
 		drecon := actor.(*InstallReconciler)
-		drecon.ATWriter = &atconf.FakeWriter{}
+		drecon.Dispatcher.(*vadmin.Admintools).ATWriter = &atconf.FakeWriter{}
 		Expect(drecon.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
 		cmdHist := fpr.FindCommands(fmt.Sprintf("cat > %s", paths.AdminToolsConf))
 		Expect(len(cmdHist)).Should(Equal(3))
@@ -89,7 +90,7 @@ var _ = Describe("k8s/install_reconcile_test", func() {
 		pfact := MakePodFacts(vdbRec, fpr)
 		actor := MakeInstallReconciler(vdbRec, logger, vdb, fpr, &pfact)
 		drecon := actor.(*InstallReconciler)
-		drecon.ATWriter = &atconf.FakeWriter{}
+		drecon.Dispatcher.(*vadmin.Admintools).ATWriter = &atconf.FakeWriter{}
 		res, err := drecon.Reconcile(ctx, &ctrl.Request{})
 		Expect(err).Should(Succeed())
 		Expect(res.Requeue).Should(BeTrue())
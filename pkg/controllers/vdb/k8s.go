@@ -21,6 +21,8 @@ import (
 
 	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
 	"github.com/vertica/vertica-kubernetes/pkg/events"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
@@ -72,3 +74,24 @@ func getConfigMapOrSecret(ctx context.Context, vrec *VerticaDBReconciler, vdb *v
 	}
 	return ctrl.Result{}, nil
 }
+
+// scaleSts will set the number of replicas in the statefulset of a
+// subcluster.  It is a no-op if the statefulset doesn't exist yet or already
+// has the given number of replicas.  It returns true if the statefulset was
+// changed.
+func scaleSts(ctx context.Context, vrec *VerticaDBReconciler, vdb *vapi.VerticaDB,
+	sc *vapi.Subcluster, replicas int32) (bool, error) {
+	sts := &appsv1.StatefulSet{}
+	if err := vrec.Client.Get(ctx, names.GenStsName(vdb, sc), sts); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if sts.Spec.Replicas != nil && *sts.Spec.Replicas == replicas {
+		return false, nil
+	}
+	patch := client.MergeFrom(sts.DeepCopy())
+	sts.Spec.Replicas = &replicas
+	return true, vrec.Client.Patch(ctx, sts, patch)
+}
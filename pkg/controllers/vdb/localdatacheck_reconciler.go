@@ -27,6 +27,10 @@ import (
 
 // LocalDataCheckReconciler will check the free space available in the PV and
 // log events if they it is too low.
+// We report a warning for any pod that has less then this amount of free
+// space in their local PV.
+const LowLocalDataAvailThreshold = 10 * 1024 * 1024 // 10mb
+
 type LocalDataCheckReconciler struct {
 	VRec      *VerticaDBReconciler
 	Vdb       *vapi.VerticaDB
@@ -45,10 +49,7 @@ func (l *LocalDataCheckReconciler) Reconcile(ctx context.Context, req *ctrl.Requ
 		return ctrl.Result{}, err
 	}
 
-	// We report a warning for any pod that has less then this amount of free
-	// space in their local PV.
-	const FreeSpaceThreshold = 10 * 1024 * 1024 // 10mb
-	pods := l.PFacts.findPodsLowOnDiskSpace(FreeSpaceThreshold)
+	pods := l.PFacts.findPodsLowOnDiskSpace(LowLocalDataAvailThreshold)
 	l.NumEvents = 0
 	for i := range pods {
 		l.VRec.Eventf(l.Vdb, corev1.EventTypeWarning, events.LowLocalDataAvailSpace,
@@ -0,0 +1,136 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/events"
+	"github.com/vertica/vertica-kubernetes/pkg/vdbstatus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// The time to wait before checking the maintenance windows again if none of
+// them will ever open.  The schedule can only be fixed by a change to the
+// spec, which will reconcile right away.
+const noMaintenanceWindowRequeueTime = time.Hour
+
+// deferredRequeueKey is the context key for the deferredRequeue of a reconcile
+type deferredRequeueKey struct{}
+
+// deferredRequeue holds the requeue for the actors that finished but want to
+// run again later, such as an operation waiting for a maintenance window or a
+// periodic drift check.  Unlike a requeue returned by an actor, it doesn't
+// stop the actors that come after.  The controller returns the soonest one
+// once all of the actors have run.
+type deferredRequeue struct {
+	requeueAfter time.Duration
+}
+
+// withDeferredRequeue returns a context that collects the deferred requeue
+// for a single reconcile.
+func withDeferredRequeue(ctx context.Context) (context.Context, *deferredRequeue) {
+	d := &deferredRequeue{}
+	return context.WithValue(ctx, deferredRequeueKey{}, d), d
+}
+
+// deferRequeue will remember that the reconcile must be requeued after the
+// given time.  The soonest time is kept if it is called more than once.
+func deferRequeue(ctx context.Context, requeueAfter time.Duration) {
+	d, ok := ctx.Value(deferredRequeueKey{}).(*deferredRequeue)
+	if !ok {
+		return
+	}
+	if d.requeueAfter == 0 || requeueAfter < d.requeueAfter {
+		d.requeueAfter = requeueAfter
+	}
+}
+
+// apply will merge the deferred requeue into the result of the reconcile.
+// The soonest requeue wins.
+func (d *deferredRequeue) apply(res ctrl.Result) ctrl.Result {
+	if d.requeueAfter == 0 || res.Requeue {
+		return res
+	}
+	if res.RequeueAfter == 0 || d.requeueAfter < res.RequeueAfter {
+		res.RequeueAfter = d.requeueAfter
+	}
+	return res
+}
+
+// waitForMaintenanceWindow decides if the disruptive operation, described by
+// op, can start now.  It returns true if the operation must be held back.  In
+// that case, the reconcile is requeued for when the next maintenance window
+// opens, once all of the other actors have run, and the status says what is
+// waiting.  The caller must only call this before it starts the operation, so
+// that an operation in progress is never stopped when its window closes.
+func waitForMaintenanceWindow(ctx context.Context, vrec *VerticaDBReconciler, log logr.Logger,
+	vdb *vapi.VerticaDB, op string) (bool, error) {
+	// The steps of an upgrade in progress, such as the rebalance of the
+	// transient subcluster, are never held back.
+	if vdb.IsImageChangeInProgress() {
+		return false, nil
+	}
+	allowed, next, err := vdb.CheckMaintenanceWindow(time.Now())
+	if err != nil {
+		// The webhook prevents this.  So, we don't requeue as this can
+		// only be fixed by a change to the spec.
+		log.Error(err, "could not check the maintenance windows")
+		return false, nil
+	}
+	if allowed {
+		if vdb.Status.MaintenanceWindowStatus == "" {
+			return false, nil
+		}
+		return false, clearMaintenanceWindowStatus(ctx, vrec, vdb)
+	}
+
+	msg := fmt.Sprintf("waiting for maintenance window to %s", op)
+	requeueAfter := noMaintenanceWindowRequeueTime
+	var nextTime *metav1.Time
+	if !next.IsZero() {
+		requeueAfter = time.Until(next) + time.Second
+		nextTime = &metav1.Time{Time: next}
+	}
+	log.Info(msg, "nextMaintenanceWindow", next)
+	deferRequeue(ctx, requeueAfter)
+	if vdb.Status.MaintenanceWindowStatus != msg {
+		vrec.Eventf(vdb, corev1.EventTypeNormal, events.MaintenanceWindowWait,
+			"Waiting for the next maintenance window to %s", op)
+	}
+	err = vdbstatus.Update(ctx, vrec.Client, vdb, func(vdbChg *vapi.VerticaDB) error {
+		vdbChg.Status.MaintenanceWindowStatus = msg
+		vdbChg.Status.NextMaintenanceWindow = nextTime
+		return nil
+	})
+	return true, err
+}
+
+// clearMaintenanceWindowStatus will remove the status that says an operation
+// is waiting for a maintenance window
+func clearMaintenanceWindowStatus(ctx context.Context, vrec *VerticaDBReconciler, vdb *vapi.VerticaDB) error {
+	return vdbstatus.Update(ctx, vrec.Client, vdb, func(vdbChg *vapi.VerticaDB) error {
+		vdbChg.Status.MaintenanceWindowStatus = ""
+		vdbChg.Status.NextMaintenanceWindow = nil
+		return nil
+	})
+}
@@ -0,0 +1,64 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vdb
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/test"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("maintenance", func() {
+	ctx := context.Background()
+
+	It("should requeue until the next maintenance window opens", func() {
+		vdb := vapi.MakeVDB()
+		// A window that opened a minute ago and already closed
+		opened := time.Now().UTC().Add(-time.Minute)
+		vdb.Spec.MaintenanceWindows = []vapi.MaintenanceWindow{
+			{Schedule: opened.Format("4 15 * * *"), Duration: metav1.Duration{Duration: time.Second}},
+		}
+		test.CreateVDB(ctx, k8sClient, vdb)
+		defer test.DeleteVDB(ctx, k8sClient, vdb)
+
+		dctx, deferred := withDeferredRequeue(ctx)
+		Expect(waitForMaintenanceWindow(dctx, vdbRec, logger, vdb, "rebalance the shards")).Should(BeTrue())
+		Expect(deferred.requeueAfter).Should(BeNumerically(">", 23*time.Hour))
+		Expect(vdb.Status.MaintenanceWindowStatus).Should(Equal("waiting for maintenance window to rebalance the shards"))
+		Expect(vdb.Status.NextMaintenanceWindow).ShouldNot(BeNil())
+
+		vdb.Annotations[vapi.IgnoreMaintenanceWindowsAnnotation] = "true"
+		Expect(waitForMaintenanceWindow(ctx, vdbRec, logger, vdb, "rebalance the shards")).Should(BeFalse())
+		Expect(vdb.Status.MaintenanceWindowStatus).Should(BeEmpty())
+		Expect(vdb.Status.NextMaintenanceWindow).Should(BeNil())
+	})
+	It("should keep the soonest deferred requeue", func() {
+		dctx, deferred := withDeferredRequeue(ctx)
+		Expect(deferred.apply(ctrl.Result{})).Should(Equal(ctrl.Result{}))
+		deferRequeue(dctx, time.Hour)
+		deferRequeue(dctx, time.Minute)
+		deferRequeue(dctx, time.Hour)
+		Expect(deferred.apply(ctrl.Result{})).Should(Equal(ctrl.Result{RequeueAfter: time.Minute}))
+		Expect(deferred.apply(ctrl.Result{RequeueAfter: time.Second})).Should(Equal(ctrl.Result{RequeueAfter: time.Second}))
+		Expect(deferred.apply(ctrl.Result{Requeue: true})).Should(Equal(ctrl.Result{Requeue: true}))
+	})
+})
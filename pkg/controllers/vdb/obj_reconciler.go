@@ -29,10 +29,13 @@ import (
 	verrors "github.com/vertica/vertica-kubernetes/pkg/errors"
 	"github.com/vertica/vertica-kubernetes/pkg/events"
 	"github.com/vertica/vertica-kubernetes/pkg/iter"
+	"github.com/vertica/vertica-kubernetes/pkg/monitoring"
 	"github.com/vertica/vertica-kubernetes/pkg/names"
 	"github.com/vertica/vertica-kubernetes/pkg/paths"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -95,6 +98,22 @@ func (o *ObjReconciler) Reconcile(ctx context.Context, req *ctrl.Request) (ctrl.
 		return ctrl.Result{}, err
 	}
 
+	// Create, update or remove the NetworkPolicies depending on whether they
+	// are enabled in the vdb.
+	if err := o.reconcileNetworkPolicies(ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := o.reconcilePodMonitor(ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// A single PodDisruptionBudget protects the pods of all of the primary
+	// subclusters.  The other subclusters get theirs as we check them below.
+	if err := o.reconcilePrimaryPDB(ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Check the objects for subclusters that should exist.  This will create
 	// missing objects and update existing objects to match the vdb.
 	for i := range o.Vdb.Spec.Subclusters {
@@ -206,6 +225,10 @@ func (o *ObjReconciler) checkForCreatedSubcluster(ctx context.Context, sc *vapi.
 		}
 	}
 
+	if err := o.reconcilePDB(ctx, sc); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	return o.reconcileSts(ctx, sc)
 }
 
@@ -252,6 +275,19 @@ func (o *ObjReconciler) checkForDeletedSubcluster(ctx context.Context) (ctrl.Res
 			return ctrl.Result{}, err
 		}
 	}
+
+	// Find any PodDisruptionBudgets that need to be deleted
+	pdbs, err := finder.FindPodDisruptionBudgets(ctx, iter.FindNotInVdb)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	for i := range pdbs.Items {
+		err = o.VRec.Client.Delete(ctx, &pdbs.Items[i])
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
 	return ctrl.Result{}, nil
 }
 
@@ -380,6 +416,171 @@ func (o *ObjReconciler) createService(ctx context.Context, svc *corev1.Service,
 	return o.VRec.Client.Create(ctx, svc)
 }
 
+// reconcileNetworkPolicies makes sure the NetworkPolicies for the vdb and
+// each of its subclusters exist when they are enabled.  Any policy that isn't
+// needed anymore is removed.
+func (o *ObjReconciler) reconcileNetworkPolicies(ctx context.Context) error {
+	if o.Mode&ObjReconcileModeAll == 0 {
+		// Bypass this check since we are doing changes to statefulsets only
+		return nil
+	}
+
+	finder := iter.MakeSubclusterFinder(o.VRec.Client, o.Vdb)
+	findFlags := iter.FindNotInVdb
+	if o.Vdb.Spec.NetworkPolicy.Enabled {
+		expNP := builder.BuildNetworkPolicy(names.GenNetworkPolicyName(o.Vdb), o.Vdb, &o.VRec.DeploymentNames)
+		if err := o.reconcileNetworkPolicy(ctx, expNP); err != nil {
+			return err
+		}
+		for i := range o.Vdb.Spec.Subclusters {
+			sc := &o.Vdb.Spec.Subclusters[i]
+			expNP = builder.BuildClientNetworkPolicy(names.GenClientNetworkPolicyName(o.Vdb, sc), o.Vdb, sc)
+			if err := o.reconcileNetworkPolicy(ctx, expNP); err != nil {
+				return err
+			}
+		}
+	} else {
+		findFlags = iter.FindAll
+	}
+
+	nps, err := finder.FindNetworkPolicies(ctx, findFlags)
+	if err != nil {
+		return err
+	}
+	for i := range nps.Items {
+		o.Log.Info("Deleting NetworkPolicy", "Name", nps.Items[i].Name)
+		if err := o.VRec.Client.Delete(ctx, &nps.Items[i]); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcilePodMonitor maintains the PodMonitor that has prometheus scrape the
+// metrics of the http server in the vertica pods.  This is only done if the
+// operator was told to create them and the prometheus operator is installed.
+func (o *ObjReconciler) reconcilePodMonitor(ctx context.Context) error {
+	if o.Mode&ObjReconcileModeAll == 0 || !o.VRec.OpCfg.CreatePrometheusMonitors {
+		return nil
+	}
+	installed, err := monitoring.IsKindInstalled(o.VRec.Client.RESTMapper(), monitoring.PodMonitorGVK)
+	if err != nil || !installed {
+		return err
+	}
+
+	nm := names.GenPodMonitorName(o.Vdb)
+	// We need the http server and its certs to scrape the metrics
+	if o.Vdb.IsHTTPServerDisabled() || o.Vdb.Spec.HTTPServerTLSSecret == "" {
+		deleted, err := monitoring.Delete(ctx, o.VRec.Client, monitoring.PodMonitorGVK, nm)
+		if deleted {
+			o.Log.Info("Deleted PodMonitor", "Name", nm)
+		}
+		return err
+	}
+	expObj := monitoring.BuildPodMonitor(nm, o.Vdb)
+	if err := ctrl.SetControllerReference(o.Vdb, expObj, o.VRec.Scheme); err != nil {
+		return err
+	}
+	changed, err := monitoring.Apply(ctx, o.VRec.Client, expObj)
+	if changed {
+		o.Log.Info("Applied PodMonitor", "Name", nm)
+	}
+	return err
+}
+
+// reconcileNetworkPolicy creates the NetworkPolicy if it doesn't exist or
+// updates it if it differs from what we expect.
+func (o *ObjReconciler) reconcileNetworkPolicy(ctx context.Context, expNP *networkingv1.NetworkPolicy) error {
+	nm := types.NamespacedName{Namespace: expNP.Namespace, Name: expNP.Name}
+	curNP := &networkingv1.NetworkPolicy{}
+	err := o.VRec.Client.Get(ctx, nm, curNP)
+	if err != nil && errors.IsNotFound(err) {
+		o.Log.Info("Creating NetworkPolicy", "Name", nm)
+		err = ctrl.SetControllerReference(o.Vdb, expNP, o.VRec.Scheme)
+		if err != nil {
+			return err
+		}
+		return o.VRec.Client.Create(ctx, expNP)
+	} else if err != nil {
+		return err
+	}
+
+	if reflect.DeepEqual(expNP.Spec, curNP.Spec) && reflect.DeepEqual(expNP.Labels, curNP.Labels) {
+		return nil
+	}
+	o.Log.Info("Updating NetworkPolicy", "Name", nm)
+	curNP.Labels = expNP.Labels
+	curNP.Spec = expNP.Spec
+	return o.VRec.Client.Update(ctx, curNP)
+}
+
+// reconcilePDBs reconciles the PodDisruptionBudget of the primary subclusters
+// and of each secondary subcluster in the vdb
+func (o *ObjReconciler) reconcilePDBs(ctx context.Context) error {
+	if err := o.reconcilePrimaryPDB(ctx); err != nil {
+		return err
+	}
+	for i := range o.Vdb.Spec.Subclusters {
+		if err := o.reconcilePDB(ctx, &o.Vdb.Spec.Subclusters[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcilePrimaryPDB verifies the PodDisruptionBudget that is shared by the
+// pods of all of the primary subclusters exists and matches the k-safety and
+// the number of primary nodes in the vdb.
+func (o *ObjReconciler) reconcilePrimaryPDB(ctx context.Context) error {
+	return o.applyPDB(ctx, builder.BuildPrimaryPDB(names.GenPrimaryPDBName(o.Vdb), o.Vdb))
+}
+
+// reconcilePDB verifies the PodDisruptionBudget for a subcluster exists and
+// matches the subcluster size in the vdb.  This is done in every mode so that
+// the budget is relaxed as soon as an upgrade starts.  The budget is removed
+// if the subcluster doesn't need its own, such as when it is shut down.
+func (o *ObjReconciler) reconcilePDB(ctx context.Context, sc *vapi.Subcluster) error {
+	nm := names.GenPDBName(o.Vdb, sc)
+	if !builder.IsPDBNeeded(sc) {
+		curPDB := &policyv1.PodDisruptionBudget{}
+		if err := o.VRec.Client.Get(ctx, nm, curPDB); err != nil {
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		o.Log.Info("Deleting PodDisruptionBudget", "Name", nm)
+		return o.VRec.Client.Delete(ctx, curPDB)
+	}
+	return o.applyPDB(ctx, builder.BuildPDB(nm, o.Vdb, sc))
+}
+
+// applyPDB will create the PodDisruptionBudget or update it if it differs
+// from the expected one.
+func (o *ObjReconciler) applyPDB(ctx context.Context, expPDB *policyv1.PodDisruptionBudget) error {
+	nm := types.NamespacedName{Namespace: expPDB.Namespace, Name: expPDB.Name}
+	curPDB := &policyv1.PodDisruptionBudget{}
+	err := o.VRec.Client.Get(ctx, nm, curPDB)
+	if err != nil && errors.IsNotFound(err) {
+		o.Log.Info("Creating PodDisruptionBudget", "Name", nm, "MaxUnavailable", expPDB.Spec.MaxUnavailable.String())
+		err = ctrl.SetControllerReference(o.Vdb, expPDB, o.VRec.Scheme)
+		if err != nil {
+			return err
+		}
+		return o.VRec.Client.Create(ctx, expPDB)
+	} else if err != nil {
+		return err
+	}
+
+	if reflect.DeepEqual(expPDB.Spec, curPDB.Spec) && reflect.DeepEqual(expPDB.Labels, curPDB.Labels) {
+		return nil
+	}
+	o.Log.Info("Updating PodDisruptionBudget", "Name", nm, "MaxUnavailable", expPDB.Spec.MaxUnavailable.String())
+	curPDB.Labels = expPDB.Labels
+	curPDB.Spec = expPDB.Spec
+	return o.VRec.Client.Update(ctx, curPDB)
+}
+
 // reconcileSts reconciles the statefulset for a particular subcluster.  Returns
 // true if any create/update was done.
 func (o *ObjReconciler) reconcileSts(ctx context.Context, sc *vapi.Subcluster) (ctrl.Result, error) {
@@ -29,10 +29,13 @@ import (
 	"github.com/vertica/vertica-kubernetes/pkg/paths"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -94,9 +97,31 @@ var _ = Describe("obj_reconcile", func() {
 			} else {
 				Expect(errors.IsNotFound(err)).Should(BeTrue())
 			}
+
+			pdb := &policyv1.PodDisruptionBudget{}
+			err = k8sClient.Get(ctx, names.GenPDBName(vdb, &vdb.Spec.Subclusters[i]), pdb)
+			if err == nil {
+				Expect(pdb.ObjectMeta.OwnerReferences).To(ContainElement(expOwnerRef))
+				Expect(k8sClient.Delete(ctx, pdb)).Should(Succeed())
+			} else {
+				Expect(errors.IsNotFound(err)).Should(BeTrue())
+			}
+		}
+		pdb := &policyv1.PodDisruptionBudget{}
+		err := k8sClient.Get(ctx, names.GenPrimaryPDBName(vdb), pdb)
+		if err == nil {
+			Expect(pdb.ObjectMeta.OwnerReferences).To(ContainElement(expOwnerRef))
+			Expect(k8sClient.Delete(ctx, pdb)).Should(Succeed())
+		} else {
+			Expect(errors.IsNotFound(err)).Should(BeTrue())
+		}
+		nps := &networkingv1.NetworkPolicyList{}
+		Expect(k8sClient.List(ctx, nps, client.InNamespace(vdb.Namespace))).Should(Succeed())
+		for i := range nps.Items {
+			Expect(k8sClient.Delete(ctx, &nps.Items[i])).Should(Succeed())
 		}
 		svc := &corev1.Service{}
-		err := k8sClient.Get(ctx, names.GenHlSvcName(vdb), svc)
+		err = k8sClient.Get(ctx, names.GenHlSvcName(vdb), svc)
 		if err == nil {
 			Expect(svc.ObjectMeta.OwnerReferences).To(ContainElement(expOwnerRef))
 			Expect(k8sClient.Delete(ctx, svc)).Should(Succeed())
@@ -422,6 +447,64 @@ var _ = Describe("obj_reconcile", func() {
 			Expect(curSize).Should(Equal(newSize))
 		})
 
+		It("should create a PodDisruptionBudget for the primaries and each secondary subcluster", func() {
+			vdb := vapi.MakeVDB()
+			vdb.Spec.Subclusters = append(vdb.Spec.Subclusters, vapi.Subcluster{
+				Name: "analytics",
+				Size: 4,
+			})
+			createCrd(vdb, true)
+			defer deleteCrd(vdb)
+
+			pdb := &policyv1.PodDisruptionBudget{}
+			Expect(k8sClient.Get(ctx, names.GenPrimaryPDBName(vdb), pdb)).Should(Succeed())
+			Expect(pdb.Spec.MaxUnavailable.IntValue()).Should(Equal(1))
+			Expect(pdb.Spec.Selector.MatchLabels).Should(Equal(builder.MakePrimarySelectorLabels(vdb)))
+			Expect(k8sClient.Get(ctx, names.GenPDBName(vdb, &vdb.Spec.Subclusters[0]), &policyv1.PodDisruptionBudget{})).ShouldNot(Succeed())
+			nm := names.GenPDBName(vdb, &vdb.Spec.Subclusters[1])
+			scPDB := &policyv1.PodDisruptionBudget{}
+			Expect(k8sClient.Get(ctx, nm, scPDB)).Should(Succeed())
+			Expect(scPDB.Spec.MaxUnavailable.IntValue()).Should(Equal(2))
+
+			vdb.Spec.Subclusters[1].Size = 6
+			Expect(k8sClient.Update(ctx, vdb)).Should(Succeed())
+			runReconciler(vdb, ctrl.Result{}, ObjReconcileModeAll)
+			Expect(k8sClient.Get(ctx, nm, scPDB)).Should(Succeed())
+			Expect(scPDB.Spec.MaxUnavailable.IntValue()).Should(Equal(3))
+
+			// A subcluster that is shut down doesn't need a budget
+			vdb.Spec.Subclusters[1].Shutdown = true
+			Expect(k8sClient.Update(ctx, vdb)).Should(Succeed())
+			runReconciler(vdb, ctrl.Result{}, ObjReconcileModeAll)
+			Expect(k8sClient.Get(ctx, nm, scPDB)).ShouldNot(Succeed())
+		})
+
+		It("should create NetworkPolicies when enabled and remove them when disabled", func() {
+			vdb := vapi.MakeVDB()
+			vdb.Spec.NetworkPolicy.Enabled = true
+			createCrd(vdb, true)
+			defer deleteCrd(vdb)
+
+			np := &networkingv1.NetworkPolicy{}
+			Expect(k8sClient.Get(ctx, names.GenNetworkPolicyName(vdb), np)).Should(Succeed())
+			Expect(np.Spec.PodSelector.MatchLabels).Should(Equal(builder.MakeBaseSvcSelectorLabels(vdb)))
+			nm := names.GenClientNetworkPolicyName(vdb, &vdb.Spec.Subclusters[0])
+			Expect(k8sClient.Get(ctx, nm, np)).Should(Succeed())
+			Expect(np.Spec.Ingress[0].From).Should(BeEmpty())
+
+			vdb.Spec.Subclusters[0].ClientIngressFrom = []networkingv1.NetworkPolicyPeer{
+				{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "client"}}},
+			}
+			runReconciler(vdb, ctrl.Result{}, ObjReconcileModeAll)
+			Expect(k8sClient.Get(ctx, nm, np)).Should(Succeed())
+			Expect(np.Spec.Ingress[0].From).Should(Equal(vdb.Spec.Subclusters[0].ClientIngressFrom))
+
+			vdb.Spec.NetworkPolicy.Enabled = false
+			runReconciler(vdb, ctrl.Result{}, ObjReconcileModeAll)
+			Expect(k8sClient.Get(ctx, nm, np)).ShouldNot(Succeed())
+			Expect(k8sClient.Get(ctx, names.GenNetworkPolicyName(vdb), np)).ShouldNot(Succeed())
+		})
+
 		It("should have updateStrategy OnDelete for kSafety 0", func() {
 			vdb := vapi.MakeVDB()
 			vdb.Spec.KSafety = vapi.KSafety0
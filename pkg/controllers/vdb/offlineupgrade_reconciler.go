@@ -73,6 +73,10 @@ func (o *OfflineUpgradeReconciler) Reconcile(ctx context.Context, req *ctrl.Requ
 		return ctrl.Result{}, err
 	}
 
+	if wait, err := o.Manager.waitForMaintenanceWindow(ctx); wait || err != nil {
+		return ctrl.Result{}, err
+	}
+
 	if err := o.PFacts.Collect(ctx, o.Vdb); err != nil {
 		return ctrl.Result{}, err
 	}
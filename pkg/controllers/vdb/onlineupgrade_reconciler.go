@@ -69,6 +69,10 @@ func (o *OnlineUpgradeReconciler) Reconcile(ctx context.Context, req *ctrl.Reque
 		return ctrl.Result{}, err
 	}
 
+	if wait, err := o.Manager.waitForMaintenanceWindow(ctx); wait || err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Functions to perform when the image changes.  Order matters.
 	funcs := []func(context.Context) (ctrl.Result, error){
 		// Initiate an upgrade by setting condition and event recording
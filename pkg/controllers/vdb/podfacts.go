@@ -23,6 +23,8 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/ghodss/yaml"
 	"github.com/lithammer/dedent"
@@ -31,8 +33,11 @@ import (
 	"github.com/vertica/vertica-kubernetes/pkg/builder"
 	"github.com/vertica/vertica-kubernetes/pkg/cmds"
 	"github.com/vertica/vertica-kubernetes/pkg/iter"
+	"github.com/vertica/vertica-kubernetes/pkg/metrics"
 	"github.com/vertica/vertica-kubernetes/pkg/names"
+	"github.com/vertica/vertica-kubernetes/pkg/opcfg"
 	"github.com/vertica/vertica-kubernetes/pkg/paths"
+	"github.com/vertica/vertica-kubernetes/pkg/vadmin"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
@@ -85,6 +90,10 @@ type PodFact struct {
 	// will get deleted.
 	pendingDelete bool
 
+	// true means the subcluster the pod is part of is shut down.  Its vertica
+	// nodes stay down and the pod is removed once the subcluster is stopped.
+	shutdown bool
+
 	// Have we run install for this pod?
 	isInstalled bool
 
@@ -175,6 +184,21 @@ type PodFacts struct {
 	Detail         PodFactDetail
 	NeedCollection bool
 	OverrideFunc   CheckerFunc // Set this if you want to be able to control the PodFact
+
+	// The generation of the VerticaDB when the facts were last collected
+	collectedGeneration int64
+	// The time the facts were last collected
+	collectedAt time.Time
+	// The epoch of the PodFactsCache entry when the facts were loaded.  See
+	// PodFactsCache.
+	cacheEpoch uint64
+}
+
+// podFactTask identifies a single pod that we need to collect facts for
+type podFactTask struct {
+	sc       *vapi.Subcluster
+	sts      *appsv1.StatefulSet
+	podIndex int32
 }
 
 // GatherState is the data exchanged with the gather pod facts script. We
@@ -218,12 +242,23 @@ func (p *PodFacts) Collect(ctx context.Context, vdb *vapi.VerticaDB) error {
 		return nil
 	}
 
-	// Collect all of the facts about each running pod
+	start := time.Now()
+	tasks := []podFactTask{}
 	for i := range subclusters {
-		if err := p.collectSubcluster(ctx, vdb, subclusters[i]); err != nil {
+		scTasks, err := p.collectSubcluster(ctx, vdb, subclusters[i])
+		if err != nil {
 			return err
 		}
+		tasks = append(tasks, scTasks...)
 	}
+
+	// Collect all of the facts about each running pod
+	if err := p.collectPods(ctx, vdb, tasks); err != nil {
+		return err
+	}
+	metrics.PodFactsCollectDuration.With(metrics.MakeVDBLabels(vdb)).Observe(time.Since(start).Seconds())
+	p.collectedGeneration = vdb.Generation
+	p.collectedAt = time.Now()
 	p.NeedCollection = false
 	return nil
 }
@@ -234,34 +269,116 @@ func (p *PodFacts) Invalidate() {
 	p.NeedCollection = true
 }
 
-// collectSubcluster will collect facts about each pod in a specific subcluster
-func (p *PodFacts) collectSubcluster(ctx context.Context, vdb *vapi.VerticaDB, sc *vapi.Subcluster) error {
+// collectSubcluster will return the list of pods in a specific subcluster
+// that we need to collect facts for
+func (p *PodFacts) collectSubcluster(ctx context.Context, vdb *vapi.VerticaDB, sc *vapi.Subcluster) ([]podFactTask, error) {
 	sts := &appsv1.StatefulSet{}
 	maxStsSize := sc.Size
 	// Attempt to fetch the sts.  We continue even for 'not found' errors
 	// because we want to populate the missing pods into the pod facts.
 	if err := p.VRec.Client.Get(ctx, names.GenStsName(vdb, sc), sts); err != nil && !k8sErrors.IsNotFound(err) {
-		return fmt.Errorf("could not fetch statefulset for pod fact collection %s %w", sc.Name, err)
+		return nil, fmt.Errorf("could not fetch statefulset for pod fact collection %s %w", sc.Name, err)
 	} else if sts.Spec.Replicas != nil && *sts.Spec.Replicas > maxStsSize {
 		maxStsSize = *sts.Spec.Replicas
 	}
 
+	tasks := make([]podFactTask, 0, maxStsSize)
 	for i := int32(0); i < maxStsSize; i++ {
-		if err := p.collectPodByStsIndex(ctx, vdb, sc, sts, i); err != nil {
-			return err
+		tasks = append(tasks, podFactTask{sc: sc, sts: sts, podIndex: i})
+	}
+	return tasks, nil
+}
+
+// collectPods will collect facts about each of the given pods.  The pods are
+// collected in parallel, limited by the number of workers in the operator
+// config.  Each pod has its own timeout so that one hung exec call doesn't
+// stall the entire collection.
+func (p *PodFacts) collectPods(ctx context.Context, vdb *vapi.VerticaDB, tasks []podFactTask) error {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	sem := make(chan struct{}, p.getWorkerCount())
+	timeout := p.getPodTimeout()
+
+	for i := range tasks {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(task *podFactTask) {
+			defer func() { <-sem; wg.Done() }()
+			pf, err := p.collectPodWithTimeout(ctx, vdb, task, timeout)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			p.Detail[pf.name] = pf
+		}(&tasks[i])
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// collectPodWithTimeout will collect the facts for a single pod.  The
+// collection is aborted if it takes longer than the given timeout.
+func (p *PodFacts) collectPodWithTimeout(ctx context.Context, vdb *vapi.VerticaDB, task *podFactTask,
+	timeout time.Duration) (*PodFact, error) {
+	podCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	pf, err := p.genPodFactByStsIndex(podCtx, vdb, task.sc, task.sts, task.podIndex)
+	if err != nil {
+		if errors.Is(podCtx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("timed out after %s collecting pod facts for %s: %w",
+				timeout, names.GenPodName(vdb, task.sc, task.podIndex), err)
 		}
+		return nil, err
 	}
-	return nil
+	metrics.PodFactsPodCollectDuration.With(metrics.MakeVDBLabels(vdb)).Observe(time.Since(start).Seconds())
+	return pf, nil
+}
+
+// getWorkerCount returns the maximum number of pods to collect facts for at
+// the same time
+func (p *PodFacts) getWorkerCount() int {
+	if p.VRec.OpCfg.PodFactsWorkers <= 0 {
+		return opcfg.DefaultPodFactsWorkers
+	}
+	return p.VRec.OpCfg.PodFactsWorkers
+}
+
+// getPodTimeout returns the amount of time we allow to collect the facts for
+// a single pod
+func (p *PodFacts) getPodTimeout() time.Duration {
+	if p.VRec.OpCfg.PodFactsTimeout <= 0 {
+		return opcfg.DefaultPodFactsTimeout
+	}
+	return p.VRec.OpCfg.PodFactsTimeout
 }
 
 // collectPodByStsIndex will collect facts about a single pod in a subcluster
 func (p *PodFacts) collectPodByStsIndex(ctx context.Context, vdb *vapi.VerticaDB, sc *vapi.Subcluster,
 	sts *appsv1.StatefulSet, podIndex int32) error {
+	pf, err := p.genPodFactByStsIndex(ctx, vdb, sc, sts, podIndex)
+	if err != nil {
+		return err
+	}
+	p.Detail[pf.name] = pf
+	return nil
+}
+
+// genPodFactByStsIndex will build the PodFact for a single pod in a subcluster
+func (p *PodFacts) genPodFactByStsIndex(ctx context.Context, vdb *vapi.VerticaDB, sc *vapi.Subcluster,
+	sts *appsv1.StatefulSet, podIndex int32) (*PodFact, error) {
 	pf := PodFact{
 		name:           names.GenPodName(vdb, sc, podIndex),
 		subclusterName: sc.Name,
 		isPrimary:      sc.IsPrimary,
 		podIndex:       podIndex,
+		shutdown:       sc.Shutdown,
 	}
 	// It is possible for a pod to be managed by a parent sts but not yet exist.
 	// So, this has to be checked before we check for pod existence.
@@ -271,7 +388,7 @@ func (p *PodFacts) collectPodByStsIndex(ctx context.Context, vdb *vapi.VerticaDB
 
 	pod := &corev1.Pod{}
 	if err := p.VRec.Client.Get(ctx, pf.name, pod); err != nil && !k8sErrors.IsNotFound(err) {
-		return err
+		return nil, err
 	} else if err == nil {
 		// Treat not found errors as if the pod is not running.  We continue
 		// checking other elements.  There are certain states, such as
@@ -285,7 +402,7 @@ func (p *PodFacts) collectPodByStsIndex(ctx context.Context, vdb *vapi.VerticaDB
 		pf.dnsName = pod.Spec.Hostname + "." + pod.Spec.Subdomain
 		pf.podIP = pod.Status.PodIP
 		pf.isTransient, _ = strconv.ParseBool(pod.Labels[builder.SubclusterTransientLabel])
-		pf.pendingDelete = podIndex >= sc.Size
+		pf.pendingDelete = podIndex >= sc.Size || sc.Shutdown
 		pf.image = pod.Spec.Containers[ServerContainerIndex].Image
 		pf.hasDCTableAnnotations = p.checkDCTableAnnotations(pod)
 		pf.catalogPath = p.getCatalogPathFromPod(vdb, pod)
@@ -312,12 +429,10 @@ func (p *PodFacts) collectPodByStsIndex(ctx context.Context, vdb *vapi.VerticaDB
 			continue
 		}
 		if err := fn(ctx, vdb, &pf, &gatherState); err != nil {
-			return err
+			return nil, err
 		}
 	}
-
-	p.Detail[pf.name] = &pf
-	return nil
+	return &pf, nil
 }
 
 // runGather will generate a script to get multiple state information
@@ -810,6 +925,29 @@ func (p *PodFacts) findPodToRunVsql(allowReadOnly bool, scName string) (*PodFact
 	return &PodFact{}, false
 }
 
+// FindUpPodName returns the name of a pod that has an up, non read-only,
+// vertica node. This is intended for controllers outside of this package that
+// need to run something against a running database. If scName is non-empty,
+// only pods from that subcluster are considered.
+func (p *PodFacts) FindUpPodName(scName string) (types.NamespacedName, bool) {
+	pf, ok := p.findPodToRunVsql(false, scName)
+	return pf.name, ok
+}
+
+// makeInitiatorOptions returns the options that tell a vadmin.Dispatcher where
+// to run an operation from.  Along with the pod to run admintools from, we
+// include a pod with an up node so that the operation can be sent to its http
+// server.
+func (p *PodFacts) makeInitiatorOptions(atPod types.NamespacedName) vadmin.InitiatorOptions {
+	opts := vadmin.InitiatorOptions{Initiator: atPod}
+	if pf, ok := p.findFirstPodSorted(func(v *PodFact) bool {
+		return v.upNode && !v.readOnly && !v.pendingDelete
+	}); ok {
+		opts.UpHost = vadmin.Host{Pod: pf.name, IP: pf.podIP}
+	}
+	return opts
+}
+
 // findPodToRunAdmintoolsAny returns the name of the pod we will exec into into
 // order to run admintools.
 // Will return false for second parameter if no pod could be found.
@@ -872,6 +1010,10 @@ func (p *PodFacts) findRestartablePods(restartReadOnly, restartTransient bool) [
 		if !restartTransient && v.isTransient {
 			return false
 		}
+		// Nodes in a shut down subcluster are meant to stay down
+		if v.shutdown {
+			return false
+		}
 		return (!v.upNode || (restartReadOnly && v.readOnly)) && v.dbExists && v.isPodRunning && v.hasDCTableAnnotations
 	})
 }
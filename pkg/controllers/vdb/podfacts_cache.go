@@ -0,0 +1,206 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vdb
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/builder"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// podFactsCacheTTL is the longest we keep the pod facts of a VerticaDB.  Some
+// facts come from the database, such as whether a node is up or read-only,
+// its shard subscriptions and the depot.  Those can change without any watch
+// event, so we collect everything again once an entry is this old.
+const podFactsCacheTTL = 30 * time.Second
+
+// PodFactsCache keeps the pod facts of each VerticaDB between reconcile
+// iterations.  This lets a reconcile reuse the facts of the previous one when
+// nothing has changed, so that we don't have to exec into every pod.  Entries
+// are invalidated by watch events for the pods and statefulsets, by any
+// reconcile that leaves the pod facts stale, and when they reach
+// podFactsCacheTTL.
+type PodFactsCache struct {
+	mu      sync.Mutex
+	entries map[types.NamespacedName]*podFactsCacheEntry
+	// Each invalidation bumps the epoch of the VerticaDB.  A reconcile can only
+	// store its facts if no invalidation happened since it loaded from the
+	// cache.  This protects against a watch event that comes in while the
+	// reconcile is running.
+	epochs map[types.NamespacedName]uint64
+	// Returns the current time.  This is overridden in the tests.
+	now func() time.Time
+}
+
+type podFactsCacheEntry struct {
+	// The generation of the VerticaDB when the facts were collected.  Some
+	// facts are derived from the spec of the VerticaDB, so any change to it
+	// makes the facts stale.  Status updates don't change the generation, so
+	// the operator's own status writes don't throw the facts away.
+	generation int64
+	// The epoch of the VerticaDB when the facts were stored
+	epoch uint64
+	// The time the facts were collected.  Reusing the facts doesn't extend
+	// their life.
+	collectedAt time.Time
+	detail      PodFactDetail
+}
+
+// MakePodFactsCache will build an empty PodFactsCache
+func MakePodFactsCache() *PodFactsCache {
+	return &PodFactsCache{
+		entries: make(map[types.NamespacedName]*podFactsCacheEntry),
+		epochs:  make(map[types.NamespacedName]uint64),
+		now:     time.Now,
+	}
+}
+
+// Load will fill in the pod facts from the cache if we have an entry for the
+// VerticaDB that is still current.  It returns true if the cache was used.
+// If false, the pod facts are left as is and will be collected when needed.
+func (c *PodFactsCache) Load(vdb *vapi.VerticaDB, pfacts *PodFacts) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	nm := vdb.ExtractNamespacedName()
+	pfacts.cacheEpoch = c.epochs[nm]
+	entry, ok := c.entries[nm]
+	if !ok || entry.generation != vdb.Generation || entry.epoch != pfacts.cacheEpoch ||
+		c.now().Sub(entry.collectedAt) >= podFactsCacheTTL {
+		return false
+	}
+	pfacts.Detail = entry.detail.deepCopy()
+	pfacts.collectedGeneration = entry.generation
+	pfacts.collectedAt = entry.collectedAt
+	pfacts.NeedCollection = false
+	return true
+}
+
+// Store will save the pod facts at the end of a reconcile.  The facts are
+// only kept if they are still current -- they were collected for the latest
+// VerticaDB and nothing invalidated them since the reconcile started.
+func (c *PodFactsCache) Store(vdb *vapi.VerticaDB, pfacts *PodFacts) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	nm := vdb.ExtractNamespacedName()
+	if pfacts.NeedCollection || pfacts.collectedGeneration != vdb.Generation ||
+		pfacts.cacheEpoch != c.epochs[nm] {
+		delete(c.entries, nm)
+		return
+	}
+	c.entries[nm] = &podFactsCacheEntry{
+		generation:  pfacts.collectedGeneration,
+		epoch:       pfacts.cacheEpoch,
+		collectedAt: pfacts.collectedAt,
+		detail:      pfacts.Detail.deepCopy(),
+	}
+}
+
+// Invalidate will drop the cached pod facts for a VerticaDB.  The next
+// reconcile will collect the facts again.
+func (c *PodFactsCache) Invalidate(nm types.NamespacedName) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.epochs[nm]++
+	delete(c.entries, nm)
+}
+
+// Remove will drop everything we track for a VerticaDB that was deleted
+func (c *PodFactsCache) Remove(nm types.NamespacedName) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.epochs, nm)
+	delete(c.entries, nm)
+}
+
+// invalidateForObject is a map function for the watches of objects that
+// affect the pod facts.  It invalidates the cache of the VerticaDB that owns
+// the object.  No reconcile is requested as the statefulset watch already
+// takes care of that.
+func (c *PodFactsCache) invalidateForObject(obj client.Object) []reconcile.Request {
+	labels := obj.GetLabels()
+	if labels[builder.ManagedByLabel] != builder.OperatorName || labels[builder.VDBInstanceLabel] == "" {
+		return nil
+	}
+	c.Invalidate(types.NamespacedName{Namespace: obj.GetNamespace(), Name: labels[builder.VDBInstanceLabel]})
+	return nil
+}
+
+// podFactsChangedPredicate filters out pod updates that can't change any of
+// the pod facts.  The operator updates the labels of the pods as part of
+// client routing, so we don't want those to invalidate the cache.
+func podFactsChangedPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldPod, ok := e.ObjectOld.(*corev1.Pod)
+			if !ok {
+				return true
+			}
+			newPod, ok := e.ObjectNew.(*corev1.Pod)
+			if !ok {
+				return true
+			}
+			return !reflect.DeepEqual(oldPod.Status, newPod.Status) ||
+				!reflect.DeepEqual(oldPod.Annotations, newPod.Annotations) ||
+				!reflect.DeepEqual(oldPod.Spec, newPod.Spec) ||
+				!oldPod.DeletionTimestamp.Equal(newPod.DeletionTimestamp)
+		},
+	}
+}
+
+// deepCopy returns a copy of the pod facts that doesn't share any memory with
+// the original
+func (p PodFactDetail) deepCopy() PodFactDetail {
+	cpy := make(PodFactDetail, len(p))
+	for nm, pf := range p {
+		pfCpy := *pf
+		pfCpy.dirExists = copyBoolMap(pf.dirExists)
+		pfCpy.fileExists = copyBoolMap(pf.fileExists)
+		cpy[nm] = &pfCpy
+	}
+	return cpy
+}
+
+func copyBoolMap(m map[string]bool) map[string]bool {
+	if m == nil {
+		return nil
+	}
+	cpy := make(map[string]bool, len(m))
+	for k, v := range m {
+		cpy[k] = v
+	}
+	return cpy
+}
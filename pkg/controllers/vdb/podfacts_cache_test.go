@@ -0,0 +1,150 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vdb
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/builder"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("podfacts_cache", func() {
+	// makeCollectedPodFacts returns pod facts as if they were collected for
+	// the given vdb
+	makeCollectedPodFacts := func(vdb *vapi.VerticaDB) PodFacts {
+		pfacts := MakePodFacts(vdbRec, &cmds.FakePodRunner{})
+		nm := names.GenPodName(vdb, &vdb.Spec.Subclusters[0], 0)
+		pfacts.Detail[nm] = &PodFact{name: nm, upNode: true, fileExists: map[string]bool{"f": true}}
+		pfacts.collectedGeneration = vdb.Generation
+		pfacts.collectedAt = time.Now()
+		pfacts.NeedCollection = false
+		return pfacts
+	}
+
+	It("should reuse the pod facts if nothing changed", func() {
+		vdb := vapi.MakeVDB()
+		cache := MakePodFactsCache()
+
+		pfacts := MakePodFacts(vdbRec, &cmds.FakePodRunner{})
+		Expect(cache.Load(vdb, &pfacts)).Should(BeFalse())
+		Expect(pfacts.NeedCollection).Should(BeTrue())
+
+		pfacts = makeCollectedPodFacts(vdb)
+		cache.Store(vdb, &pfacts)
+
+		pfacts2 := MakePodFacts(vdbRec, &cmds.FakePodRunner{})
+		Expect(cache.Load(vdb, &pfacts2)).Should(BeTrue())
+		Expect(pfacts2.NeedCollection).Should(BeFalse())
+		nm := names.GenPodName(vdb, &vdb.Spec.Subclusters[0], 0)
+		Expect(pfacts2.Detail).Should(HaveKey(nm))
+		Expect(pfacts2.Detail[nm].upNode).Should(BeTrue())
+
+		// The loaded facts must not share memory with the cache
+		pfacts2.Detail[nm].fileExists["f"] = false
+		pfacts3 := MakePodFacts(vdbRec, &cmds.FakePodRunner{})
+		Expect(cache.Load(vdb, &pfacts3)).Should(BeTrue())
+		Expect(pfacts3.Detail[nm].fileExists["f"]).Should(BeTrue())
+	})
+
+	It("should not reuse the pod facts if the vdb spec changed", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Generation = 1
+		cache := MakePodFactsCache()
+		pfacts := makeCollectedPodFacts(vdb)
+		cache.Store(vdb, &pfacts)
+
+		// A status update changes the resourceVersion but not the generation
+		vdb.ResourceVersion = "2"
+		pfacts2 := MakePodFacts(vdbRec, &cmds.FakePodRunner{})
+		Expect(cache.Load(vdb, &pfacts2)).Should(BeTrue())
+
+		vdb.Generation = 2
+		pfacts3 := MakePodFacts(vdbRec, &cmds.FakePodRunner{})
+		Expect(cache.Load(vdb, &pfacts3)).Should(BeFalse())
+	})
+
+	It("should not reuse the pod facts once they are older than the TTL", func() {
+		vdb := vapi.MakeVDB()
+		cache := MakePodFactsCache()
+		now := time.Now()
+		cache.now = func() time.Time { return now }
+		pfacts := makeCollectedPodFacts(vdb)
+		pfacts.collectedAt = now
+		cache.Store(vdb, &pfacts)
+
+		// Storing the facts that were loaded from the cache must not extend
+		// their life.
+		now = now.Add(podFactsCacheTTL / 2)
+		pfacts2 := MakePodFacts(vdbRec, &cmds.FakePodRunner{})
+		Expect(cache.Load(vdb, &pfacts2)).Should(BeTrue())
+		cache.Store(vdb, &pfacts2)
+
+		now = now.Add(podFactsCacheTTL / 2)
+		pfacts3 := MakePodFacts(vdbRec, &cmds.FakePodRunner{})
+		Expect(cache.Load(vdb, &pfacts3)).Should(BeFalse())
+		Expect(pfacts3.NeedCollection).Should(BeTrue())
+	})
+
+	It("should not store pod facts that were invalidated", func() {
+		vdb := vapi.MakeVDB()
+		cache := MakePodFactsCache()
+		pfacts := makeCollectedPodFacts(vdb)
+		pfacts.Invalidate()
+		cache.Store(vdb, &pfacts)
+		pfacts2 := MakePodFacts(vdbRec, &cmds.FakePodRunner{})
+		Expect(cache.Load(vdb, &pfacts2)).Should(BeFalse())
+	})
+
+	It("should not store pod facts if a watch event came in during the reconcile", func() {
+		vdb := vapi.MakeVDB()
+		cache := MakePodFactsCache()
+		pfacts := MakePodFacts(vdbRec, &cmds.FakePodRunner{})
+		Expect(cache.Load(vdb, &pfacts)).Should(BeFalse())
+		collected := makeCollectedPodFacts(vdb)
+		collected.cacheEpoch = pfacts.cacheEpoch
+
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pod",
+				Namespace: vdb.Namespace,
+				Labels:    builder.MakeOperatorLabels(vdb),
+			},
+		}
+		Expect(cache.invalidateForObject(pod)).Should(BeEmpty())
+		cache.Store(vdb, &collected)
+
+		pfacts2 := MakePodFacts(vdbRec, &cmds.FakePodRunner{})
+		Expect(cache.Load(vdb, &pfacts2)).Should(BeFalse())
+	})
+
+	It("should be a no-op if the cache isn't set", func() {
+		vdb := vapi.MakeVDB()
+		var cache *PodFactsCache
+		pfacts := makeCollectedPodFacts(vdb)
+		cache.Store(vdb, &pfacts)
+		cache.Invalidate(vdb.ExtractNamespacedName())
+		pfacts2 := MakePodFacts(vdbRec, &cmds.FakePodRunner{})
+		Expect(cache.Load(vdb, &pfacts2)).Should(BeFalse())
+		Expect(pfacts2.NeedCollection).Should(BeTrue())
+	})
+})
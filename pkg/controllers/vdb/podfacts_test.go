@@ -18,6 +18,7 @@ package vdb
 import (
 	"context"
 	"errors"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -392,4 +393,39 @@ var _ = Describe("podfacts", func() {
 		Expect(ok).Should(BeTrue())
 		Expect(p.dnsName).Should(Equal("p2"))
 	})
+
+	It("should collect facts for all pods when using multiple workers", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.Subclusters[0].Size = 3
+		vdb.Spec.Subclusters = append(vdb.Spec.Subclusters, vapi.Subcluster{Name: "sc2", Size: 2})
+		test.CreateVDB(ctx, k8sClient, vdb)
+		defer test.DeleteVDB(ctx, k8sClient, vdb)
+
+		fpr := &cmds.FakePodRunner{}
+		pfacts := MakePodFacts(vdbRec, fpr)
+		Expect(pfacts.Collect(ctx, vdb)).Should(Succeed())
+		Expect(len(pfacts.Detail)).Should(Equal(5))
+		for i := range vdb.Spec.Subclusters {
+			sc := &vdb.Spec.Subclusters[i]
+			for j := int32(0); j < sc.Size; j++ {
+				Expect(pfacts.Detail).Should(HaveKey(names.GenPodName(vdb, sc, j)))
+			}
+		}
+	})
+
+	It("should fail the collection if a pod takes longer than the timeout", func() {
+		vdb := vapi.MakeVDB()
+		sc := &vdb.Spec.Subclusters[0]
+		fpr := &cmds.FakePodRunner{}
+		pfacts := MakePodFacts(vdbRec, fpr)
+		pfacts.OverrideFunc = func(ctx context.Context, vdb *vapi.VerticaDB, pf *PodFact, gs *GatherState) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		task := podFactTask{sc: sc, sts: &appsv1.StatefulSet{}, podIndex: 0}
+		_, err := pfacts.collectPodWithTimeout(ctx, vdb, &task, time.Millisecond*10)
+		Expect(err).ShouldNot(Succeed())
+		Expect(err.Error()).Should(ContainSubstring("timed out"))
+		Expect(err.Error()).Should(ContainSubstring(names.GenPodName(vdb, sc, 0).Name))
+	})
 })
@@ -68,6 +68,10 @@ func (s *RebalanceShardsReconciler) Reconcile(ctx context.Context, req *ctrl.Req
 		return ctrl.Result{}, nil
 	}
 
+	if wait, err := waitForMaintenanceWindow(ctx, s.VRec, s.Log, s.Vdb, "rebalance the shards"); wait || err != nil {
+		return ctrl.Result{}, err
+	}
+
 	atPod, ok := s.PFacts.findPodToRunVsql(false, "")
 	if !ok {
 		s.Log.Info("No pod found to run vsql from. Requeue reconciliation.")
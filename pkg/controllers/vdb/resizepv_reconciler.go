@@ -96,6 +96,9 @@ func (r *ResizePVReconcile) reconcilePod(ctx context.Context, pf *PodFact) (ctrl
 func (r *ResizePVReconcile) reconcilePvc(ctx context.Context, pf *PodFact, pvc *corev1.PersistentVolumeClaim) (ctrl.Result, error) {
 	// Resize is necessary if the PVC storage is smaller than the size in the vdb
 	if pvc.Spec.Resources.Requests.Storage().Cmp(r.Vdb.Spec.Local.RequestSize) < 0 {
+		if wait, err := waitForMaintenanceWindow(ctx, r.VRec, r.VRec.Log, r.Vdb, "resize the persistent volumes"); wait || err != nil {
+			return ctrl.Result{}, err
+		}
 		return r.updatePVC(ctx, pvc)
 	}
 
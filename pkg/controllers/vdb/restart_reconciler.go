@@ -33,6 +33,7 @@ import (
 	"github.com/vertica/vertica-kubernetes/pkg/mgmterrors"
 	"github.com/vertica/vertica-kubernetes/pkg/names"
 	"github.com/vertica/vertica-kubernetes/pkg/paths"
+	"github.com/vertica/vertica-kubernetes/pkg/vadmin"
 	"github.com/vertica/vertica-kubernetes/pkg/vdbstatus"
 	corev1 "k8s.io/api/core/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
@@ -43,8 +44,6 @@ import (
 const (
 	// Amount of time to wait after a restart failover before doing another requeue.
 	RequeueWaitTimeInSeconds = 10
-	// The name of the IP map file that is used by re_ip.  re_ip is only ever used if the entire cluster is down.
-	AdminToolsMapFile = "/opt/vertica/config/ipMap.txt"
 	// Constant for an up node, this is taken from the STATE colume in NODES table
 	StateUp = "UP"
 	// Percent of livenessProbe time to wait when requeuing due to waiting on
@@ -66,6 +65,7 @@ type RestartReconciler struct {
 	ATPod           types.NamespacedName // The pod that we run admintools from
 	RestartReadOnly bool                 // Whether to restart nodes that are in read-only mode
 	EVLogr          mgmterrors.EventLogger
+	Dispatcher      vadmin.Dispatcher
 }
 
 // MakeRestartReconciler will build a RestartReconciler object
@@ -78,7 +78,9 @@ func MakeRestartReconciler(vdbrecon *VerticaDBReconciler, log logr.Logger,
 		PRunner:         prunner,
 		PFacts:          pfacts,
 		RestartReadOnly: restartReadOnly,
-		EVLogr:          mgmterrors.MakeATErrors(vdbrecon, vdb, events.MgmtFailed)}
+		EVLogr:          mgmterrors.MakeATErrors(vdbrecon, vdbrecon.Client, vdb, events.MgmtFailed),
+		Dispatcher:      vdbrecon.makeDispatcher(log, vdb, prunner),
+	}
 }
 
 // Reconcile will ensure each pod is UP in the vertica sense.
@@ -291,10 +293,7 @@ func (r *RestartReconciler) restartPods(ctx context.Context, pods []*PodFact) (c
 
 	debugDumpAdmintoolsConf(ctx, r.PRunner, r.ATPod)
 
-	vnodeList := genRestartVNodeList(downPods)
-	ipList := genRestartIPList(downPods)
-	cmd := r.genRestartNodeCmd(vnodeList, ipList)
-	if res, err := r.execRestartPods(ctx, downPods, cmd); verrors.IsReconcileAborted(res, err) {
+	if res, err := r.execRestartPods(ctx, downPods); verrors.IsReconcileAborted(res, err) {
 		return res, err
 	}
 
@@ -338,75 +337,51 @@ func (r *RestartReconciler) removePodsWithClusterUpState(ctx context.Context, po
 // that this may report a node is UP but not yet accepting connections because
 // it could doing the initialization phase.
 func (r *RestartReconciler) fetchClusterNodeStatus(ctx context.Context) (map[string]string, ctrl.Result, error) {
-	cmd := []string{
-		"-t", "list_allnodes",
-	}
-	stdout, _, err := r.PRunner.ExecAdmintools(ctx, r.ATPod, names.ServerContainer, cmd...)
+	opts := vadmin.FetchNodeStateOptions{InitiatorOptions: r.PFacts.makeInitiatorOptions(r.ATPod)}
+	stateMap, stdout, err := r.Dispatcher.FetchNodeState(ctx, &opts)
 	if err != nil {
-		res, err2 := r.EVLogr.LogFailure("list_allnodes", stdout, err)
+		res, err2 := r.EVLogr.LogFailure(ctx, "list_allnodes", stdout, err)
 		return nil, res, err2
 	}
-
-	return r.parseClusterNodeStatus(stdout), ctrl.Result{}, nil
+	return stateMap, ctrl.Result{}, r.EVLogr.LogSuccess(ctx, "list_allnodes")
 }
 
-// parseClusterNodeStatus will parse the output from a AT -t list_allnodes call
-func (r *RestartReconciler) parseClusterNodeStatus(stdout string) map[string]string {
-	stateMap := map[string]string{}
-	lines := strings.Split(stdout, "\n")
-	const ColHeaderCount = 2
-	if len(lines) <= ColHeaderCount {
-		// Nothing to parse, return empty map
-		return stateMap
-	}
-	// We skip the first two lines because they are for the header of the
-	// output. The output that we are omitting looks like this:
-	//  Node          | Host       | State | Version                 | DB
-	// ---------------+------------+-------+-------------------------+----
-	for _, line := range lines[ColHeaderCount:] {
-		// Line is something like this:
-		//   v_db_node0001 | 10.244.1.6 | UP    | vertica-11.0.0.20210309 | db
-		cols := strings.Split(line, "|")
-		const ListNodesColCount = 4
-		if len(cols) < ListNodesColCount {
-			continue
-		}
-		vnode := strings.Trim(cols[0], " ")
-		state := strings.Trim(cols[2], " ")
-		stateMap[vnode] = state
-	}
-	return stateMap
-}
-
-// execRestartPods will execute the AT command and event recording for restart pods.
-func (r *RestartReconciler) execRestartPods(ctx context.Context, downPods []*PodFact, cmd []string) (ctrl.Result, error) {
+// execRestartPods will restart the pods and handle the event recording.
+func (r *RestartReconciler) execRestartPods(ctx context.Context, downPods []*PodFact) (ctrl.Result, error) {
 	podNames := make([]string, 0, len(downPods))
 	for _, pods := range downPods {
 		podNames = append(podNames, pods.name.Name)
 	}
 
 	r.VRec.Eventf(r.Vdb, corev1.EventTypeNormal, events.NodeRestartStarted,
-		"Calling 'admintools -t restart_node' to restart the following pods: %s", strings.Join(podNames, ", "))
+		"Calling '%s' to restart the following pods: %s", r.Dispatcher.DescribeOp(vadmin.OpRestartNode),
+		strings.Join(podNames, ", "))
 	start := time.Now()
 	labels := metrics.MakeVDBLabels(r.Vdb)
-	stdout, _, err := r.PRunner.ExecAdmintools(ctx, r.ATPod, names.ServerContainer, cmd...)
+	opts := vadmin.RestartNodeOptions{
+		InitiatorOptions: r.PFacts.makeInitiatorOptions(r.ATPod),
+		Nodes:            genRestartNodeList(downPods),
+	}
+	stdout, err := r.Dispatcher.RestartNode(ctx, &opts)
 	elapsedTimeInSeconds := time.Since(start).Seconds()
 	metrics.NodesRestartDuration.With(labels).Observe(elapsedTimeInSeconds)
 	metrics.NodesRestartAttempt.With(labels).Inc()
 	if err != nil {
 		metrics.NodesRestartFailed.With(labels).Inc()
-		return r.EVLogr.LogFailure("restart_node", stdout, err)
+		return r.EVLogr.LogFailure(ctx, "restart_node", stdout, err)
+	}
+	if err := r.EVLogr.LogSuccess(ctx, "restart_node"); err != nil {
+		return ctrl.Result{}, err
 	}
 	r.VRec.Eventf(r.Vdb, corev1.EventTypeNormal, events.NodeRestartSucceeded,
-		"Successfully called 'admintools -t restart_node' and it took %ds", int(elapsedTimeInSeconds))
+		"Successfully called '%s' and it took %ds", r.Dispatcher.DescribeOp(vadmin.OpRestartNode),
+		int(elapsedTimeInSeconds))
 	return ctrl.Result{}, nil
 }
 
-// reipNodes will run admintools -t re_ip against a set of pods.
+// reipNodes will run re_ip against a set of pods.
 // If it detects that no IPs are changing, then no re_ip is done.
 func (r *RestartReconciler) reipNodes(ctx context.Context, pods []*PodFact) (ctrl.Result, error) {
-	var mapFileContents []string
-
 	// We always use the compat21 nodes when generating the IP map.  We cannot
 	// use the vnode because they are only set _after_ a node is added to a DB.
 	// ReIP can be dealing with a mix -- some nodes that have been added to the
@@ -416,7 +391,7 @@ func (r *RestartReconciler) reipNodes(ctx context.Context, pods []*PodFact) (ctr
 		return ctrl.Result{}, err
 	}
 
-	mapFileContents, ipChanging, ok := r.genMapFile(oldIPs, pods)
+	reIPHosts, ipChanging, ok := r.genMapFile(oldIPs, pods)
 	if !ok {
 		r.Log.Info("Could not generate the map file contents from nodes.  Requeue reconciliation.")
 		return ctrl.Result{Requeue: true}, nil
@@ -426,19 +401,17 @@ func (r *RestartReconciler) reipNodes(ctx context.Context, pods []*PodFact) (ctr
 		return ctrl.Result{}, nil
 	}
 
-	cmd := genMapFileUploadCmd(mapFileContents)
-	if _, _, err := r.PRunner.ExecInPod(ctx, r.ATPod, names.ServerContainer, cmd...); err != nil {
-		return ctrl.Result{}, err
-	}
-
 	// Prior to calling re_ip, dump out the state of admintools.conf for PD purposes
 	debugDumpAdmintoolsConf(ctx, r.PRunner, r.ATPod)
 
-	cmd = r.genReIPCommand()
-	if _, _, err := r.PRunner.ExecAdmintools(ctx, r.ATPod, names.ServerContainer, cmd...); err != nil {
+	opts := vadmin.ReIPOptions{
+		InitiatorOptions: r.PFacts.makeInitiatorOptions(r.ATPod),
+		Hosts:            reIPHosts,
+	}
+	if _, err := r.Dispatcher.ReIP(ctx, &opts); err != nil {
 		// Log an event as failure to re_ip means we won't be able to bring up the database.
-		r.VRec.Event(r.Vdb, corev1.EventTypeWarning, events.ReipFailed,
-			"Attempt to run 'admintools -t re_ip' failed")
+		r.VRec.Eventf(r.Vdb, corev1.EventTypeWarning, events.ReipFailed,
+			"Attempt to run '%s' failed", r.Dispatcher.DescribeOp(vadmin.OpReIP))
 		return ctrl.Result{}, err
 	}
 
@@ -448,34 +421,41 @@ func (r *RestartReconciler) reipNodes(ctx context.Context, pods []*PodFact) (ctr
 	return ctrl.Result{}, nil
 }
 
-// restartCluster will call admintools -t start_db
+// restartCluster will call start_db
 // It is assumed that the cluster has already run re_ip.
 func (r *RestartReconciler) restartCluster(ctx context.Context, downPods []*PodFact) (ctrl.Result, error) {
-	cmd := r.genStartDBCommand(downPods)
-	r.VRec.Event(r.Vdb, corev1.EventTypeNormal, events.ClusterRestartStarted,
-		"Calling 'admintools -t start_db' to restart the cluster")
+	opts := vadmin.StartDBOptions{
+		InitiatorOptions: r.PFacts.makeInitiatorOptions(r.ATPod),
+		Hosts:            genRestartIPList(downPods),
+	}
+	r.VRec.Eventf(r.Vdb, corev1.EventTypeNormal, events.ClusterRestartStarted,
+		"Calling '%s' to restart the cluster", r.Dispatcher.DescribeOp(vadmin.OpStartDB))
 	start := time.Now()
 	labels := metrics.MakeVDBLabels(r.Vdb)
-	stdout, _, err := r.PRunner.ExecAdmintools(ctx, r.ATPod, names.ServerContainer, cmd...)
+	stdout, err := r.Dispatcher.StartDB(ctx, &opts)
 	elapsedTimeInSeconds := time.Since(start).Seconds()
 	metrics.ClusterRestartDuration.With(labels).Observe(elapsedTimeInSeconds)
 	metrics.ClusterRestartAttempt.With(labels).Inc()
 	if err != nil {
 		metrics.ClusterRestartFailure.With(labels).Inc()
-		return r.EVLogr.LogFailure("start_db", stdout, err)
+		return r.EVLogr.LogFailure(ctx, "start_db", stdout, err)
+	}
+	if err := r.EVLogr.LogSuccess(ctx, "start_db"); err != nil {
+		return ctrl.Result{}, err
 	}
 	r.VRec.Eventf(r.Vdb, corev1.EventTypeNormal, events.ClusterRestartSucceeded,
-		"Successfully called 'admintools -t start_db' and it took %ds", int(elapsedTimeInSeconds))
+		"Successfully called '%s' and it took %ds", r.Dispatcher.DescribeOp(vadmin.OpStartDB),
+		int(elapsedTimeInSeconds))
 	return ctrl.Result{}, err
 }
 
-// genRestartVNodeList returns the vnodes of all of the hosts in downPods
-func genRestartVNodeList(downPods []*PodFact) []string {
-	hostList := []string{}
+// genRestartNodeList returns the vnode and IP of all of the hosts in downPods
+func genRestartNodeList(downPods []*PodFact) []vadmin.Node {
+	nodeList := []vadmin.Node{}
 	for _, v := range downPods {
-		hostList = append(hostList, v.vnodeName)
+		nodeList = append(nodeList, vadmin.Node{VNode: v.vnodeName, IP: v.podIP})
 	}
-	return hostList
+	return nodeList
 }
 
 // genRestartIPList returns the IPs of all of the hosts in downPods
@@ -620,21 +600,6 @@ func (r *RestartReconciler) isStartupProbeActive(ctx context.Context, nm types.N
 	return true, nil
 }
 
-// genRestartNodeCmd returns the command to run to restart a pod
-func (r *RestartReconciler) genRestartNodeCmd(vnodeList, ipList []string) []string {
-	cmd := []string{
-		"-t", "restart_node",
-		"--database=" + r.Vdb.Spec.DBName,
-		"--hosts=" + strings.Join(vnodeList, ","),
-		"--new-host-ips=" + strings.Join(ipList, ","),
-		"--noprompt",
-	}
-	if r.Vdb.Spec.RestartTimeout != 0 {
-		cmd = append(cmd, fmt.Sprintf("--timeout=%d", r.Vdb.Spec.RestartTimeout))
-	}
-	return cmd
-}
-
 // parseNodesFromAdmintoolConf will parse out the vertica node and IP from admintools.conf output.
 // The nodeText passed in is taken from a grep output of the node columns. As
 // such, multiple lines are concatenated together with '\n'.
@@ -676,10 +641,10 @@ func (r *RestartReconciler) genGrepNodeCmd() []string {
 // genMapFile generates the map file used by re_ip
 // The list of old IPs are passed in. We combine that with the new IPs in the
 // podfacts to generate the map file. The map file is returned as a list of
-// strings. Its format is what is expected by admintools -t re_ip.
+// hosts with their old and new IP.
 func (r *RestartReconciler) genMapFile(
-	oldIPs verticaIPLookup, pods []*PodFact) (mapContents []string, ipChanging, ok bool) {
-	mapContents = []string{}
+	oldIPs verticaIPLookup, pods []*PodFact) (mapContents []vadmin.ReIPHost, ipChanging, ok bool) {
+	mapContents = []vadmin.ReIPHost{}
 	ipChanging = false
 	ok = true
 
@@ -707,63 +672,11 @@ func (r *RestartReconciler) genMapFile(
 		if oldIP != pod.podIP {
 			ipChanging = true
 		}
-		mapContents = append(mapContents, fmt.Sprintf("%s %s", oldIP, pod.podIP))
+		mapContents = append(mapContents, vadmin.ReIPHost{Compat21Node: nodeName, OldIP: oldIP, NewIP: pod.podIP})
 	}
 	return mapContents, ipChanging, ok
 }
 
-// genMapFileUploadCmd returns the command to run to upload the map file
-func genMapFileUploadCmd(mapFileContents []string) []string {
-	return []string{
-		"bash", "-c", "cat > " + AdminToolsMapFile + "<<< '" + strings.Join(mapFileContents, "\n") + "'",
-	}
-}
-
-// genReIPCommand will return the command to run for the re_ip command
-func (r *RestartReconciler) genReIPCommand() []string {
-	cmd := []string{
-		"-t", "re_ip",
-		"--file=" + AdminToolsMapFile,
-		"--noprompt",
-	}
-
-	// In 11.1, we added a --force option to re_ip to allow us to run it while
-	// some nodes are up.  This was done to support doing a reip while there are
-	// read-only secondary nodes.
-	vinf, ok := r.Vdb.MakeVersionInfo()
-	if ok && vinf.IsEqualOrNewer(vapi.ReIPAllowedWithUpNodesVersion) {
-		cmd = append(cmd, "--force")
-	}
-
-	return cmd
-}
-
-// genStartDBCommand will return the command for start_db
-func (r *RestartReconciler) genStartDBCommand(downPods []*PodFact) []string {
-	cmd := []string{
-		"-t", "start_db",
-		"--database=" + r.Vdb.Spec.DBName,
-		"--noprompt",
-	}
-	if r.Vdb.Spec.IgnoreClusterLease {
-		cmd = append(cmd, "--ignore-cluster-lease")
-	}
-	if r.Vdb.Spec.RestartTimeout != 0 {
-		cmd = append(cmd, fmt.Sprintf("--timeout=%d", r.Vdb.Spec.RestartTimeout))
-	}
-
-	// In all versions that we support we can include a list of hosts to start.
-	// This parameter becomes important for online upgrade as we use this to
-	// start the primaries while the secondary are in read-only.
-	hostNames := []string{}
-	for _, pod := range downPods {
-		hostNames = append(hostNames, pod.podIP)
-	}
-	cmd = append(cmd, "--hosts", strings.Join(hostNames, ","))
-
-	return cmd
-}
-
 // setATPod will set r.ATPod if not already set.
 // Caller can indicate whether there is a requirement that it must be run from a
 // pod that is current not running the vertica daemon.
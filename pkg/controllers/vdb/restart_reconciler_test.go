@@ -25,8 +25,11 @@ import (
 	. "github.com/onsi/gomega"
 	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
 	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/events"
 	"github.com/vertica/vertica-kubernetes/pkg/names"
 	"github.com/vertica/vertica-kubernetes/pkg/test"
+	"github.com/vertica/vertica-kubernetes/pkg/vadmin"
+	"github.com/vertica/vertica-kubernetes/pkg/vdbstatus"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -80,6 +83,26 @@ var _ = Describe("restart_reconciler", func() {
 		))
 	})
 
+	It("should clear the last failure of restart_node once it succeeds", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.Subclusters[0].Size = 2
+		test.CreateVDB(ctx, k8sClient, vdb)
+		defer test.DeleteVDB(ctx, k8sClient, vdb)
+		sc := &vdb.Spec.Subclusters[0]
+		test.CreatePods(ctx, k8sClient, vdb, test.AllPodsRunning)
+		defer test.DeletePods(ctx, k8sClient, vdb)
+		Expect(vdbstatus.RecordFailure(ctx, k8sClient, vdb,
+			&vapi.MgmtFailure{Command: "restart_node", Reason: events.MgmtFailed})).Should(Succeed())
+
+		fpr := &cmds.FakePodRunner{}
+		pfacts := createPodFactsWithRestartNeeded(ctx, vdb, sc, fpr, []int32{1}, PodNotReadOnly)
+		r := MakeRestartReconciler(vdbRec, logger, vdb, fpr, pfacts, RestartProcessReadOnly)
+		Expect(r.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+		fetchVdb := &vapi.VerticaDB{}
+		Expect(k8sClient.Get(ctx, vapi.MakeVDBName(), fetchVdb)).Should(Succeed())
+		Expect(fetchVdb.Status.LastFailure).Should(BeNil())
+	})
+
 	It("should not call restart_node when autoRestartVertica is false", func() {
 		vdb := vapi.MakeVDB()
 		vdb.Spec.AutoRestartVertica = false
@@ -189,9 +212,9 @@ var _ = Describe("restart_reconciler", func() {
 		Expect(ok).Should(BeTrue())
 		Expect(ipChanging).Should(BeTrue())
 		Expect(mapFileContents).Should(ContainElements(
-			fmt.Sprintf("%s %s", Node1OldIP, test.FakeIPForPod(0, 0)),
-			fmt.Sprintf("%s %s", Node2OldIP, test.FakeIPForPod(0, 1)),
-			fmt.Sprintf("%s %s", Node3OldIP, test.FakeIPForPod(0, 2)),
+			vadmin.ReIPHost{Compat21Node: "node0001", OldIP: Node1OldIP, NewIP: test.FakeIPForPod(0, 0)},
+			vadmin.ReIPHost{Compat21Node: "node0002", OldIP: Node2OldIP, NewIP: test.FakeIPForPod(0, 1)},
+			vadmin.ReIPHost{Compat21Node: "node0003", OldIP: Node3OldIP, NewIP: test.FakeIPForPod(0, 2)},
 		))
 	})
 
@@ -243,7 +266,7 @@ var _ = Describe("restart_reconciler", func() {
 		Expect(ipChanging).Should(BeTrue())
 		Expect(len(mapFileContents)).Should(Equal(1))
 		Expect(mapFileContents).Should(ContainElement(
-			"10.10.2.1 " + test.FakeIPForPod(0, 0),
+			vadmin.ReIPHost{Compat21Node: "node0001", OldIP: "10.10.2.1", NewIP: test.FakeIPForPod(0, 0)},
 		))
 	})
 
@@ -271,9 +294,9 @@ var _ = Describe("restart_reconciler", func() {
 		Expect(ok).Should(BeTrue())
 		Expect(ipChanging).Should(BeTrue())
 		Expect(mapFileContents).Should(ContainElements(
-			"10.10.2.1 "+test.FakeIPForPod(0, 0),
-			"10.10.2.2 "+test.FakeIPForPod(0, 1),
-			"10.10.2.3 "+test.FakeIPForPod(0, 2),
+			vadmin.ReIPHost{Compat21Node: "node0001", OldIP: "10.10.2.1", NewIP: test.FakeIPForPod(0, 0)},
+			vadmin.ReIPHost{Compat21Node: "node0002", OldIP: "10.10.2.2", NewIP: test.FakeIPForPod(0, 1)},
+			vadmin.ReIPHost{Compat21Node: "node0003", OldIP: "10.10.2.3", NewIP: test.FakeIPForPod(0, 2)},
 		))
 	})
 
@@ -337,29 +360,6 @@ var _ = Describe("restart_reconciler", func() {
 		Expect(len(restart)).Should(Equal(1))
 	})
 
-	It("should parse the list_allnodes output", func() {
-		vdb := vapi.MakeVDB()
-		vdb.Spec.DBName = "d"
-
-		fpr := &cmds.FakePodRunner{Results: make(cmds.CmdResults)}
-		pfacts := MakePodFacts(vdbRec, fpr)
-		act := MakeRestartReconciler(vdbRec, logger, vdb, fpr, &pfacts, RestartProcessReadOnly)
-		r := act.(*RestartReconciler)
-		stateMap := r.parseClusterNodeStatus(
-			" Node          | Host       | State | Version                 | DB \n" +
-				"---------------+------------+-------+-------------------------+----\n" +
-				" v_d_node0001 | 10.244.1.6 | UP    | vertica-11.0.0.20210309 | db \n" +
-				" v_d_node0002 | 10.244.1.7 | DOWN  | vertica-11.0.0.20210309 | db \n" +
-				"\n",
-		)
-		n1, ok := stateMap["v_d_node0001"]
-		Expect(ok).Should(BeTrue())
-		Expect(n1).Should(Equal("UP"))
-		n2, ok := stateMap["v_d_node0002"]
-		Expect(ok).Should(BeTrue())
-		Expect(n2).Should(Equal("DOWN"))
-	})
-
 	It("should do full cluster restart if none of the nodes are UP and not read-only", func() {
 		vdb := vapi.MakeVDB()
 		vdb.Spec.Subclusters[0].Size = 3
@@ -577,30 +577,6 @@ var _ = Describe("restart_reconciler", func() {
 		Expect(len(restart)).Should(Equal(0))
 	})
 
-	It("should use --force option in reip if on version that supports it", func() {
-		vdb := vapi.MakeVDB()
-		fpr := &cmds.FakePodRunner{}
-		act := MakeRestartReconciler(vdbRec, logger, vdb, fpr, &PodFacts{}, RestartProcessReadOnly)
-		r := act.(*RestartReconciler)
-		vdb.Annotations[vapi.VersionAnnotation] = vapi.MinimumVersion
-		Expect(r.genReIPCommand()).ShouldNot(ContainElement("--force"))
-		vdb.Annotations[vapi.VersionAnnotation] = vapi.ReIPAllowedWithUpNodesVersion
-		Expect(r.genReIPCommand()).Should(ContainElement("--force"))
-	})
-
-	It("should use --hosts option in start_db if on earliest version that we support", func() {
-		vdb := vapi.MakeVDB()
-		fpr := &cmds.FakePodRunner{}
-		act := MakeRestartReconciler(vdbRec, logger, vdb, fpr, &PodFacts{}, RestartProcessReadOnly)
-		r := act.(*RestartReconciler)
-		downPods := []*PodFact{
-			{podIP: "9.10.1.1"},
-			{podIP: "9.10.1.2"},
-		}
-		vdb.Annotations[vapi.VersionAnnotation] = vapi.MinimumVersion
-		Expect(r.genStartDBCommand(downPods)).Should(ContainElements("--hosts", "9.10.1.1,9.10.1.2"))
-	})
-
 	It("should requeue if k-safety is 0, there are no UP nodes and some pods aren't running", func() {
 		vdb := vapi.MakeVDB()
 		vdb.Spec.Subclusters[0].Size = 3
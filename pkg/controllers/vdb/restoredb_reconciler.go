@@ -0,0 +1,347 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/controllers"
+	verrors "github.com/vertica/vertica-kubernetes/pkg/errors"
+	"github.com/vertica/vertica-kubernetes/pkg/events"
+	"github.com/vertica/vertica-kubernetes/pkg/mgmterrors"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	"github.com/vertica/vertica-kubernetes/pkg/paths"
+	"github.com/vertica/vertica-kubernetes/pkg/vadmin"
+	"github.com/vertica/vertica-kubernetes/pkg/vbr"
+	"github.com/vertica/vertica-kubernetes/pkg/vdbstatus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// RestoreDBReconciler will initialize a database from a restore point taken
+// with vbr.  The database is first created with create_db, then stopped so
+// that vbr can restore the restore point into it.
+type RestoreDBReconciler struct {
+	VRec     *VerticaDBReconciler
+	Log      logr.Logger
+	Vdb      *vapi.VerticaDB // Vdb is the CRD we are acting on.
+	PRunner  cmds.PodRunner
+	PFacts   *PodFacts
+	EVLogr   mgmterrors.EventLogger
+	VbrLogr  mgmterrors.EventLogger
+	createDB *CreateDBReconciler
+}
+
+// MakeRestoreDBReconciler will build a RestoreDBReconciler object
+func MakeRestoreDBReconciler(vdbrecon *VerticaDBReconciler, log logr.Logger,
+	vdb *vapi.VerticaDB, prunner cmds.PodRunner, pfacts *PodFacts) controllers.ReconcileActor {
+	return &RestoreDBReconciler{
+		VRec:     vdbrecon,
+		Log:      log,
+		Vdb:      vdb,
+		PRunner:  prunner,
+		PFacts:   pfacts,
+		EVLogr:   mgmterrors.MakeATErrors(vdbrecon, vdbrecon.Client, vdb, events.RestoreDBFailed),
+		VbrLogr:  mgmterrors.MakeVbrErrors(vdbrecon, vdbrecon.Client, vdb, events.RestoreDBFailed),
+		createDB: MakeCreateDBReconciler(vdbrecon, log, vdb, prunner, pfacts).(*CreateDBReconciler),
+	}
+}
+
+// Reconcile will ensure a DB exists and restore one if it doesn't
+func (r *RestoreDBReconciler) Reconcile(ctx context.Context, req *ctrl.Request) (ctrl.Result, error) {
+	// Skip this reconciler entirely if the init policy is not to restore the DB.
+	if r.Vdb.Spec.InitPolicy != vapi.CommunalInitPolicyRestore {
+		return ctrl.Result{}, nil
+	}
+	if isSet, err := r.Vdb.IsConditionSet(vapi.DBInitialized); err != nil || isSet {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.PFacts.Collect(ctx, r.Vdb); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// If the database exists but isn't initialized, then a prior create_db
+	// succeeded but the restore did not.  We skip straight to the restore.
+	if r.PFacts.doesDBExist() {
+		return r.resumeRestore(ctx)
+	}
+
+	// The remaining create_db logic is driven from GenericDatabaseInitializer.
+	// This exists to creation an abstraction that is common with create_db
+	// and revive_db.
+	g := GenericDatabaseInitializer{
+		initializer: r,
+		VRec:        r.VRec,
+		Log:         r.Log,
+		Vdb:         r.Vdb,
+		PRunner:     r.PRunner,
+		PFacts:      r.PFacts,
+	}
+	return g.checkAndRunInit(ctx)
+}
+
+// execCmd will create the database with create_db, then restore the restore
+// point into it.  This handles logging of necessary events.
+func (r *RestoreDBReconciler) execCmd(ctx context.Context, atPod types.NamespacedName, hostList []string) (ctrl.Result, error) {
+	opts, err := r.createDB.genOptions(ctx, atPod, hostList)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	r.VRec.Eventf(r.Vdb, corev1.EventTypeNormal, events.RestoreDBStart,
+		"Restoring database from restore point '%s'", r.Vdb.Spec.RestorePoint.ID)
+	start := time.Now()
+	stdout, err := r.createDB.Dispatcher.CreateDB(ctx, opts)
+	if err != nil {
+		return r.EVLogr.LogFailure(ctx, "create_db", stdout, err)
+	}
+	if err := r.EVLogr.LogSuccess(ctx, "create_db"); err != nil {
+		return ctrl.Result{}, err
+	}
+	if res, err := r.runRestore(ctx, atPod); verrors.IsReconcileAborted(res, err) {
+		return res, err
+	}
+	r.VRec.Eventf(r.Vdb, corev1.EventTypeNormal, events.RestoreDBSucceeded,
+		"Successfully restored database from restore point '%s'. It took %s",
+		r.Vdb.Spec.RestorePoint.ID, time.Since(start))
+	return ctrl.Result{}, nil
+}
+
+// preCmdSetup will generate the files for create_db and the files that vbr
+// needs to do the restore.
+func (r *RestoreDBReconciler) preCmdSetup(ctx context.Context, atPod types.NamespacedName, podList []*PodFact) (ctrl.Result, error) {
+	if res, err := r.createDB.preCmdSetup(ctx, atPod, podList); verrors.IsReconcileAborted(res, err) {
+		return res, err
+	}
+	return r.copyVbrFiles(ctx, atPod)
+}
+
+// postCmdCleanup will remove the vbr credential files.  The restore leaves
+// the database down, so we requeue to have it restarted.
+func (r *RestoreDBReconciler) postCmdCleanup(ctx context.Context) (ctrl.Result, error) {
+	if atPod, ok := r.createDB.findPodToRunInit(); ok {
+		r.destroySensitiveFiles(ctx, atPod.name)
+	}
+	r.Log.Info("Requeue reconcile cycle to start the database after the restore")
+	return ctrl.Result{Requeue: true}, nil
+}
+
+// getPodList gets a list of all of the pods we are going to use with the
+// restore.  This is the same list of pods that create_db uses.
+func (r *RestoreDBReconciler) getPodList() ([]*PodFact, bool) {
+	return r.createDB.getPodList()
+}
+
+// findPodToRunInit will return a PodFact of the pod that should run the init
+// command from
+func (r *RestoreDBReconciler) findPodToRunInit() (*PodFact, bool) {
+	return r.createDB.findPodToRunInit()
+}
+
+// resumeRestore will finish a restore when the database was already created
+// in an earlier reconcile iteration.
+func (r *RestoreDBReconciler) resumeRestore(ctx context.Context) (ctrl.Result, error) {
+	atPod, ok := r.createDB.findPodToRunInit()
+	if !ok || !atPod.isPodRunning {
+		r.Log.Info("Could not find a running pod to resume the restore from. Requeueing.")
+		return ctrl.Result{Requeue: true}, nil
+	}
+	if res, err := r.copyVbrFiles(ctx, atPod.name); verrors.IsReconcileAborted(res, err) {
+		return res, err
+	}
+	r.VRec.Eventf(r.Vdb, corev1.EventTypeNormal, events.RestoreDBStart,
+		"Resuming restore of database from restore point '%s'", r.Vdb.Spec.RestorePoint.ID)
+	start := time.Now()
+	if res, err := r.runRestore(ctx, atPod.name); verrors.IsReconcileAborted(res, err) {
+		return res, err
+	}
+	r.VRec.Eventf(r.Vdb, corev1.EventTypeNormal, events.RestoreDBSucceeded,
+		"Successfully restored database from restore point '%s'. It took %s",
+		r.Vdb.Spec.RestorePoint.ID, time.Since(start))
+
+	cond := vapi.VerticaDBCondition{Type: vapi.DBInitialized, Status: corev1.ConditionTrue}
+	if err := vdbstatus.UpdateCondition(ctx, r.VRec.Client, r.Vdb, cond); err != nil {
+		return ctrl.Result{}, err
+	}
+	r.PFacts.Invalidate()
+	return r.postCmdCleanup(ctx)
+}
+
+// runRestore will stop the database, then have vbr restore the restore point
+// into it.
+func (r *RestoreDBReconciler) runRestore(ctx context.Context, atPod types.NamespacedName) (ctrl.Result, error) {
+	_, archiveID, ok := vbr.SplitRestorePoint(r.Vdb.Spec.RestorePoint.ID)
+	if !ok {
+		r.VRec.Eventf(r.Vdb, corev1.EventTypeWarning, events.RestoreDBFailed,
+			"Restore point '%s' has an invalid format", r.Vdb.Spec.RestorePoint.ID)
+		return ctrl.Result{}, fmt.Errorf("restore point %q has an invalid format", r.Vdb.Spec.RestorePoint.ID)
+	}
+
+	// vbr can only restore into a database that is down
+	opts := vadmin.StopDBOptions{InitiatorOptions: r.PFacts.makeInitiatorOptions(atPod)}
+	stdout, err := r.createDB.Dispatcher.StopDB(ctx, &opts)
+	if err != nil {
+		return r.EVLogr.LogFailure(ctx, "stop_db", stdout, err)
+	}
+	if err := r.EVLogr.LogSuccess(ctx, "stop_db"); err != nil {
+		return ctrl.Result{}, err
+	}
+	r.PFacts.Invalidate()
+
+	cmd := vbr.GenTaskCmd(r.getEnvFileName(), r.getConfigFileName(), "restore", "--archive", archiveID)
+	stdout, _, err = r.PRunner.ExecInPod(ctx, atPod, names.ServerContainer, cmd...)
+	if err != nil {
+		return r.VbrLogr.LogFailure(ctx, "restore", stdout, err)
+	}
+	return ctrl.Result{}, r.VbrLogr.LogSuccess(ctx, "restore")
+}
+
+// copyVbrFiles will generate the vbr config, environment and password files
+// and copy them into the pod.
+func (r *RestoreDBReconciler) copyVbrFiles(ctx context.Context, atPod types.NamespacedName) (ctrl.Result, error) {
+	env, res, err := r.genVbrEnv(ctx)
+	if verrors.IsReconcileAborted(res, err) {
+		return res, err
+	}
+	passwd, err := r.VRec.GetActiveSuperuserPassword(ctx, r.Vdb, r.Log)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	_, _, err = r.PRunner.ExecInPod(ctx, atPod, names.ServerContainer,
+		"mkdir", "-p", paths.VbrConfigPath, paths.VbrLockPath)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	files := map[string]string{
+		r.getConfigFileName(): r.genVbrConfig(passwd != ""),
+		r.getEnvFileName():    env,
+	}
+	if passwd != "" {
+		files[r.getPasswordFileName()] = vbr.GenPasswordFile(passwd)
+	}
+	for dest, content := range files {
+		if err := vbr.CopyFileToPod(ctx, r.PRunner, atPod, dest, content); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// destroySensitiveFiles will remove the files that have credentials in them.
+// This is a best effort.  A failure is logged but otherwise ignored.
+func (r *RestoreDBReconciler) destroySensitiveFiles(ctx context.Context, atPod types.NamespacedName) {
+	_, _, err := r.PRunner.ExecInPod(ctx, atPod, names.ServerContainer,
+		"rm", "-f", r.getEnvFileName(), r.getPasswordFileName())
+	if err != nil {
+		r.Log.Info("failed to remove vbr credential files, ignoring failure", "err", err)
+	}
+}
+
+// genVbrConfig will generate the contents of the vbr config file
+func (r *RestoreDBReconciler) genVbrConfig(hasPassword bool) string {
+	snapshotName, _, _ := vbr.SplitRestorePoint(r.Vdb.Spec.RestorePoint.ID)
+	cfg := vbr.Config{
+		BackupPath:   r.Vdb.Spec.RestorePoint.Target.Path,
+		SnapshotName: snapshotName,
+		DBName:       r.Vdb.Spec.DBName,
+		DBUser:       r.Vdb.GetSuperuserName(),
+	}
+	if hasPassword {
+		cfg.PasswordFile = r.getPasswordFileName()
+	}
+	return cfg.Gen()
+}
+
+// genVbrEnv will generate the contents of the environment file that vbr is
+// run with.  It has the credentials to access the backup location and, for
+// Eon databases, communal storage.
+func (r *RestoreDBReconciler) genVbrEnv(ctx context.Context) (string, ctrl.Result, error) {
+	target := &r.Vdb.Spec.RestorePoint.Target
+	backupSecret := target.CredentialSecret
+	if backupSecret == "" {
+		backupSecret = r.Vdb.Spec.Communal.CredentialSecret
+	}
+	locs := []vbr.Location{
+		{
+			EnvPrefix:  vbr.BackupEnvPrefix,
+			IsAzure:    target.IsAzure(),
+			SecretName: backupSecret,
+			Endpoint:   target.Endpoint,
+			Region:     target.Region,
+			CaFile:     target.CaFile,
+		},
+	}
+	if r.Vdb.IsEON() && !r.Vdb.IsHDFS() {
+		locs = append(locs, vbr.Location{
+			EnvPrefix:  vbr.CommunalEnvPrefix,
+			IsAzure:    r.Vdb.IsAzure(),
+			SecretName: r.Vdb.Spec.Communal.CredentialSecret,
+			Endpoint:   r.Vdb.Spec.Communal.Endpoint,
+			Region:     r.Vdb.Spec.Communal.Region,
+			CaFile:     r.Vdb.Spec.Communal.CaFile,
+		})
+	}
+
+	env := vbr.MakeEnv()
+	for i := range locs {
+		if res, err := r.addLocationToEnv(ctx, env, &locs[i]); verrors.IsReconcileAborted(res, err) {
+			return "", res, err
+		}
+	}
+	content, err := env.GenContent()
+	return content, ctrl.Result{}, err
+}
+
+// addLocationToEnv will read the credential secret for a storage location and
+// add it to the vbr environment.
+func (r *RestoreDBReconciler) addLocationToEnv(ctx context.Context, env *vbr.Env, loc *vbr.Location) (ctrl.Result, error) {
+	if loc.SecretName != "" {
+		secret, res, err := getSecret(ctx, r.VRec, r.Vdb, names.GenNamespacedName(r.Vdb, loc.SecretName))
+		if verrors.IsReconcileAborted(res, err) {
+			return res, err
+		}
+		loc.SecretData = secret.Data
+	}
+	if err := env.AddLocation(loc); err != nil {
+		r.VRec.Eventf(r.Vdb, corev1.EventTypeWarning, events.CommunalCredsWrongKey,
+			"The credential secret '%s' is not setup properly: %s", loc.SecretName, err.Error())
+		return ctrl.Result{Requeue: true}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// getConfigFileName returns the path, in the pod, of the vbr config file
+func (r *RestoreDBReconciler) getConfigFileName() string {
+	return fmt.Sprintf("%s/%s-restore.ini", paths.VbrConfigPath, r.Vdb.Name)
+}
+
+// getEnvFileName returns the path, in the pod, of the file that has the
+// environment variables for vbr.
+func (r *RestoreDBReconciler) getEnvFileName() string {
+	return fmt.Sprintf("%s/%s-restore.env", paths.VbrConfigPath, r.Vdb.Name)
+}
+
+// getPasswordFileName returns the path, in the pod, of the vbr password file
+func (r *RestoreDBReconciler) getPasswordFileName() string {
+	return fmt.Sprintf("%s/%s-restore.pw", paths.VbrConfigPath, r.Vdb.Name)
+}
@@ -0,0 +1,106 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vdb
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/test"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("restoredb_reconciler", func() {
+	ctx := context.Background()
+
+	It("should skip reconciler entirely if initPolicy is not Restore", func() {
+		vdb := vapi.MakeVDB()
+
+		fpr := &cmds.FakePodRunner{}
+		pfacts := MakePodFacts(vdbRec, fpr)
+		r := MakeRestoreDBReconciler(vdbRec, logger, vdb, fpr, &pfacts)
+		Expect(r.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+		Expect(len(fpr.Histories)).Should(Equal(0))
+	})
+
+	It("should create the db then restore the restore point into it", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.InitPolicy = vapi.CommunalInitPolicyRestore
+		vdb.Spec.RestorePoint = &vapi.RestorePointPolicy{
+			ID:     "nightly_20230501_020000",
+			Target: vapi.BackupTarget{Path: "s3://backups/db"},
+		}
+		test.CreateVDB(ctx, k8sClient, vdb)
+		defer test.DeleteVDB(ctx, k8sClient, vdb)
+		test.CreatePods(ctx, k8sClient, vdb, test.AllPodsRunning)
+		defer test.DeletePods(ctx, k8sClient, vdb)
+		createS3CredSecret(ctx, vdb)
+		defer deleteCommunalCredSecret(ctx, vdb)
+
+		fpr := &cmds.FakePodRunner{}
+		pfacts := createPodFactsWithNoDB(ctx, vdb, fpr, 3)
+		r := MakeRestoreDBReconciler(vdbRec, logger, vdb, fpr, pfacts)
+		Expect(r.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{Requeue: true}))
+		Expect(fpr.FindCommands("/opt/vertica/bin/admintools -t create_db")).Should(HaveLen(1))
+		Expect(fpr.FindCommands("/opt/vertica/bin/admintools -t stop_db")).Should(HaveLen(1))
+		hist := fpr.FindCommands("vbr --task restore")
+		Expect(hist).Should(HaveLen(1))
+		Expect(hist[0].Command[2]).Should(ContainSubstring("--archive '20230501_020000'"))
+		Expect(vdb.IsConditionSet(vapi.DBInitialized)).Should(BeTrue())
+	})
+
+	It("should fail without stopping the db if the restore point has an invalid format", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.InitPolicy = vapi.CommunalInitPolicyRestore
+		vdb.Spec.RestorePoint = &vapi.RestorePointPolicy{
+			ID:     "nightly",
+			Target: vapi.BackupTarget{Path: "s3://backups/db"},
+		}
+
+		fpr := &cmds.FakePodRunner{}
+		pfacts := MakePodFacts(vdbRec, fpr)
+		r := MakeRestoreDBReconciler(vdbRec, logger, vdb, fpr, &pfacts)
+		_, err := r.(*RestoreDBReconciler).runRestore(ctx, vdb.ExtractNamespacedName())
+		Expect(err).ShouldNot(Succeed())
+		Expect(fpr.Histories).Should(BeEmpty())
+		Expect(vdb.IsConditionSet(vapi.DBInitialized)).Should(BeFalse())
+	})
+
+	It("should only restore if the db was created in an earlier attempt", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.InitPolicy = vapi.CommunalInitPolicyRestore
+		vdb.Spec.RestorePoint = &vapi.RestorePointPolicy{
+			ID:     "nightly_20230501_020000",
+			Target: vapi.BackupTarget{Path: "s3://backups/db"},
+		}
+		test.CreateVDB(ctx, k8sClient, vdb)
+		defer test.DeleteVDB(ctx, k8sClient, vdb)
+		test.CreatePods(ctx, k8sClient, vdb, test.AllPodsRunning)
+		defer test.DeletePods(ctx, k8sClient, vdb)
+		createS3CredSecret(ctx, vdb)
+		defer deleteCommunalCredSecret(ctx, vdb)
+
+		fpr := &cmds.FakePodRunner{}
+		pfacts := createPodFactsDefault(fpr)
+		r := MakeRestoreDBReconciler(vdbRec, logger, vdb, fpr, pfacts)
+		Expect(r.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{Requeue: true}))
+		Expect(fpr.FindCommands("/opt/vertica/bin/admintools -t create_db")).Should(HaveLen(0))
+		Expect(fpr.FindCommands("vbr --task restore")).Should(HaveLen(1))
+	})
+})
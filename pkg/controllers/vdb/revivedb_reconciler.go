@@ -18,7 +18,6 @@ package vdb
 import (
 	"context"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -29,8 +28,8 @@ import (
 	"github.com/vertica/vertica-kubernetes/pkg/events"
 	"github.com/vertica/vertica-kubernetes/pkg/mgmterrors"
 	"github.com/vertica/vertica-kubernetes/pkg/names"
-	"github.com/vertica/vertica-kubernetes/pkg/paths"
 	"github.com/vertica/vertica-kubernetes/pkg/reviveplanner"
+	"github.com/vertica/vertica-kubernetes/pkg/vadmin"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
@@ -40,33 +39,38 @@ import (
 
 // ReviveDBReconciler will revive a database if one doesn't exist in the vdb yet.
 type ReviveDBReconciler struct {
-	VRec    *VerticaDBReconciler
-	Log     logr.Logger
-	Vdb     *vapi.VerticaDB // Vdb is the CRD we are acting on.
-	PRunner cmds.PodRunner
-	PFacts  *PodFacts
-	EVLogr  mgmterrors.EventLogger
-	Planr   reviveplanner.Planner
+	VRec       *VerticaDBReconciler
+	Log        logr.Logger
+	Vdb        *vapi.VerticaDB // Vdb is the CRD we are acting on.
+	PRunner    cmds.PodRunner
+	PFacts     *PodFacts
+	EVLogr     mgmterrors.EventLogger
+	Planr      reviveplanner.Planner
+	Dispatcher vadmin.Dispatcher
 }
 
 // MakeReviveDBReconciler will build a ReviveDBReconciler object
 func MakeReviveDBReconciler(vdbrecon *VerticaDBReconciler, log logr.Logger,
 	vdb *vapi.VerticaDB, prunner cmds.PodRunner, pfacts *PodFacts) controllers.ReconcileActor {
 	return &ReviveDBReconciler{
-		VRec:    vdbrecon,
-		Log:     log,
-		Vdb:     vdb,
-		PRunner: prunner,
-		PFacts:  pfacts,
-		EVLogr:  mgmterrors.MakeATErrors(vdbrecon, vdb, events.ReviveDBFailed),
-		Planr:   reviveplanner.MakeATPlanner(log),
+		VRec:       vdbrecon,
+		Log:        log,
+		Vdb:        vdb,
+		PRunner:    prunner,
+		PFacts:     pfacts,
+		EVLogr:     mgmterrors.MakeATErrors(vdbrecon, vdbrecon.Client, vdb, events.ReviveDBFailed),
+		Planr:      reviveplanner.MakeATPlanner(log),
+		Dispatcher: vdbrecon.makeDispatcher(log, vdb, prunner),
 	}
 }
 
 // Reconcile will ensure a DB exists and revive one if it doesn't
 func (r *ReviveDBReconciler) Reconcile(ctx context.Context, req *ctrl.Request) (ctrl.Result, error) {
 	// Skip this reconciler entirely if the init policy is to create the DB.
-	if r.Vdb.Spec.InitPolicy != vapi.CommunalInitPolicyRevive {
+	// The exception is an Eon database that is resuming from hibernation.  We
+	// revive it in case the pods lost their local data while hibernated.
+	if r.Vdb.Spec.InitPolicy != vapi.CommunalInitPolicyRevive &&
+		!(r.Vdb.IsEON() && isResumingInitializedDB(r.Vdb)) {
 		return ctrl.Result{}, nil
 	}
 
@@ -83,15 +87,18 @@ func (r *ReviveDBReconciler) Reconcile(ctx context.Context, req *ctrl.Request) (
 	return g.checkAndRunInit(ctx)
 }
 
-// execCmd will do the actual execution of admintools -t revive_db.
+// execCmd will do the actual execution of revive_db.
 // This handles logging of necessary events.
-func (r *ReviveDBReconciler) execCmd(ctx context.Context, atPod types.NamespacedName, cmd []string) (ctrl.Result, error) {
-	r.VRec.Event(r.Vdb, corev1.EventTypeNormal, events.ReviveDBStart,
-		"Calling 'admintools -t revive_db'")
+func (r *ReviveDBReconciler) execCmd(ctx context.Context, atPod types.NamespacedName, hostList []string) (ctrl.Result, error) {
+	r.VRec.Eventf(r.Vdb, corev1.EventTypeNormal, events.ReviveDBStart,
+		"Calling '%s'", r.Dispatcher.DescribeOp(vadmin.OpReviveDB))
 	start := time.Now()
-	stdout, _, err := r.PRunner.ExecAdmintools(ctx, atPod, names.ServerContainer, cmd...)
+	stdout, err := r.Dispatcher.ReviveDB(ctx, r.genOptions(atPod, hostList, false))
 	if err != nil {
-		return r.EVLogr.LogFailure("revive_db", stdout, err)
+		return r.EVLogr.LogFailure(ctx, "revive_db", stdout, err)
+	}
+	if err := r.EVLogr.LogSuccess(ctx, "revive_db"); err != nil {
+		return ctrl.Result{}, err
 	}
 	r.VRec.Eventf(r.Vdb, corev1.EventTypeNormal, events.ReviveDBSucceeded,
 		"Successfully revived database. It took %s", time.Since(start))
@@ -202,33 +209,14 @@ func (r *ReviveDBReconciler) findPodToRunInit() (*PodFact, bool) {
 	return r.PFacts.findPodToRunAdmintoolsOffline()
 }
 
-// genCmd will return the command to run in the pod to revive the database
-func (r *ReviveDBReconciler) genCmd(ctx context.Context, hostList []string) ([]string, error) {
-	cmd := []string{
-		"-t", "revive_db",
-		"--hosts=" + strings.Join(hostList, ","),
-		"--database", r.Vdb.Spec.DBName,
-	}
-	if r.Vdb.IsEON() {
-		cmd = append(cmd,
-			"--communal-storage-location="+r.Vdb.GetCommunalPath(),
-			"--communal-storage-params="+paths.AuthParmsFile)
-	}
-	if r.Vdb.Spec.IgnoreClusterLease {
-		cmd = append(cmd, "--ignore-cluster-lease")
-	}
-	return cmd, nil
-}
-
-// genValidateCmd will return the command to run in the pod to validate some
-// options with revive
-func (r *ReviveDBReconciler) genValidateCmd(ctx context.Context, hostList []string) ([]string, error) {
-	cmd, err := r.genCmd(ctx, hostList)
-	if err != nil {
-		return []string{}, err
+// genOptions will return the options to revive the database with.  If
+// displayOnly is true, the revive only validates the options.
+func (r *ReviveDBReconciler) genOptions(atPod types.NamespacedName, hostList []string, displayOnly bool) *vadmin.ReviveDBOptions {
+	return &vadmin.ReviveDBOptions{
+		InitiatorOptions: r.PFacts.makeInitiatorOptions(atPod),
+		Hosts:            hostList,
+		DisplayOnly:      displayOnly,
 	}
-	cmd = append(cmd, "--display-only")
-	return cmd, nil
 }
 
 // deleteRevisionPendingPods will delete any pods that have a pending revision update from the sts.
@@ -260,13 +248,9 @@ func (r *ReviveDBReconciler) deleteRevisionPendingPods(ctx context.Context, podL
 // can be analyzed by the revive planner.
 func (r *ReviveDBReconciler) runRevivePrepass(ctx context.Context, atPod types.NamespacedName,
 	podList []*PodFact) (string, ctrl.Result, error) {
-	cmd, err := r.genValidateCmd(ctx, getHostList(podList))
-	if err != nil {
-		return "", ctrl.Result{}, err
-	}
-	stdout, _, err := r.PRunner.ExecAdmintools(ctx, atPod, names.ServerContainer, cmd...)
+	stdout, err := r.Dispatcher.ReviveDB(ctx, r.genOptions(atPod, getHostList(podList), true))
 	if err != nil {
-		res, err2 := r.EVLogr.LogFailure("revive_db", stdout, err)
+		res, err2 := r.EVLogr.LogFailure(ctx, "revive_db", stdout, err)
 		return "", res, err2
 	}
 	return stdout, ctrl.Result{}, nil
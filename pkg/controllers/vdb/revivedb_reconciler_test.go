@@ -121,7 +121,7 @@ var _ = Describe("revivedb_reconcile", func() {
 					},
 				},
 			}
-			Expect(r.execCmd(ctx, atPod, []string{"revive_db"})).Should(Equal(ctrl.Result{Requeue: true}), "Failing with '%s'", errStrings[i])
+			Expect(r.execCmd(ctx, atPod, []string{"hostA"})).Should(Equal(ctrl.Result{Requeue: true}), "Failing with '%s'", errStrings[i])
 		}
 
 		fpr.Results = cmds.CmdResults{
@@ -132,24 +132,11 @@ var _ = Describe("revivedb_reconcile", func() {
 				},
 			},
 		}
-		res, err := r.execCmd(ctx, atPod, []string{"create_db"})
+		res, err := r.execCmd(ctx, atPod, []string{"hostA"})
 		Expect(err).ShouldNot(Succeed())
 		Expect(res).Should(Equal(ctrl.Result{}))
 	})
 
-	It("should include --ignore-cluster-lease in revive_db command", func() {
-		vdb := vapi.MakeVDB()
-
-		fpr := &cmds.FakePodRunner{}
-		pfacts := MakePodFacts(vdbRec, fpr)
-		act := MakeReviveDBReconciler(vdbRec, logger, vdb, fpr, &pfacts)
-		r := act.(*ReviveDBReconciler)
-		vdb.Spec.IgnoreClusterLease = false
-		Expect(r.genCmd(ctx, []string{"hostA"})).ShouldNot(ContainElement("--ignore-cluster-lease"))
-		vdb.Spec.IgnoreClusterLease = true
-		Expect(r.genCmd(ctx, []string{"hostA"})).Should(ContainElement("--ignore-cluster-lease"))
-	})
-
 	It("should use reviveOrder to order the host list", func() {
 		vdb := vapi.MakeVDB()
 		vdb.Spec.Subclusters = []vapi.Subcluster{
@@ -18,6 +18,7 @@ package vdb
 import (
 	"context"
 	"fmt"
+	"reflect"
 
 	"github.com/go-logr/logr"
 	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
@@ -26,7 +27,9 @@ import (
 	"github.com/vertica/vertica-kubernetes/pkg/names"
 	"github.com/vertica/vertica-kubernetes/pkg/vdbstatus"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -77,7 +80,7 @@ func (s *StatusReconciler) Reconcile(ctx context.Context, req *ctrl.Request) (ct
 			}
 		}
 		s.calculateClusterStatus(&vdbChg.Status)
-		return nil
+		return vdbstatus.SetConditionInPlace(vdbChg, s.calculateReadyCondition())
 	}
 
 	if err := vdbstatus.Update(ctx, s.Client, s.Vdb, refreshStatus); err != nil {
@@ -86,6 +89,164 @@ func (s *StatusReconciler) Reconcile(ctx context.Context, req *ctrl.Request) (ct
 	return ctrl.Result{}, nil
 }
 
+// ReconcileAbort describes the actor that stopped a reconcile iteration before
+// all of the actors ran.
+type ReconcileAbort struct {
+	// The name of the actor that aborted the reconcile
+	Actor string
+	// The result the actor returned.  This is a requeue if Err is nil.
+	Result ctrl.Result
+	// The error the actor returned, if any
+	Err error
+}
+
+// UpdateConditions will set the Ready, Progressing and Degraded conditions.
+// This is called at the end of each reconcile iteration.  abort is the actor
+// that stopped the reconcile early, or nil if all of the actors ran.
+func (s *StatusReconciler) UpdateConditions(ctx context.Context, abort *ReconcileAbort) error {
+	if err := s.PFacts.Collect(ctx, s.Vdb); err != nil {
+		return err
+	}
+	conds := []vapi.VerticaDBCondition{
+		s.calculateReadyCondition(),
+		s.calculateProgressingCondition(abort),
+		s.calculateDegradedCondition(abort),
+	}
+	refreshConditions := func(vdbChg *vapi.VerticaDB) error {
+		for i := range conds {
+			if conds[i].LastTransitionTime.IsZero() {
+				conds[i].LastTransitionTime = metav1.Now()
+			}
+			if err := vdbstatus.SetConditionInPlace(vdbChg, conds[i]); err != nil {
+				return err
+			}
+		}
+		// A reconcile that ran to completion means nothing is waiting for a
+		// maintenance window.
+		if abort == nil {
+			vdbChg.Status.MaintenanceWindowStatus = ""
+			vdbChg.Status.NextMaintenanceWindow = nil
+		}
+		return nil
+	}
+	// This runs at the end of every reconcile.  If the vdb we have already has
+	// these conditions, skip the update so that we don't fetch the vdb and
+	// write its status for nothing.
+	vdbChg := s.Vdb.DeepCopy()
+	if err := refreshConditions(vdbChg); err != nil {
+		return err
+	}
+	if reflect.DeepEqual(s.Vdb.Status, vdbChg.Status) {
+		return nil
+	}
+	return vdbstatus.Update(ctx, s.Client, s.Vdb, refreshConditions)
+}
+
+// countUpNodes returns the number of vertica nodes that are up and the number
+// that we expect to be up.  Pods that are pending delete, or are part of a
+// subcluster that is shut down, aren't expected to be up.
+func (s *StatusReconciler) countUpNodes() (up, expected int) {
+	for _, pf := range s.PFacts.Detail {
+		if pf.pendingDelete || pf.shutdown {
+			continue
+		}
+		expected++
+		if pf.upNode && !pf.readOnly {
+			up++
+		}
+	}
+	return up, expected
+}
+
+// isDBInitialized returns true if the database has been created or revived.
+// The operator doesn't create the database with the ScheduleOnly init policy,
+// so we assume it exists.
+func (s *StatusReconciler) isDBInitialized() bool {
+	if s.Vdb.Spec.InitPolicy == vapi.CommunalInitPolicyScheduleOnly {
+		return true
+	}
+	isSet, _ := s.Vdb.IsConditionSet(vapi.DBInitialized)
+	return isSet
+}
+
+// calculateReadyCondition returns the Ready condition based on the pod facts
+func (s *StatusReconciler) calculateReadyCondition() vapi.VerticaDBCondition {
+	cond := vapi.VerticaDBCondition{
+		Type:               vapi.Ready,
+		Status:             corev1.ConditionFalse,
+		ObservedGeneration: s.Vdb.Generation,
+	}
+	up, expected := s.countUpNodes()
+	switch {
+	case s.Vdb.IsHibernated():
+		cond.Reason = vapi.ReasonHibernated
+		cond.Message = "The database is hibernated"
+	case !s.isDBInitialized():
+		cond.Reason = vapi.ReasonDBNotInitialized
+		cond.Message = "The database has not been created or revived yet"
+	case expected == 0 || up < expected:
+		cond.Reason = vapi.ReasonNodesNotUp
+		cond.Message = fmt.Sprintf("%d of %d nodes are up", up, expected)
+	default:
+		cond.Status = corev1.ConditionTrue
+		cond.Reason = vapi.ReasonAllNodesUp
+		cond.Message = fmt.Sprintf("All %d nodes are up", expected)
+	}
+	return cond
+}
+
+// calculateProgressingCondition returns the Progressing condition based on
+// how the reconcile ended
+func (s *StatusReconciler) calculateProgressingCondition(abort *ReconcileAbort) vapi.VerticaDBCondition {
+	cond := vapi.VerticaDBCondition{
+		Type:               vapi.Progressing,
+		Status:             corev1.ConditionTrue,
+		ObservedGeneration: s.Vdb.Generation,
+	}
+	switch {
+	case abort != nil && abort.Err != nil:
+		// The reconcile is retried after a failure, so we are still
+		// progressing.  The details of the failure are in the Degraded
+		// condition.
+		cond.Reason = vapi.ReasonReconcileFailed
+		cond.Message = fmt.Sprintf("%s failed and will be retried", abort.Actor)
+	case abort != nil:
+		cond.Reason = vapi.ReasonReconcileRequeued
+		cond.Message = fmt.Sprintf("%s requeued the reconcile", abort.Actor)
+	case s.Vdb.IsImageChangeInProgress():
+		cond.Reason = vapi.ReasonUpgradeInProgress
+		cond.Message = "The image of the database is being changed"
+	default:
+		cond.Status = corev1.ConditionFalse
+		cond.Reason = vapi.ReasonReconcileComplete
+		cond.Message = "The VerticaDB is fully reconciled"
+	}
+	return cond
+}
+
+// calculateDegradedCondition returns the Degraded condition based on how the
+// reconcile ended and the pod facts
+func (s *StatusReconciler) calculateDegradedCondition(abort *ReconcileAbort) vapi.VerticaDBCondition {
+	cond := vapi.VerticaDBCondition{
+		Type:               vapi.Degraded,
+		Status:             corev1.ConditionTrue,
+		ObservedGeneration: s.Vdb.Generation,
+	}
+	up, expected := s.countUpNodes()
+	switch {
+	case abort != nil && abort.Err != nil:
+		cond.Reason = vapi.ReasonReconcileFailed
+		cond.Message = fmt.Sprintf("%s: %s", abort.Actor, abort.Err)
+	case !s.Vdb.IsHibernated() && s.isDBInitialized() && up > 0 && up < expected:
+		cond.Reason = vapi.ReasonNodesDown
+		cond.Message = fmt.Sprintf("%d of %d nodes are down", expected-up, expected)
+	default:
+		cond.Status = corev1.ConditionFalse
+		cond.Reason = vapi.ReasonAsExpected
+	}
+	return cond
+}
+
 // calculateClusterStatus will roll up the subcluster status.
 func (s *StatusReconciler) calculateClusterStatus(stat *vapi.VerticaDBStatus) {
 	stat.SubclusterCount = 0
@@ -103,6 +264,9 @@ func (s *StatusReconciler) calculateClusterStatus(stat *vapi.VerticaDBStatus) {
 // calculateSubclusterStatus will figure out the status for the given subcluster
 func (s *StatusReconciler) calculateSubclusterStatus(ctx context.Context, sc *vapi.Subcluster, curStat *vapi.SubclusterStatus) error {
 	curStat.Name = sc.Name
+	curStat.Shutdown = sc.Shutdown
+	curStat.LowDiskSpaceCount = 0
+	curStat.NoShardSubscriptionCount = 0
 
 	if err := s.resizeSubclusterStatus(ctx, sc, curStat); err != nil {
 		return err
@@ -118,6 +282,31 @@ func (s *StatusReconciler) calculateSubclusterStatus(ctx context.Context, sc *va
 		curStat.Detail[podIndex].ReadOnly = pf.readOnly
 		curStat.Detail[podIndex].Installed = pf.isInstalled
 		curStat.Detail[podIndex].AddedToDB = pf.dbExists
+		curStat.Detail[podIndex].Image = pf.image
+		curStat.Detail[podIndex].AgentRunning = pf.agentRunning
+		// The disk and depot details are only valid while the pod is running
+		// or the node is up.  Clear them otherwise so that we don't report
+		// stale values.
+		curStat.Detail[podIndex].LocalDataSize = 0
+		curStat.Detail[podIndex].LocalDataAvail = 0
+		if pf.isPodRunning {
+			curStat.Detail[podIndex].LocalDataSize = int64(pf.localDataSize)
+			curStat.Detail[podIndex].LocalDataAvail = int64(pf.localDataAvail)
+			if pf.localDataAvail <= LowLocalDataAvailThreshold {
+				curStat.LowDiskSpaceCount++
+			}
+		}
+		curStat.Detail[podIndex].DepotSize = 0
+		curStat.Detail[podIndex].DepotDiskPercentSize = ""
+		curStat.Detail[podIndex].ShardSubscriptions = 0
+		if pf.upNode {
+			curStat.Detail[podIndex].DepotSize = int64(pf.maxDepotSize)
+			curStat.Detail[podIndex].DepotDiskPercentSize = pf.depotDiskPercentSize
+			curStat.Detail[podIndex].ShardSubscriptions = int32(pf.shardSubscriptions)
+			if s.Vdb.IsEON() && pf.shardSubscriptions == 0 {
+				curStat.NoShardSubscriptionCount++
+			}
+		}
 		if pf.vnodeName != "" {
 			curStat.Detail[podIndex].VNodeName = pf.vnodeName
 		}
@@ -130,7 +319,13 @@ func (s *StatusReconciler) calculateSubclusterStatus(ctx context.Context, sc *va
 	curStat.AddedToDBCount = 0
 	curStat.UpNodeCount = 0
 	curStat.ReadOnlyCount = 0
+	curStat.DepotSize = 0
+	curStat.LocalDataSize = 0
+	curStat.LocalDataAvail = 0
 	for _, v := range curStat.Detail {
+		curStat.DepotSize += v.DepotSize
+		curStat.LocalDataSize += v.LocalDataSize
+		curStat.LocalDataAvail += v.LocalDataAvail
 		if v.Installed {
 			curStat.InstallCount++
 		}
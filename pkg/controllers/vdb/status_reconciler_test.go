@@ -17,13 +17,17 @@ package vdb
 
 import (
 	"context"
+	"errors"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
 	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/events"
 	"github.com/vertica/vertica-kubernetes/pkg/names"
 	"github.com/vertica/vertica-kubernetes/pkg/test"
+	"github.com/vertica/vertica-kubernetes/pkg/vdbstatus"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
@@ -149,4 +153,149 @@ var _ = Describe("status_reconcile", func() {
 		Expect(k8sClient.Get(ctx, vapi.MakeVDBName(), fetchVdb)).Should(Succeed())
 		Expect(fetchVdb.Status.Subclusters[0].InstallCount).Should(Equal(int32(2)))
 	})
+
+	It("should set the Ready, Progressing and Degraded conditions", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.Subclusters[0].Size = 3
+		test.CreateVDB(ctx, k8sClient, vdb)
+		defer test.DeleteVDB(ctx, k8sClient, vdb)
+		test.CreatePods(ctx, k8sClient, vdb, test.AllPodsRunning)
+		defer test.DeletePods(ctx, k8sClient, vdb)
+		Expect(vdbstatus.UpdateCondition(ctx, k8sClient, vdb,
+			vapi.VerticaDBCondition{Type: vapi.DBInitialized, Status: corev1.ConditionTrue})).Should(Succeed())
+
+		pfacts := createPodFactsDefault(&cmds.FakePodRunner{})
+		r := MakeStatusReconciler(k8sClient, scheme.Scheme, logger, vdb, pfacts).(*StatusReconciler)
+		Expect(r.UpdateConditions(ctx, nil)).Should(Succeed())
+		fetchVdb := &vapi.VerticaDB{}
+		Expect(k8sClient.Get(ctx, vapi.MakeVDBName(), fetchVdb)).Should(Succeed())
+		cond := fetchVdb.Status.Conditions[vapi.ReadyIndex]
+		Expect(cond.Status).Should(Equal(corev1.ConditionTrue))
+		Expect(cond.Reason).Should(Equal(vapi.ReasonAllNodesUp))
+		Expect(cond.ObservedGeneration).Should(Equal(fetchVdb.Generation))
+		Expect(fetchVdb.Status.Conditions[vapi.ProgressingIndex].Status).Should(Equal(corev1.ConditionFalse))
+		Expect(fetchVdb.Status.Conditions[vapi.DegradedIndex].Status).Should(Equal(corev1.ConditionFalse))
+
+		abort := &ReconcileAbort{Actor: "RestartReconciler", Result: ctrl.Result{Requeue: true}}
+		Expect(r.UpdateConditions(ctx, abort)).Should(Succeed())
+		Expect(k8sClient.Get(ctx, vapi.MakeVDBName(), fetchVdb)).Should(Succeed())
+		cond = fetchVdb.Status.Conditions[vapi.ProgressingIndex]
+		Expect(cond.Status).Should(Equal(corev1.ConditionTrue))
+		Expect(cond.Reason).Should(Equal(vapi.ReasonReconcileRequeued))
+		Expect(cond.Message).Should(ContainSubstring("RestartReconciler"))
+		Expect(fetchVdb.Status.Conditions[vapi.DegradedIndex].Status).Should(Equal(corev1.ConditionFalse))
+
+		abort = &ReconcileAbort{Actor: "CreateDBReconciler", Err: errors.New("create_db failed")}
+		Expect(r.UpdateConditions(ctx, abort)).Should(Succeed())
+		Expect(k8sClient.Get(ctx, vapi.MakeVDBName(), fetchVdb)).Should(Succeed())
+		cond = fetchVdb.Status.Conditions[vapi.DegradedIndex]
+		Expect(cond.Status).Should(Equal(corev1.ConditionTrue))
+		Expect(cond.Reason).Should(Equal(vapi.ReasonReconcileFailed))
+		Expect(cond.Message).Should(Equal("CreateDBReconciler: create_db failed"))
+	})
+
+	It("should not write the status if the conditions did not change", func() {
+		vdb := vapi.MakeVDB()
+		test.CreateVDB(ctx, k8sClient, vdb)
+		defer test.DeleteVDB(ctx, k8sClient, vdb)
+		test.CreatePods(ctx, k8sClient, vdb, test.AllPodsRunning)
+		defer test.DeletePods(ctx, k8sClient, vdb)
+
+		pfacts := createPodFactsDefault(&cmds.FakePodRunner{})
+		r := MakeStatusReconciler(k8sClient, scheme.Scheme, logger, vdb, pfacts).(*StatusReconciler)
+		Expect(r.UpdateConditions(ctx, nil)).Should(Succeed())
+		fetchVdb := &vapi.VerticaDB{}
+		Expect(k8sClient.Get(ctx, vapi.MakeVDBName(), fetchVdb)).Should(Succeed())
+		rv := fetchVdb.ResourceVersion
+
+		Expect(r.UpdateConditions(ctx, nil)).Should(Succeed())
+		Expect(k8sClient.Get(ctx, vapi.MakeVDBName(), fetchVdb)).Should(Succeed())
+		Expect(fetchVdb.ResourceVersion).Should(Equal(rv))
+	})
+
+	It("should leave the last failure for the reconciler that recorded it", func() {
+		vdb := vapi.MakeVDB()
+		test.CreateVDB(ctx, k8sClient, vdb)
+		defer test.DeleteVDB(ctx, k8sClient, vdb)
+		test.CreatePods(ctx, k8sClient, vdb, test.AllPodsRunning)
+		defer test.DeletePods(ctx, k8sClient, vdb)
+		Expect(vdbstatus.RecordFailure(ctx, k8sClient, vdb,
+			&vapi.MgmtFailure{Command: "restart_node", Reason: events.MgmtFailed})).Should(Succeed())
+
+		pfacts := createPodFactsDefault(&cmds.FakePodRunner{})
+		r := MakeStatusReconciler(k8sClient, scheme.Scheme, logger, vdb, pfacts).(*StatusReconciler)
+		abort := &ReconcileAbort{Actor: "RestartReconciler", Err: errors.New("restart_node failed")}
+		Expect(r.UpdateConditions(ctx, abort)).Should(Succeed())
+		fetchVdb := &vapi.VerticaDB{}
+		Expect(k8sClient.Get(ctx, vapi.MakeVDBName(), fetchVdb)).Should(Succeed())
+		Expect(fetchVdb.Status.LastFailure).ShouldNot(BeNil())
+
+		// A reconcile that completes without running restart_node again
+		// doesn't mean it succeeded.
+		Expect(r.UpdateConditions(ctx, nil)).Should(Succeed())
+		Expect(k8sClient.Get(ctx, vapi.MakeVDBName(), fetchVdb)).Should(Succeed())
+		Expect(fetchVdb.Status.LastFailure).ShouldNot(BeNil())
+	})
+
+	It("should report nodes that are down in the Ready and Degraded conditions", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.Subclusters[0].Size = 3
+		test.CreateVDB(ctx, k8sClient, vdb)
+		defer test.DeleteVDB(ctx, k8sClient, vdb)
+		test.CreatePods(ctx, k8sClient, vdb, test.AllPodsRunning)
+		defer test.DeletePods(ctx, k8sClient, vdb)
+		Expect(vdbstatus.UpdateCondition(ctx, k8sClient, vdb,
+			vapi.VerticaDBCondition{Type: vapi.DBInitialized, Status: corev1.ConditionTrue})).Should(Succeed())
+
+		pfacts := createPodFactsDefault(&cmds.FakePodRunner{})
+		Expect(pfacts.Collect(ctx, vdb)).Should(Succeed())
+		pfacts.Detail[names.GenPodName(vdb, &vdb.Spec.Subclusters[0], 1)].upNode = false
+		r := MakeStatusReconciler(k8sClient, scheme.Scheme, logger, vdb, pfacts).(*StatusReconciler)
+		Expect(r.UpdateConditions(ctx, nil)).Should(Succeed())
+		Expect(vdb.Status.Conditions[vapi.ReadyIndex].Status).Should(Equal(corev1.ConditionFalse))
+		Expect(vdb.Status.Conditions[vapi.ReadyIndex].Message).Should(Equal("2 of 3 nodes are up"))
+		Expect(vdb.Status.Conditions[vapi.DegradedIndex].Status).Should(Equal(corev1.ConditionTrue))
+		Expect(vdb.Status.Conditions[vapi.DegradedIndex].Reason).Should(Equal(vapi.ReasonNodesDown))
+	})
+
+	It("should report the disk, depot and shard details of each pod", func() {
+		vdb := vapi.MakeVDB()
+		sc := &vdb.Spec.Subclusters[0]
+		sc.Size = 2
+		test.CreateVDB(ctx, k8sClient, vdb)
+		defer test.DeleteVDB(ctx, k8sClient, vdb)
+		test.CreatePods(ctx, k8sClient, vdb, test.AllPodsRunning)
+		defer test.DeletePods(ctx, k8sClient, vdb)
+
+		pfacts := createPodFactsDefault(&cmds.FakePodRunner{})
+		Expect(pfacts.Collect(ctx, vdb)).Should(Succeed())
+		const GB = 1024 * 1024 * 1024
+		for i := int32(0); i < sc.Size; i++ {
+			pf := pfacts.Detail[names.GenPodName(vdb, sc, i)]
+			pf.localDataSize = 10 * GB
+			pf.localDataAvail = 4 * GB
+			pf.maxDepotSize = 6 * GB
+			pf.depotDiskPercentSize = "60%"
+			pf.shardSubscriptions = int(i)
+		}
+		pfacts.Detail[names.GenPodName(vdb, sc, 1)].localDataAvail = 1024
+
+		r := MakeStatusReconciler(k8sClient, scheme.Scheme, logger, vdb, pfacts)
+		Expect(r.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+
+		fetchVdb := &vapi.VerticaDB{}
+		Expect(k8sClient.Get(ctx, vapi.MakeVDBName(), fetchVdb)).Should(Succeed())
+		scStat := &fetchVdb.Status.Subclusters[0]
+		Expect(scStat.Detail[0].Image).Should(Equal(vdb.Spec.Image))
+		Expect(scStat.Detail[0].DepotSize).Should(Equal(int64(6 * GB)))
+		Expect(scStat.Detail[0].DepotDiskPercentSize).Should(Equal("60%"))
+		Expect(scStat.Detail[0].LocalDataSize).Should(Equal(int64(10 * GB)))
+		Expect(scStat.Detail[1].ShardSubscriptions).Should(Equal(int32(1)))
+		Expect(scStat.Detail[0].AgentRunning).Should(BeTrue())
+		Expect(scStat.DepotSize).Should(Equal(int64(12 * GB)))
+		Expect(scStat.LocalDataSize).Should(Equal(int64(20 * GB)))
+		Expect(scStat.LocalDataAvail).Should(Equal(int64(4*GB + 1024)))
+		Expect(scStat.LowDiskSpaceCount).Should(Equal(int32(1)))
+		Expect(scStat.NoShardSubscriptionCount).Should(Equal(int32(1)))
+	})
 })
@@ -23,7 +23,7 @@ import (
 	"github.com/vertica/vertica-kubernetes/pkg/cmds"
 	"github.com/vertica/vertica-kubernetes/pkg/controllers"
 	"github.com/vertica/vertica-kubernetes/pkg/events"
-	"github.com/vertica/vertica-kubernetes/pkg/names"
+	"github.com/vertica/vertica-kubernetes/pkg/vadmin"
 	"github.com/vertica/vertica-kubernetes/pkg/vdbstatus"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -32,10 +32,11 @@ import (
 
 // StopDBReconciler will stop the cluster and clear the restart needed status condition
 type StopDBReconciler struct {
-	VRec    *VerticaDBReconciler
-	Vdb     *vapi.VerticaDB // Vdb is the CRD we are acting on.
-	PRunner cmds.PodRunner
-	PFacts  *PodFacts
+	VRec       *VerticaDBReconciler
+	Vdb        *vapi.VerticaDB // Vdb is the CRD we are acting on.
+	PRunner    cmds.PodRunner
+	PFacts     *PodFacts
+	Dispatcher vadmin.Dispatcher
 }
 
 // MakeStopDBReconciler will build a StopDBReconciler object
@@ -43,10 +44,11 @@ func MakeStopDBReconciler(
 	vdbrecon *VerticaDBReconciler, vdb *vapi.VerticaDB, prunner cmds.PodRunner, pfacts *PodFacts,
 ) controllers.ReconcileActor {
 	return &StopDBReconciler{
-		VRec:    vdbrecon,
-		Vdb:     vdb,
-		PRunner: prunner,
-		PFacts:  pfacts,
+		VRec:       vdbrecon,
+		Vdb:        vdb,
+		PRunner:    prunner,
+		PFacts:     pfacts,
+		Dispatcher: vdbrecon.makeDispatcher(vdbrecon.Log, vdb, prunner),
 	}
 }
 
@@ -100,13 +102,13 @@ func (s *StopDBReconciler) stopVertica(ctx context.Context) error {
 	return err
 }
 
-// runATCmd issues the admintools command to stop the database
+// runATCmd issues the command to stop the database
 func (s *StopDBReconciler) runATCmd(ctx context.Context, atPod types.NamespacedName) error {
-	cmd := s.genCmd()
-	s.VRec.Event(s.Vdb, corev1.EventTypeNormal, events.StopDBStart,
-		"Calling 'admintools -t stop_db'")
+	opts := vadmin.StopDBOptions{InitiatorOptions: s.PFacts.makeInitiatorOptions(atPod)}
+	s.VRec.Eventf(s.Vdb, corev1.EventTypeNormal, events.StopDBStart,
+		"Calling '%s'", s.Dispatcher.DescribeOp(vadmin.OpStopDB))
 	start := time.Now()
-	_, _, err := s.PRunner.ExecAdmintools(ctx, atPod, names.ServerContainer, cmd...)
+	_, err := s.Dispatcher.StopDB(ctx, &opts)
 	if err != nil {
 		s.VRec.Event(s.Vdb, corev1.EventTypeWarning, events.StopDBFailed, "Failed to stop the database")
 		return err
@@ -115,12 +117,3 @@ func (s *StopDBReconciler) runATCmd(ctx context.Context, atPod types.NamespacedN
 		"Successfully stopped the database.  It took %ds", int(time.Since(start).Seconds()))
 	return nil
 }
-
-// genCmd will return the command to run in the pod to stop the database
-func (s *StopDBReconciler) genCmd() []string {
-	return []string{
-		"-t", "stop_db",
-		"--database", s.Vdb.Spec.DBName,
-		"--force",
-	}
-}
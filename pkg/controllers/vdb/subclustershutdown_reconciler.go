@@ -0,0 +1,134 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vdb
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/controllers"
+	"github.com/vertica/vertica-kubernetes/pkg/events"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// SubclusterShutdownReconciler will stop the vertica nodes of any subcluster
+// that has its shutdown field set, then scale its statefulset to zero.  The
+// subcluster is left in the database.
+type SubclusterShutdownReconciler struct {
+	VRec    *VerticaDBReconciler
+	Log     logr.Logger
+	Vdb     *vapi.VerticaDB // Vdb is the CRD we are acting on.
+	PRunner cmds.PodRunner
+	PFacts  *PodFacts
+}
+
+// MakeSubclusterShutdownReconciler will build a SubclusterShutdownReconciler object
+func MakeSubclusterShutdownReconciler(vdbrecon *VerticaDBReconciler, log logr.Logger,
+	vdb *vapi.VerticaDB, prunner cmds.PodRunner, pfacts *PodFacts) controllers.ReconcileActor {
+	return &SubclusterShutdownReconciler{
+		VRec:    vdbrecon,
+		Log:     log,
+		Vdb:     vdb,
+		PRunner: prunner,
+		PFacts:  pfacts,
+	}
+}
+
+// Reconcile will shut down any subcluster that has its shutdown field set.
+// This depends on DrainNodeReconciler to have run first so that the pods in
+// the subcluster have no active connections.
+func (s *SubclusterShutdownReconciler) Reconcile(ctx context.Context, req *ctrl.Request) (ctrl.Result, error) {
+	// Shutdown is only possible for secondary subclusters, which only exist
+	// in Eon Mode.
+	if !s.Vdb.IsEON() {
+		return ctrl.Result{}, nil
+	}
+
+	if err := s.PFacts.Collect(ctx, s.Vdb); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	for i := range s.Vdb.Spec.Subclusters {
+		sc := &s.Vdb.Spec.Subclusters[i]
+		if !sc.Shutdown || sc.IsPrimary {
+			continue
+		}
+		if res, err := s.shutdownSubcluster(ctx, sc); err != nil || res.Requeue {
+			return res, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// shutdownSubcluster will stop the nodes in a single subcluster and scale its
+// statefulset to zero.
+func (s *SubclusterShutdownReconciler) shutdownSubcluster(ctx context.Context, sc *vapi.Subcluster) (ctrl.Result, error) {
+	upPods := s.PFacts.filterPods(func(v *PodFact) bool {
+		return v.subclusterName == sc.Name && v.upNode
+	})
+	if len(upPods) > 0 {
+		// We need to run admintools from an up pod outside of the subcluster
+		// since the pods in the subcluster are the ones being stopped.
+		atPod, ok := s.PFacts.findFirstPodSorted(func(v *PodFact) bool {
+			return v.upNode && !v.readOnly && v.subclusterName != sc.Name
+		})
+		if !ok {
+			s.Log.Info("No up pod found outside of the subcluster to run admintools from. Requeue reconciliation.",
+				"subcluster", sc.Name)
+			return ctrl.Result{Requeue: true}, nil
+		}
+		if err := s.stopSubcluster(ctx, atPod, sc); err != nil {
+			return ctrl.Result{}, err
+		}
+		s.PFacts.Invalidate()
+	}
+
+	scaled, err := scaleSts(ctx, s.VRec, s.Vdb, sc, 0)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if scaled {
+		s.Log.Info("Scaled statefulset to zero for subcluster shutdown", "subcluster", sc.Name)
+		s.PFacts.Invalidate()
+	}
+	return ctrl.Result{}, nil
+}
+
+// stopSubcluster will call admintools to stop all of the nodes in the subcluster
+func (s *SubclusterShutdownReconciler) stopSubcluster(ctx context.Context, atPod *PodFact, sc *vapi.Subcluster) error {
+	cmd := []string{
+		"-t", "stop_subcluster",
+		"--database", s.Vdb.Spec.DBName,
+		"--subcluster", sc.Name,
+		"--force",
+	}
+	s.VRec.Eventf(s.Vdb, corev1.EventTypeNormal, events.SubclusterShutdownStart,
+		"Calling 'admintools -t stop_subcluster' for subcluster '%s'", sc.Name)
+	start := time.Now()
+	if _, _, err := s.PRunner.ExecAdmintools(ctx, atPod.name, names.ServerContainer, cmd...); err != nil {
+		s.VRec.Eventf(s.Vdb, corev1.EventTypeWarning, events.SubclusterShutdownFailed,
+			"Failed to shut down subcluster '%s'", sc.Name)
+		return err
+	}
+	s.VRec.Eventf(s.Vdb, corev1.EventTypeNormal, events.SubclusterShutdownSucceeded,
+		"Successfully shut down subcluster '%s'. It took %ds", sc.Name, int(time.Since(start).Seconds()))
+	return nil
+}
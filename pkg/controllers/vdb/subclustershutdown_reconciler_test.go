@@ -0,0 +1,81 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vdb
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	"github.com/vertica/vertica-kubernetes/pkg/test"
+	appsv1 "k8s.io/api/apps/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("subclustershutdown_reconcile", func() {
+	ctx := context.Background()
+
+	It("should stop the subcluster and scale its statefulset to zero", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.Subclusters = append(vdb.Spec.Subclusters, vapi.Subcluster{Name: "sc2", Size: 2, IsPrimary: false})
+		test.CreateVDB(ctx, k8sClient, vdb)
+		defer test.DeleteVDB(ctx, k8sClient, vdb)
+		test.CreatePods(ctx, k8sClient, vdb, test.AllPodsRunning)
+		defer test.DeletePods(ctx, k8sClient, vdb)
+
+		vdb.Spec.Subclusters[1].Shutdown = true
+		fpr := &cmds.FakePodRunner{}
+		pfacts := createPodFactsDefault(fpr)
+		recon := MakeSubclusterShutdownReconciler(vdbRec, logger, vdb, fpr, pfacts)
+		Expect(recon.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+		hist := fpr.FindCommands("-t stop_subcluster")
+		Expect(hist).Should(HaveLen(1))
+		Expect(hist[0].Command).Should(ContainElement("sc2"))
+		// admintools must be run from a pod outside of the subcluster
+		Expect(hist[0].Pod.Name).ShouldNot(ContainSubstring("sc2"))
+
+		sts := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, names.GenStsName(vdb, &vdb.Spec.Subclusters[1]), sts)).Should(Succeed())
+		Expect(*sts.Spec.Replicas).Should(Equal(int32(0)))
+		Expect(k8sClient.Get(ctx, names.GenStsName(vdb, &vdb.Spec.Subclusters[0]), sts)).Should(Succeed())
+		Expect(*sts.Spec.Replicas).Should(Equal(vdb.Spec.Subclusters[0].Size))
+	})
+
+	It("should requeue if the only up pods are in the subcluster being shut down", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.Subclusters = append(vdb.Spec.Subclusters, vapi.Subcluster{Name: "sc2", Size: 2, IsPrimary: false})
+		test.CreateVDB(ctx, k8sClient, vdb)
+		defer test.DeleteVDB(ctx, k8sClient, vdb)
+		test.CreatePods(ctx, k8sClient, vdb, test.AllPodsRunning)
+		defer test.DeletePods(ctx, k8sClient, vdb)
+
+		vdb.Spec.Subclusters[1].Shutdown = true
+		fpr := &cmds.FakePodRunner{}
+		pfacts := createPodFactsDefault(fpr)
+		Expect(pfacts.Collect(ctx, vdb)).Should(Succeed())
+		for _, pf := range pfacts.Detail {
+			if pf.subclusterName == vdb.Spec.Subclusters[0].Name {
+				pf.upNode = false
+			}
+		}
+		recon := MakeSubclusterShutdownReconciler(vdbRec, logger, vdb, fpr, pfacts)
+		Expect(recon.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{Requeue: true}))
+		Expect(fpr.FindCommands("-t stop_subcluster")).Should(HaveLen(0))
+	})
+})
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/go-logr/logr"
@@ -136,11 +137,15 @@ func createPodFactsWithNoDB(ctx context.Context, vdb *vapi.VerticaDB, fpr *cmds.
 	pfacts := MakePodFacts(vdbRec, fpr)
 	// Change a number of pods to indicate db doesn't exist.  Due to the map that
 	// stores the pod facts, the specific pods we change are non-deterministic.
+	// The pod facts are collected in parallel, so guard the counter.
 	podsChanged := 0
+	var mu sync.Mutex
 	pfacts.OverrideFunc = func(ctx context.Context, vdb *vapi.VerticaDB, pf *PodFact, gs *GatherState) error {
 		if err := defaultPodFactOverrider(ctx, vdb, pf, gs); err != nil {
 			return err
 		}
+		mu.Lock()
+		defer mu.Unlock()
 		if podsChanged == numPodsToChange {
 			return nil
 		}
@@ -0,0 +1,219 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/builder"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/controllers"
+	"github.com/vertica/vertica-kubernetes/pkg/events"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// SuperuserPasswdReconciler will change the superuser password in the
+// database when the password secret changes.  The password that is set in the
+// database is kept in a secret that the operator owns, so that we can still
+// log in while the rotation is in progress.
+type SuperuserPasswdReconciler struct {
+	VRec    *VerticaDBReconciler
+	Log     logr.Logger
+	Vdb     *vapi.VerticaDB // Vdb is the CRD we are acting on.
+	PRunner cmds.PodRunner
+	PFacts  *PodFacts
+}
+
+// MakeSuperuserPasswdReconciler will build a SuperuserPasswdReconciler object
+func MakeSuperuserPasswdReconciler(vdbrecon *VerticaDBReconciler, log logr.Logger,
+	vdb *vapi.VerticaDB, prunner cmds.PodRunner, pfacts *PodFacts) controllers.ReconcileActor {
+	return &SuperuserPasswdReconciler{
+		VRec:    vdbrecon,
+		Log:     log,
+		Vdb:     vdb,
+		PRunner: prunner,
+		PFacts:  pfacts,
+	}
+}
+
+// Reconcile will rotate the superuser password if the secret has a new value
+func (s *SuperuserPasswdReconciler) Reconcile(ctx context.Context, req *ctrl.Request) (ctrl.Result, error) {
+	// The operator doesn't own the database in this mode
+	if s.Vdb.Spec.InitPolicy == vapi.CommunalInitPolicyScheduleOnly {
+		return ctrl.Result{}, nil
+	}
+	// Nothing to rotate until the database exists
+	if isSet, err := s.Vdb.IsConditionSet(vapi.DBInitialized); !isSet || err != nil {
+		return ctrl.Result{}, err
+	}
+
+	newPasswd, err := s.VRec.GetSuperuserPassword(ctx, s.Vdb, s.Log)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	curPasswd, found, err := s.VRec.getAppliedSuperuserPassword(ctx, s.Vdb)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	// If we have never recorded the password, we assume the database was
+	// created with the one that is currently in the secret.
+	if !found {
+		return ctrl.Result{}, s.saveAppliedPasswordSecret(ctx, newPasswd)
+	}
+	if curPasswd == newPasswd {
+		return ctrl.Result{}, nil
+	}
+	return s.rotatePassword(ctx, curPasswd, newPasswd)
+}
+
+// rotatePassword will change the superuser password in the database from
+// curPasswd to newPasswd.  If any step fails, we continue to use curPasswd.
+func (s *SuperuserPasswdReconciler) rotatePassword(ctx context.Context, curPasswd, newPasswd string) (ctrl.Result, error) {
+	if err := s.PFacts.Collect(ctx, s.Vdb); err != nil {
+		return ctrl.Result{}, err
+	}
+	pf, ok := s.PFacts.findPodToRunVsql(false, "")
+	if !ok {
+		s.Log.Info("No up pod found to rotate the superuser password. Requeue reconciliation.")
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	s.VRec.Event(s.Vdb, corev1.EventTypeNormal, events.SuperuserPasswordChangeStart,
+		"Changing the superuser password in the database")
+	// A prior attempt may have changed the password but failed before it was
+	// recorded. In that case, we only need to record it.
+	if !s.canLogin(ctx, pf, newPasswd) {
+		sql := fmt.Sprintf("alter user %s identified by '%s'", s.Vdb.GetSuperuserName(), escapeSQLString(newPasswd))
+		cmd := cmds.UpdateVsqlCmd(curPasswd, "-tAc", sql)
+		if _, _, err := s.PRunner.ExecInPod(ctx, pf.name, names.ServerContainer, cmd...); err != nil {
+			s.VRec.Event(s.Vdb, corev1.EventTypeWarning, events.SuperuserPasswordChangeFailed,
+				"Failed to change the superuser password. The database still has the previous password.")
+			return ctrl.Result{}, err
+		}
+		if !s.canLogin(ctx, pf, newPasswd) {
+			s.VRec.Event(s.Vdb, corev1.EventTypeWarning, events.SuperuserPasswordChangeFailed,
+				"Could not log in with the new superuser password after changing it. "+
+					"The previous password will continue to be used.")
+			return ctrl.Result{}, fmt.Errorf("failed to verify the new superuser password")
+		}
+	}
+	s.VRec.Event(s.Vdb, corev1.EventTypeNormal, events.SuperuserPasswordVerified,
+		"Logged in with the new superuser password")
+
+	if err := s.saveAppliedPasswordSecret(ctx, newPasswd); err != nil {
+		return ctrl.Result{}, err
+	}
+	// The remaining actors need to use the new password
+	s.PRunner.SetSUPassword(newPasswd)
+	s.VRec.Event(s.Vdb, corev1.EventTypeNormal, events.SuperuserPasswordChanged,
+		"Successfully changed the superuser password")
+	return ctrl.Result{}, nil
+}
+
+// canLogin returns true if vsql can connect as the superuser with the given password
+func (s *SuperuserPasswdReconciler) canLogin(ctx context.Context, pf *PodFact, passwd string) bool {
+	cmd := cmds.UpdateVsqlCmd(passwd, "-tAc", "select 1")
+	_, _, err := s.PRunner.ExecInPod(ctx, pf.name, names.ServerContainer, cmd...)
+	return err == nil
+}
+
+// saveAppliedPasswordSecret will create or update the secret that has the
+// password set in the database.
+func (s *SuperuserPasswdReconciler) saveAppliedPasswordSecret(ctx context.Context, passwd string) error {
+	return s.VRec.saveAppliedSecret(ctx, s.Vdb, names.GenSUPasswdAppliedSecretName(s.Vdb),
+		map[string][]byte{builder.SuperuserPasswordKey: []byte(passwd)})
+}
+
+// GetActiveSuperuserPassword returns the superuser password that is set in
+// the database. This differs from GetSuperuserPassword while a password
+// rotation is pending.
+func (r *VerticaDBReconciler) GetActiveSuperuserPassword(ctx context.Context, vdb *vapi.VerticaDB, log logr.Logger) (string, error) {
+	passwd, found, err := r.getAppliedSuperuserPassword(ctx, vdb)
+	if err != nil || found {
+		return passwd, err
+	}
+	return r.GetSuperuserPassword(ctx, vdb, log)
+}
+
+// getAppliedSuperuserPassword will read the password from the secret the
+// operator keeps of the password set in the database.
+func (r *VerticaDBReconciler) getAppliedSuperuserPassword(ctx context.Context, vdb *vapi.VerticaDB) (
+	passwd string, found bool, err error) {
+	data, found, err := r.getAppliedSecret(ctx, names.GenSUPasswdAppliedSecretName(vdb))
+	if !found || err != nil {
+		return "", found, err
+	}
+	return string(data[builder.SuperuserPasswordKey]), true, nil
+}
+
+// saveAppliedSecret will create or update a secret, owned by the vdb, that
+// has the values the operator last set in the database.  The operator keeps
+// these so that it can tell when the user facing secrets change.
+func (r *VerticaDBReconciler) saveAppliedSecret(ctx context.Context, vdb *vapi.VerticaDB,
+	nm types.NamespacedName, data map[string][]byte) error {
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(ctx, nm, secret); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		isController := true
+		blockOwnerDeletion := false
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        nm.Name,
+				Namespace:   nm.Namespace,
+				Annotations: builder.MakeAnnotationsForObject(vdb),
+				Labels:      builder.MakeOperatorLabels(vdb),
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion:         vapi.GroupVersion.String(),
+						Kind:               vapi.VerticaDBKind,
+						Name:               vdb.Name,
+						UID:                vdb.GetUID(),
+						Controller:         &isController,
+						BlockOwnerDeletion: &blockOwnerDeletion,
+					},
+				},
+			},
+			Data: data,
+		}
+		return r.Client.Create(ctx, secret)
+	}
+	secret.Data = data
+	return r.Client.Update(ctx, secret)
+}
+
+// getAppliedSecret will read the data of a secret that was written with
+// saveAppliedSecret.  found is false if the secret doesn't exist yet.
+func (r *VerticaDBReconciler) getAppliedSecret(ctx context.Context, nm types.NamespacedName) (
+	data map[string][]byte, found bool, err error) {
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, nm, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return secret.Data, true, nil
+}
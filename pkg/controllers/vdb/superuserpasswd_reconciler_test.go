@@ -0,0 +1,140 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vdb
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/builder"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	"github.com/vertica/vertica-kubernetes/pkg/test"
+	"github.com/vertica/vertica-kubernetes/pkg/vdbstatus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("superuserpasswd_reconcile", func() {
+	ctx := context.Background()
+
+	createSUPasswdSecret := func(vdb *vapi.VerticaDB, passwd string) {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      vdb.Spec.SuperuserPasswordSecret,
+				Namespace: vdb.Namespace,
+			},
+			Data: map[string][]byte{builder.SuperuserPasswordKey: []byte(passwd)},
+		}
+		Expect(k8sClient.Create(ctx, secret)).Should(Succeed())
+	}
+
+	// setupVDB will create an initialized vdb whose password secret has newPasswd
+	setupVDB := func(newPasswd string) *vapi.VerticaDB {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.SuperuserPasswordSecret = "su-passwd"
+		test.CreateVDB(ctx, k8sClient, vdb)
+		createSUPasswdSecret(vdb, newPasswd)
+		Expect(vdbstatus.UpdateCondition(ctx, k8sClient, vdb,
+			vapi.VerticaDBCondition{Type: vapi.DBInitialized, Status: corev1.ConditionTrue})).Should(Succeed())
+		return vdb
+	}
+
+	cleanupVDB := func(vdb *vapi.VerticaDB) {
+		deleteSecret(ctx, vdb, vdb.Spec.SuperuserPasswordSecret)
+		deleteSecret(ctx, vdb, names.GenSUPasswdAppliedSecretName(vdb).Name)
+		test.DeleteVDB(ctx, k8sClient, vdb)
+	}
+
+	getAppliedPasswd := func(vdb *vapi.VerticaDB) string {
+		passwd, found, err := vdbRec.getAppliedSuperuserPassword(ctx, vdb)
+		Expect(err).Should(Succeed())
+		Expect(found).Should(BeTrue())
+		return passwd
+	}
+
+	It("should record the password the first time without changing it", func() {
+		vdb := setupVDB("first")
+		defer cleanupVDB(vdb)
+
+		fpr := &cmds.FakePodRunner{}
+		recon := MakeSuperuserPasswdReconciler(vdbRec, logger, vdb, fpr, createPodFactsDefault(fpr))
+		Expect(recon.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+		Expect(fpr.Histories).Should(BeEmpty())
+		Expect(getAppliedPasswd(vdb)).Should(Equal("first"))
+
+		passwd, err := vdbRec.GetActiveSuperuserPassword(ctx, vdb, logger)
+		Expect(err).Should(Succeed())
+		Expect(passwd).Should(Equal("first"))
+	})
+
+	It("should change the password in the database when the secret changes", func() {
+		vdb := setupVDB("old")
+		defer cleanupVDB(vdb)
+		test.CreatePods(ctx, k8sClient, vdb, test.AllPodsRunning)
+		defer test.DeletePods(ctx, k8sClient, vdb)
+
+		fpr := &cmds.FakePodRunner{Results: make(cmds.CmdResults), SUPassword: "old"}
+		recon := MakeSuperuserPasswdReconciler(vdbRec, logger, vdb, fpr, createPodFactsDefault(fpr))
+		Expect(recon.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+
+		// Update the password in the secret
+		secret := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, names.GenSUPasswdSecretName(vdb), secret)).Should(Succeed())
+		secret.Data[builder.SuperuserPasswordKey] = []byte("new")
+		Expect(k8sClient.Update(ctx, secret)).Should(Succeed())
+
+		// The first login attempt with the new password will fail
+		sc := &vdb.Spec.Subclusters[0]
+		for i := int32(0); i < sc.Size; i++ {
+			fpr.Results[names.GenPodName(vdb, sc, i)] = []cmds.CmdResult{{Err: fmt.Errorf("auth failed")}}
+		}
+		Expect(recon.Reconcile(ctx, &ctrl.Request{})).Should(Equal(ctrl.Result{}))
+		hist := fpr.FindCommands("alter user dbadmin identified by 'new'")
+		Expect(hist).Should(HaveLen(1))
+		Expect(hist[0].Command).Should(ContainElements("--password", "old"))
+		Expect(fpr.FindCommands("--password new -tAc select 1")).Should(HaveLen(2))
+		Expect(getAppliedPasswd(vdb)).Should(Equal("new"))
+		Expect(fpr.SUPassword).Should(Equal("new"))
+	})
+
+	It("should keep the previous password if the change fails", func() {
+		vdb := setupVDB("new")
+		defer cleanupVDB(vdb)
+		test.CreatePods(ctx, k8sClient, vdb, test.AllPodsRunning)
+		defer test.DeletePods(ctx, k8sClient, vdb)
+
+		fpr := &cmds.FakePodRunner{Results: make(cmds.CmdResults), SUPassword: "old"}
+		recon := MakeSuperuserPasswdReconciler(vdbRec, logger, vdb, fpr, createPodFactsDefault(fpr)).(*SuperuserPasswdReconciler)
+		Expect(recon.saveAppliedPasswordSecret(ctx, "old")).Should(Succeed())
+
+		sc := &vdb.Spec.Subclusters[0]
+		for i := int32(0); i < sc.Size; i++ {
+			fpr.Results[names.GenPodName(vdb, sc, i)] = []cmds.CmdResult{
+				{Err: fmt.Errorf("auth failed")},
+				{Err: fmt.Errorf("alter failed")},
+			}
+		}
+		_, err := recon.Reconcile(ctx, &ctrl.Request{})
+		Expect(err).ShouldNot(Succeed())
+		Expect(getAppliedPasswd(vdb)).Should(Equal("old"))
+		Expect(fpr.SUPassword).Should(Equal("old"))
+	})
+})
@@ -78,6 +78,16 @@ func (i *UpgradeManager) IsUpgradeNeeded(ctx context.Context) (bool, error) {
 	return i.isVDBImageDifferent(ctx)
 }
 
+// waitForMaintenanceWindow returns true if a new upgrade has to wait for the
+// next maintenance window.  An upgrade that is already in progress is always
+// continued.
+func (i *UpgradeManager) waitForMaintenanceWindow(ctx context.Context) (bool, error) {
+	if i.ContinuingUpgrade {
+		return false, nil
+	}
+	return waitForMaintenanceWindow(ctx, i.VRec, i.Log, i.Vdb, fmt.Sprintf("change the image to %s", i.Vdb.Spec.Image))
+}
+
 // isUpgradeInProgress returns true if state indicates that an upgrade
 // is already occurring.
 func (i *UpgradeManager) isUpgradeInProgress() (bool, error) {
@@ -150,9 +160,17 @@ func (i *UpgradeManager) toggleImageChangeInProgress(ctx context.Context, newVal
 	if err != nil {
 		return err
 	}
-	return vdbstatus.UpdateCondition(ctx, i.VRec.Client, i.Vdb,
+	err = vdbstatus.UpdateCondition(ctx, i.VRec.Client, i.Vdb,
 		vapi.VerticaDBCondition{Type: i.StatusCondition, Status: newVal},
 	)
+	if err != nil {
+		return err
+	}
+	// The PodDisruptionBudgets are relaxed while the upgrade is in progress.
+	// Update them right away rather than waiting for the next object
+	// reconcile, so they don't get in the way of the pods we restart.
+	o := ObjReconciler{VRec: i.VRec, Log: i.Log, Vdb: i.Vdb}
+	return o.reconcilePDBs(ctx)
 }
 
 // setUpgradeStatus is a helper to set the upgradeStatus message.
@@ -155,6 +155,9 @@ func (v *VersionReconciler) updateVDBVersion(ctx context.Context, newVersion str
 			if err != nil {
 				return err
 			}
+			// What we query from the database depends on the version, so the
+			// facts have to be collected again.
+			v.PFacts.Invalidate()
 		}
 		return nil
 	})
@@ -23,22 +23,32 @@ import (
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
 	"github.com/vertica/vertica-kubernetes/pkg/builder"
 	"github.com/vertica/vertica-kubernetes/pkg/cmds"
 	"github.com/vertica/vertica-kubernetes/pkg/controllers"
+	"github.com/vertica/vertica-kubernetes/pkg/dryrun"
 	verrors "github.com/vertica/vertica-kubernetes/pkg/errors"
 	"github.com/vertica/vertica-kubernetes/pkg/events"
 	"github.com/vertica/vertica-kubernetes/pkg/metrics"
 	"github.com/vertica/vertica-kubernetes/pkg/names"
 	"github.com/vertica/vertica-kubernetes/pkg/opcfg"
+	"github.com/vertica/vertica-kubernetes/pkg/vadmin"
+	"github.com/vertica/vertica-kubernetes/pkg/vdbstatus"
 )
 
 // VerticaDBReconciler reconciles a VerticaDB object
@@ -50,6 +60,13 @@ type VerticaDBReconciler struct {
 	EVRec  record.EventRecorder
 	OpCfg  opcfg.OperatorConfig
 	builder.DeploymentNames
+	// Keeps the pod facts between reconciles of a VerticaDB.  If nil, the pod
+	// facts are collected fresh for each reconcile.
+	PFactsCache *PodFactsCache
+	// The plan that the changes are recorded in when the reconcile is done in
+	// dry-run mode.  This is only set in the copy of the reconciler that the
+	// dry-run actors use.
+	dryRunPlan *dryrun.Plan
 }
 
 //+kubebuilder:rbac:groups=vertica.com,namespace=WATCH_NAMESPACE,resources=verticadbs,verbs=get;list;watch;create;update;patch;delete
@@ -57,11 +74,16 @@ type VerticaDBReconciler struct {
 //+kubebuilder:rbac:groups=vertica.com,namespace=WATCH_NAMESPACE,resources=verticadbs/finalizers,verbs=update
 // +kubebuilder:rbac:groups=core,namespace=WATCH_NAMESPACE,resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,namespace=WATCH_NAMESPACE,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=policy,namespace=WATCH_NAMESPACE,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,namespace=WATCH_NAMESPACE,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=monitoring.coreos.com,namespace=WATCH_NAMESPACE,resources=podmonitors;servicemonitors,verbs=get;list;create;update;delete
 // +kubebuilder:rbac:groups="",namespace=WATCH_NAMESPACE,resources=pods,verbs=get;list;watch;create;update;delete;patch
 // +kubebuilder:rbac:groups="",namespace=WATCH_NAMESPACE,resources=pods/exec,verbs=create
 // +kubebuilder:rbac:groups="",namespace=WATCH_NAMESPACE,resources=pods/status,verbs=update
 // +kubebuilder:rbac:groups="",namespace=WATCH_NAMESPACE,resources=secrets,verbs=get;list;watch;create;update
+// +kubebuilder:rbac:groups="",namespace=WATCH_NAMESPACE,resources=configmaps,verbs=get;list;watch;create;update
 // +kubebuilder:rbac:groups="",namespace=WATCH_NAMESPACE,resources=persistentvolumeclaims,verbs=get;list;watch;update
+// +kubebuilder:rbac:groups=cert-manager.io,namespace=WATCH_NAMESPACE,resources=certificates,verbs=get;list;watch;create
 // +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingwebhookconfigurations,verbs=get;list;watch;update;patch
 
@@ -71,9 +93,57 @@ func (r *VerticaDBReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		For(&vapi.VerticaDB{}).
 		Owns(&corev1.Service{}).
 		Owns(&appsv1.StatefulSet{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&networkingv1.NetworkPolicy{}).
+		// Changes to the pods or statefulsets make the cached pod facts stale
+		Watches(
+			&source.Kind{Type: &corev1.Pod{}},
+			handler.EnqueueRequestsFromMapFunc(r.PFactsCache.invalidateForObject),
+			ctrlbuilder.WithPredicates(podFactsChangedPredicate()),
+		).
+		Watches(
+			&source.Kind{Type: &appsv1.StatefulSet{}},
+			handler.EnqueueRequestsFromMapFunc(r.PFactsCache.invalidateForObject),
+		).
+		// Changes to the credential secrets need to be applied to the database
+		Watches(
+			&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForSecret),
+			ctrlbuilder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
 		Complete(r)
 }
 
+// findObjectsForSecret will generate requests to reconcile any VerticaDB that
+// refers to the given secret for its credentials.
+func (r *VerticaDBReconciler) findObjectsForSecret(secret client.Object) []reconcile.Request {
+	vdbs := &vapi.VerticaDBList{}
+	if err := r.List(context.Background(), vdbs, client.InNamespace(secret.GetNamespace())); err != nil {
+		return []reconcile.Request{}
+	}
+
+	requests := []reconcile.Request{}
+	for i := range vdbs.Items {
+		vdb := &vdbs.Items[i]
+		secretNames := []string{
+			vdb.Spec.Communal.CredentialSecret,
+			vdb.Spec.Communal.S3SseCustomerKeySecret,
+			vdb.Spec.SuperuserPasswordSecret,
+			vdb.Spec.HTTPServerTLSSecret,
+		}
+		if vdb.Spec.ClientTLS != nil {
+			secretNames = append(secretNames, vdb.Spec.ClientTLS.Secret)
+		}
+		for _, nm := range secretNames {
+			if nm != "" && nm == secret.GetName() {
+				requests = append(requests, reconcile.Request{NamespacedName: vdb.ExtractNamespacedName()})
+				break
+			}
+		}
+	}
+	return requests
+}
+
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
 //
@@ -89,6 +159,7 @@ func (r *VerticaDBReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		if errors.IsNotFound(err) {
 			// Remove any metrics for the vdb that we found to be deleted
 			metrics.HandleVDBDelete(req.NamespacedName.Namespace, req.NamespacedName.Name, log)
+			r.PFactsCache.Remove(req.NamespacedName)
 			// Request object not found, cound have been deleted after reconcile request.
 			log.Info("VerticaDB resource not found.  Ignoring since object must be deleted")
 			return ctrl.Result{}, nil
@@ -97,24 +168,59 @@ func (r *VerticaDBReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, err
 	}
 
-	passwd, err := r.GetSuperuserPassword(ctx, vdb, log)
+	pauseAll, pausedActors := vapi.ParsePauseReconcileAnnotation(vdb.Annotations)
+	if err = r.updatePausedCondition(ctx, vdb, pauseAll, pausedActors); err != nil {
+		return ctrl.Result{}, err
+	}
+	if pauseAll {
+		log.Info("skipping reconcile of VerticaDB as it is paused", "annotation", vapi.PauseReconcileAnnotation)
+		return ctrl.Result{}, nil
+	}
+
+	passwd, err := r.GetActiveSuperuserPassword(ctx, vdb, log)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 	prunner := cmds.MakeClusterPodRunner(log, r.Cfg, passwd)
+	if vdb.IsDryRunEnabled() {
+		log.Info("reconciling VerticaDB in dry-run mode", "annotation", vapi.DryRunAnnotation)
+		return r.reconcileDryRun(ctx, log, &req, vdb, prunner, pausedActors)
+	}
 	// We use the same pod facts for all reconcilers. This allows to reuse as
 	// much as we can. Some reconcilers will purposely invalidate the facts if
-	// it is known they did something to make them stale.
+	// it is known they did something to make them stale.  If nothing changed
+	// since the last reconcile, we start with the facts it left behind.
 	pfacts := MakePodFacts(r, prunner)
+	if r.PFactsCache.Load(vdb, &pfacts) {
+		log.Info("using cached pod facts")
+	}
+	actors := r.constructActors(log, vdb, prunner, &pfacts)
+	return r.runActors(ctx, log, &req, vdb, &pfacts, actors, pausedActors)
+}
+
+// runActors will run each of the actors in sequence to reconcile the vdb.  The
+// reconcile stops at the first actor that fails or requeues.  A requeue for
+// an operation that waits for a maintenance window is deferred until all of
+// the actors have run.
+func (r *VerticaDBReconciler) runActors(ctx context.Context, log logr.Logger, req *ctrl.Request, vdb *vapi.VerticaDB,
+	pfacts *PodFacts, actors []controllers.ReconcileActor, pausedActors map[string]bool) (ctrl.Result, error) {
+	ctx, deferred := withDeferredRequeue(ctx)
 	var res ctrl.Result
+	var err error
 
 	// Iterate over each actor
-	actors := r.constructActors(log, vdb, prunner, &pfacts)
 	for _, act := range actors {
+		if pausedActors[controllers.GetActorName(act)] {
+			log.Info("skipping actor as it is paused", "name", fmt.Sprintf("%T", act))
+			continue
+		}
 		log.Info("starting actor", "name", fmt.Sprintf("%T", act))
-		res, err = act.Reconcile(ctx, &req)
+		res, err = act.Reconcile(ctx, req)
 		// Error or a request to requeue will stop the reconciliation.
 		if verrors.IsReconcileAborted(res, err) {
+			abort := &ReconcileAbort{Actor: controllers.GetActorName(act), Result: res, Err: err}
+			r.updateReconcileConditions(ctx, log, vdb, pfacts, abort)
+			r.savePodFacts(vdb, pfacts, err)
 			// Handle requeue time priority.
 			// If any function needs a requeue and we have a RequeueTime set,
 			// then overwrite RequeueAfter.
@@ -123,20 +229,96 @@ func (r *VerticaDBReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 				res.Requeue = false
 				res.RequeueAfter = time.Second * time.Duration(vdb.Spec.RequeueTime)
 			}
+			res = deferred.apply(res)
 			log.Info("aborting reconcile of VerticaDB", "result", res, "err", err)
 			return res, err
 		}
 	}
 
+	r.updateReconcileConditions(ctx, log, vdb, pfacts, nil)
+	r.savePodFacts(vdb, pfacts, err)
+	res = deferred.apply(res)
 	log.Info("ending reconcile of VerticaDB", "result", res, "err", err)
 	return res, err
 }
 
+// updateReconcileConditions will set the Ready, Progressing and Degraded
+// conditions for the outcome of the reconcile.  Failures are only logged so
+// that they don't hide the result of the reconcile.
+func (r *VerticaDBReconciler) updateReconcileConditions(ctx context.Context, log logr.Logger, vdb *vapi.VerticaDB,
+	pfacts *PodFacts, abort *ReconcileAbort) {
+	sr := &StatusReconciler{Client: r.Client, Scheme: r.Scheme, Log: log, Vdb: vdb, PFacts: pfacts}
+	if err := sr.UpdateConditions(ctx, abort); err != nil {
+		log.Info("failed to update the reconcile conditions", "err", err)
+	}
+}
+
+// updatePausedCondition will set the Paused condition from the pause-reconcile
+// annotation.  An event is written each time the reconcile gets paused or
+// resumed.  The condition is left out of the status until the first pause.
+func (r *VerticaDBReconciler) updatePausedCondition(ctx context.Context, vdb *vapi.VerticaDB,
+	pauseAll bool, pausedActors map[string]bool) error {
+	ps := controllers.MakePauseState("VerticaDB", pauseAll, pausedActors)
+	cond := vapi.VerticaDBCondition{
+		Type:               vapi.Paused,
+		Status:             ps.Status,
+		Reason:             ps.Reason,
+		Message:            ps.Message,
+		ObservedGeneration: vdb.Generation,
+	}
+
+	wasPaused, err := vdb.IsConditionSet(vapi.Paused)
+	if err != nil {
+		return err
+	}
+	inx := vapi.PausedIndex
+	if inx < len(vdb.Status.Conditions) {
+		cur := &vdb.Status.Conditions[inx]
+		if cur.Status == cond.Status && cur.Reason == cond.Reason && cur.Message == cond.Message {
+			return nil
+		}
+	} else if cond.Status == corev1.ConditionFalse {
+		return nil
+	}
+	if err := vdbstatus.UpdateCondition(ctx, r.Client, vdb, cond); err != nil {
+		return err
+	}
+	isPaused := ps.IsPaused()
+	switch {
+	case isPaused && !wasPaused:
+		r.EVRec.Eventf(vdb, corev1.EventTypeWarning, events.ReconcilePaused,
+			"Reconcile paused with the %s annotation: %s", vapi.PauseReconcileAnnotation, cond.Message)
+	case !isPaused && wasPaused:
+		r.EVRec.Event(vdb, corev1.EventTypeNormal, events.ReconcileResumed, "Reconcile of the VerticaDB resumed")
+	}
+	return nil
+}
+
+// savePodFacts will keep the pod facts for the next reconcile.  If the
+// reconcile failed, we don't know what state the pods were left in, so we
+// drop the cached facts instead.
+func (r *VerticaDBReconciler) savePodFacts(vdb *vapi.VerticaDB, pfacts *PodFacts, err error) {
+	if err != nil {
+		r.PFactsCache.Invalidate(vdb.ExtractNamespacedName())
+		return
+	}
+	r.PFactsCache.Store(vdb, pfacts)
+}
+
 // constructActors will a list of actors that should be run for the reconcile.
 // Order matters in that some actors depend on the successeful execution of
 // earlier ones.
-func (r *VerticaDBReconciler) constructActors(log logr.Logger, vdb *vapi.VerticaDB, prunner *cmds.ClusterPodRunner,
+func (r *VerticaDBReconciler) constructActors(log logr.Logger, vdb *vapi.VerticaDB, prunner cmds.PodRunner,
 	pfacts *PodFacts) []controllers.ReconcileActor {
+	// A hibernated database has no pods, so only a few actors apply to it.
+	// The HibernateReconciler will notice when it needs to be resumed.
+	if vdb.IsHibernated() {
+		return []controllers.ReconcileActor{
+			MakeStatusReconciler(r.Client, r.Scheme, log, vdb, pfacts),
+			MakeHibernateReconciler(r, log, vdb, prunner, pfacts),
+			MakeMetricReconciler(r, vdb, prunner, pfacts),
+		}
+	}
 	// The actors that will be applied, in sequence, to reconcile a vdb.
 	// Note, we run the StatusReconciler multiple times. This allows us to
 	// refresh the status of the vdb as we do operations that affect it.
@@ -149,6 +331,9 @@ func (r *VerticaDBReconciler) constructActors(log logr.Logger, vdb *vapi.Vertica
 		// Handle upgrade actions for any k8s objects created in prior versions
 		// of the operator.
 		MakeUpgradeOperator120Reconciler(r, log, vdb),
+		// Stop the database and scale down its pods if it is to be hibernated,
+		// or bring them back if it is being resumed.
+		MakeHibernateReconciler(r, log, vdb, prunner, pfacts),
 		// Create a TLS secret for the HTTP server
 		MakeHTTPServerCertGenReconciler(r, vdb),
 		// Update any k8s objects with some exceptions. For instance, preserve
@@ -178,6 +363,8 @@ func (r *VerticaDBReconciler) constructActors(log logr.Logger, vdb *vapi.Vertica
 		MakeClientRoutingLabelReconciler(r, vdb, pfacts, DelNodeApplyMethod, ""),
 		// Wait for any nodes that are pending delete with active connections to leave.
 		MakeDrainNodeReconciler(r, vdb, prunner, pfacts),
+		// Stop the nodes of any subcluster that is to be shut down
+		MakeSubclusterShutdownReconciler(r, log, vdb, prunner, pfacts),
 		// Handles calls to admintools -t db_remove_subcluster
 		MakeDBRemoveSubclusterReconciler(r, log, vdb, prunner, pfacts),
 		MakeStatusReconciler(r.Client, r.Scheme, log, vdb, pfacts),
@@ -200,9 +387,11 @@ func (r *VerticaDBReconciler) constructActors(log logr.Logger, vdb *vapi.Vertica
 		MakeCreateDBReconciler(r, log, vdb, prunner, pfacts),
 		// Handle calls to admintools -t revive_db
 		MakeReviveDBReconciler(r, log, vdb, prunner, pfacts),
+		// Handle calls to vbr to restore a database from a restore point
+		MakeRestoreDBReconciler(r, log, vdb, prunner, pfacts),
 		MakeMetricReconciler(r, vdb, prunner, pfacts),
-		// Create and revive are mutually exclusive exclusive, so this handles
-		// status updates after both of them.
+		// Create, revive and restore are mutually exclusive, so this handles
+		// status updates after all of them.
 		MakeStatusReconciler(r.Client, r.Scheme, log, vdb, pfacts),
 		// Update the labels in pods so that Services route to nodes to them.
 		MakeClientRoutingLabelReconciler(r, vdb, pfacts, PodRescheduleApplyMethod, ""),
@@ -222,6 +411,17 @@ func (r *VerticaDBReconciler) constructActors(log logr.Logger, vdb *vapi.Vertica
 		MakeClientRoutingLabelReconciler(r, vdb, pfacts, AddNodeApplyMethod, ""),
 		// Resize any PVs if the local data size changed in the vdb
 		MakeResizePVReconciler(r, vdb, prunner, pfacts),
+		// Change the superuser password if the secret was updated
+		MakeSuperuserPasswdReconciler(r, log, vdb, prunner, pfacts),
+		// Renew the http server certificate if it is about to expire
+		MakeHTTPServerCertRenewReconciler(r, log, vdb, prunner, pfacts),
+		// Set the server TLS configuration for client connections
+		MakeClientTLSReconciler(r, log, vdb, prunner, pfacts),
+		// Set new communal credentials if the credential secrets changed
+		MakeCommunalCredsReconciler(r, log, vdb, prunner, pfacts),
+		// Set any configuration parameters that differ from the spec.  This
+		// must be last as it requeues to periodically check for drift.
+		MakeConfigParmsReconciler(r, log, vdb, prunner, pfacts),
 	}
 }
 
@@ -250,6 +450,22 @@ func (r *VerticaDBReconciler) GetSuperuserPassword(ctx context.Context, vdb *vap
 	return passwd, nil
 }
 
+// makeDispatcher will build the vadmin.Dispatcher to use for the vdb.  This is
+// always admintools, unless the experimental annotation to fetch the node
+// state through the http server is set.
+func (r *VerticaDBReconciler) makeDispatcher(log logr.Logger, vdb *vapi.VerticaDB, prunner cmds.PodRunner) vadmin.Dispatcher {
+	at := vadmin.MakeAdmintools(log, vdb, prunner)
+	// The requests to the http server can't be recorded, so dry-run mode
+	// reports the admintools commands instead.
+	if !vdb.IsExperimentalHTTPSNodeStateEnabled() || r.dryRunPlan != nil {
+		return at
+	}
+	getPassword := func(ctx context.Context) (string, error) {
+		return r.GetActiveSuperuserPassword(ctx, vdb, log)
+	}
+	return vadmin.MakeHTTPS(log, vdb, r.Client, getPassword, at)
+}
+
 // checkShardToNodeRatio will check the subclusters ratio of shards to node.  If
 // it is outside the bounds of optimal value then an event is written to inform
 // the user.
@@ -0,0 +1,166 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/controllers"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	"github.com/vertica/vertica-kubernetes/pkg/test"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// maintenanceGatedActor is an actor whose only step waits for a maintenance
+// window
+type maintenanceGatedActor struct {
+	vdb *vapi.VerticaDB
+}
+
+func (m *maintenanceGatedActor) Reconcile(ctx context.Context, _ *ctrl.Request) (ctrl.Result, error) {
+	_, err := waitForMaintenanceWindow(ctx, vdbRec, logger, m.vdb, "rebalance the shards")
+	return ctrl.Result{}, err
+}
+
+// countingActor is an actor that counts the number of times it ran
+type countingActor struct {
+	runs int
+}
+
+func (c *countingActor) Reconcile(_ context.Context, _ *ctrl.Request) (ctrl.Result, error) {
+	c.runs++
+	return ctrl.Result{}, nil
+}
+
+// deferringActor is an actor that finishes but wants to run again later
+type deferringActor struct {
+	requeueAfter time.Duration
+}
+
+func (d *deferringActor) Reconcile(ctx context.Context, _ *ctrl.Request) (ctrl.Result, error) {
+	deferRequeue(ctx, d.requeueAfter)
+	return ctrl.Result{}, nil
+}
+
+var _ = Describe("verticadb_controller", func() {
+	ctx := context.Background()
+
+	It("should skip the reconcile and set the Paused condition when paused", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Annotations[vapi.PauseReconcileAnnotation] = "true"
+		test.CreateVDB(ctx, k8sClient, vdb)
+		defer test.DeleteVDB(ctx, k8sClient, vdb)
+
+		Expect(vdbRec.Reconcile(ctx, ctrl.Request{NamespacedName: vdb.ExtractNamespacedName()})).Should(Equal(ctrl.Result{}))
+		fetchVdb := &vapi.VerticaDB{}
+		Expect(k8sClient.Get(ctx, vdb.ExtractNamespacedName(), fetchVdb)).Should(Succeed())
+		Expect(len(fetchVdb.Status.Conditions)).Should(Equal(vapi.PausedIndex + 1))
+		cond := fetchVdb.Status.Conditions[vapi.PausedIndex]
+		Expect(cond.Status).Should(Equal(corev1.ConditionTrue))
+		Expect(cond.Reason).Should(Equal(vapi.ReasonReconcilePaused))
+		// Nothing else was reconciled, so the statefulsets don't exist
+		Expect(fetchVdb.Status.Subclusters).Should(BeEmpty())
+	})
+
+	It("should only add the Paused condition once the reconcile is paused", func() {
+		vdb := vapi.MakeVDB()
+		test.CreateVDB(ctx, k8sClient, vdb)
+		defer test.DeleteVDB(ctx, k8sClient, vdb)
+
+		Expect(vdbRec.updatePausedCondition(ctx, vdb, false, nil)).Should(Succeed())
+		Expect(vdb.Status.Conditions).Should(BeEmpty())
+
+		Expect(vdbRec.updatePausedCondition(ctx, vdb, false,
+			map[string]bool{"RestartReconciler": true, "UpgradeOperator120Reconciler": true})).Should(Succeed())
+		Expect(len(vdb.Status.Conditions)).Should(Equal(vapi.PausedIndex + 1))
+		cond := vdb.Status.Conditions[vapi.PausedIndex]
+		Expect(cond.Status).Should(Equal(corev1.ConditionTrue))
+		Expect(cond.Reason).Should(Equal(vapi.ReasonActorsPaused))
+		Expect(cond.Message).Should(ContainSubstring("RestartReconciler, UpgradeOperator120Reconciler"))
+
+		Expect(vdbRec.updatePausedCondition(ctx, vdb, false, nil)).Should(Succeed())
+		cond = vdb.Status.Conditions[vapi.PausedIndex]
+		Expect(cond.Status).Should(Equal(corev1.ConditionFalse))
+		Expect(cond.Reason).Should(Equal(vapi.ReasonAsExpected))
+	})
+
+	It("should only report the changes in dry-run mode", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Annotations[vapi.DryRunAnnotation] = "true"
+		test.CreateVDB(ctx, k8sClient, vdb)
+		defer test.DeleteVDB(ctx, k8sClient, vdb)
+
+		fpr := &cmds.FakePodRunner{}
+		req := ctrl.Request{NamespacedName: vdb.ExtractNamespacedName()}
+		Expect(vdbRec.reconcileDryRun(ctx, logger, &req, vdb, fpr, nil)).Should(Equal(ctrl.Result{}))
+
+		// Nothing was created other than the ConfigMap with the plan
+		sts := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, names.GenStsName(vdb, &vdb.Spec.Subclusters[0]), sts)).ShouldNot(Succeed())
+		cm := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, names.GenDryRunConfigMapName(vdb), cm)).Should(Succeed())
+		defer func() { Expect(k8sClient.Delete(ctx, cm)).Should(Succeed()) }()
+		stsNm := names.GenStsName(vdb, &vdb.Spec.Subclusters[0])
+		Expect(cm.Data[DryRunPlanKey]).Should(ContainSubstring(
+			fmt.Sprintf("ObjReconciler: Create StatefulSet %s/%s", stsNm.Namespace, stsNm.Name)))
+	})
+	It("should run the actors after one that waits for a maintenance window", func() {
+		vdb := vapi.MakeVDB()
+		// A window that opened a minute ago and already closed
+		opened := time.Now().UTC().Add(-time.Minute)
+		vdb.Spec.MaintenanceWindows = []vapi.MaintenanceWindow{
+			{Schedule: opened.Format("4 15 * * *"), Duration: metav1.Duration{Duration: time.Second}},
+		}
+		test.CreateVDB(ctx, k8sClient, vdb)
+		defer test.DeleteVDB(ctx, k8sClient, vdb)
+
+		pfacts := createPodFactsDefault(&cmds.FakePodRunner{})
+		later := &countingActor{}
+		actors := []controllers.ReconcileActor{&maintenanceGatedActor{vdb: vdb}, later}
+		req := ctrl.Request{NamespacedName: vdb.ExtractNamespacedName()}
+		res, err := vdbRec.runActors(ctx, logger, &req, vdb, pfacts, actors, nil)
+		Expect(err).Should(Succeed())
+		Expect(later.runs).Should(Equal(1))
+		Expect(res.RequeueAfter).Should(BeNumerically(">", 23*time.Hour))
+		Expect(vdb.Status.MaintenanceWindowStatus).Should(Equal("waiting for maintenance window to rebalance the shards"))
+	})
+
+	It("should return the soonest requeue of the actors that finished", func() {
+		vdb := vapi.MakeVDB()
+		test.CreateVDB(ctx, k8sClient, vdb)
+		defer test.DeleteVDB(ctx, k8sClient, vdb)
+
+		pfacts := createPodFactsDefault(&cmds.FakePodRunner{})
+		later := &countingActor{}
+		actors := []controllers.ReconcileActor{
+			&deferringActor{requeueAfter: 5 * time.Minute},
+			&deferringActor{requeueAfter: time.Minute},
+			later,
+		}
+		req := ctrl.Request{NamespacedName: vdb.ExtractNamespacedName()}
+		Expect(vdbRec.runActors(ctx, logger, &req, vdb, pfacts, actors, nil)).Should(Equal(ctrl.Result{RequeueAfter: time.Minute}))
+		Expect(later.runs).Should(Equal(1))
+	})
+})
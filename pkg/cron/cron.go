@@ -0,0 +1,250 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression.  It uses the standard five field
+// format: minute, hour, day of month, month and day of week.  All times are
+// evaluated in UTC, unless the schedule was parsed with ParseInLocation.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// The time zone the fields are evaluated in
+	loc *time.Location
+	// True if the day of month or day of week field was anything other than
+	// '*'.  This follows the cron convention where, if both are restricted, a
+	// day matches if either of them match.
+	domRestricted, dowRestricted bool
+}
+
+// bounds describes the allowed values for a single field
+type bounds struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteBounds = bounds{"minute", 0, 59, nil}
+	hourBounds   = bounds{"hour", 0, 23, nil}
+	domBounds    = bounds{"day of month", 1, 31, nil}
+	monthBounds  = bounds{"month", 1, 12, map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Day of week allows 7 as an alias for Sunday
+	dowBounds = bounds{"day of week", 0, 7, map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// descriptors are the predefined schedules that can be used in place of the
+// five fields.
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// maxSearchYears is how far in the future Next will look for a matching time.
+// This protects against schedules that can never fire, such as Feb 30.
+const maxSearchYears = 5
+
+// Parse will parse a cron expression and return the Schedule for it
+func Parse(spec string) (*Schedule, error) {
+	return ParseInLocation(spec, time.UTC)
+}
+
+// ParseInLocation is like Parse but the schedule is evaluated in the given
+// time zone.  For example, "0 2 * * *" in America/New_York fires at 2am New
+// York time.
+func ParseInLocation(spec string, loc *time.Location) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "@") {
+		expanded, ok := descriptors[strings.ToLower(spec)]
+		if !ok {
+			return nil, fmt.Errorf("unrecognized descriptor: %s", spec)
+		}
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	const NumFields = 5
+	if len(fields) != NumFields {
+		return nil, fmt.Errorf("expected %d fields, found %d: %s", NumFields, len(fields), spec)
+	}
+
+	s := &Schedule{loc: loc}
+	var err error
+	if s.minute, err = parseField(fields[0], &minuteBounds); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseField(fields[1], &hourBounds); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseField(fields[2], &domBounds); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseField(fields[3], &monthBounds); err != nil {
+		return nil, err
+	}
+	if s.dow, err = parseField(fields[4], &dowBounds); err != nil {
+		return nil, err
+	}
+	// Sunday can be given as 0 or 7.  We only check for 0 when matching.
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domRestricted = fields[2] != "*"
+	s.dowRestricted = fields[4] != "*"
+	return s, nil
+}
+
+// Next returns the first time after t that matches the schedule.  The zero
+// time is returned if the schedule never fires.
+func (s *Schedule) Next(t time.Time) time.Time {
+	// Start at the next whole minute
+	loc := s.loc
+	t = t.In(loc).Truncate(time.Minute).Add(time.Minute)
+	yearLimit := t.Year() + maxSearchYears
+
+	for t.Year() <= yearLimit {
+		if !isSet(s.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !isSet(s.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if !isSet(s.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches returns true if the day of t satisfies the day of month and day
+// of week fields.
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := isSet(s.dom, t.Day())
+	dowMatch := isSet(s.dow, int(t.Weekday()))
+	if s.domRestricted && s.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+// parseField will parse a single field of the cron expression.  It returns a
+// bitset with a bit set for each value that the field matches.
+func parseField(field string, b *bounds) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(field, ",") {
+		itemBits, err := parseItem(item, b)
+		if err != nil {
+			return 0, err
+		}
+		bits |= itemBits
+	}
+	return bits, nil
+}
+
+// parseItem will parse one comma separated item of a field.  An item has the
+// form: '*', 'v', 'v-v', each of which can optionally have a '/step' suffix.
+func parseItem(item string, b *bounds) (uint64, error) {
+	rangeAndStep := strings.Split(item, "/")
+	if len(rangeAndStep) > 2 {
+		return 0, fmt.Errorf("invalid %s: %s", b.name, item)
+	}
+
+	var start, end int
+	var err error
+	switch lowAndHigh := strings.Split(rangeAndStep[0], "-"); {
+	case rangeAndStep[0] == "*":
+		start, end = b.min, b.max
+	case len(lowAndHigh) == 1:
+		if start, err = parseValue(lowAndHigh[0], b); err != nil {
+			return 0, err
+		}
+		end = start
+		// A single value with a step means from that value to the max
+		if len(rangeAndStep) == 2 {
+			end = b.max
+		}
+	case len(lowAndHigh) == 2:
+		if start, err = parseValue(lowAndHigh[0], b); err != nil {
+			return 0, err
+		}
+		if end, err = parseValue(lowAndHigh[1], b); err != nil {
+			return 0, err
+		}
+	default:
+		return 0, fmt.Errorf("invalid %s: %s", b.name, item)
+	}
+	if start > end {
+		return 0, fmt.Errorf("invalid %s range, start is after end: %s", b.name, item)
+	}
+
+	step := 1
+	if len(rangeAndStep) == 2 {
+		step, err = strconv.Atoi(rangeAndStep[1])
+		if err != nil || step <= 0 {
+			return 0, fmt.Errorf("invalid %s step: %s", b.name, item)
+		}
+	}
+
+	var bits uint64
+	for i := start; i <= end; i += step {
+		bits |= 1 << uint(i)
+	}
+	return bits, nil
+}
+
+// parseValue will parse a single value in a field.  This can either be a
+// number or, for fields that allow it, a three letter name.
+func parseValue(val string, b *bounds) (int, error) {
+	if n, ok := b.names[strings.ToLower(val)]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %s", b.name, val)
+	}
+	if n < b.min || n > b.max {
+		return 0, fmt.Errorf("%s out of range [%d-%d]: %d", b.name, b.min, b.max, n)
+	}
+	return n, nil
+}
+
+// isSet returns true if the bit for val is set in the bitset
+func isSet(bits uint64, val int) bool {
+	return bits&(1<<uint(val)) != 0
+}
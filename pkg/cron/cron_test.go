@@ -0,0 +1,96 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package cron
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestAPIs(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "cron Suite")
+}
+
+// mustNext is a test helper that parses the spec and returns the next time
+// after the given time
+func mustNext(spec, after string) string {
+	s, err := Parse(spec)
+	ExpectWithOffset(1, err).Should(Succeed())
+	t, err := time.Parse(time.RFC3339, after)
+	ExpectWithOffset(1, err).Should(Succeed())
+	return s.Next(t).Format(time.RFC3339)
+}
+
+var _ = Describe("cron", func() {
+	It("should reject invalid expressions", func() {
+		for _, spec := range []string{
+			"", "* * * *", "* * * * * *", "60 * * * *", "* 24 * * *", "* * 0 * *",
+			"* * * 13 *", "* * * * 8", "5-1 * * * *", "*/0 * * * *", "a * * * *", "@every5m",
+		} {
+			_, err := Parse(spec)
+			Expect(err).ShouldNot(Succeed(), spec)
+		}
+	})
+
+	It("should find the next time for simple schedules", func() {
+		Expect(mustNext("* * * * *", "2023-05-01T10:15:30Z")).Should(Equal("2023-05-01T10:16:00Z"))
+		Expect(mustNext("0 2 * * *", "2023-05-01T10:15:00Z")).Should(Equal("2023-05-02T02:00:00Z"))
+		Expect(mustNext("0 2 * * *", "2023-05-01T01:59:00Z")).Should(Equal("2023-05-01T02:00:00Z"))
+		Expect(mustNext("*/15 * * * *", "2023-05-01T10:15:00Z")).Should(Equal("2023-05-01T10:30:00Z"))
+		Expect(mustNext("30 1,13 * * *", "2023-05-01T10:15:00Z")).Should(Equal("2023-05-01T13:30:00Z"))
+		Expect(mustNext("0 9-17/4 * * *", "2023-05-01T14:00:00Z")).Should(Equal("2023-05-01T17:00:00Z"))
+	})
+
+	It("should handle month and year rollover", func() {
+		Expect(mustNext("0 0 1 * *", "2023-12-15T00:00:00Z")).Should(Equal("2024-01-01T00:00:00Z"))
+		Expect(mustNext("0 0 29 2 *", "2023-03-01T00:00:00Z")).Should(Equal("2024-02-29T00:00:00Z"))
+		Expect(mustNext("@yearly", "2023-05-01T00:00:00Z")).Should(Equal("2024-01-01T00:00:00Z"))
+	})
+
+	It("should handle day of week and day of month", func() {
+		// 2023-05-01 is a Monday
+		Expect(mustNext("0 0 * * sun", "2023-05-01T00:00:00Z")).Should(Equal("2023-05-07T00:00:00Z"))
+		Expect(mustNext("0 0 * * 7", "2023-05-01T00:00:00Z")).Should(Equal("2023-05-07T00:00:00Z"))
+		Expect(mustNext("@weekly", "2023-05-01T00:00:00Z")).Should(Equal("2023-05-07T00:00:00Z"))
+		Expect(mustNext("0 0 * * MON-FRI", "2023-05-05T12:00:00Z")).Should(Equal("2023-05-08T00:00:00Z"))
+		// When both are restricted either one can match
+		Expect(mustNext("0 0 15 * fri", "2023-05-01T00:00:00Z")).Should(Equal("2023-05-05T00:00:00Z"))
+		Expect(mustNext("0 0 15 * fri", "2023-05-12T00:00:00Z")).Should(Equal("2023-05-15T00:00:00Z"))
+	})
+
+	It("should return the zero time if the schedule never fires", func() {
+		s, err := Parse("0 0 30 2 *")
+		Expect(err).Should(Succeed())
+		Expect(s.Next(time.Now()).IsZero()).Should(BeTrue())
+	})
+
+	It("should evaluate the schedule in the time zone it was parsed with", func() {
+		loc := time.FixedZone("UTC-4", -4*60*60)
+		s, err := ParseInLocation("0 2 * * *", loc)
+		Expect(err).Should(Succeed())
+		t, err := time.Parse(time.RFC3339, "2023-05-01T04:00:00Z")
+		Expect(err).Should(Succeed())
+		Expect(s.Next(t).UTC().Format(time.RFC3339)).Should(Equal("2023-05-01T06:00:00Z"))
+		t, err = time.Parse(time.RFC3339, "2023-05-01T06:00:00Z")
+		Expect(err).Should(Succeed())
+		Expect(s.Next(t).UTC().Format(time.RFC3339)).Should(Equal("2023-05-02T06:00:00Z"))
+	})
+})
@@ -0,0 +1,140 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// Client is a client.Client that reads from the cluster but never changes
+// anything in it.  Each write is recorded in a plan instead.  Writes to the
+// status are dropped because they only reflect the state of the objects.
+type Client struct {
+	client.Client
+	Plan *Plan
+}
+
+// MakeClient will build a Client object that wraps a client that does the
+// reads.
+func MakeClient(cli client.Client, plan *Plan) *Client {
+	return &Client{Client: cli, Plan: plan}
+}
+
+// Create records the create of the object
+func (c *Client) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.Plan.Record("Create", c.describe(obj), "")
+	return nil
+}
+
+// Update records the update of the object
+func (c *Client) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.Plan.Record("Update", c.describe(obj), "")
+	return nil
+}
+
+// Patch records the patch of the object along with the patch contents
+func (c *Client) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	detail := ""
+	if data, err := patch.Data(obj); err == nil {
+		detail = string(data)
+	}
+	c.Plan.Record("Patch", c.describe(obj), detail)
+	return nil
+}
+
+// Delete records the delete of the object
+func (c *Client) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	c.Plan.Record("Delete", c.describe(obj), "")
+	return nil
+}
+
+// DeleteAllOf records the delete of all objects of a type
+func (c *Client) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	c.Plan.Record("DeleteAllOf", c.kindOf(obj), "")
+	return nil
+}
+
+// Status returns a writer that drops all status changes
+func (c *Client) Status() client.SubResourceWriter {
+	return &subResourceClient{SubResourceReader: c.Client.SubResource("status")}
+}
+
+// SubResource returns a client for the subresource that can read it but will
+// only record the changes.  This covers things like pod evictions.
+func (c *Client) SubResource(subResource string) client.SubResourceClient {
+	return &subResourceClient{
+		SubResourceReader: c.Client.SubResource(subResource),
+		subResource:       subResource,
+		c:                 c,
+	}
+}
+
+// describe returns the kind and name of the object for use in the plan
+func (c *Client) describe(obj client.Object) string {
+	nm := obj.GetName()
+	if nm == "" {
+		nm = obj.GetGenerateName() + "*"
+	}
+	return fmt.Sprintf("%s %s/%s", c.kindOf(obj), obj.GetNamespace(), nm)
+}
+
+// kindOf returns the kind of the object.  Typed objects often don't have the
+// kind set, so we find it through the scheme.
+func (c *Client) kindOf(obj runtime.Object) string {
+	if kind := obj.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+		return kind
+	}
+	if gvk, err := apiutil.GVKForObject(obj, c.Scheme()); err == nil {
+		return gvk.Kind
+	}
+	return fmt.Sprintf("%T", obj)
+}
+
+// subResourceClient reads a subresource but doesn't change it.  If c is nil,
+// the changes are dropped rather than recorded.
+type subResourceClient struct {
+	client.SubResourceReader
+	subResource string
+	c           *Client
+}
+
+func (s *subResourceClient) record(verb string, obj client.Object) {
+	if s.c != nil {
+		s.c.Plan.Record(verb, fmt.Sprintf("%s (%s)", s.c.describe(obj), s.subResource), "")
+	}
+}
+
+func (s *subResourceClient) Create(ctx context.Context, obj, subResource client.Object,
+	opts ...client.SubResourceCreateOption) error {
+	s.record("Create", obj)
+	return nil
+}
+
+func (s *subResourceClient) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	s.record("Update", obj)
+	return nil
+}
+
+func (s *subResourceClient) Patch(ctx context.Context, obj client.Object, patch client.Patch,
+	opts ...client.SubResourcePatchOption) error {
+	s.record("Patch", obj)
+	return nil
+}
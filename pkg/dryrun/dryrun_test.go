@@ -0,0 +1,84 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("dryrun", func() {
+	ctx := context.Background()
+
+	It("should record the actions against the current actor", func() {
+		plan := MakePlan()
+		Expect(plan.String()).Should(Equal("No changes\n"))
+		plan.SetActor("RestartReconciler")
+		plan.Record("Exec", "pod v-main-0", "admintools -t restart_node")
+		plan.Record("Exec", "pod v-main-0", "admintools -t restart_node")
+		plan.SetActor("ObjReconciler")
+		plan.Recordf("Delete", "Pod default/v-main-1", "")
+		Expect(plan.Actions()).Should(HaveLen(2))
+		Expect(plan.String()).Should(Equal(
+			"RestartReconciler: Exec pod v-main-0: admintools -t restart_node\nObjReconciler: Delete Pod default/v-main-1\n"))
+	})
+
+	It("should read objects but only record the changes", func() {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "v-main-0"}}
+		plan := MakePlan()
+		c := MakeClient(fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(pod).Build(), plan)
+
+		fetched := &corev1.Pod{}
+		nm := types.NamespacedName{Namespace: "default", Name: "v-main-0"}
+		Expect(c.Get(ctx, nm, fetched)).Should(Succeed())
+
+		Expect(c.Delete(ctx, fetched)).Should(Succeed())
+		svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "v-main"}}
+		Expect(c.Create(ctx, svc)).Should(Succeed())
+		patch := client.MergeFrom(fetched.DeepCopy())
+		fetched.Labels = map[string]string{"a": "b"}
+		Expect(c.Patch(ctx, fetched, patch)).Should(Succeed())
+		fetched.Status.Message = "changed"
+		Expect(c.Status().Update(ctx, fetched)).Should(Succeed())
+
+		// The pod is unchanged and the service was never created
+		Expect(c.Get(ctx, nm, fetched)).Should(Succeed())
+		Expect(fetched.Labels).Should(BeEmpty())
+		Expect(fetched.Status.Message).Should(BeEmpty())
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(svc), &corev1.Service{})).ShouldNot(Succeed())
+
+		Expect(plan.Actions()).Should(Equal([]Action{
+			{Verb: "Delete", Target: "Pod default/v-main-0"},
+			{Verb: "Create", Target: "Service default/v-main"},
+			{Verb: "Patch", Target: "Pod default/v-main-0", Detail: `{"metadata":{"labels":{"a":"b"}}}`},
+		}))
+	})
+
+	It("should record events instead of writing them", func() {
+		plan := MakePlan()
+		ev := MakeEventRecorder(plan)
+		ev.Eventf(&corev1.Pod{}, corev1.EventTypeNormal, "NodeRestartStarted", "Restarting %d nodes", 3)
+		Expect(plan.String()).Should(Equal("Event: Normal NodeRestartStarted: Restarting 3 nodes\n"))
+	})
+})
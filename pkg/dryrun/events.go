@@ -0,0 +1,50 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// EventRecorder is a record.EventRecorder that adds the events to a plan
+// rather than writing them.  Events like "restart started" would be
+// misleading if nothing was restarted.
+type EventRecorder struct {
+	Plan *Plan
+}
+
+var _ record.EventRecorder = &EventRecorder{}
+
+// MakeEventRecorder will build an EventRecorder object
+func MakeEventRecorder(plan *Plan) *EventRecorder {
+	return &EventRecorder{Plan: plan}
+}
+
+func (e *EventRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	e.Plan.Recordf("Event", "", "%s %s: %s", eventtype, reason, message)
+}
+
+func (e *EventRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	e.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (e *EventRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string,
+	eventtype, reason, messageFmt string, args ...interface{}) {
+	e.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
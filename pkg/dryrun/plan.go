@@ -0,0 +1,113 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Action is a single change the operator would have made
+type Action struct {
+	// The name of the reconcile actor that wanted to make the change
+	Actor string
+	// What would have been done.  For example, Create, Delete or Exec.
+	Verb string
+	// The object or pod the action applies to
+	Target string
+	// Optional details of the action, such as the command that would run
+	Detail string
+}
+
+// String returns a printable form of the action
+func (a *Action) String() string {
+	var sb strings.Builder
+	if a.Actor != "" {
+		sb.WriteString(a.Actor)
+		sb.WriteString(": ")
+	}
+	sb.WriteString(a.Verb)
+	if a.Target != "" {
+		sb.WriteString(" ")
+		sb.WriteString(a.Target)
+	}
+	if a.Detail != "" {
+		sb.WriteString(": ")
+		sb.WriteString(a.Detail)
+	}
+	return sb.String()
+}
+
+// Plan collects the actions the operator would have made during a reconcile
+type Plan struct {
+	// Guards the fields below.  Pod facts are collected from many pods at
+	// once, so actions can be recorded concurrently.
+	mu      sync.Mutex
+	actor   string
+	actions []Action
+}
+
+// MakePlan will build an empty Plan object
+func MakePlan() *Plan {
+	return &Plan{}
+}
+
+// SetActor sets the actor that subsequent actions are recorded against
+func (p *Plan) SetActor(actor string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.actor = actor
+}
+
+// Record adds an action to the plan.  An action that is identical to the one
+// before it is dropped, as some actors repeat the same change while they
+// retry.
+func (p *Plan) Record(verb, target, detail string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	a := Action{Actor: p.actor, Verb: verb, Target: target, Detail: detail}
+	if n := len(p.actions); n > 0 && p.actions[n-1] == a {
+		return
+	}
+	p.actions = append(p.actions, a)
+}
+
+// Recordf is like Record but the details are built with a format string
+func (p *Plan) Recordf(verb, target, detailFmt string, args ...interface{}) {
+	p.Record(verb, target, fmt.Sprintf(detailFmt, args...))
+}
+
+// Actions returns a copy of the actions recorded so far
+func (p *Plan) Actions() []Action {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Action{}, p.actions...)
+}
+
+// String returns the plan with one action per line
+func (p *Plan) String() string {
+	actions := p.Actions()
+	if len(actions) == 0 {
+		return "No changes\n"
+	}
+	var sb strings.Builder
+	for i := range actions {
+		sb.WriteString(actions[i].String())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
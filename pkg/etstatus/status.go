@@ -17,9 +17,11 @@ package etstatus
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/go-logr/logr"
 	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -56,6 +58,40 @@ func Apply(ctx context.Context, clnt client.Client, log logr.Logger, et *vapi.Ev
 	})
 }
 
+// UpdateCondition will update a condition status in the EventTrigger.  This
+// is a no-op if the status condition is already set.
+func UpdateCondition(ctx context.Context, clnt client.Client, log logr.Logger, et *vapi.EventTrigger,
+	condition *vapi.EventTriggerCondition) error {
+	inx, ok := vapi.EventTriggerConditionIndexMap[condition.Type]
+	if !ok {
+		return fmt.Errorf("eventTrigger condition '%s' missing from EventTriggerConditionType", condition.Type)
+	}
+	if condition.LastTransitionTime.IsZero() {
+		condition.LastTransitionTime = metav1.Now()
+	}
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		nm := et.ExtractNamespacedName()
+		if err := clnt.Get(ctx, nm, et); err != nil {
+			return err
+		}
+
+		// Ensure the array is big enough
+		for i := len(et.Status.Conditions); i <= inx; i++ {
+			et.Status.Conditions = append(et.Status.Conditions, vapi.EventTriggerCondition{})
+		}
+		// Only update if status is different change.  Cannot compare the entire
+		// condition since LastTransitionTime will be different each time.
+		cur := &et.Status.Conditions[inx]
+		if cur.Status == condition.Status && cur.Reason == condition.Reason && cur.Message == condition.Message {
+			return nil
+		}
+		et.Status.Conditions[inx] = *condition
+
+		log.Info("condition status update", "condition", condition)
+		return clnt.Status().Update(ctx, et)
+	})
+}
+
 // Fetch returns the status for the reference object. If one is not in the ET object, it will create a new one.
 func Fetch(et *vapi.EventTrigger, objRef *vapi.ETRefObject) *vapi.ETRefObjectStatus {
 	for i := range et.Status.References {
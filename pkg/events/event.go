@@ -32,6 +32,12 @@ const (
 	ReviveDBPermissionDenied        = "ReviveDBPermissionDenied"
 	ReviveDBNodeCountMismatch       = "ReviveDBNodeCountMismatch"
 	ReviveOrderBad                  = "ReviveOrderBad"
+	RestoreDBStart                  = "RestoreDBStart"
+	RestoreDBSucceeded              = "RestoreDBSucceeded"
+	RestoreDBFailed                 = "RestoreDBFailed"
+	RestorePointNotFound            = "RestorePointNotFound"
+	RestoreDBNodeCountMismatch      = "RestoreDBNodeCountMismatch"
+	BackupLocationAccessDenied      = "BackupLocationAccessDenied"
 	ObjectNotFound                  = "ObjectNotFound"
 	CommunalCredsWrongKey           = "CommunalCredsWrongKey" //nolint:gosec
 	S3EndpointIssue                 = "S3EndpointIssue"
@@ -83,6 +89,36 @@ const (
 	RunAgentStart                   = "RunAgentStart"
 	RunAgentSucceeded               = "RunAgentSucceeded"
 	RunAgentFailed                  = "RunAgentFailed"
+	HibernateStart                  = "HibernateStart"
+	HibernateSucceeded              = "HibernateSucceeded"
+	ResumeStart                     = "ResumeStart"
+	ResumeSucceeded                 = "ResumeSucceeded"
+	ResumeLocalDataLost             = "ResumeLocalDataLost"
+	SubclusterShutdownStart         = "SubclusterShutdownStart"
+	SubclusterShutdownSucceeded     = "SubclusterShutdownSucceeded"
+	SubclusterShutdownFailed        = "SubclusterShutdownFailed"
+	ConfigParametersSet             = "ConfigParametersSet"
+	ConfigParameterSetFailed        = "ConfigParameterSetFailed"
+	ConfigParameterRestartNeeded    = "ConfigParameterRestartNeeded"
+	InvalidConfigParameter          = "InvalidConfigParameter"
+	SuperuserPasswordChangeStart    = "SuperuserPasswordChangeStart"
+	SuperuserPasswordVerified       = "SuperuserPasswordVerified"
+	SuperuserPasswordChanged        = "SuperuserPasswordChanged"
+	SuperuserPasswordChangeFailed   = "SuperuserPasswordChangeFailed"
+	CommunalCredsUpdateStart        = "CommunalCredsUpdateStart"
+	CommunalCredsUpdated            = "CommunalCredsUpdated"
+	CommunalCredsUpdateFailed       = "CommunalCredsUpdateFailed"
+	CommunalCredsVerifyFailed       = "CommunalCredsVerifyFailed"
+	HTTPServerCertRenewed           = "HTTPServerCertRenewed"
+	HTTPServerCertRolledOut         = "HTTPServerCertRolledOut"
+	HTTPServerRestartFailed         = "HTTPServerRestartFailed"
+	CertManagerCertRequested        = "CertManagerCertRequested"
+	CertManagerNotInstalled         = "CertManagerNotInstalled"
+	CertManagerCertReissued         = "CertManagerCertReissued"
+	ClientTLSApplied                = "ClientTLSApplied"
+	ClientTLSApplyFailed            = "ClientTLSApplyFailed"
+	DryRunPlanUpdated               = "DryRunPlanUpdated"
+	MaintenanceWindowWait           = "MaintenanceWindowWait"
 )
 
 // Constants for VerticaAutoscaler reconciler
@@ -91,3 +127,21 @@ const (
 	VerticaDBNotFound             = "VerticaDBNotFound"
 	NoSubclusterTemplate          = "NoSubclusterTemplate"
 )
+
+// Constants for VerticaBackup reconciler
+const (
+	BackupStart             = "BackupStart"
+	BackupSucceeded         = "BackupSucceeded"
+	BackupFailed            = "BackupFailed"
+	BackupScheduleSuspended = "BackupScheduleSuspended"
+	BackupScheduleResumed   = "BackupScheduleResumed"
+	BackupScheduleInvalid   = "BackupScheduleInvalid"
+	RestorePointsPruned     = "RestorePointsPruned"
+	RestorePointPruneFailed = "RestorePointPruneFailed"
+)
+
+// Constants shared by all of the reconcilers
+const (
+	ReconcilePaused  = "ReconcilePaused"
+	ReconcileResumed = "ReconcileResumed"
+)
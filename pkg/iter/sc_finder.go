@@ -24,6 +24,8 @@ import (
 	"github.com/vertica/vertica-kubernetes/pkg/builder"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -92,6 +94,34 @@ func (m *SubclusterFinder) FindServices(ctx context.Context, flags FindFlags) (*
 	return svcs, nil
 }
 
+// FindPodDisruptionBudgets returns the PodDisruptionBudgets of the subclusters
+func (m *SubclusterFinder) FindPodDisruptionBudgets(ctx context.Context, flags FindFlags) (*policyv1.PodDisruptionBudgetList, error) {
+	pdbs := &policyv1.PodDisruptionBudgetList{}
+	if err := m.buildObjList(ctx, pdbs, flags); err != nil {
+		return nil, err
+	}
+	if flags&FindSorted != 0 {
+		sort.Slice(pdbs.Items, func(i, j int) bool {
+			return pdbs.Items[i].Name < pdbs.Items[j].Name
+		})
+	}
+	return pdbs, nil
+}
+
+// FindNetworkPolicies returns the NetworkPolicies of the subclusters
+func (m *SubclusterFinder) FindNetworkPolicies(ctx context.Context, flags FindFlags) (*networkingv1.NetworkPolicyList, error) {
+	nps := &networkingv1.NetworkPolicyList{}
+	if err := m.buildObjList(ctx, nps, flags); err != nil {
+		return nil, err
+	}
+	if flags&FindSorted != 0 {
+		sort.Slice(nps.Items, func(i, j int) bool {
+			return nps.Items[i].Name < nps.Items[j].Name
+		})
+	}
+	return nps, nil
+}
+
 // FindPods returns pod objects that are are used to run Vertica.  It limits the
 // pods that were created by the VerticaDB object.
 func (m *SubclusterFinder) FindPods(ctx context.Context, flags FindFlags) (*corev1.PodList, error) {
@@ -228,6 +258,10 @@ func getLabelsFromObject(obj runtime.Object) (map[string]string, bool) {
 		return svc.Labels, true
 	} else if pod, ok := obj.(*corev1.Pod); ok {
 		return pod.Labels, true
+	} else if pdb, ok := obj.(*policyv1.PodDisruptionBudget); ok {
+		return pdb.Labels, true
+	} else if np, ok := obj.(*networkingv1.NetworkPolicy); ok {
+		return np.Labels, true
 	}
 	return nil, false
 }
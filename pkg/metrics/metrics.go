@@ -33,16 +33,22 @@ const (
 	ClusterRestartSubsystem = "cluster_restart"
 	NodesRestartSubsystem   = "nodes_restart"
 	SubclusterSubsystem     = "subclusters"
+	HTTPServerSubsystem     = "http_server"
+	PodFactsSubsystem       = "pod_facts"
+	MgmtSubsystem           = "mgmt"
 
 	// Names of the labels that we can apply to metrics.
 	NamespaceLabel        = "namespace"
 	VerticaDBLabel        = "verticadb"
 	SubclusterOidLabel    = "subcluster_oid"
 	ReviveInstanceIDLabel = "revive_instance_id"
+	CommandLabel          = "command"
+	ReasonLabel           = "reason"
 )
 
 var (
 	AdminToolsBucket = []float64{1, 5, 10, 30, 60, 120, 300, 600}
+	PodFactsBucket   = []float64{0.1, 0.5, 1, 2, 5, 10, 30, 60, 120}
 
 	UpgradeCount = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -142,6 +148,44 @@ var (
 		},
 		[]string{NamespaceLabel, VerticaDBLabel, ReviveInstanceIDLabel, SubclusterOidLabel},
 	)
+	HTTPServerCertExpiry = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: HTTPServerSubsystem,
+			Name:      "cert_expiry_timestamp_seconds",
+			Help:      "The time, in seconds since the epoch, when the HTTP server certificate expires",
+		},
+		[]string{NamespaceLabel, VerticaDBLabel, ReviveInstanceIDLabel},
+	)
+	PodFactsCollectDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: PodFactsSubsystem,
+			Name:      "collection_seconds",
+			Help:      "The number of seconds it took to collect the pod facts for all of the pods",
+			Buckets:   PodFactsBucket,
+		},
+		[]string{NamespaceLabel, VerticaDBLabel, ReviveInstanceIDLabel},
+	)
+	PodFactsPodCollectDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: PodFactsSubsystem,
+			Name:      "pod_collection_seconds",
+			Help:      "The number of seconds it took to collect the pod facts for a single pod",
+			Buckets:   PodFactsBucket,
+		},
+		[]string{NamespaceLabel, VerticaDBLabel, ReviveInstanceIDLabel},
+	)
+	MgmtFailureCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: MgmtSubsystem,
+			Name:      "failures_total",
+			Help:      "The number of times a management command failed, labeled by the command and the class of failure",
+		},
+		[]string{NamespaceLabel, VerticaDBLabel, ReviveInstanceIDLabel, CommandLabel, ReasonLabel},
+	)
 	// Add new metrics above this comment.
 	//
 	// Once a metric is added a few other things need to be updated:
@@ -169,6 +213,10 @@ func init() {
 		TotalNodeCount,
 		RunningNodeCount,
 		UpNodeCount,
+		HTTPServerCertExpiry,
+		PodFactsCollectDuration,
+		PodFactsPodCollectDuration,
+		MgmtFailureCount,
 	)
 }
 
@@ -205,6 +253,10 @@ func HandleVDBDelete(namespaceName, vdbName string, log logr.Logger) {
 	TotalNodeCount.DeletePartialMatch(labels)
 	RunningNodeCount.DeletePartialMatch(labels)
 	UpNodeCount.DeletePartialMatch(labels)
+	HTTPServerCertExpiry.DeletePartialMatch(labels)
+	PodFactsCollectDuration.DeletePartialMatch(labels)
+	PodFactsPodCollectDuration.DeletePartialMatch(labels)
+	MgmtFailureCount.DeletePartialMatch(labels)
 }
 
 // HandleVDBInit will initialized metrics that use verticadb as a
@@ -222,6 +274,8 @@ func HandleVDBInit(vdb *vapi.VerticaDB) {
 	NodesRestartAttempt.WithLabelValues(vdb.Namespace, vdb.Name, reviveInstanceID)
 	NodesRestartFailed.WithLabelValues(vdb.Namespace, vdb.Name, reviveInstanceID)
 	NodesRestartDuration.WithLabelValues(vdb.Namespace, vdb.Name, reviveInstanceID)
+	PodFactsCollectDuration.WithLabelValues(vdb.Namespace, vdb.Name, reviveInstanceID)
+	PodFactsPodCollectDuration.WithLabelValues(vdb.Namespace, vdb.Name, reviveInstanceID)
 }
 
 // MakeVDBLabels return a prometheus.Labels that includes the VerticaDB name
@@ -244,6 +298,18 @@ func MakeSubclusterLabels(vdb *vapi.VerticaDB, scOid string) prometheus.Labels {
 	}
 }
 
+// MakeMgmtFailureLabels returns a prometheus.Labels that includes the
+// VerticaDB name, the management command and the reason it failed.
+func MakeMgmtFailureLabels(vdb *vapi.VerticaDB, cmd, reason string) prometheus.Labels {
+	return prometheus.Labels{
+		NamespaceLabel:        vdb.Namespace,
+		VerticaDBLabel:        vdb.Name,
+		ReviveInstanceIDLabel: getReviveInstanceID(vdb),
+		CommandLabel:          cmd,
+		ReasonLabel:           reason,
+	}
+}
+
 // getReviveInstanceID returns the revive instance ID stored in the vdb, or an
 // empty string if not present yet.
 func getReviveInstanceID(vdb *vapi.VerticaDB) string {
@@ -16,6 +16,7 @@
 package mgmterrors
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strings"
@@ -24,21 +25,23 @@ import (
 	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
 	"github.com/vertica/vertica-kubernetes/pkg/cloud"
 	"github.com/vertica/vertica-kubernetes/pkg/events"
-	corev1 "k8s.io/api/core/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // ATErrors handles event logging for errors that come back from admintools
 type ATErrors struct {
 	Writer               EVWriter
+	Client               client.Client // Used to save the failure in the status. This is optional.
 	VDB                  *vapi.VerticaDB
 	GenericFailureReason string // The failure reason when no specific error is found
 }
 
 // MakeATErrors will consturct the ATErrors struct
-func MakeATErrors(writer EVWriter, vdb *vapi.VerticaDB, genericFailureReason string) EventLogger {
+func MakeATErrors(writer EVWriter, clnt client.Client, vdb *vapi.VerticaDB, genericFailureReason string) EventLogger {
 	return &ATErrors{
 		Writer:               writer,
+		Client:               clnt,
 		VDB:                  vdb,
 		GenericFailureReason: genericFailureReason,
 	}
@@ -52,82 +55,132 @@ const (
 // LogFailure is called when admintools had attempted an option but
 // failed. The command used, along with the output of the command are
 // given. This function will parse the output and determine the appropriate
-// Event and log message to write.
-func (a *ATErrors) LogFailure(cmd, op string, err error) (ctrl.Result, error) {
+// Event and log message to write. The failure is also saved in the status of
+// the VerticaDB.
+func (a *ATErrors) LogFailure(ctx context.Context, cmd, op string, err error) (ctrl.Result, error) {
+	f, res, known := a.classify(cmd, op)
+	if recErr := recordFailure(ctx, a.Writer, a.Client, a.VDB, cmd, f); recErr != nil {
+		return ctrl.Result{}, recErr
+	}
+	if !known {
+		return ctrl.Result{}, fmt.Errorf("failed mgmt command %s %w", cmd, err)
+	}
+	return res, nil
+}
+
+// LogSuccess is called when an admintools command succeeded.  It clears the
+// last failure of the command from the status of the VerticaDB.
+func (a *ATErrors) LogSuccess(ctx context.Context, cmd string) error {
+	return clearFailure(ctx, a.Client, a.VDB, cmd)
+}
+
+// classify will parse the admintools output to determine the class of failure.
+// It returns false if the output didn't match any of the errors we know about.
+func (a *ATErrors) classify(cmd, op string) (f *failure, res ctrl.Result, known bool) {
 	switch {
 	case isDiskFull(op):
-		a.Writer.Eventf(a.VDB, corev1.EventTypeWarning, events.MgmtFailedDiskFull,
-			"'admintools -t %s' failed because of disk full", cmd)
-		return ctrl.Result{Requeue: true}, nil
+		return &failure{
+			reason:      events.MgmtFailedDiskFull,
+			message:     fmt.Sprintf("'admintools -t %s' failed because of disk full", cmd),
+			remediation: "Free up space in the local volume of the pods or increase spec.local.requestSize",
+		}, ctrl.Result{Requeue: true}, true
 
 	case areSomeNodesUpForRestart(op):
-		a.Writer.Eventf(a.VDB, corev1.EventTypeWarning, a.GenericFailureReason,
-			"Failed while calling 'admintools -t %s'", cmd)
-		return ctrl.Result{Requeue: false, RequeueAfter: time.Second * RestartNodesNotDownRequeueWaitTimeInSeconds}, nil
+		return &failure{
+			reason:      a.GenericFailureReason,
+			message:     fmt.Sprintf("Failed while calling 'admintools -t %s'", cmd),
+			remediation: "None needed. The restart is retried once vertica has stopped on all of the nodes",
+		}, ctrl.Result{Requeue: false, RequeueAfter: time.Second * RestartNodesNotDownRequeueWaitTimeInSeconds}, true
 
 	case cloud.IsEndpointBadError(op):
-		a.Writer.Eventf(a.VDB, corev1.EventTypeWarning, events.S3EndpointIssue,
-			"Unable to write to the bucket in the S3 endpoint '%s'", a.VDB.Spec.Communal.Endpoint)
-		return ctrl.Result{Requeue: true}, nil
+		return &failure{
+			reason:      events.S3EndpointIssue,
+			message:     fmt.Sprintf("Unable to write to the bucket in the S3 endpoint '%s'", a.VDB.Spec.Communal.Endpoint),
+			remediation: "Verify that spec.communal.endpoint is correct and can be reached from the pods",
+		}, ctrl.Result{Requeue: true}, true
 
 	case cloud.IsBucketNotExistError(op):
-		a.Writer.Eventf(a.VDB, corev1.EventTypeWarning, events.S3BucketDoesNotExist,
-			"The bucket in the S3 path '%s' does not exist", a.VDB.GetCommunalPath())
-		return ctrl.Result{Requeue: true}, nil
+		return &failure{
+			reason:      events.S3BucketDoesNotExist,
+			message:     fmt.Sprintf("The bucket in the S3 path '%s' does not exist", a.VDB.GetCommunalPath()),
+			remediation: "Create the bucket or fix the bucket name in spec.communal.path",
+		}, ctrl.Result{Requeue: true}, true
 
 	case isCommunalPathNotEmpty(op):
-		a.Writer.Eventf(a.VDB, corev1.EventTypeWarning, events.CommunalPathIsNotEmpty,
-			"The communal path '%s' is not empty", a.VDB.GetCommunalPath())
-		return ctrl.Result{Requeue: true}, nil
+		return &failure{
+			reason:      events.CommunalPathIsNotEmpty,
+			message:     fmt.Sprintf("The communal path '%s' is not empty", a.VDB.GetCommunalPath()),
+			remediation: "Use an empty spec.communal.path, or set spec.initPolicy to Revive to use the existing database",
+		}, ctrl.Result{Requeue: true}, true
 
 	case isWrongRegion(op):
-		a.Writer.Event(a.VDB, corev1.EventTypeWarning, events.S3WrongRegion,
-			"You are trying to access your S3 bucket using the wrong region")
-		return ctrl.Result{Requeue: true}, nil
+		return &failure{
+			reason:      events.S3WrongRegion,
+			message:     "You are trying to access your S3 bucket using the wrong region",
+			remediation: "Set spec.communal.region to the region of the bucket",
+		}, ctrl.Result{Requeue: true}, true
 
 	case isConfigParmWrong(op):
-		a.Writer.Event(a.VDB, corev1.EventTypeWarning, events.InvalidConfigParm,
-			"Invalid communal storage parameter")
-		return ctrl.Result{Requeue: true}, nil
+		return &failure{
+			reason:      events.InvalidConfigParm,
+			message:     "Invalid communal storage parameter",
+			remediation: "Remove or fix the invalid parameter in spec.communal.additionalConfig",
+		}, ctrl.Result{Requeue: true}, true
 
 	case isS3SseCustomerKeyInvalid(op):
-		a.Writer.Event(a.VDB, corev1.EventTypeWarning, events.InvalidS3SseCustomerKey,
-			"Invalid key: should be either 32-character plaintext or 44-character base64-encoded")
-		return ctrl.Result{Requeue: true}, nil
+		return &failure{
+			reason:      events.InvalidS3SseCustomerKey,
+			message:     "Invalid key: should be either 32-character plaintext or 44-character base64-encoded",
+			remediation: "Fix the key in the secret named in spec.communal.s3SseCustomerKeySecret",
+		}, ctrl.Result{Requeue: true}, true
 
 	case isKerberosAuthError(op):
-		a.Writer.Event(a.VDB, corev1.EventTypeWarning, events.KerberosAuthError,
-			"Error during keberos authentication")
-		return ctrl.Result{Requeue: true}, nil
+		return &failure{
+			reason:      events.KerberosAuthError,
+			message:     "Error during keberos authentication",
+			remediation: "Verify the keytab and krb5.conf in spec.kerberosSecret and the Kerberos config parameters",
+		}, ctrl.Result{Requeue: true}, true
 
 	case isClusterLeaseNotExpired(op):
-		a.Writer.Eventf(a.VDB, corev1.EventTypeWarning, events.ReviveDBClusterInUse,
-			"revive_db failed because the cluster lease has not expired for '%s'",
-			a.VDB.GetCommunalPath())
-		return ctrl.Result{Requeue: true}, nil
+		return &failure{
+			reason: events.ReviveDBClusterInUse,
+			message: fmt.Sprintf("revive_db failed because the cluster lease has not expired for '%s'",
+				a.VDB.GetCommunalPath()),
+			remediation: "Wait for the cluster lease to expire, or set spec.ignoreClusterLease if the database " +
+				"is no longer running elsewhere",
+		}, ctrl.Result{Requeue: true}, true
 
 	case isDatabaseNotFound(op):
-		a.Writer.Eventf(a.VDB, corev1.EventTypeWarning, events.ReviveDBNotFound,
-			"revive_db failed because the database '%s' could not be found in the communal path '%s'",
-			a.VDB.Spec.DBName, a.VDB.GetCommunalPath())
-		return ctrl.Result{Requeue: true}, nil
+		return &failure{
+			reason: events.ReviveDBNotFound,
+			message: fmt.Sprintf("revive_db failed because the database '%s' could not be found in the communal path '%s'",
+				a.VDB.Spec.DBName, a.VDB.GetCommunalPath()),
+			remediation: "Verify that spec.dbName and spec.communal.path refer to the database to revive",
+		}, ctrl.Result{Requeue: true}, true
 
 	case isPermissionDeniedError(op):
-		a.Writer.Eventf(a.VDB, corev1.EventTypeWarning, events.ReviveDBPermissionDenied,
-			"revive_db failed because of a permission denied error. Verify these paths match the "+
+		return &failure{
+			reason: events.ReviveDBPermissionDenied,
+			message: fmt.Sprintf("revive_db failed because of a permission denied error. Verify these paths match the "+
 				"ones used by the database: 'DATA,TEMP' => %s, 'DEPOT' => %s, 'CATALOG' => %s",
-			a.VDB.Spec.Local.DataPath, a.VDB.Spec.Local.DepotPath, a.VDB.Spec.Local.GetCatalogPath())
-		return ctrl.Result{Requeue: true}, nil
+				a.VDB.Spec.Local.DataPath, a.VDB.Spec.Local.DepotPath, a.VDB.Spec.Local.GetCatalogPath()),
+			remediation: "Set spec.local.dataPath, spec.local.depotPath and spec.local.catalogPath to the paths " +
+				"used by the database",
+		}, ctrl.Result{Requeue: true}, true
 
 	case isNodeCountMismatch(op):
-		a.Writer.Event(a.VDB, corev1.EventTypeWarning, events.ReviveDBNodeCountMismatch,
-			"revive_db failed because of a node count mismatch")
-		return ctrl.Result{Requeue: true}, nil
+		return &failure{
+			reason:      events.ReviveDBNodeCountMismatch,
+			message:     "revive_db failed because of a node count mismatch",
+			remediation: "Change spec.subclusters so that the number of primary nodes matches the database",
+		}, ctrl.Result{Requeue: true}, true
 
 	default:
-		a.Writer.Eventf(a.VDB, corev1.EventTypeWarning, a.GenericFailureReason,
-			"Failed while calling 'admintools -t %s'", cmd)
-		return ctrl.Result{}, fmt.Errorf("failed mgmt command %s %w", cmd, err)
+		return &failure{
+			reason:      a.GenericFailureReason,
+			message:     fmt.Sprintf("Failed while calling 'admintools -t %s'", cmd),
+			remediation: genericRemediation,
+		}, ctrl.Result{}, false
 	}
 }
 
@@ -16,17 +16,22 @@
 package mgmterrors
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
 	"github.com/vertica/vertica-kubernetes/pkg/events"
+	"github.com/vertica/vertica-kubernetes/pkg/metrics"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
 var _ = Describe("aterrors", func() {
+	ctx := context.Background()
+
 	It("should log a diskfull event", func() {
 		sampleOutput := `Info: no password specified, using none
 *** Updating IP addresses for nodes of database vertdb ***
@@ -52,8 +57,8 @@ Call stack:\r
 		`
 		vdb := vapi.MakeVDB()
 		tw := TestEVWriter{}
-		evlogr := MakeATErrors(&tw, vdb, events.MgmtFailed)
-		Expect(evlogr.LogFailure("restart_db", sampleOutput, nil)).Should(Equal(ctrl.Result{Requeue: true}))
+		evlogr := MakeATErrors(&tw, nil, vdb, events.MgmtFailed)
+		Expect(evlogr.LogFailure(ctx, "restart_db", sampleOutput, nil)).Should(Equal(ctrl.Result{Requeue: true}))
 		Expect(len(tw.RecordedEvents)).Should(Equal(1))
 		Expect(tw.RecordedEvents[0].Reason).Should(Equal(events.MgmtFailedDiskFull))
 	})
@@ -62,8 +67,8 @@ Call stack:\r
 		vdb := vapi.MakeVDB()
 		tw := TestEVWriter{}
 		const GenErrorReason = events.MgmtFailed
-		evlogr := MakeATErrors(&tw, vdb, GenErrorReason)
-		res, err := evlogr.LogFailure("test_cmd", "", nil)
+		evlogr := MakeATErrors(&tw, nil, vdb, GenErrorReason)
+		res, err := evlogr.LogFailure(ctx, "test_cmd", "", nil)
 		Expect(err).ShouldNot(Succeed())
 		Expect(res).Should(Equal(ctrl.Result{}))
 		Expect(len(tw.RecordedEvents)).Should(Equal(1))
@@ -84,8 +89,8 @@ Call stack:\r
 
 		for i := range errStrings {
 			tw := TestEVWriter{}
-			evlogr := MakeATErrors(&tw, vdb, events.CreateDBFailed)
-			Expect(evlogr.LogFailure("create_db", errStrings[i], fmt.Errorf("error"))).Should(Equal(ctrl.Result{Requeue: true}))
+			evlogr := MakeATErrors(&tw, nil, vdb, events.CreateDBFailed)
+			Expect(evlogr.LogFailure(ctx, "create_db", errStrings[i], fmt.Errorf("error"))).Should(Equal(ctrl.Result{Requeue: true}))
 		}
 	})
 
@@ -93,8 +98,8 @@ Call stack:\r
 		vdb := vapi.MakeVDB()
 		tw := TestEVWriter{}
 		const GenErrorReason = events.MgmtFailed
-		evlogr := MakeATErrors(&tw, vdb, GenErrorReason)
-		Expect(evlogr.LogFailure("test_cmd", "All nodes in the input are not down, can't restart", nil)).Should(Equal(ctrl.Result{
+		evlogr := MakeATErrors(&tw, nil, vdb, GenErrorReason)
+		Expect(evlogr.LogFailure(ctx, "test_cmd", "All nodes in the input are not down, can't restart", nil)).Should(Equal(ctrl.Result{
 			Requeue:      false,
 			RequeueAfter: time.Second * RestartNodesNotDownRequeueWaitTimeInSeconds,
 		}))
@@ -122,8 +127,8 @@ Call stack:\r
 		}
 		for i := range errStrings {
 			tw := TestEVWriter{}
-			evlogr := MakeATErrors(&tw, vdb, events.ReviveDBFailed)
-			Expect(evlogr.LogFailure("revive_db", errStrings[i], fmt.Errorf("error"))).Should(Equal(ctrl.Result{Requeue: true}))
+			evlogr := MakeATErrors(&tw, nil, vdb, events.ReviveDBFailed)
+			Expect(evlogr.LogFailure(ctx, "revive_db", errStrings[i], fmt.Errorf("error"))).Should(Equal(ctrl.Result{Requeue: true}))
 		}
 	})
 
@@ -135,8 +140,25 @@ Call stack:\r
 		}
 		for i := range errStrings {
 			tw := TestEVWriter{}
-			evlogr := MakeATErrors(&tw, vdb, events.CreateDBFailed)
-			Expect(evlogr.LogFailure("create_db", errStrings[i], fmt.Errorf("error"))).Should(Equal(ctrl.Result{Requeue: true}))
+			evlogr := MakeATErrors(&tw, nil, vdb, events.CreateDBFailed)
+			Expect(evlogr.LogFailure(ctx, "create_db", errStrings[i], fmt.Errorf("error"))).Should(Equal(ctrl.Result{Requeue: true}))
 		}
 	})
+
+	It("should count the failure and give a remediation hint", func() {
+		vdb := vapi.MakeVDB()
+		tw := TestEVWriter{}
+		evlogr := MakeATErrors(&tw, nil, vdb, events.ReviveDBFailed)
+		labels := metrics.MakeMgmtFailureLabels(vdb, "revive_db", events.ReviveDBClusterInUse)
+		before := testutil.ToFloat64(metrics.MgmtFailureCount.With(labels))
+		const op = "Error: The database vertdb cannot continue because the communal storage location\n\ts3://nimbusdb/db\n" +
+			"might still be in use.\n\nthe cluster lease will expire:\n\t2021-05-13 14:35:00.280925"
+		Expect(evlogr.LogFailure(ctx, "revive_db", op, fmt.Errorf("error"))).Should(Equal(ctrl.Result{Requeue: true}))
+		Expect(testutil.ToFloat64(metrics.MgmtFailureCount.With(labels))).Should(Equal(before + 1))
+
+		f, _, known := evlogr.(*ATErrors).classify("revive_db", op)
+		Expect(known).Should(BeTrue())
+		Expect(f.reason).Should(Equal(events.ReviveDBClusterInUse))
+		Expect(f.remediation).Should(ContainSubstring("spec.ignoreClusterLease"))
+	})
 })
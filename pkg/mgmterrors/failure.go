@@ -0,0 +1,64 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package mgmterrors
+
+import (
+	"context"
+
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/metrics"
+	"github.com/vertica/vertica-kubernetes/pkg/vdbstatus"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The remediation hint when we don't know the specific error
+const genericRemediation = "Check the operator log and the vertica logs in the pod for details"
+
+// failure is how we classified the output of a failed management command
+type failure struct {
+	// The event reason.  This doubles as the reason code of the failure.
+	reason      string
+	message     string
+	remediation string
+}
+
+// recordFailure will write an event for the failure and count it in the
+// metrics.  If a client is given, the failure is saved in the status of the
+// VerticaDB too.
+func recordFailure(ctx context.Context, writer EVWriter, clnt client.Client, vdb *vapi.VerticaDB,
+	cmd string, f *failure) error {
+	writer.Event(vdb, corev1.EventTypeWarning, f.reason, f.message)
+	metrics.MgmtFailureCount.With(metrics.MakeMgmtFailureLabels(vdb, cmd, f.reason)).Inc()
+	if clnt == nil {
+		return nil
+	}
+	return vdbstatus.RecordFailure(ctx, clnt, vdb, &vapi.MgmtFailure{
+		Command:     cmd,
+		Reason:      f.reason,
+		Message:     f.message,
+		Remediation: f.remediation,
+	})
+}
+
+// clearFailure will remove the last failure from the status of the VerticaDB
+// if it was for the given command.  This is a no-op if no client is given.
+func clearFailure(ctx context.Context, clnt client.Client, vdb *vapi.VerticaDB, cmd string) error {
+	if clnt == nil {
+		return nil
+	}
+	return vdbstatus.ClearFailure(ctx, clnt, vdb, cmd)
+}
@@ -16,6 +16,8 @@
 package mgmterrors
 
 import (
+	"context"
+
 	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
@@ -26,7 +28,11 @@ type EventLogger interface {
 	// given. This function will parse the output and determine the appropriate
 	// Event and log message to write. It will also determine the appropriate
 	// ctrl.Result to bubble back up.
-	LogFailure(cmd, op string, err error) (ctrl.Result, error)
+	LogFailure(ctx context.Context, cmd, op string, err error) (ctrl.Result, error)
+	// LogSuccess is called when a command succeeded.  If the last failure
+	// saved in the status of the VerticaDB was for the same command, it is
+	// cleared since it no longer applies.
+	LogSuccess(ctx context.Context, cmd string) error
 }
 
 // EVWriter is an interface for writing k8s events
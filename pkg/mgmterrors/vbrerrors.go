@@ -0,0 +1,159 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package mgmterrors
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/cloud"
+	"github.com/vertica/vertica-kubernetes/pkg/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// VbrErrors handles event logging for errors that come back from vbr when it
+// is run against a VerticaDB
+type VbrErrors struct {
+	Writer               EVWriter
+	Client               client.Client // Used to save the failure in the status. This is optional.
+	VDB                  *vapi.VerticaDB
+	GenericFailureReason string // The failure reason when no specific error is found
+}
+
+// MakeVbrErrors will construct the VbrErrors struct
+func MakeVbrErrors(writer EVWriter, clnt client.Client, vdb *vapi.VerticaDB, genericFailureReason string) EventLogger {
+	return &VbrErrors{
+		Writer:               writer,
+		Client:               clnt,
+		VDB:                  vdb,
+		GenericFailureReason: genericFailureReason,
+	}
+}
+
+// LogFailure is called when vbr had attempted a task but failed. The task,
+// along with the output of vbr are given. This function will parse the output
+// and determine the appropriate Event and log message to write. The failure
+// is also saved in the status of the VerticaDB.
+func (v *VbrErrors) LogFailure(ctx context.Context, task, op string, err error) (ctrl.Result, error) {
+	f, known := v.classify(task, op)
+	if recErr := recordFailure(ctx, v.Writer, v.Client, v.VDB, task, f); recErr != nil {
+		return ctrl.Result{}, recErr
+	}
+	if !known {
+		return ctrl.Result{}, fmt.Errorf("failed vbr task %s %w", task, err)
+	}
+	return ctrl.Result{Requeue: true}, nil
+}
+
+// LogSuccess is called when a vbr task succeeded.  It clears the last failure
+// of the task from the status of the VerticaDB.
+func (v *VbrErrors) LogSuccess(ctx context.Context, task string) error {
+	return clearFailure(ctx, v.Client, v.VDB, task)
+}
+
+// classify will parse the vbr output to determine the class of failure. It
+// returns false if the output didn't match any of the errors we know about.
+func (v *VbrErrors) classify(task, op string) (f *failure, known bool) {
+	switch {
+	case isRestorePointNotFound(op):
+		return &failure{
+			reason: events.RestorePointNotFound,
+			message: fmt.Sprintf("vbr %s failed because the restore point '%s' could not be found in '%s'",
+				task, v.getRestorePointID(), v.getBackupPath()),
+			remediation: "Verify that spec.restorePoint refers to a restore point that exists in the backup location",
+		}, true
+
+	case cloud.IsEndpointBadError(op):
+		return &failure{
+			reason: events.S3EndpointIssue,
+			message: fmt.Sprintf("vbr %s failed because it was unable to connect to the endpoint of the backup location",
+				task),
+			remediation: "Verify that the endpoint of the backup location is correct and can be reached from the pods",
+		}, true
+
+	case cloud.IsBucketNotExistError(op):
+		return &failure{
+			reason:      events.S3BucketDoesNotExist,
+			message:     fmt.Sprintf("The bucket in the backup path '%s' does not exist", v.getBackupPath()),
+			remediation: "Create the bucket or fix the backup path in spec.restorePoint",
+		}, true
+
+	case isVbrAccessDenied(op):
+		return &failure{
+			reason:      events.BackupLocationAccessDenied,
+			message:     fmt.Sprintf("vbr %s failed because access was denied to the backup path '%s'", task, v.getBackupPath()),
+			remediation: "Verify the credentials used to access the backup location",
+		}, true
+
+	case isVbrNodeCountMismatch(op):
+		return &failure{
+			reason: events.RestoreDBNodeCountMismatch,
+			message: fmt.Sprintf("vbr %s failed because the nodes in the restore point do not match the nodes in the database",
+				task),
+			remediation: "Change spec.subclusters so that the nodes match the ones in the restore point",
+		}, true
+
+	default:
+		return &failure{
+			reason:      v.GenericFailureReason,
+			message:     fmt.Sprintf("Failed while calling 'vbr --task %s'", task),
+			remediation: genericRemediation,
+		}, false
+	}
+}
+
+// getRestorePointID returns the restore point that is used to initialize the
+// database
+func (v *VbrErrors) getRestorePointID() string {
+	if v.VDB.Spec.RestorePoint == nil {
+		return ""
+	}
+	return v.VDB.Spec.RestorePoint.ID
+}
+
+// getBackupPath returns the path where the restore point is stored
+func (v *VbrErrors) getBackupPath() string {
+	if v.VDB.Spec.RestorePoint == nil {
+		return ""
+	}
+	return v.VDB.Spec.RestorePoint.Target.Path
+}
+
+// isRestorePointNotFound will look at the vbr output to see if the restore
+// failed because the archive doesn't exist in the backup location
+func isRestorePointNotFound(op string) bool {
+	rs := `(?i)(archive|restore point|backup) .*(not found|does not exist|cannot be found)`
+	re := regexp.MustCompile(rs)
+	return re.FindAllString(op, -1) != nil
+}
+
+// isVbrAccessDenied will check the vbr output to see if the object store
+// rejected the credentials
+func isVbrAccessDenied(op string) bool {
+	return strings.Contains(op, "Access Denied") || strings.Contains(op, "AccessDenied")
+}
+
+// isVbrNodeCountMismatch will check the vbr output to see if the restore
+// failed because the database doesn't have the same nodes as the backup
+func isVbrNodeCountMismatch(op string) bool {
+	rs := `(?i)(node mapping|number of nodes|node count).*(mismatch|does not match|incomplete|different)`
+	re := regexp.MustCompile(rs)
+	return re.FindAllString(op, -1) != nil
+}
@@ -0,0 +1,63 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package mgmterrors
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("vbrerrors", func() {
+	ctx := context.Background()
+
+	It("should classify known vbr errors", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.RestorePoint = &vapi.RestorePointPolicy{
+			ID:     "nightly_20230101_020000",
+			Target: vapi.BackupTarget{Path: "s3://backup-bucket/db"},
+		}
+
+		cases := map[string]string{
+			"Error: Backup archive 20230101_020000 not found.":                          events.RestorePointNotFound,
+			"Error: Unable to connect to endpoint":                                      events.S3EndpointIssue,
+			"The specified bucket does not exist.":                                      events.S3BucketDoesNotExist,
+			"An error occurred (AccessDenied) when calling the ListObjectsV2 operation": events.BackupLocationAccessDenied,
+			"Error: Node mapping is incomplete for the restore":                         events.RestoreDBNodeCountMismatch,
+		}
+		for op, reason := range cases {
+			tw := TestEVWriter{}
+			evlogr := MakeVbrErrors(&tw, nil, vdb, events.RestoreDBFailed)
+			Expect(evlogr.LogFailure(ctx, "restore", op, fmt.Errorf("exit 1"))).Should(Equal(ctrl.Result{Requeue: true}), op)
+			Expect(tw.RecordedEvents).Should(HaveLen(1))
+			Expect(tw.RecordedEvents[0].Reason).Should(Equal(reason), op)
+		}
+	})
+
+	It("should return an error for unknown vbr errors", func() {
+		tw := TestEVWriter{}
+		evlogr := MakeVbrErrors(&tw, nil, vapi.MakeVDB(), events.RestoreDBFailed)
+		res, err := evlogr.LogFailure(ctx, "restore", "Error: something else", fmt.Errorf("exit 1"))
+		Expect(err).ShouldNot(Succeed())
+		Expect(res).Should(Equal(ctrl.Result{}))
+		Expect(tw.RecordedEvents[0].Reason).Should(Equal(events.RestoreDBFailed))
+	})
+})
@@ -0,0 +1,126 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package monitoring
+
+import (
+	"fmt"
+	"strings"
+
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/builder"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	"github.com/vertica/vertica-kubernetes/pkg/paths"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// The path of the prometheus metrics in the vertica http server
+	VerticaMetricsPath = "/v1/metrics"
+	// The value of the svc-type label in the service of the operator metrics
+	OperatorMetricsSvcType = "operator-metrics"
+	// The name of the port in the service of the operator metrics
+	OperatorMetricsPortName = "metrics"
+	// The token that prometheus uses to get through the rbac proxy in front
+	// of the operator metrics
+	ServiceAccountTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+// BuildPodMonitor builds the PodMonitor that scrapes the metrics of the http
+// server in each vertica pod.  The http server TLS secret is used both to
+// verify the server and as the client cert to authenticate with it.
+func BuildPodMonitor(nm types.NamespacedName, vdb *vapi.VerticaDB) *unstructured.Unstructured {
+	secretKeyRef := func(key string) map[string]interface{} {
+		return map[string]interface{}{"name": vdb.Spec.HTTPServerTLSSecret, "key": key}
+	}
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(PodMonitorGVK)
+	obj.SetName(nm.Name)
+	obj.SetNamespace(nm.Namespace)
+	obj.SetLabels(builder.MakeLabelsForStsObject(vdb, nil))
+	obj.Object["spec"] = map[string]interface{}{
+		"selector": map[string]interface{}{
+			"matchLabels": toUnstructuredMap(builder.MakeOperatorLabels(vdb)),
+		},
+		"namespaceSelector": map[string]interface{}{
+			"matchNames": []interface{}{vdb.Namespace},
+		},
+		"podMetricsEndpoints": []interface{}{
+			map[string]interface{}{
+				// The http server port isn't named in the pod spec, so we
+				// must refer to it by number.
+				"targetPort": int64(builder.VerticaHTTPPort),
+				"path":       VerticaMetricsPath,
+				"scheme":     "https",
+				"tlsConfig": map[string]interface{}{
+					"ca":        map[string]interface{}{"secret": secretKeyRef(paths.HTTPServerCACrtName)},
+					"cert":      map[string]interface{}{"secret": secretKeyRef(corev1.TLSCertKey)},
+					"keySecret": secretKeyRef(corev1.TLSPrivateKeyKey),
+					// The pods are scraped by IP, so we pick a name that
+					// matches the certs the operator generates.
+					"serverName": fmt.Sprintf("%s.%s.svc", names.GenHlSvcName(vdb).Name, vdb.Namespace),
+				},
+			},
+		},
+	}
+	return obj
+}
+
+// BuildOperatorServiceMonitor builds the ServiceMonitor for the metrics
+// endpoint of the operator.  When the operator binds its metrics to the
+// loopback interface, they are exposed through the rbac proxy, which needs
+// https and a token.  Otherwise, they are exposed with plain http.
+func BuildOperatorServiceMonitor(nm types.NamespacedName, metricsAddr string) *unstructured.Unstructured {
+	endpoint := map[string]interface{}{
+		"port":   OperatorMetricsPortName,
+		"path":   "/metrics",
+		"scheme": "http",
+	}
+	if strings.HasPrefix(metricsAddr, "127.0.0.1:") || strings.HasPrefix(metricsAddr, "localhost:") {
+		endpoint["scheme"] = "https"
+		endpoint["bearerTokenFile"] = ServiceAccountTokenFile
+		endpoint["tlsConfig"] = map[string]interface{}{"insecureSkipVerify": true}
+	}
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(ServiceMonitorGVK)
+	obj.SetName(nm.Name)
+	obj.SetNamespace(nm.Namespace)
+	obj.SetLabels(map[string]string{builder.ManagedByLabel: builder.OperatorName})
+	obj.Object["spec"] = map[string]interface{}{
+		"selector": map[string]interface{}{
+			"matchLabels": map[string]interface{}{builder.SvcTypeLabel: OperatorMetricsSvcType},
+		},
+		"endpoints": []interface{}{endpoint},
+	}
+	return obj
+}
+
+// GenOperatorServiceMonitorName returns the name of the ServiceMonitor for
+// the operator metrics
+func GenOperatorServiceMonitorName(prefixName, ns string) types.NamespacedName {
+	return types.NamespacedName{Namespace: ns, Name: prefixName + "-operator-metrics"}
+}
+
+// toUnstructuredMap converts a map of strings so that it can be used in an
+// unstructured object
+func toUnstructuredMap(m map[string]string) map[string]interface{} {
+	res := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		res[k] = v
+	}
+	return res
+}
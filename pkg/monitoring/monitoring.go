@@ -0,0 +1,87 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package monitoring
+
+import (
+	"context"
+	"reflect"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The prometheus operator types are handled as unstructured objects so that
+// we don't depend on its API.  They are only created if its CRDs are installed.
+var (
+	ServiceMonitorGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}
+	PodMonitorGVK     = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PodMonitor"}
+)
+
+// IsKindInstalled returns true if the CRD for the given kind is installed in
+// the cluster
+func IsKindInstalled(mapper meta.RESTMapper, gvk schema.GroupVersionKind) (bool, error) {
+	_, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Apply will create the monitor object if it doesn't exist, or update its
+// labels and spec if they differ from the expected object.  It returns true
+// if a create or update was done.
+func Apply(ctx context.Context, clnt client.Client, expObj *unstructured.Unstructured) (bool, error) {
+	curObj := &unstructured.Unstructured{}
+	curObj.SetGroupVersionKind(expObj.GroupVersionKind())
+	err := clnt.Get(ctx, client.ObjectKeyFromObject(expObj), curObj)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return true, clnt.Create(ctx, expObj)
+		}
+		return false, err
+	}
+	if reflect.DeepEqual(curObj.Object["spec"], expObj.Object["spec"]) &&
+		reflect.DeepEqual(curObj.GetLabels(), expObj.GetLabels()) {
+		return false, nil
+	}
+	curObj.Object["spec"] = expObj.Object["spec"]
+	curObj.SetLabels(expObj.GetLabels())
+	return true, clnt.Update(ctx, curObj)
+}
+
+// Delete will remove the monitor object if it exists.  It returns true if an
+// object was deleted.
+func Delete(ctx context.Context, clnt client.Client, gvk schema.GroupVersionKind, nm types.NamespacedName) (bool, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	if err := clnt.Get(ctx, nm, obj); err != nil {
+		if kerrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if err := clnt.Delete(ctx, obj); err != nil && !kerrors.IsNotFound(err) {
+		return false, err
+	}
+	return true, nil
+}
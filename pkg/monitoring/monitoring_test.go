@@ -0,0 +1,94 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package monitoring
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/builder"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("monitoring", func() {
+	ctx := context.Background()
+
+	makeMapper := func() *meta.DefaultRESTMapper {
+		mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{PodMonitorGVK.GroupVersion()})
+		mapper.Add(PodMonitorGVK, meta.RESTScopeNamespace)
+		return mapper
+	}
+
+	It("should only report the kinds that are installed", func() {
+		mapper := makeMapper()
+		Expect(IsKindInstalled(mapper, PodMonitorGVK)).Should(BeTrue())
+		Expect(IsKindInstalled(mapper, ServiceMonitorGVK)).Should(BeFalse())
+	})
+
+	It("should create, update and delete the PodMonitor of a VerticaDB", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.HTTPServerTLSSecret = "http-certs"
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRESTMapper(makeMapper()).Build()
+		nm := names.GenPodMonitorName(vdb)
+
+		Expect(Apply(ctx, c, BuildPodMonitor(nm, vdb))).Should(BeTrue())
+		Expect(Apply(ctx, c, BuildPodMonitor(nm, vdb))).Should(BeFalse())
+
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(PodMonitorGVK)
+		Expect(c.Get(ctx, nm, obj)).Should(Succeed())
+		selector, _, _ := unstructured.NestedStringMap(obj.Object, "spec", "selector", "matchLabels")
+		Expect(selector).Should(Equal(builder.MakeOperatorLabels(vdb)))
+		eps, _, _ := unstructured.NestedSlice(obj.Object, "spec", "podMetricsEndpoints")
+		Expect(eps).Should(HaveLen(1))
+		secretName, _, _ := unstructured.NestedString(eps[0].(map[string]interface{}), "tlsConfig", "keySecret", "name")
+		Expect(secretName).Should(Equal("http-certs"))
+
+		vdb.Spec.HTTPServerTLSSecret = "new-certs"
+		Expect(Apply(ctx, c, BuildPodMonitor(nm, vdb))).Should(BeTrue())
+		Expect(c.Get(ctx, nm, obj)).Should(Succeed())
+		eps, _, _ = unstructured.NestedSlice(obj.Object, "spec", "podMetricsEndpoints")
+		secretName, _, _ = unstructured.NestedString(eps[0].(map[string]interface{}), "tlsConfig", "keySecret", "name")
+		Expect(secretName).Should(Equal("new-certs"))
+
+		Expect(Delete(ctx, c, PodMonitorGVK, nm)).Should(BeTrue())
+		Expect(Delete(ctx, c, PodMonitorGVK, nm)).Should(BeFalse())
+		Expect(c.Get(ctx, nm, obj)).ShouldNot(Succeed())
+	})
+
+	It("should scrape the operator through the rbac proxy only when the metrics are bound to localhost", func() {
+		nm := GenOperatorServiceMonitorName("verticadb-operator", "vertica")
+		obj := BuildOperatorServiceMonitor(nm, "127.0.0.1:8080")
+		Expect(obj.GetName()).Should(Equal("verticadb-operator-operator-metrics"))
+		eps, _, _ := unstructured.NestedSlice(obj.Object, "spec", "endpoints")
+		Expect(eps[0].(map[string]interface{})["scheme"]).Should(Equal("https"))
+		Expect(eps[0].(map[string]interface{})["bearerTokenFile"]).Should(Equal(ServiceAccountTokenFile))
+
+		obj = BuildOperatorServiceMonitor(nm, ":8443")
+		eps, _, _ = unstructured.NestedSlice(obj.Object, "spec", "endpoints")
+		Expect(eps[0].(map[string]interface{})["scheme"]).Should(Equal("http"))
+		Expect(eps[0].(map[string]interface{})).ShouldNot(HaveKey("bearerTokenFile"))
+		Expect(client.ObjectKeyFromObject(obj)).Should(Equal(nm))
+	})
+})
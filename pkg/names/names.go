@@ -52,6 +52,35 @@ func GenStsName(vdb *vapi.VerticaDB, sc *vapi.Subcluster) types.NamespacedName {
 	return GenNamespacedName(vdb, vdb.Name+"-"+sc.GenCompatibleFQDN())
 }
 
+// GenPDBName returns the name of the PodDisruptionBudget of a subcluster
+func GenPDBName(vdb *vapi.VerticaDB, sc *vapi.Subcluster) types.NamespacedName {
+	return GenStsName(vdb, sc)
+}
+
+// GenPrimaryPDBName returns the name of the PodDisruptionBudget for the pods
+// of all of the primary subclusters
+func GenPrimaryPDBName(vdb *vapi.VerticaDB) types.NamespacedName {
+	return GenNamespacedName(vdb, vdb.Name)
+}
+
+// GenNetworkPolicyName returns the name of the NetworkPolicy for the traffic
+// between the pods of the VerticaDB
+func GenNetworkPolicyName(vdb *vapi.VerticaDB) types.NamespacedName {
+	return GenNamespacedName(vdb, vdb.Name)
+}
+
+// GenClientNetworkPolicyName returns the name of the NetworkPolicy for the
+// client traffic of a subcluster
+func GenClientNetworkPolicyName(vdb *vapi.VerticaDB, sc *vapi.Subcluster) types.NamespacedName {
+	return GenStsName(vdb, sc)
+}
+
+// GenPodMonitorName returns the name of the PodMonitor for the metrics of
+// the vertica pods
+func GenPodMonitorName(vdb *vapi.VerticaDB) types.NamespacedName {
+	return GenNamespacedName(vdb, vdb.Name)
+}
+
 // GenCommunalCredSecretName returns the name of the secret that has the credentials to access s3
 func GenCommunalCredSecretName(vdb *vapi.VerticaDB) types.NamespacedName {
 	return GenNamespacedName(vdb, vdb.Spec.Communal.CredentialSecret)
@@ -73,6 +102,30 @@ func GenSUPasswdSecretName(vdb *vapi.VerticaDB) types.NamespacedName {
 	return GenNamespacedName(vdb, vdb.Spec.SuperuserPasswordSecret)
 }
 
+// GenSUPasswdAppliedSecretName returns the name of the secret the operator
+// keeps with the superuser password that is currently set in the database.
+func GenSUPasswdAppliedSecretName(vdb *vapi.VerticaDB) types.NamespacedName {
+	return GenNamespacedName(vdb, fmt.Sprintf("%s-su-passwd-applied", vdb.Name))
+}
+
+// GenCommunalCredsAppliedSecretName returns the name of the secret the operator
+// keeps with the communal credentials that are currently set in the database.
+func GenCommunalCredsAppliedSecretName(vdb *vapi.VerticaDB) types.NamespacedName {
+	return GenNamespacedName(vdb, fmt.Sprintf("%s-communal-creds-applied", vdb.Name))
+}
+
+// GenHTTPServerCertName returns the name of the cert-manager Certificate that
+// we create for the http server.  The issued secret has the same name.
+func GenHTTPServerCertName(vdb *vapi.VerticaDB) types.NamespacedName {
+	return GenNamespacedName(vdb, fmt.Sprintf("%s-http-server-tls", vdb.Name))
+}
+
+// GenDryRunConfigMapName returns the name of the ConfigMap that has the
+// changes the operator would make when the VerticaDB is in dry-run mode.
+func GenDryRunConfigMapName(vdb *vapi.VerticaDB) types.NamespacedName {
+	return GenNamespacedName(vdb, fmt.Sprintf("%s-dry-run", vdb.Name))
+}
+
 // GenPodName returns the name of a specific pod in a subcluster
 // The name of the pod is generated, this function is just a helper for when we need
 // to lookup a pod by its generated name.
@@ -19,6 +19,7 @@ import (
 	"flag"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -35,6 +36,13 @@ const (
 	DefaultMaxFileRotation = 3
 	DefaultLevel           = "info"
 	DefaultDevMode         = true
+	// The value for --watch-namespaces that means every namespace is watched
+	AllNamespaces = "*"
+	// The default number of pods we collect pod facts for concurrently
+	DefaultPodFactsWorkers = 10
+	// The default amount of time we wait to collect the pod facts of a
+	// single pod
+	DefaultPodFactsTimeout = 2 * time.Minute
 )
 
 type OperatorConfig struct {
@@ -51,6 +59,24 @@ type OperatorConfig struct {
 	// deployment or using cert-manager, which handles the CA bundle injection
 	// itself.
 	SkipWebhookPatch bool
+	// The cert-manager issuer to use for the webhook cert. When set, and
+	// WebhookCertSecret is empty, the operator has cert-manager issue the cert
+	// rather than generating a self-signed one.
+	WebhookCertIssuerName string
+	WebhookCertIssuerKind string
+	// A comma separated list of namespaces the operator watches. A value of
+	// "*" means all namespaces. When this is empty, we watch the namespace set
+	// in the WATCH_NAMESPACE environment variable.
+	WatchNamespaces string
+	// The maximum number of pods that we collect pod facts for concurrently
+	PodFactsWorkers int
+	// The amount of time we wait to collect the pod facts for a single pod
+	// before giving up.
+	PodFactsTimeout time.Duration
+	// If true, the operator creates the prometheus operator ServiceMonitor
+	// and PodMonitor objects for its metrics and for each VerticaDB.  This is
+	// skipped if the CRDs for them aren't installed.
+	CreatePrometheusMonitors bool
 	Logging
 }
 
@@ -82,6 +108,22 @@ func (o *OperatorConfig) SetFlagArgs() {
 			"then the operator will generate the certificate.")
 	flag.BoolVar(&o.SkipWebhookPatch, "skip-webhook-patch", false,
 		"If the operator should skip updating the CA bundle in the webhook config")
+	flag.StringVar(&o.WebhookCertIssuerName, "webhook-cert-issuer-name", "",
+		"The name of a cert-manager issuer to sign the webhook cert. This is only used if "+
+			"--webhook-cert-secret is omitted. If cert-manager is not installed, the operator "+
+			"falls back to generating the certificate.")
+	flag.StringVar(&o.WebhookCertIssuerKind, "webhook-cert-issuer-kind", "Issuer",
+		"The kind of the cert-manager issuer set in --webhook-cert-issuer-name. Valid values are: Issuer or ClusterIssuer.")
+	flag.StringVar(&o.WatchNamespaces, "watch-namespaces", "",
+		"A comma separated list of namespaces that the operator watches. Use '*' to watch all namespaces. "+
+			"If omitted, the operator watches the namespace set in the WATCH_NAMESPACE environment variable.")
+	flag.IntVar(&o.PodFactsWorkers, "pod-facts-workers", DefaultPodFactsWorkers,
+		"The maximum number of pods that pod facts are collected for concurrently.")
+	flag.DurationVar(&o.PodFactsTimeout, "pod-facts-timeout", DefaultPodFactsTimeout,
+		"The amount of time to wait when collecting the pod facts of a single pod before giving up.")
+	flag.BoolVar(&o.CreatePrometheusMonitors, "create-prometheus-monitors", false,
+		"If true, a ServiceMonitor is created for the operator metrics and a PodMonitor for the http server "+
+			"metrics of each VerticaDB. This requires the CRDs of the prometheus operator to be installed.")
 	flag.BoolVar(&o.DevMode, "dev", DefaultDevMode,
 		"Enables development mode if true and production mode otherwise.")
 	flag.StringVar(&o.FilePath, "filepath", "",
@@ -97,6 +139,22 @@ func (o *OperatorConfig) SetFlagArgs() {
 		"The minimum logging level.  Valid values are: debug, info, warn, and error.")
 }
 
+// ParseWatchNamespaces will split a comma separated list of namespaces.  An
+// empty slice is returned if all namespaces should be watched.
+func ParseWatchNamespaces(namespaces string) []string {
+	res := []string{}
+	for _, ns := range strings.Split(namespaces, ",") {
+		ns = strings.TrimSpace(ns)
+		if ns == AllNamespaces {
+			return []string{}
+		}
+		if ns != "" {
+			res = append(res, ns)
+		}
+	}
+	return res
+}
+
 // getEncoderConfig returns a concrete encoders configuration
 func (o *OperatorConfig) getEncoderConfig(devMode bool) zapcore.EncoderConfig {
 	encoderConfig := zap.NewDevelopmentEncoderConfig()
@@ -59,6 +59,9 @@ const (
 	Krb5Keytab                = "/etc/krb5/krb5.keytab"
 	SSHPath                   = "/home/dbadmin/.ssh"
 	HTTPServerCACrtName       = "ca.crt"
+	VbrConfigPath             = "/home/dbadmin/vbr"
+	VbrLockPath               = "/home/dbadmin/backup_locks"
+	VbrBinary                 = "/opt/vertica/bin/vbr"
 )
 
 // MountPaths lists all of the paths for internally generated mounts.
@@ -17,9 +17,12 @@ package security
 
 import (
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
+	"encoding/hex"
 	"encoding/pem"
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -29,6 +32,8 @@ type Certificate interface {
 	TLSCrt() []byte
 	Buildx509() (*x509.Certificate, error)
 	BuildPrivateKey() (*rsa.PrivateKey, error)
+	NotAfter() (time.Time, error)
+	Fingerprint() (string, error)
 }
 
 type certificate struct {
@@ -36,6 +41,11 @@ type certificate struct {
 	tlsCrt []byte
 }
 
+// MakeCertificate builds a Certificate from an existing PEM encoded key and cert
+func MakeCertificate(tlsKey, tlsCrt []byte) Certificate {
+	return &certificate{tlsKey: tlsKey, tlsCrt: tlsCrt}
+}
+
 func (c *certificate) TLSKey() []byte { return c.tlsKey }
 func (c *certificate) TLSCrt() []byte { return c.tlsCrt }
 
@@ -64,3 +74,22 @@ func (c *certificate) BuildPrivateKey() (*rsa.PrivateKey, error) {
 	}
 	return pk, nil
 }
+
+// NotAfter returns the time when the certificate expires
+func (c *certificate) NotAfter() (time.Time, error) {
+	cert, err := c.Buildx509()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return cert.NotAfter, nil
+}
+
+// Fingerprint returns the hex encoded SHA-256 digest of the certificate
+func (c *certificate) Fingerprint() (string, error) {
+	cert, err := c.Buildx509()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,261 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package security
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	CertManagerGroup             = "cert-manager.io"
+	CertManagerVersion           = "v1"
+	CertManagerCertificateKind   = "Certificate"
+	CertManagerIssuerKind        = "Issuer"
+	CertManagerClusterIssuerKind = "ClusterIssuer"
+
+	// The amount of time we wait for cert-manager to issue the webhook cert
+	// before falling back to a self-signed cert.
+	webhookCertManagerTimeout = 2 * time.Minute
+	certManagerPollInterval   = 2 * time.Second
+	// How often we check if cert-manager renewed the webhook cert
+	webhookCertRefreshInterval = time.Minute
+)
+
+// CertManagerIssuer identifies the cert-manager Issuer or ClusterIssuer that
+// will sign a certificate.
+type CertManagerIssuer struct {
+	Name  string
+	Kind  string
+	Group string
+}
+
+// CertificateGVK is the GroupVersionKind of the cert-manager Certificate
+var CertificateGVK = schema.GroupVersionKind{
+	Group:   CertManagerGroup,
+	Version: CertManagerVersion,
+	Kind:    CertManagerCertificateKind,
+}
+
+// MakeCertManagerCertificate builds a cert-manager Certificate.  cert-manager
+// will store the signed certificate in a secret with the given name.  We use
+// an unstructured object so that we don't depend on the cert-manager API.
+func MakeCertManagerCertificate(nm types.NamespacedName, secretName, commonName string, dnsNames []string,
+	issuer CertManagerIssuer) *unstructured.Unstructured {
+	kind := issuer.Kind
+	if kind == "" {
+		kind = CertManagerIssuerKind
+	}
+	group := issuer.Group
+	if group == "" {
+		group = CertManagerGroup
+	}
+	names := make([]interface{}, len(dnsNames))
+	for i := range dnsNames {
+		names[i] = dnsNames[i]
+	}
+	cert := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"secretName": secretName,
+				"commonName": commonName,
+				"dnsNames":   names,
+				"issuerRef": map[string]interface{}{
+					"name":  issuer.Name,
+					"kind":  kind,
+					"group": group,
+				},
+			},
+		},
+	}
+	cert.SetGroupVersionKind(CertificateGVK)
+	cert.SetName(nm.Name)
+	cert.SetNamespace(nm.Namespace)
+	return cert
+}
+
+// IsCertManagerNotInstalled returns true if the error is because the
+// cert-manager CRDs are not installed in the cluster.
+func IsCertManagerNotInstalled(err error) bool {
+	return meta.IsNoMatchError(err)
+}
+
+// CreateCertManagerCertificate will create the Certificate if it doesn't
+// already exist.
+func CreateCertManagerCertificate(ctx context.Context, cl client.Client, cert *unstructured.Unstructured) error {
+	err := cl.Create(ctx, cert)
+	if err != nil && !kerrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// GetCertManagerSecret will return the secret that cert-manager stores the
+// signed certificate in.  If the certificate hasn't been issued yet, the
+// secret returned is nil.
+func GetCertManagerSecret(ctx context.Context, cl client.Client, nm types.NamespacedName) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	if err := cl.Get(ctx, nm, secret); err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(secret.Data[corev1.TLSCertKey]) == 0 || len(secret.Data[corev1.TLSPrivateKeyKey]) == 0 {
+		return nil, nil
+	}
+	return secret, nil
+}
+
+// GenerateWebhookCertWithCertManager will have cert-manager issue the cert to
+// be used by the webhook.  It creates a Certificate that references the given
+// issuer, waits for the secret and then writes the cert to the cert directory
+// (CertDir).  If cert-manager isn't installed, or the cert isn't issued in
+// time, it falls back to generating a self-signed cert.
+func GenerateWebhookCertWithCertManager(ctx context.Context, log *logr.Logger, cfg *rest.Config, certDir, prefixName, ns string,
+	issuer CertManagerIssuer) error {
+	log.Info("Requesting cert for webhook from cert-manager", "issuer", issuer.Name, "kind", issuer.Kind)
+	cl, err := client.New(cfg, client.Options{})
+	if err != nil {
+		return errors.Wrap(err, "could not create client")
+	}
+	secret, err := requestWebhookCert(ctx, cl, prefixName, ns, issuer)
+	if err != nil {
+		if !IsCertManagerNotInstalled(err) && !errors.Is(err, wait.ErrWaitTimeout) {
+			return err
+		}
+		log.Info("Could not get the webhook cert from cert-manager. Falling back to a self-signed cert.", "err", err)
+		return GenerateWebhookCert(ctx, log, cfg, certDir, prefixName, ns)
+	}
+
+	err = writeCert(certDir, MakeCertificate(secret.Data[corev1.TLSPrivateKeyKey], secret.Data[corev1.TLSCertKey]))
+	if err != nil {
+		return errors.Wrap(err, "could not write out cert")
+	}
+	caCrt, ok := secret.Data[CACertKey]
+	if !ok {
+		log.Info("could not find key in secret. Not updating CA bundle in webhook config.",
+			"key", CACertKey, "secret", secret.Name)
+		return nil
+	}
+	return PatchWebhookCABundle(ctx, log, cfg, caCrt, prefixName, ns)
+}
+
+// requestWebhookCert will create the Certificate for the webhook and wait for
+// cert-manager to issue it.
+func requestWebhookCert(ctx context.Context, cl client.Client, prefixName, ns string,
+	issuer CertManagerIssuer) (*corev1.Secret, error) {
+	certName := types.NamespacedName{Name: fmt.Sprintf("%s-webhook-serving-cert", prefixName), Namespace: ns}
+	secretName := genWebhookCertSecretName(prefixName, ns)
+	dnsNames := getWebhookDNSNames(prefixName, ns)
+	cert := MakeCertManagerCertificate(certName, secretName.Name, dnsNames[0], dnsNames, issuer)
+	if err := CreateCertManagerCertificate(ctx, cl, cert); err != nil {
+		return nil, err
+	}
+
+	var secret *corev1.Secret
+	err := wait.PollImmediateWithContext(ctx, certManagerPollInterval, webhookCertManagerTimeout,
+		func(ctx context.Context) (bool, error) {
+			var err error
+			secret, err = GetCertManagerSecret(ctx, cl, secretName)
+			return secret != nil, err
+		})
+	return secret, err
+}
+
+// WatchWebhookCertFromCertManager will keep the webhook cert in the cert
+// directory (CertDir) in sync with the secret that cert-manager issued.
+// cert-manager renews the cert in place, so we poll the secret and rewrite the
+// files when it changes.  The webhook server reloads the cert files on its
+// own.  This runs until the context is cancelled.
+func WatchWebhookCertFromCertManager(ctx context.Context, log *logr.Logger, cfg *rest.Config, certDir, prefixName, ns string) error {
+	cl, err := client.New(cfg, client.Options{})
+	if err != nil {
+		return errors.Wrap(err, "could not create client")
+	}
+	secretName := genWebhookCertSecretName(prefixName, ns)
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		caCrt, changed, err := refreshWebhookCert(ctx, cl, certDir, secretName)
+		if err != nil {
+			log.Error(err, "failed to refresh the webhook cert", "secret", secretName)
+			return
+		}
+		if !changed {
+			return
+		}
+		log.Info("Reloaded the webhook cert that was renewed by cert-manager", "secret", secretName)
+		if len(caCrt) == 0 {
+			return
+		}
+		if err := PatchWebhookCABundle(ctx, log, cfg, caCrt, prefixName, ns); err != nil {
+			log.Error(err, "failed to patch the webhook CA bundle with the renewed cert")
+		}
+	}, webhookCertRefreshInterval)
+	return nil
+}
+
+// refreshWebhookCert will write the cert from the secret to the cert directory
+// if it differs from the one that is there.  It returns the CA cert from the
+// secret, and true if the files were rewritten.
+func refreshWebhookCert(ctx context.Context, cl client.Client, certDir string, secretName types.NamespacedName) (
+	caCrt []byte, changed bool, err error) {
+	secret, err := GetCertManagerSecret(ctx, cl, secretName)
+	if err != nil || secret == nil {
+		return nil, false, err
+	}
+	curCrt, err := os.ReadFile(filepath.Join(certDir, corev1.TLSCertKey))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, false, err
+	}
+	if bytes.Equal(curCrt, secret.Data[corev1.TLSCertKey]) {
+		return nil, false, nil
+	}
+	err = writeCert(certDir, MakeCertificate(secret.Data[corev1.TLSPrivateKeyKey], secret.Data[corev1.TLSCertKey]))
+	if err != nil {
+		return nil, false, errors.Wrap(err, "could not write out cert")
+	}
+	return secret.Data[CACertKey], true, nil
+}
+
+// genWebhookCertSecretName returns the name of the secret that cert-manager
+// stores the webhook cert in
+func genWebhookCertSecretName(prefixName, ns string) types.NamespacedName {
+	return types.NamespacedName{Name: fmt.Sprintf("%s-webhook-server-cert", prefixName), Namespace: ns}
+}
+
+// getWebhookDNSNames returns the DNS names of the webhook service
+func getWebhookDNSNames(prefixName, ns string) []string {
+	return []string{
+		fmt.Sprintf("%s-webhook-service.%s.svc", prefixName, ns),
+		fmt.Sprintf("%s-webhook-service.%s.svc.cluster.local", prefixName, ns),
+	}
+}
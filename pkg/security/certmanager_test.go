@@ -0,0 +1,90 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package security
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("certmanager", func() {
+	It("should build a Certificate that references the issuer", func() {
+		nm := types.NamespacedName{Name: "my-cert", Namespace: ns}
+		dnsNames := []string{"a.svc", "a.svc.cluster.local"}
+		cert := MakeCertManagerCertificate(nm, "my-secret", dnsNames[0], dnsNames, CertManagerIssuer{Name: "my-issuer"})
+		Expect(cert.GroupVersionKind()).Should(Equal(CertificateGVK))
+		Expect(cert.GetName()).Should(Equal(nm.Name))
+		Expect(cert.GetNamespace()).Should(Equal(nm.Namespace))
+
+		secretName, _, _ := unstructured.NestedString(cert.Object, "spec", "secretName")
+		Expect(secretName).Should(Equal("my-secret"))
+		names, _, _ := unstructured.NestedStringSlice(cert.Object, "spec", "dnsNames")
+		Expect(names).Should(Equal(dnsNames))
+		// The kind and group default to the cert-manager Issuer
+		issuer, _, _ := unstructured.NestedStringMap(cert.Object, "spec", "issuerRef")
+		Expect(issuer).Should(Equal(map[string]string{
+			"name":  "my-issuer",
+			"kind":  CertManagerIssuerKind,
+			"group": CertManagerGroup,
+		}))
+	})
+
+	It("should rewrite the webhook cert in the cert dir when the secret changes", func() {
+		ctx := context.Background()
+		certDir := GinkgoT().TempDir()
+		nm := genWebhookCertSecretName("refresh-test", ns)
+
+		// Nothing is written until cert-manager issues the cert
+		_, changed, err := refreshWebhookCert(ctx, k8sClient, certDir, nm)
+		Expect(err).Should(Succeed())
+		Expect(changed).Should(BeFalse())
+
+		const KeySize = 2048
+		caCert, err := NewSelfSignedCACertificate(KeySize)
+		Expect(err).Should(Succeed())
+		cert, err := NewCertificate(caCert, KeySize, "webhook", []string{"webhook.svc"})
+		Expect(err).Should(Succeed())
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: nm.Name, Namespace: nm.Namespace},
+			Data: map[string][]byte{
+				corev1.TLSCertKey:       cert.TLSCrt(),
+				corev1.TLSPrivateKeyKey: cert.TLSKey(),
+				CACertKey:               caCert.TLSCrt(),
+			},
+		}
+		Expect(k8sClient.Create(ctx, secret)).Should(Succeed())
+		defer func() { Expect(k8sClient.Delete(ctx, secret)).Should(Succeed()) }()
+
+		caCrt, changed, err := refreshWebhookCert(ctx, k8sClient, certDir, nm)
+		Expect(err).Should(Succeed())
+		Expect(changed).Should(BeTrue())
+		Expect(caCrt).Should(Equal(caCert.TLSCrt()))
+		Expect(os.ReadFile(filepath.Join(certDir, corev1.TLSCertKey))).Should(Equal(cert.TLSCrt()))
+
+		// A second call is a no-op since the cert is already current
+		_, changed, err = refreshWebhookCert(ctx, k8sClient, certDir, nm)
+		Expect(err).Should(Succeed())
+		Expect(changed).Should(BeFalse())
+	})
+})
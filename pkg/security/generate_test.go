@@ -16,6 +16,8 @@
 package security
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -32,6 +34,31 @@ var _ = Describe("security", func() {
 		Expect(err).Should(Succeed())
 		verifyCerts(NewCertificate(caCert, 512, "dbadmin", []string{"host1", "host2"}))
 	})
+
+	It("should return the expiry time of a cert", func() {
+		caCert, err := NewSelfSignedCACertificate(512)
+		Expect(err).Should(Succeed())
+		cert, err := NewCertificate(caCert, 512, "dbadmin", nil)
+		Expect(err).Should(Succeed())
+		notAfter, err := MakeCertificate(cert.TLSKey(), cert.TLSCrt()).NotAfter()
+		Expect(err).Should(Succeed())
+		Expect(notAfter).Should(BeTemporally(">", time.Now().Add(365*24*time.Hour)))
+		_, err = MakeCertificate(nil, []byte("not a cert")).NotAfter()
+		Expect(err).ShouldNot(Succeed())
+	})
+
+	It("should return the fingerprint of a cert", func() {
+		caCert, err := NewSelfSignedCACertificate(512)
+		Expect(err).Should(Succeed())
+		cert, err := NewCertificate(caCert, 512, "dbadmin", nil)
+		Expect(err).Should(Succeed())
+		fp, err := cert.Fingerprint()
+		Expect(err).Should(Succeed())
+		Expect(fp).Should(HaveLen(64))
+		caFp, err := caCert.Fingerprint()
+		Expect(err).Should(Succeed())
+		Expect(caFp).ShouldNot(Equal(fp))
+	})
 })
 
 func verifyCerts(cert Certificate, err error) {
@@ -23,6 +23,7 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -30,7 +31,12 @@ import (
 	"k8s.io/client-go/util/retry"
 )
 
-const CACertKey = "ca.crt"
+const (
+	CACertKey = "ca.crt"
+	// The label that has the name of the namespace. This is used in the
+	// namespaceSelector of the webhook configs.
+	namespaceNameLabel = "kubernetes.io/metadata.name"
+)
 
 // PatchWebhookCABundle will update the webhook configuration with the given CA cert.
 func PatchWebhookCABundle(ctx context.Context, log *logr.Logger, cfg *rest.Config, caCert []byte, prefixName, ns string) error {
@@ -40,18 +46,75 @@ func PatchWebhookCABundle(ctx context.Context, log *logr.Logger, cfg *rest.Confi
 		return errors.Wrap(err, "could not create config")
 	}
 	cfgName := getMutatingWebhookConfigName(prefixName, ns)
-	err = patchMutatingWebhookConfig(ctx, cs, cfgName, caCert)
+	err = patchMutatingWebhookConfig(ctx, cs, cfgName, func(wh *admissionregistrationv1.MutatingWebhook) {
+		wh.ClientConfig.CABundle = caCert
+	})
 	if err != nil {
 		return errors.Wrap(err, "failed to patch the mutating webhook cfg")
 	}
 	cfgName = getValidatingWebhookConfigName(prefixName, ns)
-	err = patchValidatingWebhookConfig(ctx, cs, cfgName, caCert)
+	err = patchValidatingWebhookConfig(ctx, cs, cfgName, func(wh *admissionregistrationv1.ValidatingWebhook) {
+		wh.ClientConfig.CABundle = caCert
+	})
 	if err != nil {
 		return errors.Wrap(err, "failed to patch the mutating webhook cfg")
 	}
 	return nil
 }
 
+// PatchWebhookNamespaceSelector will update the namespaceSelector in the
+// webhook configurations so that the webhook applies to each namespace the
+// operator watches.  An empty list of namespaces means all namespaces.
+func PatchWebhookNamespaceSelector(ctx context.Context, log *logr.Logger, cfg *rest.Config, prefixName, ns string,
+	watchNamespaces []string) error {
+	log.Info("Patching webhook configurations with namespace selector", "namespaces", watchNamespaces)
+	cs, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "could not create config")
+	}
+	cfgName := getMutatingWebhookConfigName(prefixName, ns)
+	err = patchMutatingWebhookConfig(ctx, cs, cfgName, func(wh *admissionregistrationv1.MutatingWebhook) {
+		wh.NamespaceSelector = genNamespaceSelector(wh.NamespaceSelector, watchNamespaces)
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to patch the mutating webhook cfg")
+	}
+	cfgName = getValidatingWebhookConfigName(prefixName, ns)
+	err = patchValidatingWebhookConfig(ctx, cs, cfgName, func(wh *admissionregistrationv1.ValidatingWebhook) {
+		wh.NamespaceSelector = genNamespaceSelector(wh.NamespaceSelector, watchNamespaces)
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to patch the validating webhook cfg")
+	}
+	return nil
+}
+
+// genNamespaceSelector returns a copy of the given selector with the
+// expression for the namespace name set to the watched namespaces.  Any
+// other expression in the selector is kept.
+func genNamespaceSelector(sel *metav1.LabelSelector, watchNamespaces []string) *metav1.LabelSelector {
+	newSel := &metav1.LabelSelector{}
+	if sel != nil {
+		newSel = sel.DeepCopy()
+	}
+	exprs := []metav1.LabelSelectorRequirement{}
+	for i := range newSel.MatchExpressions {
+		if newSel.MatchExpressions[i].Key != namespaceNameLabel {
+			exprs = append(exprs, newSel.MatchExpressions[i])
+		}
+	}
+	nsExpr := metav1.LabelSelectorRequirement{
+		Key:      namespaceNameLabel,
+		Operator: metav1.LabelSelectorOpExists,
+	}
+	if len(watchNamespaces) > 0 {
+		nsExpr.Operator = metav1.LabelSelectorOpIn
+		nsExpr.Values = watchNamespaces
+	}
+	newSel.MatchExpressions = append(exprs, nsExpr)
+	return newSel
+}
+
 // PatchWebhookCABundleFromSecret will update the webhook configurations with the CA cert in the given secret.
 func PatchWebhookCABundleFromSecret(ctx context.Context, log *logr.Logger, cfg *rest.Config, secretName, prefixName, ns string) error {
 	cs, err := kubernetes.NewForConfig(cfg)
@@ -91,10 +154,7 @@ func GenerateWebhookCert(ctx context.Context, log *logr.Logger, cfg *rest.Config
 	if err != nil {
 		return errors.Wrap(err, "could not create self-signed CA for webhook")
 	}
-	dnsNames := []string{
-		fmt.Sprintf("%s-webhook-service.%s.svc", prefixName, ns),
-		fmt.Sprintf("%s-webhook-service.%s.svc.cluster.local", prefixName, ns),
-	}
+	dnsNames := getWebhookDNSNames(prefixName, ns)
 	cert, err := NewCertificate(caCert, PKKeySize, dnsNames[0], dnsNames)
 	if err != nil {
 		return errors.Wrap(err, "could not create webhook cert")
@@ -125,7 +185,8 @@ func writeCert(certDir string, cert Certificate) error {
 }
 
 //nolint:dupl
-func patchMutatingWebhookConfig(ctx context.Context, cs *kubernetes.Clientset, cfgName string, caCert []byte) error {
+func patchMutatingWebhookConfig(ctx context.Context, cs *kubernetes.Clientset, cfgName string,
+	updateFunc func(wh *admissionregistrationv1.MutatingWebhook)) error {
 	api := cs.AdmissionregistrationV1().MutatingWebhookConfigurations()
 	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 		cfg, err := api.Get(ctx, cfgName, metav1.GetOptions{})
@@ -133,7 +194,7 @@ func patchMutatingWebhookConfig(ctx context.Context, cs *kubernetes.Clientset, c
 			return err
 		}
 		for i := range cfg.Webhooks {
-			cfg.Webhooks[i].ClientConfig.CABundle = caCert
+			updateFunc(&cfg.Webhooks[i])
 		}
 		_, err = api.Update(ctx, cfg, metav1.UpdateOptions{})
 		return err
@@ -141,7 +202,8 @@ func patchMutatingWebhookConfig(ctx context.Context, cs *kubernetes.Clientset, c
 }
 
 //nolint:dupl
-func patchValidatingWebhookConfig(ctx context.Context, cs *kubernetes.Clientset, cfgName string, caCert []byte) error {
+func patchValidatingWebhookConfig(ctx context.Context, cs *kubernetes.Clientset, cfgName string,
+	updateFunc func(wh *admissionregistrationv1.ValidatingWebhook)) error {
 	api := cs.AdmissionregistrationV1().ValidatingWebhookConfigurations()
 	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 		cfg, err := api.Get(ctx, cfgName, metav1.GetOptions{})
@@ -149,7 +211,7 @@ func patchValidatingWebhookConfig(ctx context.Context, cs *kubernetes.Clientset,
 			return err
 		}
 		for i := range cfg.Webhooks {
-			cfg.Webhooks[i].ClientConfig.CABundle = caCert
+			updateFunc(&cfg.Webhooks[i])
 		}
 		_, err = api.Update(ctx, cfg, metav1.UpdateOptions{})
 		return err
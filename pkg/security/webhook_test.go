@@ -68,6 +68,27 @@ var _ = Describe("webhook", func() {
 		verifyCABundleEquals(ctx, prefixName, ns, nil)
 	})
 
+	It("should update webhook configuration with the watched namespaces", func() {
+		createWebhookConfiguration(ctx)
+		defer deleteWebhookConfiguration(ctx)
+
+		Expect(PatchWebhookNamespaceSelector(ctx, &logger, restCfg, prefixName, ns, []string{"ns1", "ns2"})).Should(Succeed())
+		vcfg := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+		nm := types.NamespacedName{Name: getValidatingWebhookConfigName(prefixName, ns)}
+		Expect(k8sClient.Get(ctx, nm, vcfg)).Should(Succeed())
+		Expect(vcfg.Webhooks[0].NamespaceSelector.MatchExpressions).Should(HaveLen(1))
+		Expect(vcfg.Webhooks[0].NamespaceSelector.MatchExpressions[0].Operator).Should(Equal(metav1.LabelSelectorOpIn))
+		Expect(vcfg.Webhooks[0].NamespaceSelector.MatchExpressions[0].Values).Should(ConsistOf("ns1", "ns2"))
+
+		Expect(PatchWebhookNamespaceSelector(ctx, &logger, restCfg, prefixName, ns, []string{})).Should(Succeed())
+		mcfg := &admissionregistrationv1.MutatingWebhookConfiguration{}
+		nm = types.NamespacedName{Name: getMutatingWebhookConfigName(prefixName, ns)}
+		Expect(k8sClient.Get(ctx, nm, mcfg)).Should(Succeed())
+		Expect(mcfg.Webhooks[0].NamespaceSelector.MatchExpressions).Should(HaveLen(1))
+		Expect(mcfg.Webhooks[0].NamespaceSelector.MatchExpressions[0].Operator).Should(Equal(metav1.LabelSelectorOpExists))
+		Expect(mcfg.Webhooks[0].NamespaceSelector.MatchExpressions[0].Values).Should(BeEmpty())
+	})
+
 	It("should write out certs to a file", func() {
 		createWebhookConfiguration(ctx)
 		defer deleteWebhookConfiguration(ctx)
@@ -86,6 +107,21 @@ var _ = Describe("webhook", func() {
 		Expect(files[0].Name()).Should(Equal(corev1.TLSCertKey))
 		Expect(files[1].Name()).Should(Equal(corev1.TLSPrivateKeyKey))
 	})
+
+	It("should fall back to a self-signed cert if cert-manager isn't installed", func() {
+		createWebhookConfiguration(ctx)
+		defer deleteWebhookConfiguration(ctx)
+
+		dir, err := os.MkdirTemp("", "mock-cert")
+		Expect(err).Should(Succeed())
+		defer os.RemoveAll(dir)
+
+		issuer := CertManagerIssuer{Name: "my-issuer", Kind: CertManagerClusterIssuerKind}
+		Expect(GenerateWebhookCertWithCertManager(ctx, &logger, restCfg, dir, prefixName, ns, issuer)).Should(Succeed())
+		files, err := os.ReadDir(dir)
+		Expect(err).Should(Succeed())
+		Expect(len(files)).Should(Equal(2))
+	})
 })
 
 func createWebhookConfiguration(ctx context.Context) {
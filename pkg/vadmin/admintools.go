@@ -0,0 +1,336 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vadmin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-logr/logr"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/atconf"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	"github.com/vertica/vertica-kubernetes/pkg/paths"
+)
+
+const (
+	// The name of the IP map file that is used by re_ip.  re_ip is only ever
+	// used if the entire cluster is down.
+	AdminToolsMapFile = "/opt/vertica/config/ipMap.txt"
+)
+
+// Admintools is the Dispatcher that runs admintools in one of the pods
+type Admintools struct {
+	Log      logr.Logger
+	Vdb      *vapi.VerticaDB
+	PRunner  cmds.PodRunner
+	ATWriter atconf.Writer
+}
+
+// MakeAdmintools will build an Admintools object
+func MakeAdmintools(log logr.Logger, vdb *vapi.VerticaDB, prunner cmds.PodRunner) Dispatcher {
+	return &Admintools{
+		Log:      log,
+		Vdb:      vdb,
+		PRunner:  prunner,
+		ATWriter: atconf.MakeFileWriter(log, vdb, prunner),
+	}
+}
+
+// FetchNodeState gets the node state from admintools -t list_allnodes
+func (a *Admintools) FetchNodeState(ctx context.Context, opts *FetchNodeStateOptions) (map[string]string, string, error) {
+	stdout, err := a.execAdmintools(ctx, &opts.InitiatorOptions, "-t", "list_allnodes")
+	if err != nil {
+		return nil, stdout, err
+	}
+	return ParseClusterNodeStatus(stdout), stdout, nil
+}
+
+// StartDB will call admintools -t start_db
+func (a *Admintools) StartDB(ctx context.Context, opts *StartDBOptions) (string, error) {
+	return a.execAdmintools(ctx, &opts.InitiatorOptions, a.genStartDBCmd(opts)...)
+}
+
+// StopDB will call admintools -t stop_db
+func (a *Admintools) StopDB(ctx context.Context, opts *StopDBOptions) (string, error) {
+	return a.execAdmintools(ctx, &opts.InitiatorOptions, a.genStopDBCmd()...)
+}
+
+// RestartNode will call admintools -t restart_node
+func (a *Admintools) RestartNode(ctx context.Context, opts *RestartNodeOptions) (string, error) {
+	return a.execAdmintools(ctx, &opts.InitiatorOptions, a.genRestartNodeCmd(opts)...)
+}
+
+// AddNode will call admintools -t db_add_node
+func (a *Admintools) AddNode(ctx context.Context, opts *AddNodeOptions) (string, error) {
+	return a.execAdmintools(ctx, &opts.InitiatorOptions, a.genAddNodeCmd(opts)...)
+}
+
+// RemoveNode will call admintools -t db_remove_node
+func (a *Admintools) RemoveNode(ctx context.Context, opts *RemoveNodeOptions) (string, error) {
+	return a.execAdmintools(ctx, &opts.InitiatorOptions, a.genRemoveNodeCmd(opts)...)
+}
+
+// ReIP will upload the map file then call admintools -t re_ip
+func (a *Admintools) ReIP(ctx context.Context, opts *ReIPOptions) (string, error) {
+	cmd := genMapFileUploadCmd(opts.Hosts)
+	if stdout, _, err := a.PRunner.ExecInPod(ctx, opts.Initiator, names.ServerContainer, cmd...); err != nil {
+		return stdout, err
+	}
+	return a.execAdmintools(ctx, &opts.InitiatorOptions, a.genReIPCmd()...)
+}
+
+// CreateDB will call admintools -t create_db
+func (a *Admintools) CreateDB(ctx context.Context, opts *CreateDBOptions) (string, error) {
+	return a.execAdmintools(ctx, &opts.InitiatorOptions, a.genCreateDBCmd(opts)...)
+}
+
+// ReviveDB will call admintools -t revive_db
+func (a *Admintools) ReviveDB(ctx context.Context, opts *ReviveDBOptions) (string, error) {
+	return a.execAdmintools(ctx, &opts.InitiatorOptions, a.genReviveDBCmd(opts)...)
+}
+
+// Install will add the hosts to admintools.conf and copy it to the pods
+func (a *Admintools) Install(ctx context.Context, opts *InstallOptions) (string, error) {
+	atConfTempFile, err := a.ATWriter.AddHosts(ctx, opts.Initiator, opts.Hosts)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(atConfTempFile)
+
+	// We will do error checking at the end so that we try to copy it to each
+	// pod.
+	errs := []error{}
+	for i, pod := range opts.Pods {
+		_, _, err := a.PRunner.CopyToPod(ctx, pod, names.ServerContainer, atConfTempFile, paths.AdminToolsConf)
+		if err != nil {
+			// The first pod is the base for any later change to the config.
+			// So there is no point in copying to the rest if it failed.
+			if i == 0 {
+				return "", err
+			}
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return "", &PartialCopyError{Failed: len(errs), Err: errs[0]}
+	}
+	return "", nil
+}
+
+// DescribeOp returns the admintools command for the operation
+func (a *Admintools) DescribeOp(op Op) string {
+	return fmt.Sprintf("admintools -t %s", op)
+}
+
+// execAdmintools will run admintools in the initiator pod and return its stdout
+func (a *Admintools) execAdmintools(ctx context.Context, opts *InitiatorOptions, cmd ...string) (string, error) {
+	stdout, _, err := a.PRunner.ExecAdmintools(ctx, opts.Initiator, names.ServerContainer, cmd...)
+	return stdout, err
+}
+
+// ParseClusterNodeStatus will parse the output from a AT -t list_allnodes call
+func ParseClusterNodeStatus(stdout string) map[string]string {
+	stateMap := map[string]string{}
+	lines := strings.Split(stdout, "\n")
+	const ColHeaderCount = 2
+	if len(lines) <= ColHeaderCount {
+		// Nothing to parse, return empty map
+		return stateMap
+	}
+	// We skip the first two lines because they are for the header of the
+	// output. The output that we are omitting looks like this:
+	//  Node          | Host       | State | Version                 | DB
+	// ---------------+------------+-------+-------------------------+----
+	for _, line := range lines[ColHeaderCount:] {
+		// Line is something like this:
+		//   v_db_node0001 | 10.244.1.6 | UP    | vertica-11.0.0.20210309 | db
+		cols := strings.Split(line, "|")
+		const ListNodesColCount = 4
+		if len(cols) < ListNodesColCount {
+			continue
+		}
+		vnode := strings.Trim(cols[0], " ")
+		state := strings.Trim(cols[2], " ")
+		stateMap[vnode] = state
+	}
+	return stateMap
+}
+
+// genStartDBCmd will return the command for start_db
+func (a *Admintools) genStartDBCmd(opts *StartDBOptions) []string {
+	cmd := []string{
+		"-t", "start_db",
+		"--database=" + a.Vdb.Spec.DBName,
+		"--noprompt",
+	}
+	if a.Vdb.Spec.IgnoreClusterLease {
+		cmd = append(cmd, "--ignore-cluster-lease")
+	}
+	if a.Vdb.Spec.RestartTimeout != 0 {
+		cmd = append(cmd, fmt.Sprintf("--timeout=%d", a.Vdb.Spec.RestartTimeout))
+	}
+
+	// In all versions that we support we can include a list of hosts to start.
+	// This parameter becomes important for online upgrade as we use this to
+	// start the primaries while the secondary are in read-only.
+	cmd = append(cmd, "--hosts", strings.Join(opts.Hosts, ","))
+	return cmd
+}
+
+// genStopDBCmd will return the command to stop the database
+func (a *Admintools) genStopDBCmd() []string {
+	return []string{
+		"-t", "stop_db",
+		"--database", a.Vdb.Spec.DBName,
+		"--force",
+	}
+}
+
+// genRestartNodeCmd returns the command to run to restart a pod
+func (a *Admintools) genRestartNodeCmd(opts *RestartNodeOptions) []string {
+	vnodeList := make([]string, 0, len(opts.Nodes))
+	ipList := make([]string, 0, len(opts.Nodes))
+	for i := range opts.Nodes {
+		vnodeList = append(vnodeList, opts.Nodes[i].VNode)
+		ipList = append(ipList, opts.Nodes[i].IP)
+	}
+	cmd := []string{
+		"-t", "restart_node",
+		"--database=" + a.Vdb.Spec.DBName,
+		"--hosts=" + strings.Join(vnodeList, ","),
+		"--new-host-ips=" + strings.Join(ipList, ","),
+		"--noprompt",
+	}
+	if a.Vdb.Spec.RestartTimeout != 0 {
+		cmd = append(cmd, fmt.Sprintf("--timeout=%d", a.Vdb.Spec.RestartTimeout))
+	}
+	return cmd
+}
+
+// genAddNodeCmd returns the command to run to add nodes to the cluster.
+func (a *Admintools) genAddNodeCmd(opts *AddNodeOptions) []string {
+	return []string{
+		"-t", "db_add_node",
+		"--hosts", strings.Join(opts.Hosts, ","),
+		"--database", a.Vdb.Spec.DBName,
+		"--subcluster", opts.Subcluster,
+		"--noprompt",
+	}
+}
+
+// genRemoveNodeCmd returns the command to run to remove nodes from the cluster.
+func (a *Admintools) genRemoveNodeCmd(opts *RemoveNodeOptions) []string {
+	return []string{
+		"-t", "db_remove_node",
+		"--database", a.Vdb.Spec.DBName,
+		"--hosts=" + strings.Join(opts.Hosts, ","),
+		"--noprompts",
+	}
+}
+
+// genMapFileUploadCmd returns the command to run to upload the map file.  Its
+// format is what is expected by admintools -t re_ip.
+func genMapFileUploadCmd(hosts []ReIPHost) []string {
+	mapFileContents := make([]string, 0, len(hosts))
+	for i := range hosts {
+		mapFileContents = append(mapFileContents, fmt.Sprintf("%s %s", hosts[i].OldIP, hosts[i].NewIP))
+	}
+	return []string{
+		"bash", "-c", "cat > " + AdminToolsMapFile + "<<< '" + strings.Join(mapFileContents, "\n") + "'",
+	}
+}
+
+// genReIPCmd will return the command to run for the re_ip command
+func (a *Admintools) genReIPCmd() []string {
+	cmd := []string{
+		"-t", "re_ip",
+		"--file=" + AdminToolsMapFile,
+		"--noprompt",
+	}
+
+	// In 11.1, we added a --force option to re_ip to allow us to run it while
+	// some nodes are up.  This was done to support doing a reip while there are
+	// read-only secondary nodes.
+	vinf, ok := a.Vdb.MakeVersionInfo()
+	if ok && vinf.IsEqualOrNewer(vapi.ReIPAllowedWithUpNodesVersion) {
+		cmd = append(cmd, "--force")
+	}
+
+	return cmd
+}
+
+// genCreateDBCmd will return the command to create the database
+func (a *Admintools) genCreateDBCmd(opts *CreateDBOptions) []string {
+	cmd := []string{
+		"-t", "create_db",
+		"--skip-fs-checks",
+		"--hosts=" + strings.Join(opts.Hosts, ","),
+		"--sql=" + opts.PostDBCreateSQLFile,
+		"--catalog_path=" + a.Vdb.Spec.Local.GetCatalogPath(),
+		"--database", a.Vdb.Spec.DBName,
+		"--force-cleanup-on-failure",
+		"--noprompt",
+		"--license", opts.LicensePath,
+		"--depot-path=" + a.Vdb.Spec.Local.DepotPath,
+	}
+
+	// If a communal path is set, include all of the EON parameters.
+	if a.Vdb.Spec.Communal.Path != "" {
+		cmd = append(cmd,
+			"--communal-storage-location="+a.Vdb.GetCommunalPath(),
+			"--communal-storage-params="+paths.AuthParmsFile,
+		)
+	}
+
+	if a.Vdb.Spec.ShardCount > 0 {
+		cmd = append(cmd,
+			fmt.Sprintf("--shard-count=%d", a.Vdb.Spec.ShardCount),
+		)
+	}
+
+	if a.Vdb.Spec.InitPolicy == vapi.CommunalInitPolicyCreateSkipPackageInstall {
+		vinf, ok := a.Vdb.MakeVersionInfo()
+		if ok && vinf.IsEqualOrNewer(vapi.CreateDBSkipPackageInstallVersion) {
+			cmd = append(cmd, "--skip-package-install")
+		}
+	}
+	return cmd
+}
+
+// genReviveDBCmd will return the command to revive the database
+func (a *Admintools) genReviveDBCmd(opts *ReviveDBOptions) []string {
+	cmd := []string{
+		"-t", "revive_db",
+		"--hosts=" + strings.Join(opts.Hosts, ","),
+		"--database", a.Vdb.Spec.DBName,
+	}
+	if a.Vdb.IsEON() {
+		cmd = append(cmd,
+			"--communal-storage-location="+a.Vdb.GetCommunalPath(),
+			"--communal-storage-params="+paths.AuthParmsFile)
+	}
+	if a.Vdb.Spec.IgnoreClusterLease {
+		cmd = append(cmd, "--ignore-cluster-lease")
+	}
+	if opts.DisplayOnly {
+		cmd = append(cmd, "--display-only")
+	}
+	return cmd
+}
@@ -0,0 +1,127 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vadmin
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/atconf"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/paths"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("admintools", func() {
+	ctx := context.Background()
+	atPod := types.NamespacedName{Namespace: "default", Name: "pod-0"}
+
+	It("should parse the list_allnodes output", func() {
+		stateMap := ParseClusterNodeStatus(
+			" Node          | Host       | State | Version                 | DB \n" +
+				"---------------+------------+-------+-------------------------+----\n" +
+				" v_d_node0001 | 10.244.1.6 | UP    | vertica-11.0.0.20210309 | db \n" +
+				" v_d_node0002 | 10.244.1.7 | DOWN  | vertica-11.0.0.20210309 | db \n" +
+				"\n",
+		)
+		Expect(stateMap).Should(HaveKeyWithValue("v_d_node0001", "UP"))
+		Expect(stateMap).Should(HaveKeyWithValue("v_d_node0002", "DOWN"))
+	})
+
+	It("should use --force option in reip if on version that supports it", func() {
+		vdb := vapi.MakeVDB()
+		a := MakeAdmintools(logger, vdb, &cmds.FakePodRunner{}).(*Admintools)
+		vdb.Annotations[vapi.VersionAnnotation] = vapi.MinimumVersion
+		Expect(a.genReIPCmd()).ShouldNot(ContainElement("--force"))
+		vdb.Annotations[vapi.VersionAnnotation] = vapi.ReIPAllowedWithUpNodesVersion
+		Expect(a.genReIPCmd()).Should(ContainElement("--force"))
+	})
+
+	It("should upload the map file before calling re_ip", func() {
+		vdb := vapi.MakeVDB()
+		fpr := &cmds.FakePodRunner{}
+		a := MakeAdmintools(logger, vdb, fpr)
+		opts := &ReIPOptions{
+			InitiatorOptions: InitiatorOptions{Initiator: atPod},
+			Hosts: []ReIPHost{
+				{Compat21Node: "node0001", OldIP: "4.4.4.4", NewIP: "10.10.1.1"},
+				{Compat21Node: "node0002", OldIP: "5.5.5.5", NewIP: "10.10.1.2"},
+			},
+		}
+		Expect(a.ReIP(ctx, opts)).Error().Should(Succeed())
+		Expect(fpr.Histories).Should(HaveLen(2))
+		Expect(fpr.Histories[0].Command).Should(ContainElement(
+			"cat > " + AdminToolsMapFile + "<<< '4.4.4.4 10.10.1.1\n5.5.5.5 10.10.1.2'"))
+		Expect(fpr.FindCommands("/opt/vertica/bin/admintools", "-t", "re_ip")).Should(HaveLen(1))
+	})
+
+	It("should use --hosts option in start_db if on earliest version that we support", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Annotations[vapi.VersionAnnotation] = vapi.MinimumVersion
+		a := MakeAdmintools(logger, vdb, &cmds.FakePodRunner{}).(*Admintools)
+		opts := &StartDBOptions{Hosts: []string{"9.10.1.1", "9.10.1.2"}}
+		Expect(a.genStartDBCmd(opts)).Should(ContainElements("--hosts", "9.10.1.1,9.10.1.2"))
+	})
+
+	It("should only include --ignore-cluster-lease in revive_db if set in the vdb", func() {
+		vdb := vapi.MakeVDB()
+		a := MakeAdmintools(logger, vdb, &cmds.FakePodRunner{}).(*Admintools)
+		opts := &ReviveDBOptions{Hosts: []string{"hostA"}}
+		Expect(a.genReviveDBCmd(opts)).ShouldNot(ContainElement("--ignore-cluster-lease"))
+		vdb.Spec.IgnoreClusterLease = true
+		Expect(a.genReviveDBCmd(opts)).Should(ContainElement("--ignore-cluster-lease"))
+		Expect(a.genReviveDBCmd(opts)).ShouldNot(ContainElement("--display-only"))
+		opts.DisplayOnly = true
+		Expect(a.genReviveDBCmd(opts)).Should(ContainElement("--display-only"))
+	})
+
+	It("should return the stdout of a failed admintools command", func() {
+		vdb := vapi.MakeVDB()
+		fpr := &cmds.FakePodRunner{
+			Results: cmds.CmdResults{
+				atPod: []cmds.CmdResult{{Stdout: "Node not found", Err: errors.New("failed")}},
+			},
+		}
+		a := MakeAdmintools(logger, vdb, fpr)
+		stdout, err := a.RemoveNode(ctx, &RemoveNodeOptions{
+			InitiatorOptions: InitiatorOptions{Initiator: atPod},
+			Hosts:            []string{"pod-1.svc"},
+		})
+		Expect(err).ShouldNot(Succeed())
+		Expect(stdout).Should(Equal("Node not found"))
+		Expect(fpr.FindCommands("-t", "db_remove_node", "--database", vdb.Spec.DBName, "--hosts=pod-1.svc")).Should(HaveLen(1))
+	})
+
+	It("should copy admintools.conf to every pod even if some of them fail", func() {
+		vdb := vapi.MakeVDB()
+		pods := []types.NamespacedName{atPod, {Namespace: "default", Name: "pod-1"}, {Namespace: "default", Name: "pod-2"}}
+		fpr := &cmds.FakePodRunner{
+			Results: cmds.CmdResults{
+				pods[1]: []cmds.CmdResult{{Err: errors.New("copy failed")}},
+			},
+		}
+		a := MakeAdmintools(logger, vdb, fpr).(*Admintools)
+		a.ATWriter = &atconf.FakeWriter{}
+		_, err := a.Install(ctx, &InstallOptions{Hosts: []string{"10.1.1.1"}, Pods: pods})
+		partialErr := &PartialCopyError{}
+		Expect(errors.As(err, &partialErr)).Should(BeTrue())
+		Expect(partialErr.Failed).Should(Equal(1))
+		Expect(fpr.FindCommands("cat > " + paths.AdminToolsConf)).Should(HaveLen(len(pods)))
+	})
+})
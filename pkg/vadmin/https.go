@@ -0,0 +1,261 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vadmin
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/builder"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	"github.com/vertica/vertica-kubernetes/pkg/paths"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// The amount of time we wait for a single request to the http server.
+	httpsRequestTimeout = time.Minute
+
+	httpsNodesEndpoint = "v1/nodes"
+)
+
+// HTTPS is an experimental Dispatcher that uses the REST interface of the
+// vertica http server.  It is only used when the
+// vertica.com/experimental-https-node-state annotation is set.  The http server
+// only runs while vertica is up, and of the node operations it only has an
+// endpoint to list the nodes.  So, only the node state is fetched through it.
+// Every other operation is handed off to the fallback dispatcher, which is
+// logged each time.
+type HTTPS struct {
+	Log    logr.Logger
+	Vdb    *vapi.VerticaDB
+	Client client.Client
+	// Returns the password of the superuser
+	GetPassword func(ctx context.Context) (string, error)
+	// The dispatcher to use for the operations the http server doesn't have
+	// an endpoint for, or when vertica isn't up.
+	Fallback Dispatcher
+	// The client used to send the requests.  If nil, we build one that trusts
+	// the CA in the httpServerTLSSecret.
+	HTTPClient *http.Client
+	// The port the http server listens on
+	Port int
+}
+
+// MakeHTTPS will build an HTTPS object
+func MakeHTTPS(log logr.Logger, vdb *vapi.VerticaDB, cli client.Client,
+	getPassword func(ctx context.Context) (string, error), fallback Dispatcher) Dispatcher {
+	return &HTTPS{
+		Log:         log,
+		Vdb:         vdb,
+		Client:      cli,
+		GetPassword: getPassword,
+		Fallback:    fallback,
+		Port:        builder.VerticaHTTPPort,
+	}
+}
+
+// httpsNode is the representation of a node in the GET v1/nodes response
+type httpsNode struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	State   string `json:"state"`
+}
+
+type httpsNodeListResponse struct {
+	NodeList []httpsNode `json:"node_list"`
+}
+
+// FetchNodeState gets the node state from the http server
+func (h *HTTPS) FetchNodeState(ctx context.Context, opts *FetchNodeStateOptions) (map[string]string, string, error) {
+	if opts.UpHost.IP == "" {
+		h.logFallback("FetchNodeState", "no host is up")
+		return h.Fallback.FetchNodeState(ctx, opts)
+	}
+	body, err := h.sendRequest(ctx, &opts.InitiatorOptions, http.MethodGet, httpsNodesEndpoint)
+	if err != nil {
+		return nil, body, err
+	}
+	resp := httpsNodeListResponse{}
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		return nil, body, fmt.Errorf("failed to parse the node list from the http server: %w", err)
+	}
+	stateMap := map[string]string{}
+	for i := range resp.NodeList {
+		stateMap[resp.NodeList[i].Name] = resp.NodeList[i].State
+	}
+	return stateMap, body, nil
+}
+
+// StartDB is always done with the fallback as the http server isn't running
+// when the database is down.
+func (h *HTTPS) StartDB(ctx context.Context, opts *StartDBOptions) (string, error) {
+	h.logFallback("StartDB", "the http server isn't running when the database is down")
+	return h.Fallback.StartDB(ctx, opts)
+}
+
+// StopDB is done with the fallback as the http server has no endpoint for it.
+func (h *HTTPS) StopDB(ctx context.Context, opts *StopDBOptions) (string, error) {
+	h.logFallback("StopDB", "the http server has no endpoint for it")
+	return h.Fallback.StopDB(ctx, opts)
+}
+
+// RestartNode is done with the fallback as the http server has no endpoint
+// for it.
+func (h *HTTPS) RestartNode(ctx context.Context, opts *RestartNodeOptions) (string, error) {
+	h.logFallback("RestartNode", "the http server has no endpoint for it")
+	return h.Fallback.RestartNode(ctx, opts)
+}
+
+// AddNode is done with the fallback as the http server has no endpoint for
+// it.
+func (h *HTTPS) AddNode(ctx context.Context, opts *AddNodeOptions) (string, error) {
+	h.logFallback("AddNode", "the http server has no endpoint for it")
+	return h.Fallback.AddNode(ctx, opts)
+}
+
+// RemoveNode is done with the fallback as the http server has no endpoint for
+// it.
+func (h *HTTPS) RemoveNode(ctx context.Context, opts *RemoveNodeOptions) (string, error) {
+	h.logFallback("RemoveNode", "the http server has no endpoint for it")
+	return h.Fallback.RemoveNode(ctx, opts)
+}
+
+// ReIP is always done with the fallback as it is only done when the database
+// is down.
+func (h *HTTPS) ReIP(ctx context.Context, opts *ReIPOptions) (string, error) {
+	h.logFallback("ReIP", "the database is down during re_ip")
+	return h.Fallback.ReIP(ctx, opts)
+}
+
+// CreateDB is always done with the fallback as there is no database, and
+// thus no http server, yet.
+func (h *HTTPS) CreateDB(ctx context.Context, opts *CreateDBOptions) (string, error) {
+	h.logFallback("CreateDB", "there is no database yet")
+	return h.Fallback.CreateDB(ctx, opts)
+}
+
+// ReviveDB is always done with the fallback as there is no database, and
+// thus no http server, yet.
+func (h *HTTPS) ReviveDB(ctx context.Context, opts *ReviveDBOptions) (string, error) {
+	h.logFallback("ReviveDB", "there is no database yet")
+	return h.Fallback.ReviveDB(ctx, opts)
+}
+
+// Install is always done with the fallback as the cluster config it writes is
+// used by the fallback.
+func (h *HTTPS) Install(ctx context.Context, opts *InstallOptions) (string, error) {
+	h.logFallback("Install", "the fallback uses the cluster config it writes")
+	return h.Fallback.Install(ctx, opts)
+}
+
+// DescribeOp returns the http endpoint for the operations that are sent to
+// the http server.  The rest are described by the fallback.
+func (h *HTTPS) DescribeOp(op Op) string {
+	if op == OpFetchNodeState {
+		return fmt.Sprintf("%s %s", http.MethodGet, httpsNodesEndpoint)
+	}
+	return h.Fallback.DescribeOp(op)
+}
+
+// logFallback will log that an operation is handed off to the fallback
+// dispatcher, along with the reason why.
+func (h *HTTPS) logFallback(op, reason string) {
+	h.Log.Info("Using the fallback dispatcher since the http server can't do the operation",
+		"op", op, "reason", reason)
+}
+
+// sendRequest will send a request to the http server of the up host.  The body
+// of the response is returned.  Any response that isn't a 2xx is treated as
+// an error.
+func (h *HTTPS) sendRequest(ctx context.Context, opts *InitiatorOptions, method, endpoint string) (string, error) {
+	httpClient, err := h.getHTTPClient(ctx)
+	if err != nil {
+		return "", err
+	}
+	passwd, err := h.GetPassword(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("https://%s/%s", net.JoinHostPort(opts.UpHost.IP, strconv.Itoa(h.Port)), endpoint)
+	req, err := http.NewRequestWithContext(ctx, method, url, http.NoBody)
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(h.Vdb.GetSuperuserName(), passwd)
+
+	h.Log.Info("Sending request to the http server", "method", method, "url", url, "pod", opts.UpHost.Pod)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return string(body), fmt.Errorf("%s %s failed with status %d: %s", method, endpoint, resp.StatusCode, body)
+	}
+	return string(body), nil
+}
+
+// getHTTPClient returns the client to send requests with.  It will trust the
+// CA that signed the certificate of the http server.
+func (h *HTTPS) getHTTPClient(ctx context.Context) (*http.Client, error) {
+	if h.HTTPClient != nil {
+		return h.HTTPClient, nil
+	}
+	if h.Vdb.Spec.HTTPServerTLSSecret == "" {
+		return nil, fmt.Errorf("httpServerTLSSecret must be set to use the %s annotation",
+			vapi.ExperimentalHTTPSNodeStateAnnotation)
+	}
+	secret := &corev1.Secret{}
+	if err := h.Client.Get(ctx, names.GenNamespacedName(h.Vdb, h.Vdb.Spec.HTTPServerTLSSecret), secret); err != nil {
+		return nil, err
+	}
+	caPool := x509.NewCertPool()
+	if !caPool.AppendCertsFromPEM(secret.Data[paths.HTTPServerCACrtName]) {
+		return nil, fmt.Errorf("could not find a CA certificate in the key '%s' of secret '%s'",
+			paths.HTTPServerCACrtName, secret.Name)
+	}
+	h.HTTPClient = &http.Client{
+		Timeout: httpsRequestTimeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs:    caPool,
+				MinVersion: tls.VersionTLS12,
+				// We connect with the pod IP.  The certificate we generate is
+				// for the service names in the namespace, so we verify
+				// against the name of the headless service.
+				ServerName: fmt.Sprintf("%s.%s.svc", names.GenHlSvcName(h.Vdb).Name, h.Vdb.Namespace),
+			},
+		},
+	}
+	return h.HTTPClient, nil
+}
@@ -0,0 +1,133 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vadmin
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("https", func() {
+	ctx := context.Background()
+	atPod := types.NamespacedName{Namespace: "default", Name: "pod-0"}
+	upHost := Host{Pod: types.NamespacedName{Namespace: "default", Name: "pod-1"}, IP: "127.0.0.1"}
+
+	// makeHTTPS will build an HTTPS dispatcher that sends its requests to the
+	// given test server.  The fallback uses the fake pod runner.
+	makeHTTPS := func(vdb *vapi.VerticaDB, srv *httptest.Server, fpr *cmds.FakePodRunner) *HTTPS {
+		getPassword := func(ctx context.Context) (string, error) { return "secret", nil }
+		h := MakeHTTPS(logger, vdb, nil, getPassword, MakeAdmintools(logger, vdb, fpr)).(*HTTPS)
+		h.HTTPClient = srv.Client()
+		_, port, err := net.SplitHostPort(srv.Listener.Addr().String())
+		Expect(err).Should(Succeed())
+		h.Port, err = strconv.Atoi(port)
+		Expect(err).Should(Succeed())
+		return h
+	}
+
+	It("should fetch the node state from the http server", func() {
+		srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, passwd, ok := r.BasicAuth()
+			if !ok || user != "vadmin" || passwd != "secret" || r.URL.Path != "/"+httpsNodesEndpoint {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"node_list":[{"name":"v_db_node0001","address":"10.1.1.1","state":"UP"},`+
+				`{"name":"v_db_node0002","address":"10.1.1.2","state":"DOWN"}]}`)
+		}))
+		defer srv.Close()
+
+		vdb := vapi.MakeVDB()
+		vdb.Spec.SuperuserName = "vadmin"
+		fpr := &cmds.FakePodRunner{}
+		h := makeHTTPS(vdb, srv, fpr)
+		stateMap, _, err := h.FetchNodeState(ctx, &FetchNodeStateOptions{
+			InitiatorOptions: InitiatorOptions{Initiator: atPod, UpHost: upHost},
+		})
+		Expect(err).Should(Succeed())
+		Expect(stateMap).Should(HaveKeyWithValue("v_db_node0001", "UP"))
+		Expect(stateMap).Should(HaveKeyWithValue("v_db_node0002", "DOWN"))
+		Expect(fpr.Histories).Should(BeEmpty())
+	})
+
+	It("should return the body as an error if the http server fails the request", func() {
+		srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"detail":"catalog is not available"}`)
+		}))
+		defer srv.Close()
+
+		h := makeHTTPS(vapi.MakeVDB(), srv, &cmds.FakePodRunner{})
+		_, body, err := h.FetchNodeState(ctx, &FetchNodeStateOptions{
+			InitiatorOptions: InitiatorOptions{Initiator: atPod, UpHost: upHost},
+		})
+		Expect(err).ShouldNot(Succeed())
+		Expect(err.Error()).Should(ContainSubstring("catalog is not available"))
+		Expect(body).Should(ContainSubstring("catalog is not available"))
+	})
+
+	It("should use admintools for the operations the http server has no endpoint for", func() {
+		requests := 0
+		srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+		}))
+		defer srv.Close()
+
+		fpr := &cmds.FakePodRunner{}
+		h := makeHTTPS(vapi.MakeVDB(), srv, fpr)
+		initOpts := InitiatorOptions{Initiator: atPod, UpHost: upHost}
+		_, err := h.RestartNode(ctx, &RestartNodeOptions{
+			InitiatorOptions: initOpts,
+			Nodes:            []Node{{VNode: "v_db_node0002", IP: "10.1.1.2"}},
+		})
+		Expect(err).Should(Succeed())
+		Expect(fpr.FindCommands("/opt/vertica/bin/admintools", "-t", "restart_node")).Should(HaveLen(1))
+		_, err = h.RemoveNode(ctx, &RemoveNodeOptions{InitiatorOptions: initOpts, Hosts: []string{"pod-2.svc"}})
+		Expect(err).Should(Succeed())
+		Expect(fpr.FindCommands("/opt/vertica/bin/admintools", "-t", "db_remove_node")).Should(HaveLen(1))
+		_, err = h.StopDB(ctx, &StopDBOptions{InitiatorOptions: initOpts})
+		Expect(err).Should(Succeed())
+		Expect(fpr.FindCommands("/opt/vertica/bin/admintools", "-t", "stop_db")).Should(HaveLen(1))
+		Expect(requests).Should(Equal(0))
+		Expect(h.DescribeOp(OpRestartNode)).Should(Equal("admintools -t restart_node"))
+		Expect(h.DescribeOp(OpFetchNodeState)).Should(Equal("GET v1/nodes"))
+	})
+
+	It("should fallback to admintools to fetch the node state if no host is up", func() {
+		requests := 0
+		srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+		}))
+		defer srv.Close()
+
+		fpr := &cmds.FakePodRunner{}
+		h := makeHTTPS(vapi.MakeVDB(), srv, fpr)
+		_, _, err := h.FetchNodeState(ctx, &FetchNodeStateOptions{InitiatorOptions: InitiatorOptions{Initiator: atPod}})
+		Expect(err).Should(Succeed())
+		Expect(fpr.FindCommands("/opt/vertica/bin/admintools", "-t", "list_allnodes")).Should(HaveLen(1))
+		Expect(requests).Should(Equal(0))
+	})
+})
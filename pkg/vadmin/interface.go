@@ -0,0 +1,184 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vadmin
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Dispatcher is the interface the reconcilers use to manage the nodes of a
+// database.  Each function returns the output of the operation, the stdout of
+// admintools or the body of the http response, so that the caller can use it
+// when reporting a failure.
+type Dispatcher interface {
+	// FetchNodeState will return the state (UP/DOWN) of each node in the
+	// cluster.  The map is keyed by the vertica node name.
+	FetchNodeState(ctx context.Context, opts *FetchNodeStateOptions) (map[string]string, string, error)
+	// StartDB will start the database when the entire cluster is down.
+	StartDB(ctx context.Context, opts *StartDBOptions) (string, error)
+	// StopDB will stop the database on all of the nodes.
+	StopDB(ctx context.Context, opts *StopDBOptions) (string, error)
+	// RestartNode will restart a subset of the nodes while the database is up.
+	RestartNode(ctx context.Context, opts *RestartNodeOptions) (string, error)
+	// AddNode will add hosts to an existing subcluster of the database.
+	AddNode(ctx context.Context, opts *AddNodeOptions) (string, error)
+	// RemoveNode will remove hosts from the database.
+	RemoveNode(ctx context.Context, opts *RemoveNodeOptions) (string, error)
+	// ReIP will update the IP of the nodes in the cluster config.
+	ReIP(ctx context.Context, opts *ReIPOptions) (string, error)
+	// CreateDB will create a new database.
+	CreateDB(ctx context.Context, opts *CreateDBOptions) (string, error)
+	// ReviveDB will revive a database from communal storage.
+	ReviveDB(ctx context.Context, opts *ReviveDBOptions) (string, error)
+	// Install will add hosts to the cluster config and copy the new config to
+	// the pods.
+	Install(ctx context.Context, opts *InstallOptions) (string, error)
+	// DescribeOp returns how the operation is carried out, such as the
+	// admintools command or the http endpoint.  This is used in events.
+	DescribeOp(op Op) string
+}
+
+// Op identifies one of the node operations of the Dispatcher.  The value is
+// the admintools tool that does the operation.
+type Op string
+
+const (
+	OpFetchNodeState Op = "list_allnodes"
+	OpStartDB        Op = "start_db"
+	OpStopDB         Op = "stop_db"
+	OpRestartNode    Op = "restart_node"
+	OpAddNode        Op = "db_add_node"
+	OpRemoveNode     Op = "db_remove_node"
+	OpReIP           Op = "re_ip"
+	OpCreateDB       Op = "create_db"
+	OpReviveDB       Op = "revive_db"
+)
+
+// Host identifies a pod that has a running vertica process
+type Host struct {
+	Pod types.NamespacedName
+	IP  string
+}
+
+// InitiatorOptions are common to all of the operations.  They tell the
+// dispatcher where the operation is run from.
+type InitiatorOptions struct {
+	// The pod to run admintools from
+	Initiator types.NamespacedName
+	// A pod that has vertica up.  This is used to send requests to the http
+	// server.  If empty, the operation is done with admintools.
+	UpHost Host
+}
+
+// PartialCopyError is returned by Install when the new config was copied to
+// some of the pods but not all of them.
+type PartialCopyError struct {
+	// The number of pods the copy failed for
+	Failed int
+	// The first error that was seen
+	Err error
+}
+
+func (p *PartialCopyError) Error() string {
+	return fmt.Sprintf("failed to copy the config to %d pod(s): %s", p.Failed, p.Err)
+}
+
+func (p *PartialCopyError) Unwrap() error {
+	return p.Err
+}
+
+// Node is a vertica node that we restart
+type Node struct {
+	VNode string
+	IP    string
+}
+
+// ReIPHost maps the old IP of a node to its new IP
+type ReIPHost struct {
+	Compat21Node string
+	OldIP        string
+	NewIP        string
+}
+
+type FetchNodeStateOptions struct {
+	InitiatorOptions
+}
+
+type StartDBOptions struct {
+	InitiatorOptions
+	// IPs of the hosts to start
+	Hosts []string
+}
+
+type StopDBOptions struct {
+	InitiatorOptions
+}
+
+type RestartNodeOptions struct {
+	InitiatorOptions
+	Nodes []Node
+}
+
+type AddNodeOptions struct {
+	InitiatorOptions
+	// DNS names of the hosts to add
+	Hosts      []string
+	Subcluster string
+}
+
+type RemoveNodeOptions struct {
+	InitiatorOptions
+	// DNS names of the hosts to remove
+	Hosts []string
+}
+
+type ReIPOptions struct {
+	InitiatorOptions
+	Hosts []ReIPHost
+}
+
+type CreateDBOptions struct {
+	InitiatorOptions
+	// IPs of the hosts to create the database with
+	Hosts []string
+	// Path, in the pod, of the SQL file to run after the database is created
+	PostDBCreateSQLFile string
+	// Path, in the pod, of the license file
+	LicensePath string
+}
+
+type ReviveDBOptions struct {
+	InitiatorOptions
+	// IPs of the hosts to revive the database with
+	Hosts []string
+	// Only check the preconditions of the revive.  The output of this is
+	// fed into the revive planner.
+	DisplayOnly bool
+}
+
+type InstallOptions struct {
+	// The pod whose config we add the hosts to.  If empty, the config is
+	// built from scratch.
+	InitiatorOptions
+	// IPs of the hosts to add
+	Hosts []string
+	// The pods to copy the new config to.  The first pod is copied to before
+	// any of the others.
+	Pods []types.NamespacedName
+}
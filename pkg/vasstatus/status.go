@@ -57,19 +57,25 @@ func RefreshCurrentSize(ctx context.Context, c client.Client, log logr.Logger, r
 // status condition is already set.
 func UpdateCondition(ctx context.Context, clnt client.Client, log logr.Logger,
 	req *ctrl.Request, condition vapi.VerticaAutoscalerCondition) error {
+	inx, ok := vapi.VerticaAutoscalerConditionIndexMap[condition.Type]
+	if !ok {
+		return fmt.Errorf("verticaAutoscaler condition '%s' missing from VerticaAutoscalerConditionType", condition.Type)
+	}
 	if condition.LastTransitionTime.IsZero() {
 		condition.LastTransitionTime = metav1.Now()
 	}
-	// refreshConditionInPlace will update the status condition in vdb.  The update
+	// refreshConditionInPlace will update the status condition in vas.  The update
 	// will be applied in-place.
 	refreshConditionInPlace := func(vas *vapi.VerticaAutoscaler) {
-		if len(vas.Status.Conditions) == 0 {
+		// Ensure the array is big enough
+		for i := len(vas.Status.Conditions); i <= inx; i++ {
 			vas.Status.Conditions = append(vas.Status.Conditions, vapi.VerticaAutoscalerCondition{})
 		}
 		// Only update if status is different change.  Cannot compare the entire
 		// condition since LastTransitionTime will be different each time.
-		if vas.Status.Conditions[vapi.TargetSizeInitializedIndex].Status != condition.Status {
-			vas.Status.Conditions[vapi.TargetSizeInitializedIndex] = condition
+		cur := &vas.Status.Conditions[inx]
+		if cur.Status != condition.Status || cur.Reason != condition.Reason || cur.Message != condition.Message {
+			vas.Status.Conditions[inx] = condition
 		}
 	}
 
@@ -97,7 +103,7 @@ func vasStatusUpdater(ctx context.Context, c client.Client, log logr.Logger,
 
 		statusUpdateFunc(vas)
 
-		if !reflect.DeepEqual(vasOrig, vas.Status) {
+		if !reflect.DeepEqual(vasOrig.Status, vas.Status) {
 			log.Info("Updating vas status", "status", vas.Status)
 			if err := c.Status().Update(ctx, vas); err != nil {
 				return err
@@ -136,4 +136,30 @@ var _ = Describe("status", func() {
 		Expect(len(vas.Status.Conditions)).Should(Equal(1))
 		Expect(vas.Status.Conditions[vapi.TargetSizeInitializedIndex].Status).Should(Equal(cond.Status))
 	})
+	It("should pad the conditions when setting the Paused condition", func() {
+		vas := vapi.MakeVAS()
+		test.CreateVAS(ctx, k8sClient, vas)
+		defer test.DeleteVAS(ctx, k8sClient, vas)
+
+		nm := vapi.MakeVASName()
+		req := ctrl.Request{NamespacedName: nm}
+		cond := vapi.VerticaAutoscalerCondition{
+			Type:    vapi.AutoscalerPaused,
+			Status:  corev1.ConditionTrue,
+			Reason:  vapi.ReasonReconcilePaused,
+			Message: "paused",
+		}
+		Expect(UpdateCondition(ctx, k8sClient, logger, &req, cond)).Should(Succeed())
+
+		Expect(k8sClient.Get(ctx, nm, vas)).Should(Succeed())
+		Expect(len(vas.Status.Conditions)).Should(Equal(vapi.AutoscalerPausedIndex + 1))
+		Expect(vas.Status.Conditions[vapi.AutoscalerPausedIndex].Reason).Should(Equal(vapi.ReasonReconcilePaused))
+		Expect(vas.Status.Conditions[vapi.TargetSizeInitializedIndex].Status).Should(BeEmpty())
+
+		// A condition with the same status is a no-op
+		rv := vas.ResourceVersion
+		Expect(UpdateCondition(ctx, k8sClient, logger, &req, cond)).Should(Succeed())
+		Expect(k8sClient.Get(ctx, nm, vas)).Should(Succeed())
+		Expect(vas.ResourceVersion).Should(Equal(rv))
+	})
 })
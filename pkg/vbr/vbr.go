@@ -0,0 +1,243 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vbr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/vertica/vertica-kubernetes/pkg/cloud"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	"github.com/vertica/vertica-kubernetes/pkg/paths"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// Prefixes of the environment variables that vbr reads to get the
+	// credentials for the backup location and for communal storage.
+	BackupEnvPrefix   = "VBR_BACKUP_STORAGE"
+	CommunalEnvPrefix = "VBR_COMMUNAL_STORAGE"
+
+	// RestorePointTimeLayout is the layout of the timestamp that vbr appends
+	// to the snapshot name when it creates a restore point.
+	RestorePointTimeLayout = "20060102_150405"
+)
+
+// restorePointRegexp splits a restore point into its snapshot name and the
+// archive ID, which is the timestamp of the restore point.
+var restorePointRegexp = regexp.MustCompile(`^(.+)_(\d{8}_\d{6})$`)
+
+// Location describes one of the object stores that vbr must access
+type Location struct {
+	EnvPrefix  string
+	IsAzure    bool
+	SecretName string
+	// The contents of the credential secret.  This is nil if the location
+	// doesn't have a credential secret.
+	SecretData map[string][]byte
+	Endpoint   string
+	Region     string
+	CaFile     string
+}
+
+// Env holds the environment variables that are passed to vbr
+type Env struct {
+	vars        []string
+	secretsSeen map[string]bool
+	azureCreds  []cloud.AzureCredential
+	azureConfig []cloud.AzureEndpointConfig
+}
+
+// Config has the settings that go in the vbr config file
+type Config struct {
+	// The path in the object store where backups are kept
+	BackupPath   string
+	SnapshotName string
+	DBName       string
+	// The database superuser that vbr connects as
+	DBUser string
+	// Path, in the pod, of the file that has the superuser password.  Leave
+	// empty if the superuser doesn't have a password.
+	PasswordFile string
+}
+
+// MakeEnv will build an empty Env object
+func MakeEnv() *Env {
+	return &Env{secretsSeen: map[string]bool{}}
+}
+
+// AddLocation will include the environment variables needed to access a
+// single storage location.  An error is returned if the credential secret
+// isn't setup properly.
+func (e *Env) AddLocation(loc *Location) error {
+	if loc.IsAzure {
+		// Azure credentials for all locations are combined into a single
+		// variable.  Skip if we have already included this secret.
+		if loc.SecretData == nil || e.secretsSeen[loc.SecretName] {
+			return nil
+		}
+		creds, config, err := cloud.ReadAzureCredential(loc.SecretData)
+		if err != nil {
+			return fmt.Errorf("it is not setup properly for azure: %w", err)
+		}
+		e.secretsSeen[loc.SecretName] = true
+		e.azureCreds = append(e.azureCreds, creds)
+		e.azureConfig = append(e.azureConfig, config)
+		return nil
+	}
+
+	if loc.SecretData != nil {
+		accessKey, secretKey, missingKey := cloud.GetAccessAndSecretKey(loc.SecretData)
+		if missingKey != "" {
+			return fmt.Errorf("it does not have a key named '%s'", missingKey)
+		}
+		e.add(loc.EnvPrefix+"_ACCESS_KEY_ID", accessKey)
+		e.add(loc.EnvPrefix+"_SECRET_ACCESS_KEY", secretKey)
+	}
+	e.add(loc.EnvPrefix+"_ENDPOINT_URL", loc.Endpoint)
+	e.add(loc.EnvPrefix+"_REGION", loc.Region)
+	e.add(loc.EnvPrefix+"_CA_FILE", loc.CaFile)
+	return nil
+}
+
+// GenContent returns the contents of the environment file
+func (e *Env) GenContent() (string, error) {
+	if len(e.azureCreds) > 0 {
+		creds, err := json.Marshal(e.azureCreds)
+		if err != nil {
+			return "", err
+		}
+		config, err := json.Marshal(e.azureConfig)
+		if err != nil {
+			return "", err
+		}
+		e.add("AzureStorageCredentials", string(creds))
+		e.add("AzureStorageEndpointConfig", string(config))
+	}
+	if len(e.vars) == 0 {
+		return "", nil
+	}
+	return strings.Join(e.vars, "\n") + "\n", nil
+}
+
+// add will include an environment variable. Nothing is added if the value is
+// empty.
+func (e *Env) add(key, val string) {
+	if val == "" {
+		return
+	}
+	e.vars = append(e.vars, fmt.Sprintf("%s=%s", key, ShellQuote(val)))
+}
+
+// Gen will generate the contents of the vbr config file
+func (c *Config) Gen() string {
+	var sb strings.Builder
+	sb.WriteString("[CloudStorage]\n")
+	fmt.Fprintf(&sb, "cloud_storage_backup_path = %s/\n", strings.TrimSuffix(c.BackupPath, "/"))
+	fmt.Fprintf(&sb, "cloud_storage_backup_file_system_path = []:%s/\n", paths.VbrLockPath)
+	sb.WriteString("\n[Misc]\n")
+	fmt.Fprintf(&sb, "snapshotName = %s\n", c.SnapshotName)
+	sb.WriteString("tempDir = /tmp/vbr\n")
+	if c.PasswordFile != "" {
+		fmt.Fprintf(&sb, "passwordFile = %s\n", c.PasswordFile)
+	}
+	sb.WriteString("\n[Database]\n")
+	fmt.Fprintf(&sb, "dbName = %s\n", c.DBName)
+	fmt.Fprintf(&sb, "dbUser = %s\n", c.DBUser)
+	sb.WriteString("dbPromptForPassword = False\n")
+	return sb.String()
+}
+
+// GenPasswordFile returns the contents of the vbr password file
+func GenPasswordFile(passwd string) string {
+	return fmt.Sprintf("[Passwords]\ndbPassword = %s\n", passwd)
+}
+
+// GenTaskCmd returns the command to run a single vbr task in a pod
+func GenTaskCmd(envFile, configFile, task string, extraArgs ...string) []string {
+	vbrCmd := fmt.Sprintf("%s --task %s --config-file %s", paths.VbrBinary, task, configFile)
+	for _, arg := range extraArgs {
+		vbrCmd += " " + ShellQuote(arg)
+	}
+	// The environment file is sourced with allexport so that vbr can see the
+	// credentials without having them in the command line.
+	return []string{
+		"bash", "-c", fmt.Sprintf("set -a && . %s && set +a && %s", envFile, vbrCmd),
+	}
+}
+
+// CopyFileToPod will write the content to a temporary file and copy it into
+// the pod.  We use a temporary file rather than an exec with the content so
+// that credentials are never written to the operator log.
+func CopyFileToPod(ctx context.Context, prunner cmds.PodRunner, atPod types.NamespacedName, dest, content string) error {
+	tmp, err := os.CreateTemp("", "vbr.")
+	if err != nil {
+		return err
+	}
+	defer tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.WriteString(content)
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+
+	_, _, err = prunner.CopyToPod(ctx, atPod, names.ServerContainer, tmp.Name(), dest)
+	return err
+}
+
+// ShellQuote will quote a value so that it can be safely sourced by bash
+func ShellQuote(val string) string {
+	return "'" + strings.ReplaceAll(val, "'", `'\''`) + "'"
+}
+
+// SplitRestorePoint will split a restore point, which vbr names
+// <snapshotName>_<YYYYMMDD>_<HHMMSS>, into the snapshot name and the archive
+// ID.  The archive ID is what vbr expects in its --archive option.
+func SplitRestorePoint(restorePoint string) (snapshotName, archiveID string, ok bool) {
+	m := restorePointRegexp.FindStringSubmatch(restorePoint)
+	if m == nil {
+		return "", "", false
+	}
+	if _, err := time.Parse(RestorePointTimeLayout, m[2]); err != nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// IsBackupLocationInitialized returns true if the vbr output indicates the
+// backup location was already initialized.
+func IsBackupLocationInitialized(op string) bool {
+	return strings.Contains(op, "already initialized")
+}
+
+// GetErrorMessage returns a short message describing why vbr failed.  vbr
+// prefixes its errors with "Error:", so we return the first one of those.
+func GetErrorMessage(op string, err error) string {
+	for _, line := range strings.Split(op, "\n") {
+		if i := strings.Index(line, "Error:"); i >= 0 {
+			return strings.TrimSpace(line[i:])
+		}
+	}
+	return err.Error()
+}
@@ -0,0 +1,100 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vbr
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/vertica/vertica-kubernetes/pkg/cloud"
+)
+
+func TestAPIs(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "vbr Suite")
+}
+
+var _ = Describe("vbr", func() {
+	It("should generate a config file", func() {
+		cfg := Config{BackupPath: "s3://bucket/db/", SnapshotName: "nightly", DBName: "vertdb", DBUser: "admin"}
+		content := cfg.Gen()
+		Expect(content).Should(ContainSubstring("cloud_storage_backup_path = s3://bucket/db/\n"))
+		Expect(content).Should(ContainSubstring("snapshotName = nightly\n"))
+		Expect(content).Should(ContainSubstring("dbName = vertdb\n"))
+		Expect(content).Should(ContainSubstring("dbUser = admin\n"))
+		Expect(content).ShouldNot(ContainSubstring("passwordFile"))
+		cfg.PasswordFile = "/tmp/pw"
+		Expect(cfg.Gen()).Should(ContainSubstring("passwordFile = /tmp/pw\n"))
+	})
+
+	It("should generate the environment for s3 locations", func() {
+		env := MakeEnv()
+		Expect(env.AddLocation(&Location{
+			EnvPrefix:  BackupEnvPrefix,
+			SecretName: "s1",
+			SecretData: map[string][]byte{cloud.CommunalAccessKeyName: []byte("ak"), cloud.CommunalSecretKeyName: []byte("s'k")},
+			Endpoint:   "https://s3.amazonaws.com",
+		})).Should(Succeed())
+		Expect(env.AddLocation(&Location{
+			EnvPrefix:  CommunalEnvPrefix,
+			SecretName: "s2",
+			SecretData: map[string][]byte{cloud.CommunalAccessKeyName: []byte("ak")},
+		})).ShouldNot(Succeed())
+		content, err := env.GenContent()
+		Expect(err).Should(Succeed())
+		Expect(content).Should(Equal(fmt.Sprintf("%s_ACCESS_KEY_ID='ak'\n%s_SECRET_ACCESS_KEY='s'\\''k'\n%s_ENDPOINT_URL='https://s3.amazonaws.com'\n",
+			BackupEnvPrefix, BackupEnvPrefix, BackupEnvPrefix)))
+	})
+
+	It("should combine azure credentials from different locations", func() {
+		env := MakeEnv()
+		data := map[string][]byte{cloud.AzureAccountName: []byte("acct"), cloud.AzureAccountKey: []byte("key")}
+		Expect(env.AddLocation(&Location{EnvPrefix: BackupEnvPrefix, IsAzure: true, SecretName: "az", SecretData: data})).Should(Succeed())
+		Expect(env.AddLocation(&Location{EnvPrefix: CommunalEnvPrefix, IsAzure: true, SecretName: "az", SecretData: data})).Should(Succeed())
+		Expect(env.AddLocation(&Location{EnvPrefix: BackupEnvPrefix, IsAzure: true, SecretName: "bad",
+			SecretData: map[string][]byte{}})).ShouldNot(Succeed())
+		content, err := env.GenContent()
+		Expect(err).Should(Succeed())
+		Expect(content).Should(ContainSubstring(`AzureStorageCredentials='[{"accountName":"acct","accountKey":"key"}]'`))
+	})
+
+	It("should generate a command to run a vbr task", func() {
+		cmd := GenTaskCmd("/tmp/env", "/tmp/cfg.ini", "remove", "--archive", "20230101_120000")
+		Expect(cmd).Should(HaveLen(3))
+		Expect(cmd[2]).Should(Equal("set -a && . /tmp/env && set +a && /opt/vertica/bin/vbr --task remove " +
+			"--config-file /tmp/cfg.ini '--archive' '20230101_120000'"))
+	})
+
+	It("should split a restore point", func() {
+		snapshot, archive, ok := SplitRestorePoint("db_nightly_20230101_120000")
+		Expect(ok).Should(BeTrue())
+		Expect(snapshot).Should(Equal("db_nightly"))
+		Expect(archive).Should(Equal("20230101_120000"))
+		_, _, ok = SplitRestorePoint("nightly")
+		Expect(ok).Should(BeFalse())
+		_, _, ok = SplitRestorePoint("nightly_20231301_120000")
+		Expect(ok).Should(BeFalse())
+	})
+
+	It("should extract the error message from vbr output", func() {
+		Expect(GetErrorMessage("line1\nvbr: Error: bad config\n", fmt.Errorf("exit 1"))).Should(Equal("Error: bad config"))
+		Expect(GetErrorMessage("", fmt.Errorf("exit 1"))).Should(Equal("exit 1"))
+		Expect(IsBackupLocationInitialized("Backup location is already initialized")).Should(BeTrue())
+	})
+})
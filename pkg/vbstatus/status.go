@@ -0,0 +1,97 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vbstatus
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Update will set status fields in the VerticaBackup.  It handles retry for
+// transient errors like when update fails because another client updated the
+// VerticaBackup.
+func Update(ctx context.Context, clnt client.Client, vb *vapi.VerticaBackup, updateFunc func(*vapi.VerticaBackup) error) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		// Always fetch the latest to minimize the chance of getting a conflict error.
+		if err := clnt.Get(ctx, vb.ExtractNamespacedName(), vb); err != nil {
+			return err
+		}
+
+		// We will calculate the status for the vb object. This update is done in
+		// place. If anything differs from the copy then we will do a single update.
+		vbChg := vb.DeepCopy()
+
+		// Refresh the status using the users provided function
+		if err := updateFunc(vbChg); err != nil {
+			return err
+		}
+
+		if !reflect.DeepEqual(vb.Status, vbChg.Status) {
+			vbChg.Status.DeepCopyInto(&vb.Status)
+			if err := clnt.Status().Update(ctx, vb); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateCondition will update a condition status.  This is a no-op if the
+// status condition is already set.  The input vb will be updated with the
+// status condition.
+func UpdateCondition(ctx context.Context, clnt client.Client, vb *vapi.VerticaBackup, condition *vapi.VerticaBackupCondition) error {
+	return Update(ctx, clnt, vb, func(vb *vapi.VerticaBackup) error {
+		return SetConditionInPlace(vb, condition)
+	})
+}
+
+// SetConditionInPlace will set a condition in the given VerticaBackup without
+// writing it to k8s.  This can be used when multiple status fields need to be
+// changed in a single update.
+func SetConditionInPlace(vb *vapi.VerticaBackup, condition *vapi.VerticaBackupCondition) error {
+	inx, ok := vapi.VerticaBackupConditionIndexMap[condition.Type]
+	if !ok {
+		return fmt.Errorf("vertica backup condition '%s' missing from VerticaBackupConditionType", condition.Type)
+	}
+	// Ensure the array is big enough
+	for i := len(vb.Status.Conditions); i <= inx; i++ {
+		vb.Status.Conditions = append(vb.Status.Conditions, vapi.VerticaBackupCondition{
+			Type:               vapi.VerticaBackupConditionNameMap[i],
+			Status:             corev1.ConditionFalse,
+			LastTransitionTime: metav1.Unix(0, 0),
+		})
+	}
+	// Only update if status, reason or message changed.  Cannot compare the
+	// entire condition since LastTransitionTime will be different each time.
+	cur := &vb.Status.Conditions[inx]
+	if cur.Status != condition.Status {
+		cur.Status = condition.Status
+		cur.LastTransitionTime = condition.LastTransitionTime
+		if cur.LastTransitionTime.IsZero() {
+			cur.LastTransitionTime = metav1.Now()
+		}
+	}
+	cur.Reason = condition.Reason
+	cur.Message = condition.Message
+	return nil
+}
@@ -0,0 +1,109 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vbstatus
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+var k8sClient client.Client
+var testEnv *envtest.Environment
+var logger logr.Logger
+
+var _ = BeforeSuite(func() {
+	logger = zap.New(zap.WriteTo(GinkgoWriter), zap.UseDevMode(true))
+	logf.SetLogger(logger)
+
+	By("bootstrapping test environment")
+	testEnv = &envtest.Environment{
+		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "config", "crd", "bases")},
+		ErrorIfCRDPathMissing: true,
+	}
+
+	cfg, err := testEnv.Start()
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	ExpectWithOffset(1, cfg).NotTo(BeNil())
+	restCfg := cfg
+
+	err = vapi.AddToScheme(scheme.Scheme)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+
+	k8sClient, err = client.New(restCfg, client.Options{Scheme: scheme.Scheme})
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+})
+
+var _ = AfterSuite(func() {
+	By("tearing down the test environment")
+	err := testEnv.Stop()
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+})
+
+func TestAPIs(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "vbstatus Suite")
+}
+
+var _ = Describe("status", func() {
+	ctx := context.Background()
+
+	It("should fill in earlier conditions when setting a later one", func() {
+		vb := vapi.MakeVB()
+		Expect(k8sClient.Create(ctx, vb)).Should(Succeed())
+		defer func() { Expect(k8sClient.Delete(ctx, vb)).Should(Succeed()) }()
+
+		cond := &vapi.VerticaBackupCondition{Type: vapi.BackupFailed, Status: corev1.ConditionTrue,
+			Reason: "VbrFailed", Message: "backup failed"}
+		Expect(UpdateCondition(ctx, k8sClient, vb, cond)).Should(Succeed())
+
+		fetchVb := &vapi.VerticaBackup{}
+		Expect(k8sClient.Get(ctx, vb.ExtractNamespacedName(), fetchVb)).Should(Succeed())
+		for _, v := range []*vapi.VerticaBackup{vb, fetchVb} {
+			Expect(len(v.Status.Conditions)).Should(Equal(vapi.BackupFailedIndex + 1))
+			Expect(v.Status.Conditions[vapi.BackupInProgressIndex].Status).Should(Equal(corev1.ConditionFalse))
+			Expect(v.Status.Conditions[vapi.BackupFailedIndex].Status).Should(Equal(corev1.ConditionTrue))
+			Expect(v.Status.Conditions[vapi.BackupFailedIndex].Reason).Should(Equal("VbrFailed"))
+			Expect(v.IsConditionSet(vapi.BackupFailed)).Should(BeTrue())
+		}
+	})
+
+	It("should update other status fields", func() {
+		vb := vapi.MakeVB()
+		Expect(k8sClient.Create(ctx, vb)).Should(Succeed())
+		defer func() { Expect(k8sClient.Delete(ctx, vb)).Should(Succeed()) }()
+
+		Expect(Update(ctx, k8sClient, vb, func(vb *vapi.VerticaBackup) error {
+			vb.Status.RestorePointID = "snap_20230101_120000"
+			return nil
+		})).Should(Succeed())
+		fetchVb := &vapi.VerticaBackup{}
+		Expect(k8sClient.Get(ctx, vb.ExtractNamespacedName(), fetchVb)).Should(Succeed())
+		Expect(fetchVb.Status.RestorePointID).Should(Equal("snap_20230101_120000"))
+	})
+})
@@ -0,0 +1,81 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vctl
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/go-logr/logr"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/atconf"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	"github.com/vertica/vertica-kubernetes/pkg/paths"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GenATConf returns the admintools.conf that the operator would generate from
+// scratch for the pods of the VerticaDB that have an IP.
+func GenATConf(ctx context.Context, clnt client.Client, vdb *vapi.VerticaDB) (string, error) {
+	ips := []string{}
+	for i := range vdb.Spec.Subclusters {
+		sc := &vdb.Spec.Subclusters[i]
+		for j := int32(0); j < sc.Size; j++ {
+			pod := &corev1.Pod{}
+			if err := clnt.Get(ctx, names.GenPodName(vdb, sc, j), pod); err != nil {
+				if kerrors.IsNotFound(err) {
+					continue
+				}
+				return "", err
+			}
+			if pod.Status.PodIP != "" {
+				ips = append(ips, pod.Status.PodIP)
+			}
+		}
+	}
+	if len(ips) == 0 {
+		return "", fmt.Errorf("none of the pods of VerticaDB '%s' have an IP", vdb.Name)
+	}
+	return genATConfForIPs(ctx, vdb, ips)
+}
+
+// genATConfForIPs builds admintools.conf for the given IPs with the same
+// writer the operator uses.
+func genATConfForIPs(ctx context.Context, vdb *vapi.VerticaDB, ips []string) (string, error) {
+	// A blank source pod means the writer never has to exec into a pod
+	w := atconf.MakeFileWriter(logr.Discard(), vdb, nil)
+	fname, err := w.AddHosts(ctx, types.NamespacedName{}, ips)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(fname)
+	contents, err := os.ReadFile(fname)
+	if err != nil {
+		return "", err
+	}
+	return string(contents), nil
+}
+
+// FetchATConf returns the admintools.conf that is currently in a pod
+func FetchATConf(ctx context.Context, prunner cmds.PodRunner, pn types.NamespacedName) (string, error) {
+	stdout, _, err := prunner.ExecInPod(ctx, pn, names.ServerContainer, "cat", paths.AdminToolsConf)
+	return stdout, err
+}
@@ -0,0 +1,36 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vctl
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+)
+
+var _ = Describe("atconf", func() {
+	ctx := context.Background()
+
+	It("should generate admintools.conf with each of the IPs", func() {
+		vdb := vapi.MakeVDB()
+		contents, err := genATConfForIPs(ctx, vdb, []string{"10.1.1.1", "10.1.1.2"})
+		Expect(err).Should(Succeed())
+		Expect(contents).Should(ContainSubstring("hosts = 10.1.1.1,10.1.1.2"))
+		Expect(contents).Should(ContainSubstring("ipv6 = False"))
+	})
+})
@@ -0,0 +1,174 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vctl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/builder"
+	"github.com/vertica/vertica-kubernetes/pkg/cmds"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	"github.com/vertica/vertica-kubernetes/pkg/paths"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// The number of lines we copy from the end of vertica.log in each pod
+	VerticaLogTailLines = 10000
+	// Permissions of the files and directories we create
+	diagDirPerm  = 0o755
+	diagFilePerm = 0o644
+)
+
+// DiagCollector gathers what is needed to debug a VerticaDB into a directory
+type DiagCollector struct {
+	Client    client.Client
+	Clientset kubernetes.Interface
+	PRunner   cmds.PodRunner
+	Vdb       *vapi.VerticaDB
+	// The directory the diagnostics are written to
+	Dir string
+	// Where the progress messages are written to
+	Out io.Writer
+}
+
+// Collect will write the k8s objects of the VerticaDB, the events, the
+// container logs and the vertica logs into the directory.  Failures to get
+// the details of one pod are reported but don't stop the collection.
+func (d *DiagCollector) Collect(ctx context.Context) error {
+	if err := os.MkdirAll(d.Dir, diagDirPerm); err != nil {
+		return err
+	}
+	if err := d.writeYAML("verticadb.yaml", d.Vdb); err != nil {
+		return err
+	}
+	sel := client.MatchingLabels{
+		builder.ManagedByLabel:   builder.OperatorName,
+		builder.VDBInstanceLabel: d.Vdb.Name,
+	}
+	lists := map[string]client.ObjectList{
+		"statefulsets.yaml":           &appsv1.StatefulSetList{},
+		"pods.yaml":                   &corev1.PodList{},
+		"services.yaml":               &corev1.ServiceList{},
+		"persistentvolumeclaims.yaml": &corev1.PersistentVolumeClaimList{},
+		"poddisruptionbudgets.yaml":   &policyv1.PodDisruptionBudgetList{},
+		"networkpolicies.yaml":        &networkingv1.NetworkPolicyList{},
+	}
+	for fname, list := range lists {
+		if err := d.Client.List(ctx, list, client.InNamespace(d.Vdb.Namespace), sel); err != nil {
+			return err
+		}
+		if err := d.writeYAML(fname, list); err != nil {
+			return err
+		}
+	}
+	if err := d.collectEvents(ctx); err != nil {
+		return err
+	}
+
+	pods := lists["pods.yaml"].(*corev1.PodList)
+	for i := range pods.Items {
+		d.collectPod(ctx, &pods.Items[i])
+	}
+	fmt.Fprintf(d.Out, "Diagnostics written to %s\n", d.Dir)
+	return nil
+}
+
+// collectEvents writes the events for the VerticaDB and the objects it owns.
+// All of the objects the operator creates have the name of the VerticaDB as
+// a prefix.
+func (d *DiagCollector) collectEvents(ctx context.Context) error {
+	evs := corev1.EventList{}
+	if err := d.Client.List(ctx, &evs, client.InNamespace(d.Vdb.Namespace)); err != nil {
+		return err
+	}
+	matched := corev1.EventList{}
+	for i := range evs.Items {
+		if strings.HasPrefix(evs.Items[i].InvolvedObject.Name, d.Vdb.Name) {
+			matched.Items = append(matched.Items, evs.Items[i])
+		}
+	}
+	return d.writeYAML("events.yaml", &matched)
+}
+
+// collectPod writes the logs of each container in the pod, along with the
+// admintools.conf and the tail of vertica.log.
+func (d *DiagCollector) collectPod(ctx context.Context, pod *corev1.Pod) {
+	podDir := filepath.Join("pods", pod.Name)
+	for i := range pod.Spec.Containers {
+		cnt := pod.Spec.Containers[i].Name
+		logs, err := d.Clientset.CoreV1().Pods(pod.Namespace).
+			GetLogs(pod.Name, &corev1.PodLogOptions{Container: cnt}).DoRaw(ctx)
+		if err != nil {
+			fmt.Fprintf(d.Out, "Failed to get the logs of container %s in pod %s: %s\n", cnt, pod.Name, err)
+			continue
+		}
+		if err := d.writeFile(filepath.Join(podDir, cnt+".log"), logs); err != nil {
+			fmt.Fprintf(d.Out, "Failed to write the logs of container %s in pod %s: %s\n", cnt, pod.Name, err)
+		}
+	}
+
+	if pod.Status.Phase != corev1.PodRunning {
+		return
+	}
+	pn := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+	podCmds := map[string][]string{
+		"admintools.conf": {"cat", paths.AdminToolsConf},
+		"vertica.log": {"bash", "-c", fmt.Sprintf("tail -n %d %s/%s/*_catalog/vertica.log",
+			VerticaLogTailLines, d.Vdb.Spec.Local.GetCatalogPath(), d.Vdb.Spec.DBName)},
+	}
+	for fname, cmd := range podCmds {
+		stdout, _, err := d.PRunner.ExecInPod(ctx, pn, names.ServerContainer, cmd...)
+		if err != nil {
+			fmt.Fprintf(d.Out, "Failed to copy %s from pod %s: %s\n", fname, pod.Name, err)
+			continue
+		}
+		if err := d.writeFile(filepath.Join(podDir, fname), []byte(stdout)); err != nil {
+			fmt.Fprintf(d.Out, "Failed to write %s of pod %s: %s\n", fname, pod.Name, err)
+		}
+	}
+}
+
+// writeYAML will write the object as YAML to a file in the directory
+func (d *DiagCollector) writeYAML(fname string, obj interface{}) error {
+	contents, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return d.writeFile(fname, contents)
+}
+
+// writeFile will write a file relative to the directory
+func (d *DiagCollector) writeFile(fname string, contents []byte) error {
+	fullPath := filepath.Join(d.Dir, fname)
+	if err := os.MkdirAll(filepath.Dir(fullPath), diagDirPerm); err != nil {
+		return err
+	}
+	return os.WriteFile(fullPath, contents, diagFilePerm)
+}
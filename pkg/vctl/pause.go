@@ -0,0 +1,67 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vctl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MakeObjectForKind returns an empty object for one of the kinds the operator
+// reconciles.  The kind can be given by its name, its short name or in the
+// plural form.
+func MakeObjectForKind(kind string) (client.Object, error) {
+	switch strings.ToLower(kind) {
+	case "verticadb", "verticadbs", "vdb":
+		return &vapi.VerticaDB{}, nil
+	case "verticaautoscaler", "verticaautoscalers", "vas":
+		return &vapi.VerticaAutoscaler{}, nil
+	case "eventtrigger", "eventtriggers", "et":
+		return &vapi.EventTrigger{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported kind '%s': must be one of verticadb, verticaautoscaler or eventtrigger", kind)
+	}
+}
+
+// SetPauseReconcile will add or remove the annotation that tells the operator
+// to stop reconciling the object.  If actors is set, only those parts of the
+// reconcile are paused.
+func SetPauseReconcile(ctx context.Context, clnt client.Client, obj client.Object, nm types.NamespacedName,
+	pause bool, actors []string) error {
+	if err := clnt.Get(ctx, nm, obj); err != nil {
+		return err
+	}
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	anns := obj.GetAnnotations()
+	if pause {
+		if anns == nil {
+			anns = map[string]string{}
+		}
+		anns[vapi.PauseReconcileAnnotation] = "true"
+		if len(actors) > 0 {
+			anns[vapi.PauseReconcileAnnotation] = strings.Join(actors, ",")
+		}
+	} else {
+		delete(anns, vapi.PauseReconcileAnnotation)
+	}
+	obj.SetAnnotations(anns)
+	return clnt.Patch(ctx, obj, patch)
+}
@@ -0,0 +1,203 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vctl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	DefaultRestartTimeout      = 10 * time.Minute
+	DefaultRestartPollInterval = 5 * time.Second
+
+	// The name of the operator actor that restarts vertica in the pods.  It
+	// can be paused on its own with the pause-reconcile annotation.
+	restartActorName = "RestartReconciler"
+)
+
+// RestartOptions control how the rolling restart is done
+type RestartOptions struct {
+	// Only restart the pods of these subclusters.  All subclusters are
+	// restarted if this is empty.
+	Subclusters []string
+	// The amount of time to wait for a single pod to come back
+	Timeout time.Duration
+	// How often we check if the pod came back
+	PollInterval time.Duration
+	// Restart the primary pods even if the database has a k-safety of 0.  This
+	// takes the database down.  The pods are deleted rather than evicted so
+	// that their PodDisruptionBudget doesn't block it.
+	Force bool
+	// Where the progress messages are written to
+	Out io.Writer
+}
+
+// RollingRestart will restart the pods of a VerticaDB one at a time.  Each pod
+// is evicted and we wait for the statefulset to recreate it, and for the
+// operator to restart vertica in it, before moving onto the next pod.  The
+// secondary subclusters are done before the primaries.
+func RollingRestart(ctx context.Context, clnt client.Client, vdb *vapi.VerticaDB, opts *RestartOptions) error {
+	if err := checkCanRestart(vdb, opts); err != nil {
+		return err
+	}
+	pods, err := genRestartOrder(vdb, opts.Subclusters)
+	if err != nil {
+		return err
+	}
+	for _, pn := range pods {
+		if err := restartPod(ctx, clnt, pn, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkCanRestart returns an error if the rolling restart can't succeed, or
+// would take the database down without the user asking for it.
+func checkCanRestart(vdb *vapi.VerticaDB, opts *RestartOptions) error {
+	// The operator restarts vertica in the new pods.  We would wait on the
+	// first pod until we time out if it doesn't.
+	pauseAll, pausedActors := vapi.ParsePauseReconcileAnnotation(vdb.Annotations)
+	if pauseAll || pausedActors[restartActorName] {
+		return fmt.Errorf("the reconcile of VerticaDB '%s' is paused, so the operator will not restart vertica "+
+			"in the pods. Resume it before doing a restart", vdb.Name)
+	}
+	if vdb.Spec.KSafety != vapi.KSafety0 || opts.Force {
+		return nil
+	}
+	for i := range vdb.Spec.Subclusters {
+		sc := &vdb.Spec.Subclusters[i]
+		if sc.IsPrimary && sc.Size > 0 && isSubclusterIncluded(sc, opts.Subclusters) {
+			return fmt.Errorf("VerticaDB '%s' has a k-safety of 0, so restarting a pod of primary subcluster '%s' "+
+				"takes the database down. Use --force to restart it anyway", vdb.Name, sc.Name)
+		}
+	}
+	return nil
+}
+
+// genRestartOrder returns the names of the pods in the order we restart them
+func genRestartOrder(vdb *vapi.VerticaDB, subclusters []string) ([]types.NamespacedName, error) {
+	scMap := vdb.GenSubclusterMap()
+	for _, scName := range subclusters {
+		if _, ok := scMap[scName]; !ok {
+			return nil, fmt.Errorf("subcluster '%s' does not exist in VerticaDB '%s'", scName, vdb.Name)
+		}
+	}
+
+	pods := []types.NamespacedName{}
+	for _, primaries := range []bool{false, true} {
+		for i := range vdb.Spec.Subclusters {
+			sc := &vdb.Spec.Subclusters[i]
+			if sc.IsPrimary != primaries || !isSubclusterIncluded(sc, subclusters) {
+				continue
+			}
+			for j := int32(0); j < sc.Size; j++ {
+				pods = append(pods, names.GenPodName(vdb, sc, j))
+			}
+		}
+	}
+	return pods, nil
+}
+
+// isSubclusterIncluded returns true if the subcluster is part of the restart
+func isSubclusterIncluded(sc *vapi.Subcluster, subclusters []string) bool {
+	if len(subclusters) == 0 {
+		return true
+	}
+	for _, scName := range subclusters {
+		if sc.Name == scName {
+			return true
+		}
+	}
+	return false
+}
+
+// restartPod will evict a single pod and wait for it to be ready again
+func restartPod(ctx context.Context, clnt client.Client, pn types.NamespacedName, opts *RestartOptions) error {
+	pod := &corev1.Pod{}
+	if err := clnt.Get(ctx, pn, pod); err != nil {
+		if kerrors.IsNotFound(err) {
+			fmt.Fprintf(opts.Out, "Skipping pod %s as it does not exist\n", pn.Name)
+			return nil
+		}
+		return err
+	}
+	oldUID := pod.UID
+	fmt.Fprintf(opts.Out, "Restarting pod %s\n", pn.Name)
+	if err := removePod(ctx, clnt, pod, opts); err != nil {
+		return err
+	}
+
+	err := wait.PollImmediate(opts.PollInterval, opts.Timeout, func() (bool, error) {
+		newPod := &corev1.Pod{}
+		if err := clnt.Get(ctx, pn, newPod); err != nil {
+			if kerrors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return newPod.UID != oldUID && isPodReady(newPod), nil
+	})
+	if err != nil {
+		return fmt.Errorf("pod %s did not become ready after it was restarted: %w", pn.Name, err)
+	}
+	fmt.Fprintf(opts.Out, "Pod %s is ready\n", pn.Name)
+	return nil
+}
+
+// removePod will evict the pod so that its PodDisruptionBudget is honored.
+// An eviction that the budget refuses is retried until the timeout.  With
+// the force option, the pod is deleted instead.
+func removePod(ctx context.Context, clnt client.Client, pod *corev1.Pod, opts *RestartOptions) error {
+	if opts.Force {
+		if err := clnt.Delete(ctx, pod); err != nil && !kerrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+	eviction := &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+	}
+	err := wait.PollImmediate(opts.PollInterval, opts.Timeout, func() (bool, error) {
+		err := clnt.SubResource("eviction").Create(ctx, pod, eviction)
+		switch {
+		case err == nil, kerrors.IsNotFound(err):
+			return true, nil
+		case kerrors.IsTooManyRequests(err):
+			// The PodDisruptionBudget doesn't allow the pod to be evicted yet
+			return false, nil
+		default:
+			return false, err
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to evict pod %s: %w", pod.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vctl
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("restart", func() {
+	It("should restart the secondary subclusters before the primaries", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.Subclusters = []vapi.Subcluster{
+			{Name: "pri", Size: 2, IsPrimary: true, ServiceType: corev1.ServiceTypeClusterIP},
+			{Name: "sec", Size: 1, IsPrimary: false, ServiceType: corev1.ServiceTypeClusterIP},
+		}
+		pods, err := genRestartOrder(vdb, nil)
+		Expect(err).Should(Succeed())
+		Expect(pods).Should(Equal([]types.NamespacedName{
+			names.GenPodName(vdb, &vdb.Spec.Subclusters[1], 0),
+			names.GenPodName(vdb, &vdb.Spec.Subclusters[0], 0),
+			names.GenPodName(vdb, &vdb.Spec.Subclusters[0], 1),
+		}))
+	})
+
+	It("should only restart the subclusters that were asked for", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.Subclusters = []vapi.Subcluster{
+			{Name: "pri", Size: 2, IsPrimary: true, ServiceType: corev1.ServiceTypeClusterIP},
+			{Name: "sec", Size: 1, IsPrimary: false, ServiceType: corev1.ServiceTypeClusterIP},
+		}
+		pods, err := genRestartOrder(vdb, []string{"pri"})
+		Expect(err).Should(Succeed())
+		Expect(pods).Should(HaveLen(2))
+
+		_, err = genRestartOrder(vdb, []string{"notthere"})
+		Expect(err).ShouldNot(Succeed())
+	})
+
+	It("should refuse to restart a primary of a k-safety 0 database unless forced", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.KSafety = vapi.KSafety0
+		vdb.Spec.Subclusters = []vapi.Subcluster{
+			{Name: "pri", Size: 1, IsPrimary: true, ServiceType: corev1.ServiceTypeClusterIP},
+			{Name: "sec", Size: 1, IsPrimary: false, ServiceType: corev1.ServiceTypeClusterIP},
+		}
+		Expect(checkCanRestart(vdb, &RestartOptions{})).ShouldNot(Succeed())
+		Expect(checkCanRestart(vdb, &RestartOptions{Subclusters: []string{"sec"}})).Should(Succeed())
+		Expect(checkCanRestart(vdb, &RestartOptions{Force: true})).Should(Succeed())
+		vdb.Spec.KSafety = vapi.KSafety1
+		Expect(checkCanRestart(vdb, &RestartOptions{})).Should(Succeed())
+	})
+
+	It("should fail fast if the reconcile of the VerticaDB is paused", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Annotations[vapi.PauseReconcileAnnotation] = "true"
+		Expect(checkCanRestart(vdb, &RestartOptions{})).ShouldNot(Succeed())
+		vdb.Annotations[vapi.PauseReconcileAnnotation] = "ObjReconciler"
+		Expect(checkCanRestart(vdb, &RestartOptions{})).Should(Succeed())
+		vdb.Annotations[vapi.PauseReconcileAnnotation] = "ObjReconciler,RestartReconciler"
+		Expect(checkCanRestart(vdb, &RestartOptions{})).ShouldNot(Succeed())
+	})
+})
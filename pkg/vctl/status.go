@@ -0,0 +1,257 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vctl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// StatusReport is a consolidated view of a VerticaDB along with its pods and
+// the other custom resources that refer to it.
+type StatusReport struct {
+	Vdb *vapi.VerticaDB
+	// The pods of the VerticaDB, keyed by name.  Pods that don't exist yet
+	// are absent from the map.
+	Pods        map[types.NamespacedName]*corev1.Pod
+	Autoscalers []vapi.VerticaAutoscaler
+	Triggers    []vapi.EventTrigger
+}
+
+// FetchStatusReport will read the VerticaDB and all of the objects that make
+// up its status report.
+func FetchStatusReport(ctx context.Context, clnt client.Client, nm types.NamespacedName) (*StatusReport, error) {
+	vdb := &vapi.VerticaDB{}
+	if err := clnt.Get(ctx, nm, vdb); err != nil {
+		return nil, err
+	}
+	report := &StatusReport{Vdb: vdb, Pods: map[types.NamespacedName]*corev1.Pod{}}
+
+	for i := range vdb.Spec.Subclusters {
+		sc := &vdb.Spec.Subclusters[i]
+		for j := int32(0); j < sc.Size; j++ {
+			pn := names.GenPodName(vdb, sc, j)
+			pod := &corev1.Pod{}
+			if err := clnt.Get(ctx, pn, pod); err != nil {
+				if kerrors.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			report.Pods[pn] = pod
+		}
+	}
+
+	vasList := vapi.VerticaAutoscalerList{}
+	if err := clnt.List(ctx, &vasList, client.InNamespace(vdb.Namespace)); err != nil {
+		return nil, err
+	}
+	for i := range vasList.Items {
+		if vasList.Items[i].Spec.VerticaDBName == vdb.Name {
+			report.Autoscalers = append(report.Autoscalers, vasList.Items[i])
+		}
+	}
+
+	etList := vapi.EventTriggerList{}
+	if err := clnt.List(ctx, &etList, client.InNamespace(vdb.Namespace)); err != nil {
+		return nil, err
+	}
+	for i := range etList.Items {
+		if isTriggerForVDB(&etList.Items[i], vdb) {
+			report.Triggers = append(report.Triggers, etList.Items[i])
+		}
+	}
+	return report, nil
+}
+
+// isTriggerForVDB returns true if the EventTrigger watches the given VerticaDB
+func isTriggerForVDB(et *vapi.EventTrigger, vdb *vapi.VerticaDB) bool {
+	for i := range et.Spec.References {
+		obj := et.Spec.References[i].Object
+		if obj == nil || obj.Kind != vapi.VerticaDBKind || obj.Name != vdb.Name {
+			continue
+		}
+		if obj.Namespace == "" || obj.Namespace == vdb.Namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// Print will write the status report in a human readable form
+func (s *StatusReport) Print(out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	s.printSummary(w)
+	fmt.Fprintln(w)
+	s.printSubclusters(w)
+	fmt.Fprintln(w)
+	s.printPods(w)
+	if len(s.Autoscalers) > 0 {
+		fmt.Fprintln(w)
+		s.printAutoscalers(w)
+	}
+	if len(s.Triggers) > 0 {
+		fmt.Fprintln(w)
+		s.printTriggers(w)
+	}
+	return w.Flush()
+}
+
+// printSummary writes the details that apply to the entire VerticaDB
+func (s *StatusReport) printSummary(w io.Writer) {
+	vdb := s.Vdb
+	mode := "Enterprise"
+	if vdb.IsEON() {
+		mode = "Eon"
+	}
+	fmt.Fprintf(w, "VerticaDB:\t%s/%s\n", vdb.Namespace, vdb.Name)
+	fmt.Fprintf(w, "Database:\t%s (%s)\n", vdb.Spec.DBName, mode)
+	fmt.Fprintf(w, "Image:\t%s\n", vdb.Spec.Image)
+	var expected int32
+	for i := range vdb.Spec.Subclusters {
+		expected += vdb.Spec.Subclusters[i].Size
+	}
+	fmt.Fprintf(w, "Nodes up:\t%d/%d\n", vdb.Status.UpNodeCount, expected)
+	fmt.Fprintf(w, "Conditions:\t%s\n", formatConditions(vdb, vapi.Ready, vapi.Progressing, vapi.Degraded))
+	upgrade := "none"
+	if vdb.IsImageChangeInProgress() {
+		upgrade = vdb.Status.UpgradeStatus
+		if upgrade == "" {
+			upgrade = "in progress"
+		}
+	}
+	fmt.Fprintf(w, "Upgrade:\t%s\n", upgrade)
+	if f := vdb.Status.LastFailure; f != nil {
+		fmt.Fprintf(w, "Last failure:\t%s failed with %s (retries: %d) at %s\n",
+			f.Command, f.Reason, f.RetryCount, f.Time.UTC().Format("2006-01-02T15:04:05Z"))
+		if f.Remediation != "" {
+			fmt.Fprintf(w, "Remediation:\t%s\n", f.Remediation)
+		}
+	}
+}
+
+// formatConditions returns a one line summary of the given conditions
+func formatConditions(vdb *vapi.VerticaDB, condTypes ...vapi.VerticaDBConditionType) string {
+	conds := []string{}
+	for _, ct := range condTypes {
+		inx, ok := vapi.VerticaDBConditionIndexMap[ct]
+		if !ok || inx >= len(vdb.Status.Conditions) {
+			conds = append(conds, fmt.Sprintf("%s=Unknown", ct))
+			continue
+		}
+		c := vdb.Status.Conditions[inx]
+		if c.Reason != "" {
+			conds = append(conds, fmt.Sprintf("%s=%s (%s)", ct, c.Status, c.Reason))
+		} else {
+			conds = append(conds, fmt.Sprintf("%s=%s", ct, c.Status))
+		}
+	}
+	return strings.Join(conds, ", ")
+}
+
+// printSubclusters writes a table with a row for each subcluster
+func (s *StatusReport) printSubclusters(w io.Writer) {
+	scStatusMap := s.genSubclusterStatusMap()
+	fmt.Fprintln(w, "SUBCLUSTER\tTYPE\tSIZE\tINSTALLED\tADDED\tUP\tREAD-ONLY\tSHUTDOWN")
+	for i := range s.Vdb.Spec.Subclusters {
+		sc := &s.Vdb.Spec.Subclusters[i]
+		ss, ok := scStatusMap[sc.Name]
+		if !ok {
+			ss = &vapi.SubclusterStatus{}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%t\n", sc.Name, sc.GetType(), sc.Size,
+			ss.InstallCount, ss.AddedToDBCount, ss.UpNodeCount, ss.ReadOnlyCount, ss.Shutdown)
+	}
+}
+
+// printPods writes a table with a row for each pod of the VerticaDB
+func (s *StatusReport) printPods(w io.Writer) {
+	scStatusMap := s.genSubclusterStatusMap()
+	fmt.Fprintln(w, "POD\tSUBCLUSTER\tPHASE\tREADY\tUP\tVNODE\tIMAGE")
+	for i := range s.Vdb.Spec.Subclusters {
+		sc := &s.Vdb.Spec.Subclusters[i]
+		for j := int32(0); j < sc.Size; j++ {
+			pn := names.GenPodName(s.Vdb, sc, j)
+			phase, ready := "Missing", false
+			if pod, ok := s.Pods[pn]; ok {
+				phase = string(pod.Status.Phase)
+				ready = isPodReady(pod)
+			}
+			podStatus := vapi.VerticaDBPodStatus{}
+			if ss, ok := scStatusMap[sc.Name]; ok && int(j) < len(ss.Detail) {
+				podStatus = ss.Detail[j]
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%t\t%s\t%s\n", pn.Name, sc.Name, phase, ready,
+				podStatus.UpNode, podStatus.VNodeName, podStatus.Image)
+		}
+	}
+}
+
+// printAutoscalers writes a table with a row for each VerticaAutoscaler
+func (s *StatusReport) printAutoscalers(w io.Writer) {
+	fmt.Fprintln(w, "AUTOSCALER\tSERVICE\tGRANULARITY\tTARGET\tCURRENT\tSCALING-COUNT")
+	for i := range s.Autoscalers {
+		vas := &s.Autoscalers[i]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\n", vas.Name, vas.Spec.ServiceName, vas.Spec.ScalingGranularity,
+			vas.Spec.TargetSize, vas.Status.CurrentSize, vas.Status.ScalingCount)
+	}
+}
+
+// printTriggers writes a table with a row for each EventTrigger
+func (s *StatusReport) printTriggers(w io.Writer) {
+	fmt.Fprintln(w, "EVENT TRIGGER\tJOB")
+	for i := range s.Triggers {
+		et := &s.Triggers[i]
+		job := ""
+		for j := range et.Status.References {
+			ref := &et.Status.References[j]
+			if ref.Kind == vapi.VerticaDBKind && ref.Name == s.Vdb.Name && ref.JobName != "" {
+				job = fmt.Sprintf("%s/%s", ref.JobNamespace, ref.JobName)
+				break
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\n", et.Name, job)
+	}
+}
+
+// genSubclusterStatusMap returns the status of each subcluster keyed by name
+func (s *StatusReport) genSubclusterStatusMap() map[string]*vapi.SubclusterStatus {
+	m := map[string]*vapi.SubclusterStatus{}
+	for i := range s.Vdb.Status.Subclusters {
+		m[s.Vdb.Status.Subclusters[i].Name] = &s.Vdb.Status.Subclusters[i]
+	}
+	return m
+}
+
+// isPodReady returns true if the pod has passed its readiness probe
+func isPodReady(pod *corev1.Pod) bool {
+	for i := range pod.Status.Conditions {
+		if pod.Status.Conditions[i].Type == corev1.PodReady {
+			return pod.Status.Conditions[i].Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
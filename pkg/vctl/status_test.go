@@ -0,0 +1,72 @@
+/*
+ (c) Copyright [2021-2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vctl
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	vapi "github.com/vertica/vertica-kubernetes/api/v1beta1"
+	"github.com/vertica/vertica-kubernetes/pkg/names"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("status", func() {
+	It("should print the subclusters, pods and related objects", func() {
+		vdb := vapi.MakeVDB()
+		vdb.Spec.Subclusters[0].Size = 2
+		vdb.Status.UpNodeCount = 1
+		vdb.Status.Conditions = []vapi.VerticaDBCondition{}
+		vdb.Status.Subclusters = []vapi.SubclusterStatus{
+			{Name: vdb.Spec.Subclusters[0].Name, InstallCount: 2, AddedToDBCount: 2, UpNodeCount: 1,
+				Detail: []vapi.VerticaDBPodStatus{{UpNode: true, VNodeName: "v_db_node0001"}, {}}},
+		}
+		vdb.Status.LastFailure = &vapi.MgmtFailure{Command: "restart_node", Reason: "MgmtFailedDiskFull",
+			Remediation: "Free up space", RetryCount: 2}
+		pn := names.GenPodName(vdb, &vdb.Spec.Subclusters[0], 0)
+		report := StatusReport{
+			Vdb: vdb,
+			Pods: map[types.NamespacedName]*corev1.Pod{
+				pn: {Status: corev1.PodStatus{
+					Phase:      corev1.PodRunning,
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+				}},
+			},
+			Autoscalers: []vapi.VerticaAutoscaler{{ObjectMeta: metav1.ObjectMeta{Name: "vas1"}}},
+		}
+
+		out := bytes.Buffer{}
+		Expect(report.Print(&out)).Should(Succeed())
+		Expect(out.String()).Should(ContainSubstring("Nodes up:      1/2"))
+		Expect(out.String()).Should(ContainSubstring("Ready=Unknown"))
+		Expect(out.String()).Should(MatchRegexp("restart_node failed with MgmtFailedDiskFull \\(retries: 2\\)"))
+		Expect(out.String()).Should(MatchRegexp(pn.Name + " +defaultsubcluster +Running +true +true +v_db_node0001"))
+		Expect(out.String()).Should(MatchRegexp("-1 +defaultsubcluster +Missing +false +false"))
+		Expect(out.String()).Should(ContainSubstring("vas1"))
+		Expect(out.String()).ShouldNot(ContainSubstring("EVENT TRIGGER"))
+	})
+
+	It("should only include event triggers that reference the VerticaDB", func() {
+		vdb := vapi.MakeVDB()
+		et := vapi.MakeET()
+		Expect(isTriggerForVDB(et, vdb)).Should(BeTrue())
+		et.Spec.References[0].Object.Name = "other"
+		Expect(isTriggerForVDB(et, vdb)).Should(BeFalse())
+	})
+})
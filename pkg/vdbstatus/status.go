@@ -62,33 +62,59 @@ func Update(ctx context.Context, clnt client.Client, vdb *vapi.VerticaDB, update
 // This is a no-op if the status condition is already set.  The input vdb will
 // be updated with the status condition.
 func UpdateCondition(ctx context.Context, clnt client.Client, vdb *vapi.VerticaDB, condition vapi.VerticaDBCondition) error {
-	if condition.LastTransitionTime.IsZero() {
-		condition.LastTransitionTime = metav1.Now()
-	}
-	// refreshConditionInPlace will update the status condition in vdb.  The update
-	// will be applied in-place.
-	refreshConditionInPlace := func(vdb *vapi.VerticaDB) error {
-		inx, ok := vapi.VerticaDBConditionIndexMap[condition.Type]
-		if !ok {
-			return fmt.Errorf("vertica DB condition '%s' missing from VerticaDBConditionType", condition.Type)
-		}
-		// Ensure the array is big enough
-		for i := len(vdb.Status.Conditions); i <= inx; i++ {
-			vdb.Status.Conditions = append(vdb.Status.Conditions, vapi.VerticaDBCondition{
-				Type:               vapi.VerticaDBConditionNameMap[i],
-				Status:             corev1.ConditionFalse,
-				LastTransitionTime: metav1.Unix(0, 0),
-			})
+	return UpdateConditions(ctx, clnt, vdb, []vapi.VerticaDBCondition{condition})
+}
+
+// UpdateConditions will update multiple status conditions with a single
+// update of the vdb.  The input vdb will be updated with the status conditions.
+func UpdateConditions(ctx context.Context, clnt client.Client, vdb *vapi.VerticaDB, conditions []vapi.VerticaDBCondition) error {
+	now := metav1.Now()
+	for i := range conditions {
+		if conditions[i].LastTransitionTime.IsZero() {
+			conditions[i].LastTransitionTime = now
 		}
-		// Only update if status is different change.  Cannot compare the entire
-		// condition since LastTransitionTime will be different each time.
-		if vdb.Status.Conditions[inx].Status != condition.Status {
-			vdb.Status.Conditions[inx] = condition
+	}
+	// refreshConditionsInPlace will update the status conditions in vdb.  The
+	// update will be applied in-place.
+	refreshConditionsInPlace := func(vdb *vapi.VerticaDB) error {
+		for i := range conditions {
+			if err := SetConditionInPlace(vdb, conditions[i]); err != nil {
+				return err
+			}
 		}
 		return nil
 	}
 
-	return Update(ctx, clnt, vdb, refreshConditionInPlace)
+	return Update(ctx, clnt, vdb, refreshConditionsInPlace)
+}
+
+// SetConditionInPlace will set a status condition in the given vdb.  The
+// LastTransitionTime is only changed if the status of the condition changes.
+func SetConditionInPlace(vdb *vapi.VerticaDB, condition vapi.VerticaDBCondition) error {
+	inx, ok := vapi.VerticaDBConditionIndexMap[condition.Type]
+	if !ok {
+		return fmt.Errorf("vertica DB condition '%s' missing from VerticaDBConditionType", condition.Type)
+	}
+	// Ensure the array is big enough
+	for i := len(vdb.Status.Conditions); i <= inx; i++ {
+		vdb.Status.Conditions = append(vdb.Status.Conditions, vapi.VerticaDBCondition{
+			Type:               vapi.VerticaDBConditionNameMap[i],
+			Status:             corev1.ConditionFalse,
+			LastTransitionTime: metav1.Unix(0, 0),
+		})
+	}
+	// Cannot compare the entire condition since LastTransitionTime will be
+	// different each time.  If only the details changed, we keep the time of
+	// the last transition.
+	cur := &vdb.Status.Conditions[inx]
+	if cur.Status != condition.Status {
+		*cur = condition
+	} else if cur.Reason != condition.Reason || cur.Message != condition.Message ||
+		cur.ObservedGeneration != condition.ObservedGeneration {
+		condition.LastTransitionTime = cur.LastTransitionTime
+		*cur = condition
+	}
+	return nil
 }
 
 // UpdateUpgradeStatus will update the upgrade status message.  The
@@ -99,3 +125,37 @@ func UpdateUpgradeStatus(ctx context.Context, clnt client.Client, vdb *vapi.Vert
 		return nil
 	})
 }
+
+// RecordFailure will save the failure of a management command in the status.
+// If the last failure was for the same command and reason, this is counted as
+// a retry of it.  The input vdb will be updated with the failure.
+func RecordFailure(ctx context.Context, clnt client.Client, vdb *vapi.VerticaDB, failure *vapi.MgmtFailure) error {
+	if failure.Time.IsZero() {
+		failure.Time = metav1.Now()
+	}
+	return Update(ctx, clnt, vdb, func(vdb *vapi.VerticaDB) error {
+		lastFailure := failure.DeepCopy()
+		cur := vdb.Status.LastFailure
+		if cur != nil && cur.Command == failure.Command && cur.Reason == failure.Reason {
+			lastFailure.RetryCount = cur.RetryCount + 1
+		}
+		vdb.Status.LastFailure = lastFailure
+		return nil
+	})
+}
+
+// ClearFailure will remove the last failure of a management command from the
+// status once the command succeeds.  Nothing is changed if the last failure is
+// for a different command.  The input vdb will be updated.
+func ClearFailure(ctx context.Context, clnt client.Client, vdb *vapi.VerticaDB, cmd string) error {
+	// Avoid fetching the vdb in the common case where nothing failed
+	if vdb.Status.LastFailure == nil || vdb.Status.LastFailure.Command != cmd {
+		return nil
+	}
+	return Update(ctx, clnt, vdb, func(vdb *vapi.VerticaDB) error {
+		if vdb.Status.LastFailure != nil && vdb.Status.LastFailure.Command == cmd {
+			vdb.Status.LastFailure = nil
+		}
+		return nil
+	})
+}
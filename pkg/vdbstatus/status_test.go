@@ -191,6 +191,26 @@ var _ = Describe("status", func() {
 		Expect(vdb.Status.Conditions[0].LastTransitionTime).ShouldNot(Equal(origTime))
 	})
 
+	It("should keep the lastTransitionTime when only the reason of a condition changes", func() {
+		vdb := vapi.MakeVDB()
+		Expect(k8sClient.Create(ctx, vdb)).Should(Succeed())
+		defer func() { Expect(k8sClient.Delete(ctx, vdb)).Should(Succeed()) }()
+
+		origTime := metav1.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+		Expect(UpdateConditions(ctx, k8sClient, vdb, []vapi.VerticaDBCondition{
+			{Type: vapi.Ready, Status: corev1.ConditionFalse, Reason: "NodesNotUp", LastTransitionTime: origTime},
+			{Type: vapi.Degraded, Status: corev1.ConditionFalse, Reason: "AsExpected"},
+		})).Should(Succeed())
+		Expect(UpdateCondition(ctx, k8sClient, vdb,
+			vapi.VerticaDBCondition{Type: vapi.Ready, Status: corev1.ConditionFalse, Reason: "DBNotInitialized", Message: "msg"},
+		)).Should(Succeed())
+		cond := vdb.Status.Conditions[vapi.ReadyIndex]
+		Expect(cond.Reason).Should(Equal("DBNotInitialized"))
+		Expect(cond.Message).Should(Equal("msg"))
+		Expect(cond.LastTransitionTime.Time.Equal(origTime.Time)).Should(BeTrue())
+		Expect(vdb.Status.Conditions[vapi.DegradedIndex].Reason).Should(Equal("AsExpected"))
+	})
+
 	It("should return false in IsStatusConditionSet if condition isn't present", func() {
 		vdb := vapi.MakeVDB()
 		Expect(k8sClient.Create(ctx, vdb)).Should(Succeed())
@@ -202,4 +222,38 @@ var _ = Describe("status", func() {
 		)).Should(Succeed())
 		Expect(vdb.IsConditionSet(vapi.VerticaRestartNeeded)).Should(BeTrue())
 	})
+
+	It("should count retries of the same failure", func() {
+		vdb := vapi.MakeVDB()
+		Expect(k8sClient.Create(ctx, vdb)).Should(Succeed())
+		defer func() { Expect(k8sClient.Delete(ctx, vdb)).Should(Succeed()) }()
+
+		Expect(RecordFailure(ctx, k8sClient, vdb, &vapi.MgmtFailure{Command: "restart_node", Reason: "DiskFull"})).Should(Succeed())
+		Expect(vdb.Status.LastFailure).ShouldNot(BeNil())
+		Expect(vdb.Status.LastFailure.RetryCount).Should(Equal(int32(0)))
+		Expect(vdb.Status.LastFailure.Time.IsZero()).Should(BeFalse())
+
+		Expect(RecordFailure(ctx, k8sClient, vdb, &vapi.MgmtFailure{Command: "restart_node", Reason: "DiskFull"})).Should(Succeed())
+		Expect(vdb.Status.LastFailure.RetryCount).Should(Equal(int32(1)))
+
+		Expect(RecordFailure(ctx, k8sClient, vdb, &vapi.MgmtFailure{Command: "start_db", Reason: "DiskFull"})).Should(Succeed())
+		Expect(vdb.Status.LastFailure.Command).Should(Equal("start_db"))
+		Expect(vdb.Status.LastFailure.RetryCount).Should(Equal(int32(0)))
+	})
+
+	It("should only clear the last failure when the same command succeeds", func() {
+		vdb := vapi.MakeVDB()
+		Expect(k8sClient.Create(ctx, vdb)).Should(Succeed())
+		defer func() { Expect(k8sClient.Delete(ctx, vdb)).Should(Succeed()) }()
+
+		Expect(RecordFailure(ctx, k8sClient, vdb, &vapi.MgmtFailure{Command: "restart_node", Reason: "DiskFull"})).Should(Succeed())
+		Expect(ClearFailure(ctx, k8sClient, vdb, "start_db")).Should(Succeed())
+		Expect(vdb.Status.LastFailure).ShouldNot(BeNil())
+
+		Expect(ClearFailure(ctx, k8sClient, vdb, "restart_node")).Should(Succeed())
+		Expect(vdb.Status.LastFailure).Should(BeNil())
+		fetchVdb := &vapi.VerticaDB{}
+		Expect(k8sClient.Get(ctx, vapi.MakeVDBName(), fetchVdb)).Should(Succeed())
+		Expect(fetchVdb.Status.LastFailure).Should(BeNil())
+	})
 })
@@ -65,6 +65,9 @@ do
   perl -i -0777 -pe 's/(.*- mountPath: .*\n.*name: webhook-cert\n.*readOnly:.*)/\{\{- if or (ne .Values.webhook.certSource "internal") (not (empty .Values.webhook.tlsSecret)) \}\}\n$1\n\{\{- end \}\}/g' $fn
   # Update the --webhook-cert-secret option to include the actual name of the secret
   perl -i -0777 -pe 's/(- --webhook-cert-secret=)(.*)/$1\{\{ include "vdb-op.certSecret" . \}\}/g' $fn
+  # Template the cert-manager issuer used when the operator requests the webhook cert
+  sed -i 's/--webhook-cert-issuer-name=.*/--webhook-cert-issuer-name={{ .Values.webhook.certManagerIssuer.name }}/' $fn
+  sed -i 's/--webhook-cert-issuer-kind=.*/--webhook-cert-issuer-kind={{ .Values.webhook.certManagerIssuer.kind }}/' $fn
   # Set ENABLE_WEBHOOK according to webhook.enable value
   perl -i -0777 -pe 's/(name: ENABLE_WEBHOOKS\n.*value:) .*/$1 {{ quote .Values.webhook.enable }}/g' $fn
 done
@@ -76,10 +79,11 @@ do
 done
 # Include WEBHOOK_CERT_SOURCE in the config map
 perl -i -0777 -pe 's/(\ndata:)/$1\n  WEBHOOK_CERT_SOURCE: {{ include "vdb-op.certSource" . }}/g' $TEMPLATE_DIR/verticadb-operator-manager-config-cm.yaml
-# 6. Template the caBundle
+# 6. Template the caBundle and the namespaces the webhook applies to
 for fn in $(ls $TEMPLATE_DIR/*webhookconfiguration.yaml)
 do
   sed -i 's/clientConfig:/clientConfig:\n    caBundle: {{ .Values.webhook.caBundle }}/' $fn
+  perl -i -0777 -pe 's/ *operator: "In"\n *values: \[\{\{ .Release.Namespace \}\}\]/\{\{- include "vdb-op.webhookNamespaceMatch" . | nindent 8 \}\}/g' $fn
 done
 # 7. Template the resource limits and requests
 sed -i 's/resources: template-placeholder/resources:\n          {{- toYaml .Values.resources | nindent 10 }}/' $TEMPLATE_DIR/verticadb-operator-controller-manager-deployment.yaml
@@ -91,10 +95,41 @@ sed -i "s/--maxfileage=.*/--maxfileage={{ .Values.logging.maxFileAge }}/" $TEMPL
 sed -i "s/--maxfilerotation=.*/--maxfilerotation={{ .Values.logging.maxFileRotation }}/" $TEMPLATE_DIR/verticadb-operator-controller-manager-deployment.yaml
 sed -i "s/--level=.*/--level={{ .Values.logging.level }}/" $TEMPLATE_DIR/verticadb-operator-controller-manager-deployment.yaml
 sed -i "s/--dev=.*/--dev={{ .Values.logging.dev }}/" $TEMPLATE_DIR/verticadb-operator-controller-manager-deployment.yaml
+sed -i "s/--pod-facts-workers=.*/--pod-facts-workers={{ .Values.podFacts.workers }}/" $TEMPLATE_DIR/verticadb-operator-controller-manager-deployment.yaml
+sed -i "s/--pod-facts-timeout=.*/--pod-facts-timeout={{ .Values.podFacts.timeout }}/" $TEMPLATE_DIR/verticadb-operator-controller-manager-deployment.yaml
+sed -i "s/--create-prometheus-monitors=.*/--create-prometheus-monitors={{ .Values.prometheus.createMonitors }}/" $TEMPLATE_DIR/verticadb-operator-controller-manager-deployment.yaml
 
 # 9.  Template the serviceaccount, roles and rolebindings
 sed -i 's/serviceAccountName: verticadb-operator-controller-manager/serviceAccountName: {{ include "vdb-op.serviceAccount" . }}/' $TEMPLATE_DIR/verticadb-operator-controller-manager-deployment.yaml
 sed -i 's/--service-account-name=.*/--service-account-name={{ include "vdb-op.serviceAccount" . }}/' $TEMPLATE_DIR/verticadb-operator-controller-manager-deployment.yaml
+sed -i 's/--watch-namespaces=.*/--watch-namespaces={{ .Values.watchNamespaces }}/' $TEMPLATE_DIR/verticadb-operator-controller-manager-deployment.yaml
+# When watching namespaces other than the release namespace, the operator
+# needs the same privileges as the manager Role in each of them. We create a
+# ClusterRole from the manager Role along with a ClusterRoleBinding for it.
+sed -e 's/^kind: Role$/kind: ClusterRole/' \
+    -e '/^  namespace: /d' \
+    -e 's/-manager-role/-{{ .Release.Namespace }}-manager-watch-role/' \
+    $TEMPLATE_DIR/verticadb-operator-manager-role-role.yaml > $TEMPLATE_DIR/verticadb-operator-manager-watch-role-cr.yaml
+cat << EOF > $TEMPLATE_DIR/verticadb-operator-manager-watch-rolebinding-crb.yaml
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: verticadb-operator-{{ .Release.Namespace }}-manager-watch-rolebinding
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: verticadb-operator-{{ .Release.Namespace }}-manager-watch-role
+subjects:
+- kind: ServiceAccount
+  name: {{ include "vdb-op.serviceAccount" . }}
+  namespace: {{ .Release.Namespace }}
+EOF
+for f in verticadb-operator-manager-watch-role-cr.yaml \
+    verticadb-operator-manager-watch-rolebinding-crb.yaml
+do
+    sed -i '1s/^/{{- if and (.Values.watchNamespaces) (not .Values.skipRoleAndRoleBindingCreation) -}}\n/' $TEMPLATE_DIR/$f
+    echo "{{- end }}" >> $TEMPLATE_DIR/$f
+done
 for f in verticadb-operator-controller-manager-sa.yaml
 do
     sed -i '1s/^/{{- if not .Values.serviceAccountNameOverride -}}\n/' $TEMPLATE_DIR/$f